@@ -1,15 +1,188 @@
 package nfsd
 
-import "github.com/swiftstack/onc"
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
 
 // See also consts.go and structs.go for exported constants and structures referenced by this API
 
 // MountV3Interface describes the interface for an object supplied to StartIPv4{TCP|UDP}MountV3Server to enable callbacks
 type MountV3Interface interface {
 	ErrorLog(err error)
-	MountProc3Null(authSysBody *onc.AuthSysBodyStruct)
-	MountProc3Mnt(authSysBody *onc.AuthSysBodyStruct, mountProc3MntArgs *MountProc3MntArgsStruct) (mountProc3MntResults *MountProc3MntResultsStruct)
-	MountProc3Umnt(authSysBody *onc.AuthSysBodyStruct, mountProc3UmntArgs *MountProc3UmntArgsStruct)
+	MountProc3Null(credential *CredentialStruct)
+	MountProc3Mnt(credential *CredentialStruct, mountProc3MntArgs *MountProc3MntArgsStruct) (mountProc3MntResults *MountProc3MntResultsStruct)
+	MountProc3Dump(credential *CredentialStruct) (mountProc3DumpResults *MountProc3DumpResultsStruct)
+	MountProc3Umnt(credential *CredentialStruct, mountProc3UmntArgs *MountProc3UmntArgsStruct)
+	MountProc3UmntAll(credential *CredentialStruct)
+	MountProc3Export(credential *CredentialStruct) (mountProc3ExportResults *MountProc3ExportResultsStruct)
+}
+
+// EnableMountTable instructs Mount V3 servers subsequently launched via StartIPv4{TCP|UDP}MountV3Server to
+// track successful MNT requests (removing them upon UMNT and UMNTALL) such that DUMP requests are answered
+// by this package rather than via MountV3Interface.MountProc3Dump
+//
+// Clients are identified by their IP address together with the MachineName of their AUTH_SYS credential. Where
+// the connection of a request does not report the address of the client, a client is identified by MachineName
+// alone and, lacking that as well (e.g. for AUTH_NONE requests), its mounts are not tracked. Malformed lines
+// of an existing rmtab file are reported via the log package and skipped.
+//
+// Arguments:
+//   rmtabPath specifies the file (e.g. /var/lib/nfs/rmtab) in which the mount table is persisted (or "" if it is not)
+//
+// Returns:
+//   err       is non-nil on failure (e.g. an existing rmtab file could not be read)
+func EnableMountTable(rmtabPath string) (err error) {
+	err = enableMountTable(rmtabPath)
+	return
+}
+
+// DisableMountTable reverts Mount V3 servers subsequently launched to invoking MountV3Interface.MountProc3Dump
+func DisableMountTable() {
+	disableMountTable()
+}
+
+// SetAnonymousIdentity specifies the identity to which requests bearing an AUTH_NONE credential are mapped
+// (in the CredentialStruct supplied to callbacks) by Mount V3 and NFSv3 servers subsequently launched
+//
+// Arguments:
+//   anonUID specifies the UID reported for AUTH_NONE requests (defaults to DefaultAnonUID)
+//   anonGID specifies the GID reported for AUTH_NONE requests (defaults to DefaultAnonGID)
+func SetAnonymousIdentity(anonUID uint32, anonGID uint32) {
+	setAnonymousIdentity(anonUID, anonGID)
+}
+
+// SetIdentityMapping specifies how the CredentialStruct supplied to callbacks is rewritten (e.g. root_squash,
+// all_squash, and UID/GID translation as in exports(5)) by NFSv3 servers subsequently launched. The mapping is
+// applied uniformly to every NFSv3 procedure before the corresponding NFSv3Interface callback is invoked.
+//
+// Arguments:
+//   identityMapping specifies the mapping to apply (or nil if credentials are to be supplied as received)
+func SetIdentityMapping(identityMapping *IdentityMappingStruct) {
+	setIdentityMapping(identityMapping)
+}
+
+// SetReadOnly specifies whether NFSv3 servers subsequently launched serve all file systems read-only. When
+// read-only (either via this setting or via the ReadOnly option of the matching export table entry), SETATTR,
+// WRITE, CREATE, MKDIR, SYMLINK, REMOVE, RMDIR, RENAME, LINK, and COMMIT are answered with NFS3ErrROFS without
+// invoking the corresponding NFSv3Interface callback and Access3{Modify|Extend|Delete} are removed from the
+// Access granted in ACCESS results.
+//
+// Arguments:
+//   readOnly specifies whether or not file systems are served read-only (defaults to false)
+func SetReadOnly(readOnly bool) {
+	setReadOnly(readOnly)
+}
+
+// EnableDuplicateRequestCache instructs NFSv3 servers subsequently launched to share a duplicate request cache.
+// Retransmissions of SETATTR, CREATE, MKDIR, SYMLINK, REMOVE, RMDIR, RENAME, and LINK calls (matched by client
+// IP address, xid, procedure, and a checksum of the arguments) are answered with the reply to the original call
+// rather than invoking the corresponding NFSv3Interface callback again (which might, for example, answer a
+// retried REMOVE with NFS3ErrNOENT). Retransmissions received while the original call is still in progress are
+// dropped.
+//
+// Arguments:
+//   maxEntries specifies the number of replies retained (if 0, DefaultDRCMaxEntries)
+//   maxAge     specifies the duration for which replies are retained (if 0, DefaultDRCMaxAge)
+func EnableDuplicateRequestCache(maxEntries int, maxAge time.Duration) {
+	enableDuplicateRequestCache(maxEntries, maxAge)
+}
+
+// DisableDuplicateRequestCache reverts NFSv3 servers subsequently launched to executing every retransmitted call
+func DisableDuplicateRequestCache() {
+	disableDuplicateRequestCache()
+}
+
+// DuplicateRequestCacheStats reports the counters of the duplicate request cache established by the most recent
+// call to EnableDuplicateRequestCache
+//
+// Returns:
+//   drcStats is zero if the cache is disabled
+func DuplicateRequestCacheStats() (drcStats DRCStatsStruct) {
+	drcStats = duplicateRequestCacheStats()
+	return
+}
+
+// SetExportTable specifies the exports (and per-client access rules) enforced by Mount V3 and NFSv3 servers
+// subsequently launched. MNT requests are admitted only for a DirPath within an export for which the client
+// matches one of the export's client patterns (and EXPORT requests are answered from the table rather than via
+// MountV3Interface.MountProc3Export). Every file handle returned to clients is prefixed by an ID of the export
+// to which it belongs (such that callbacks must return handles no longer than FHSize3-4 bytes) and the prefix
+// is removed before handles are supplied to callbacks. Each NFSv3 request is checked against the export to which
+// its file handle(s) belong and the export's IdentityMapping is applied in place of that set by SetIdentityMapping.
+// Where the matching client's options specify Secure, requests from source ports >= 1024 are rejected (MNT with
+// MNT3ErrACCES and NFSv3 procedures with an RPC auth_stat of AUTH_TOOWEAK).
+//
+// Arguments:
+//   exportTable specifies the exports to enforce (or nil to revert to admitting all requests)
+//
+// Returns:
+//   err         is non-nil on failure (e.g. a client pattern references an undefined netgroup)
+func SetExportTable(exportTable *ExportTableStruct) (err error) {
+	err = setExportTable(exportTable)
+	return
+}
+
+// ParseExportTable parses an export table in the syntax of the Linux /etc/exports file (see exports(5)) with
+// client patterns of the form "@netgroup" resolved against an optional file in the syntax of /etc/netgroup
+//
+// Arguments:
+//   exportsReader  supplies the contents of an exports(5) file
+//   netgroupReader supplies the contents of a netgroup(5) file (or nil if none)
+//
+// Returns:
+//   exportTable    is the parsed export table (suitable for passing to SetExportTable)
+//   err            is non-nil on failure (e.g. an unrecognized option)
+func ParseExportTable(exportsReader io.Reader, netgroupReader io.Reader) (exportTable *ExportTableStruct, err error) {
+	exportTable, err = parseExportTable(exportsReader, netgroupReader)
+	return
+}
+
+// DefaultExportOptions returns the options applied to an export's clients absent any options (i.e. as in
+// exports(5): ro, secure, sync, root_squash, anonuid=DefaultAnonUID, anongid=DefaultAnonGID, and sec=sys)
+func DefaultExportOptions() (exportOptions ExportOptionsStruct) {
+	exportOptions = defaultExportOptions()
+	return
+}
+
+// GSSMechanismInterface describes the server side of a GSS-API mechanism (e.g. Kerberos V5) supplied to
+// EnableRPCSecGSS. AcceptSecContext is called for each RPCSEC_GSS_INIT (with a nil gssContext) and each
+// subsequent RPCSEC_GSS_CONTINUE_INIT (with the gssContext previously returned) until complete is returned
+// as true. A non-nil err aborts context establishment (any outputToken is still returned to the client).
+type GSSMechanismInterface interface {
+	AcceptSecContext(gssContext GSSContextInterface, inputToken []byte) (acceptedGSSContext GSSContextInterface, outputToken []byte, complete bool, err error)
+}
+
+// GSSContextInterface describes an established GSS-API security context returned by GSSMechanismInterface
+type GSSContextInterface interface {
+	Principal() (principal string)                     // name of the authenticated client (e.g. "alice@EXAMPLE.COM")
+	GetMIC(message []byte) (mic []byte, err error)     // computes a checksum of message (GSS_GetMIC)
+	VerifyMIC(message []byte, mic []byte) (err error)  // verifies a checksum of message (GSS_VerifyMIC)
+	Wrap(message []byte) (wrapped []byte, err error)   // encrypts (and checksums) message (GSS_Wrap with conf_req_flag)
+	Unwrap(wrapped []byte) (message []byte, err error) // reverses Wrap (GSS_Unwrap)
+}
+
+// GSSPrincipalMapper maps an authenticated RPCSEC_GSS principal to the identity reported in the
+// CredentialStruct supplied to callbacks. If ok is returned as false, the anonymous identity is reported.
+type GSSPrincipalMapper func(principal string) (uid uint32, gid uint32, gids []uint32, ok bool)
+
+// EnableRPCSecGSS instructs Mount V3 and NFSv3 servers subsequently launched to accept RPCSEC_GSS (RFC 2203)
+// credentials (in addition to AUTH_NONE and AUTH_SYS) with any of the RPCGSSSvc{None|Integrity|Privacy}
+// service levels. The CredentialStruct supplied to callbacks for such requests reports the Principal.
+//
+// Arguments:
+//   mechanism    specifies the GSS-API mechanism used to establish and apply security contexts
+//   mapPrincipal specifies how principals are mapped to UID/GID/GIDs (or nil if all map to the anonymous identity)
+func EnableRPCSecGSS(mechanism GSSMechanismInterface, mapPrincipal GSSPrincipalMapper) {
+	enableRPCSecGSS(mechanism, mapPrincipal)
+}
+
+// DisableRPCSecGSS reverts Mount V3 and NFSv3 servers subsequently launched to rejecting RPCSEC_GSS credentials
+func DisableRPCSecGSS() {
+	disableRPCSecGSS()
 }
 
 // StartIPv4TCPMountV3Server launches a Mount V3 server on the specified IPv4 TCP Port
@@ -70,30 +243,375 @@ func StopIPv4UDPMountV3Server(port uint16, unpublish bool) (unpublished bool, er
 	return
 }
 
+// StartMountV3Server launches a Mount V3 server via IPv4, IPv6, or both (i.e. dual-stack)
+//
+// Arguments:
+//   network   specifies "tcp" or "udp" (dual-stack), "tcp4" or "udp4" (IPv4 only), or "tcp6" or "udp6" (IPv6 only)
+//   bindAddr  specifies the IP address upon which to listen (or "" for all addresses)
+//   port      specifies the port # upon which to serve Mount V3
+//   publish   indicates whether or not to publish the Mount V3 server via rpcbind (versions 4 & 3)
+//   callbacks specifies the receiver of the API "up calls" as listed in MountV3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartMountV3Server(network string, bindAddr string, port uint16, publish bool, callbacks MountV3Interface) (published bool, err error) {
+	published, err = startMountV3Server(network, bindAddr, port, publish, callbacks)
+	return
+}
+
+// StopMountV3Server stops a Mount V3 server launched via StartMountV3Server
+//
+// Arguments:
+//   network   specifies the network supplied to StartMountV3Server
+//   bindAddr  specifies the bindAddr supplied to StartMountV3Server
+//   port      specifies the port # upon which Mount V3 servicing should be halted
+//   unpublish indicates whether or not to remove a previously published Mount V3 server via rpcbind
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is non-nil on failure (but unpublished is valid either way)
+func StopMountV3Server(network string, bindAddr string, port uint16, unpublish bool) (unpublished bool, err error) {
+	unpublished, err = stopMountV3Server(network, bindAddr, port, unpublish)
+	return
+}
+
+// StartMountV3ServerOnListener launches a Mount V3 server accepting connections from a listener supplied by the
+// caller (e.g. one inherited via systemd socket activation or a Unix-domain listener). The listener is closed
+// once the server is stopped.
+//
+// Arguments:
+//   listener  specifies the (TCP or Unix-domain stream) listener from which to accept connections
+//   publish   indicates whether or not to publish the Mount V3 server via rpcbind (versions 4 & 3)
+//   callbacks specifies the receiver of the API "up calls" as listed in MountV3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartMountV3ServerOnListener(listener net.Listener, publish bool, callbacks MountV3Interface) (published bool, err error) {
+	published, err = startMountV3ServerOn(listener, nil, publish, callbacks)
+	return
+}
+
+// StartMountV3ServerOnPacketConn launches a Mount V3 server receiving datagrams via a packetConn supplied by the
+// caller. The packetConn is closed once the server is stopped.
+//
+// Arguments:
+//   packetConn specifies the (UDP or Unix-domain datagram) socket via which to receive calls and send replies
+//   publish    indicates whether or not to publish the Mount V3 server via rpcbind (versions 4 & 3)
+//   callbacks  specifies the receiver of the API "up calls" as listed in MountV3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartMountV3ServerOnPacketConn(packetConn net.PacketConn, publish bool, callbacks MountV3Interface) (published bool, err error) {
+	published, err = startMountV3ServerOn(nil, packetConn, publish, callbacks)
+	return
+}
+
+// StopMountV3ServerOn stops a Mount V3 server launched via StartMountV3ServerOn{Listener|PacketConn}
+//
+// Arguments:
+//   addr      specifies the address of the listener (i.e. listener.Addr()) or packetConn (i.e. packetConn.LocalAddr())
+//   unpublish indicates whether or not to remove a previously published Mount V3 server via rpcbind
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is non-nil on failure (but unpublished is valid either way)
+func StopMountV3ServerOn(addr net.Addr, unpublish bool) (unpublished bool, err error) {
+	unpublished, err = stopMountV3ServerOn(addr, unpublish)
+	return
+}
+
 // NFSv3Interface describes the interface for an object supplied to StartIPv4{TCP|UDP}NFSv3Server to enable callbacks
 type NFSv3Interface interface {
 	ErrorLog(err error)
-	NFSProc3Null(authSysBody *onc.AuthSysBodyStruct)
-	NFSProc3GetAttr(authSysBody *onc.AuthSysBodyStruct, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct)
-	NFSProc3SetAttr(authSysBody *onc.AuthSysBodyStruct, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct)
-	NFSProc3Lookup(authSysBody *onc.AuthSysBodyStruct, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct)
-	NFSProc3Access(authSysBody *onc.AuthSysBodyStruct, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct)
-	NFSProc3ReadLink(authSysBody *onc.AuthSysBodyStruct, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct)
-	NFSProc3Read(authSysBody *onc.AuthSysBodyStruct, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct)
-	NFSProc3Write(authSysBody *onc.AuthSysBodyStruct, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct)
-	NFSProc3Create(authSysBody *onc.AuthSysBodyStruct, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct)
-	NFSProc3MKDir(authSysBody *onc.AuthSysBodyStruct, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct)
-	NFSProc3SymLink(authSysBody *onc.AuthSysBodyStruct, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct)
-	NFSProc3Remove(authSysBody *onc.AuthSysBodyStruct, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct)
-	NFSProc3RMDir(authSysBody *onc.AuthSysBodyStruct, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct)
-	NFSProc3Rename(authSysBody *onc.AuthSysBodyStruct, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct)
-	NFSProc3Link(authSysBody *onc.AuthSysBodyStruct, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct)
-	NFSProc3ReadDir(authSysBody *onc.AuthSysBodyStruct, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct)
-	NFSProc3ReadDirPlus(authSysBody *onc.AuthSysBodyStruct, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct)
-	NFSProc3FSStat(authSysBody *onc.AuthSysBodyStruct, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct)
-	NFSProc3FSInfo(authSysBody *onc.AuthSysBodyStruct, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct)
-	NFSProc3PathConf(authSysBody *onc.AuthSysBodyStruct, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct)
-	NFSProc3Commit(authSysBody *onc.AuthSysBodyStruct, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct)
+	NFSProc3Null(credential *CredentialStruct)
+	NFSProc3GetAttr(credential *CredentialStruct, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct)
+	NFSProc3SetAttr(credential *CredentialStruct, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct)
+	NFSProc3Lookup(credential *CredentialStruct, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct)
+	NFSProc3Access(credential *CredentialStruct, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct)
+	NFSProc3ReadLink(credential *CredentialStruct, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct)
+	NFSProc3Read(credential *CredentialStruct, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct)
+	NFSProc3Write(credential *CredentialStruct, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct)
+	NFSProc3Create(credential *CredentialStruct, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct)
+	NFSProc3MKDir(credential *CredentialStruct, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct)
+	NFSProc3SymLink(credential *CredentialStruct, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct)
+	NFSProc3Remove(credential *CredentialStruct, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct)
+	NFSProc3RMDir(credential *CredentialStruct, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct)
+	NFSProc3Rename(credential *CredentialStruct, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct)
+	NFSProc3Link(credential *CredentialStruct, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct)
+	NFSProc3ReadDir(credential *CredentialStruct, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct)
+	NFSProc3ReadDirPlus(credential *CredentialStruct, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct)
+	NFSProc3FSStat(credential *CredentialStruct, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct)
+	NFSProc3FSInfo(credential *CredentialStruct, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct)
+	NFSProc3PathConf(credential *CredentialStruct, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct)
+	NFSProc3Commit(credential *CredentialStruct, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct)
+}
+
+// NFSv3ContextInterface is the context-aware successor to NFSv3Interface. Each callback is supplied a context
+// conveying the metadata of the request (see RequestInfoFromContext) that, should the request have been received
+// via a TCP connection, is cancelled once the connection has been closed (or the server stopped). An existing
+// NFSv3Interface may be adapted via NewNFSv3ContextAdapter.
+type NFSv3ContextInterface interface {
+	ErrorLog(err error)
+	NFSProc3Null(ctx context.Context)
+	NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct)
+	NFSProc3SetAttr(ctx context.Context, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct)
+	NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct)
+	NFSProc3Access(ctx context.Context, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct)
+	NFSProc3ReadLink(ctx context.Context, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct)
+	NFSProc3Read(ctx context.Context, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct)
+	NFSProc3Write(ctx context.Context, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct)
+	NFSProc3Create(ctx context.Context, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct)
+	NFSProc3MKDir(ctx context.Context, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct)
+	NFSProc3SymLink(ctx context.Context, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct)
+	NFSProc3Remove(ctx context.Context, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct)
+	NFSProc3RMDir(ctx context.Context, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct)
+	NFSProc3Rename(ctx context.Context, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct)
+	NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct)
+	NFSProc3ReadDir(ctx context.Context, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct)
+	NFSProc3ReadDirPlus(ctx context.Context, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct)
+	NFSProc3FSStat(ctx context.Context, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct)
+	NFSProc3FSInfo(ctx context.Context, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct)
+	NFSProc3PathConf(ctx context.Context, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct)
+	NFSProc3Commit(ctx context.Context, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct)
+}
+
+// NFSv3BackendInterface is the least a backend supplied via ServerConfigStruct.NFSContextCallbacks must implement.
+// Each remaining NFSv3ContextInterface callback is discovered via the optional interfaces that follow. Should a
+// backend not implement one, the corresponding procedure is answered without invoking the backend:
+//
+//   NULL, ACCESS, FSINFO, & PATHCONF are answered with defaults (ACCESS grants whatever access was requested)
+//   procedures that would modify the file system are answered with NFS3ErrROFS if the backend implements none
+//     of NFSv3AttrSetterInterface, NFSv3WriterInterface, NFSv3CreatorInterface, NFSv3SymLinkerInterface,
+//     NFSv3RemoverInterface, NFSv3RenamerInterface, or NFSv3LinkerInterface (and NFS3ErrNOTSUPP otherwise)
+//   all other procedures are answered with NFS3ErrNOTSUPP
+//
+// The FSF3Link & FSF3SymLink bits of FSINFO's Properties (as well as PATHCONF's LinkMax) reflect whether or not
+// the backend implements NFSv3LinkerInterface & NFSv3SymLinkerInterface.
+type NFSv3BackendInterface interface {
+	ErrorLog(err error)
+	NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct)
+	NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct)
+}
+
+// NFSv3NullInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3NullInterface interface {
+	NFSProc3Null(ctx context.Context)
+}
+
+// NFSv3AccessInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3AccessInterface interface {
+	NFSProc3Access(ctx context.Context, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct)
+}
+
+// NFSv3AttrSetterInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3AttrSetterInterface interface {
+	NFSProc3SetAttr(ctx context.Context, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct)
+}
+
+// NFSv3ReadLinkerInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3ReadLinkerInterface interface {
+	NFSProc3ReadLink(ctx context.Context, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct)
+}
+
+// NFSv3ReaderInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3ReaderInterface interface {
+	NFSProc3Read(ctx context.Context, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct)
+}
+
+// NFSv3WriterInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3WriterInterface interface {
+	NFSProc3Write(ctx context.Context, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct)
+	NFSProc3Commit(ctx context.Context, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct)
+}
+
+// NFSv3CreatorInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3CreatorInterface interface {
+	NFSProc3Create(ctx context.Context, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct)
+	NFSProc3MKDir(ctx context.Context, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct)
+}
+
+// NFSv3SymLinkerInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3SymLinkerInterface interface {
+	NFSProc3SymLink(ctx context.Context, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct)
+}
+
+// NFSv3RemoverInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3RemoverInterface interface {
+	NFSProc3Remove(ctx context.Context, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct)
+	NFSProc3RMDir(ctx context.Context, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct)
+}
+
+// NFSv3RenamerInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3RenamerInterface interface {
+	NFSProc3Rename(ctx context.Context, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct)
+}
+
+// NFSv3LinkerInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3LinkerInterface interface {
+	NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct)
+}
+
+// NFSv3DirReaderInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3DirReaderInterface interface {
+	NFSProc3ReadDir(ctx context.Context, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct)
+}
+
+// NFSv3DirPlusReaderInterface is optionally implemented by an NFSv3BackendInterface (clients typically fall
+// back to READDIR should READDIRPLUS be answered with NFS3ErrNOTSUPP)
+type NFSv3DirPlusReaderInterface interface {
+	NFSProc3ReadDirPlus(ctx context.Context, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct)
+}
+
+// NFSv3FSStatInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3FSStatInterface interface {
+	NFSProc3FSStat(ctx context.Context, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct)
+}
+
+// NFSv3FSInfoInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3FSInfoInterface interface {
+	NFSProc3FSInfo(ctx context.Context, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct)
+}
+
+// NFSv3PathConfInterface is optionally implemented by an NFSv3BackendInterface
+type NFSv3PathConfInterface interface {
+	NFSProc3PathConf(ctx context.Context, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct)
+}
+
+// FileSystemInterface is a simpler alternative to implementing NFSv3ContextInterface (see NewFileSystemAdapter)
+// expressed in terms of inode numbers rather than file handles. The package mints & decodes the file handles
+// (embedding the fsid supplied to NewFileSystemAdapter along with the inode number & its Generation), detects
+// stale handles, and translates each callback's results into the corresponding NFSProc3* results struct
+// (including the pre- & post-operation attributes obtained via GetAttr). Each callback returns OK or an nfsstat3
+// (e.g. NFS3ErrNOENT or NFS3ErrNOTEMPTY) and may obtain the requester via RequestInfoFromContext.
+//
+// GetAttr is invoked to resolve every file handle received. Should it return NFS3ErrNOENT or NFS3ErrSTALE (or a
+// Generation differing from that of the handle), the handle is answered with NFS3ErrSTALE.
+//
+// Lookup must resolve ".." (the adapter resolves "." itself). ReadAt returns the number of bytes read into buf
+// along with whether the end of the file was reached. ReadDir returns the entries (in a stable order) following
+// the one whose Cookie is cookie (0 specifying the start of the directory) along with whether these include the
+// last entry. Should a directory be modified, previously returned cookies must continue to identify the same
+// position (the cookie verifier is always 0). The adapter trims entries to fit the client's count.
+//
+// Procedures that would modify the file system are answered with NFS3ErrROFS should the file system implement
+// none of the optional interfaces that follow (and NFS3ErrNOTSUPP should it implement some but not the one
+// required). READLINK & FSSTAT are answered with NFS3ErrNOTSUPP unless FileSystemReadLinkerInterface &
+// FileSystemStatterInterface respectively are implemented. NULL, ACCESS (evaluated against the Mode, UID, &
+// GID of the inode), FSINFO, & PATHCONF are answered by the adapter.
+type FileSystemInterface interface {
+	ErrorLog(err error)
+	GetAttr(ctx context.Context, ino uint64) (attr *FileSystemAttrStruct, status uint32)
+	Lookup(ctx context.Context, dirIno uint64, name string) (ino uint64, status uint32)
+	ReadAt(ctx context.Context, ino uint64, buf []byte, offset uint64) (count int, eof bool, status uint32)
+	ReadDir(ctx context.Context, dirIno uint64, cookie uint64) (entries []FileSystemDirEntryStruct, eof bool, status uint32)
+}
+
+// FileSystemWriterInterface is optionally implemented by a FileSystemInterface. As WRITE is answered with
+// FileSync, data must be durable upon return from WriteAt.
+type FileSystemWriterInterface interface {
+	WriteAt(ctx context.Context, ino uint64, data []byte, offset uint64) (count int, status uint32)
+}
+
+// FileSystemAttrSetterInterface is optionally implemented by a FileSystemInterface
+type FileSystemAttrSetterInterface interface {
+	SetAttr(ctx context.Context, ino uint64, sAttr3 *SAttr3Struct) (status uint32)
+}
+
+// FileSystemCreatorInterface is optionally implemented by a FileSystemInterface. Create & MKDir must return
+// NFS3ErrEXIST should name already exist. An exclusive CREATE is recorded by setting the ATime & MTime (to whole
+// seconds) of the new file.
+type FileSystemCreatorInterface interface {
+	Create(ctx context.Context, dirIno uint64, name string, sAttr3 *SAttr3Struct) (ino uint64, status uint32)
+	MKDir(ctx context.Context, dirIno uint64, name string, sAttr3 *SAttr3Struct) (ino uint64, status uint32)
+}
+
+// FileSystemRemoverInterface is optionally implemented by a FileSystemInterface
+type FileSystemRemoverInterface interface {
+	Remove(ctx context.Context, dirIno uint64, name string) (status uint32)
+	RMDir(ctx context.Context, dirIno uint64, name string) (status uint32)
+}
+
+// FileSystemRenamerInterface is optionally implemented by a FileSystemInterface
+type FileSystemRenamerInterface interface {
+	Rename(ctx context.Context, fromDirIno uint64, fromName string, toDirIno uint64, toName string) (status uint32)
+}
+
+// FileSystemLinkerInterface is optionally implemented by a FileSystemInterface
+type FileSystemLinkerInterface interface {
+	Link(ctx context.Context, ino uint64, dirIno uint64, name string) (status uint32)
+}
+
+// FileSystemSymLinkerInterface is optionally implemented by a FileSystemInterface
+type FileSystemSymLinkerInterface interface {
+	SymLink(ctx context.Context, dirIno uint64, name string, target string, sAttr3 *SAttr3Struct) (ino uint64, status uint32)
+}
+
+// FileSystemReadLinkerInterface is optionally implemented by a FileSystemInterface
+type FileSystemReadLinkerInterface interface {
+	ReadLink(ctx context.Context, ino uint64) (target string, status uint32)
+}
+
+// FileSystemStatterInterface is optionally implemented by a FileSystemInterface
+type FileSystemStatterInterface interface {
+	StatFS(ctx context.Context) (tBytes uint64, fBytes uint64, tFiles uint64, fFiles uint64, status uint32)
+}
+
+// NewFileSystemAdapter returns both the NFSv3ContextInterface & MountV3Interface callbacks serving a
+// FileSystemInterface (see FileSystemInterface)
+//
+// Arguments:
+//   fileSystem specifies the receiver of the API "up calls" as listed in FileSystemInterface
+//   rootIno    specifies the inode number of the root directory (as returned by MNT of "/")
+//   fsid       is embedded in each file handle (such that handles of another file system are answered with
+//              NFS3ErrSTALE) and reported in the FSID of each FAttr3Struct
+//
+// Returns:
+//   contextCallbacks satisfies NFSv3ContextInterface by invoking fileSystem
+//   mountCallbacks   satisfies MountV3Interface by resolving DirPath via fileSystem's Lookup
+func NewFileSystemAdapter(fileSystem FileSystemInterface, rootIno uint64, fsid uint64) (contextCallbacks NFSv3ContextInterface, mountCallbacks MountV3Interface) {
+	contextCallbacks, mountCallbacks = newFileSystemAdapter(fileSystem, rootIno, fsid)
+	return
+}
+
+// NewNFSv3ContextAdapter returns an NFSv3ContextInterface invoking the callbacks of an NFSv3Interface (supplying
+// each the Credential of the RequestInfoStruct conveyed via its context)
+//
+// Arguments:
+//   callbacks specifies the receiver of the API "up calls" as listed in NFSv3Interface
+//
+// Returns:
+//   contextCallbacks satisfies NFSv3ContextInterface by invoking callbacks
+func NewNFSv3ContextAdapter(callbacks NFSv3Interface) (contextCallbacks NFSv3ContextInterface) {
+	contextCallbacks = newNFSv3ContextAdapter(callbacks)
+	return
+}
+
+// RequestInfoFromContext returns the metadata of the request on whose behalf an NFSv3ContextInterface callback
+// was invoked
+//
+// Arguments:
+//   ctx is the context supplied to the callback
+//
+// Returns:
+//   requestInfo is the metadata of the request (or nil if ctx was not supplied to a callback)
+func RequestInfoFromContext(ctx context.Context) (requestInfo *RequestInfoStruct) {
+	requestInfo = requestInfoFromContext(ctx)
+	return
+}
+
+// NFSTime returns the NFSTime3Struct reporting a time (e.g. in an FAttr3Struct or WCCAttrStruct)
+//
+// Arguments:
+//   t is the time to report
+//
+// Returns:
+//   nfsTime3 is t truncated to the seconds & nanoseconds representable by struct nfstime3
+func NFSTime(t time.Time) (nfsTime3 NFSTime3Struct) {
+	nfsTime3 = nfsTime(t)
+	return
 }
 
 // StartIPv4TCPNFSv3Server launches an NFSv3 server on the specified IPv4 TCP Port
@@ -153,3 +671,209 @@ func StopIPv4UDPNFSv3Server(port uint16, unpublish bool) (unpublished bool, err
 	unpublished, err = stopIPv4UDPNFSv3Server(port, unpublish)
 	return
 }
+
+// DrainIPv4TCPNFSv3Server gracefully stops an NFSv3 server. No further connections are accepted and, once the
+// callbacks in flight have returned and their replies have been sent, the server is stopped. In the meantime,
+// requests received via existing connections are either ignored (such that clients will retransmit them) or,
+// if jukebox is set, answered with NFS3ErrJUKEBOX (such that clients will retry them after a delay).
+//
+// Arguments:
+//   ctx       bounds the time allowed for callbacks in flight to return (after which connections are closed at once)
+//   port      specifies the TCP port # upon which NFSv3 servicing via IPv4 should be halted
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via portmapper/rpcbind
+//   jukebox   indicates whether or not to answer requests received while draining with NFS3ErrJUKEBOX
+//
+// Returns:
+//   unpublished indicates whether or not portmapper/rpcbind successfully unregistered the program:version:port tuple
+//   err         is ctx.Err() if ctx is done before callbacks in flight have returned, else non-nil on failure
+func DrainIPv4TCPNFSv3Server(ctx context.Context, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	unpublished, err = drainIPv4TCPNFSv3Server(ctx, port, unpublish, jukebox)
+	return
+}
+
+// DrainIPv4UDPNFSv3Server gracefully stops an NFSv3 server (see DrainIPv4TCPNFSv3Server)
+//
+// Arguments:
+//   ctx       bounds the time allowed for callbacks in flight to return (after which the server is stopped at once)
+//   port      specifies the UDP port # upon which NFSv3 servicing via IPv4 should be halted
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via portmapper/rpcbind
+//   jukebox   indicates whether or not to answer requests received while draining with NFS3ErrJUKEBOX
+//
+// Returns:
+//   unpublished indicates whether or not portmapper/rpcbind successfully unregistered the program:version:port tuple
+//   err         is ctx.Err() if ctx is done before callbacks in flight have returned, else non-nil on failure
+func DrainIPv4UDPNFSv3Server(ctx context.Context, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	unpublished, err = drainIPv4UDPNFSv3Server(ctx, port, unpublish, jukebox)
+	return
+}
+
+// StartNFSv3Server launches an NFSv3 server via IPv4, IPv6, or both (i.e. dual-stack)
+//
+// Arguments:
+//   network   specifies "tcp" or "udp" (dual-stack), "tcp4" or "udp4" (IPv4 only), or "tcp6" or "udp6" (IPv6 only)
+//   bindAddr  specifies the IP address upon which to listen (or "" for all addresses)
+//   port      specifies the port # upon which to serve NFSv3
+//   publish   indicates whether or not to publish the NFSv3 server via rpcbind (versions 4 & 3)
+//   callbacks specifies the receiver of the API "up calls" as listed in NFSv3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartNFSv3Server(network string, bindAddr string, port uint16, publish bool, callbacks NFSv3Interface) (published bool, err error) {
+	published, err = startNFSv3Server(network, bindAddr, port, publish, callbacks)
+	return
+}
+
+// StopNFSv3Server stops an NFSv3 server launched via StartNFSv3Server
+//
+// Arguments:
+//   network   specifies the network supplied to StartNFSv3Server
+//   bindAddr  specifies the bindAddr supplied to StartNFSv3Server
+//   port      specifies the port # upon which NFSv3 servicing should be halted
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via rpcbind
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is non-nil on failure (but unpublished is valid either way)
+func StopNFSv3Server(network string, bindAddr string, port uint16, unpublish bool) (unpublished bool, err error) {
+	unpublished, err = stopNFSv3Server(network, bindAddr, port, unpublish)
+	return
+}
+
+// DrainNFSv3Server gracefully stops an NFSv3 server launched via StartNFSv3Server (see DrainIPv4TCPNFSv3Server)
+//
+// Arguments:
+//   ctx       bounds the time allowed for callbacks in flight to return (after which the server is stopped at once)
+//   network   specifies the network supplied to StartNFSv3Server
+//   bindAddr  specifies the bindAddr supplied to StartNFSv3Server
+//   port      specifies the port # upon which NFSv3 servicing should be halted
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via rpcbind
+//   jukebox   indicates whether or not to answer requests received while draining with NFS3ErrJUKEBOX
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is ctx.Err() if ctx is done before callbacks in flight have returned, else non-nil on failure
+func DrainNFSv3Server(ctx context.Context, network string, bindAddr string, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	unpublished, err = drainNFSv3Server(ctx, network, bindAddr, port, unpublish, jukebox)
+	return
+}
+
+// StartNFSv3ServerOnListener launches an NFSv3 server accepting connections from a listener supplied by the
+// caller (e.g. one inherited via systemd socket activation or a Unix-domain listener). The listener is closed
+// once the server is stopped.
+//
+// Arguments:
+//   listener  specifies the (TCP or Unix-domain stream) listener from which to accept connections
+//   publish   indicates whether or not to publish the NFSv3 server via rpcbind (versions 4 & 3)
+//   callbacks specifies the receiver of the API "up calls" as listed in NFSv3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartNFSv3ServerOnListener(listener net.Listener, publish bool, callbacks NFSv3Interface) (published bool, err error) {
+	published, err = startNFSv3ServerOn(listener, nil, publish, callbacks)
+	return
+}
+
+// StartNFSv3ServerOnPacketConn launches an NFSv3 server receiving datagrams via a packetConn supplied by the
+// caller. The packetConn is closed once the server is stopped.
+//
+// Arguments:
+//   packetConn specifies the (UDP or Unix-domain datagram) socket via which to receive calls and send replies
+//   publish    indicates whether or not to publish the NFSv3 server via rpcbind (versions 4 & 3)
+//   callbacks  specifies the receiver of the API "up calls" as listed in NFSv3Interface
+//
+// Returns:
+//   published indicates whether or not rpcbind successfully registered the program:version for each netid served
+//   err       is non-nil on failure (but published is valid either way)
+func StartNFSv3ServerOnPacketConn(packetConn net.PacketConn, publish bool, callbacks NFSv3Interface) (published bool, err error) {
+	published, err = startNFSv3ServerOn(nil, packetConn, publish, callbacks)
+	return
+}
+
+// StopNFSv3ServerOn stops an NFSv3 server launched via StartNFSv3ServerOn{Listener|PacketConn}
+//
+// Arguments:
+//   addr      specifies the address of the listener (i.e. listener.Addr()) or packetConn (i.e. packetConn.LocalAddr())
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via rpcbind
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is non-nil on failure (but unpublished is valid either way)
+func StopNFSv3ServerOn(addr net.Addr, unpublish bool) (unpublished bool, err error) {
+	unpublished, err = stopNFSv3ServerOn(addr, unpublish)
+	return
+}
+
+// DrainNFSv3ServerOn gracefully stops an NFSv3 server launched via StartNFSv3ServerOn{Listener|PacketConn}
+// (see DrainIPv4TCPNFSv3Server)
+//
+// Arguments:
+//   ctx       bounds the time allowed for callbacks in flight to return (after which the server is stopped at once)
+//   addr      specifies the address of the listener (i.e. listener.Addr()) or packetConn (i.e. packetConn.LocalAddr())
+//   unpublish indicates whether or not to remove a previously published NFSv3 server via rpcbind
+//   jukebox   indicates whether or not to answer requests received while draining with NFS3ErrJUKEBOX
+//
+// Returns:
+//   unpublished indicates whether or not rpcbind successfully unregistered the program:version for each netid served
+//   err         is ctx.Err() if ctx is done before callbacks in flight have returned, else non-nil on failure
+func DrainNFSv3ServerOn(ctx context.Context, addr net.Addr, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	unpublished, err = drainNFSv3ServerOn(ctx, addr, unpublish, jukebox)
+	return
+}
+
+// ErrServerClosed is returned by ServerStruct.Serve following a call to ServerStruct.Shutdown
+var ErrServerClosed = errors.New("nfsd: server closed")
+
+// NewServer constructs a ServerStruct serving Mount V3 & NFSv3 as specified by config. Multiple instances may
+// be served concurrently (e.g. on different bind addresses with the same ports). Listeners & packet connections
+// supplied via ListenerConfigStruct are owned (i.e. closed upon Shutdown) by the server once serving.
+//
+// Arguments:
+//   config specifies the listeners, callbacks, exports, and options of the server (config is copied)
+//
+// Returns:
+//   server is the ServerStruct (not yet serving)
+//   err    is non-nil on failure (e.g. config.ExportTable is invalid)
+func NewServer(config *ServerConfigStruct) (server *ServerStruct, err error) {
+	server, err = newServer(config)
+	return
+}
+
+// Serve listens upon each of the server's listeners (publishing them via rpcbind if so configured) and
+// serves until either ctx is done or Shutdown is called. If ctx is done first, listeners and connections are
+// closed immediately (though Serve does not return until callbacks in flight have returned).
+//
+// Arguments:
+//   ctx is the context governing the lifetime of the server
+//
+// Returns:
+//   err is ErrServerClosed following Shutdown, ctx.Err() if ctx is done first, or the reason for failing to listen
+func (server *ServerStruct) Serve(ctx context.Context) (err error) {
+	err = server.serve(ctx)
+	return
+}
+
+// Shutdown gracefully halts the server. Listeners are closed (and unpublished) such that no further requests are
+// accepted (though see ServerConfigStruct.ShutdownJukebox), after which requests in flight are allowed to
+// complete. If ctx is done before they do, connections are closed immediately (though Shutdown does not return
+// until callbacks in flight have returned).
+//
+// Arguments:
+//   ctx bounds the time allowed for requests in flight to complete
+//
+// Returns:
+//   err is ctx.Err() if ctx is done before all requests in flight have completed
+func (server *ServerStruct) Shutdown(ctx context.Context) (err error) {
+	err = server.shutdown(ctx)
+	return
+}
+
+// DRCStats reports the counters of the server's duplicate request cache (see ServerConfigStruct.DRC)
+//
+// Returns:
+//   drcStats is zero if the cache is disabled
+func (server *ServerStruct) DRCStats() (drcStats DRCStatsStruct) {
+	drcStats = server.drc.getStats()
+	return
+}
@@ -1,15 +1,143 @@
 package nfsd
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
 	"github.com/swiftstack/onc"
 	"github.com/swiftstack/onc/oncclient"
-	"github.com/swiftstack/onc/oncserver"
 )
 
+func enableMountTable(rmtabPath string) (err error) {
+	globalMountTable, err = newMountTable(rmtabPath, nil)
+	return
+}
+
+func disableMountTable() {
+	globalMountTable = nil
+}
+
+func setAnonymousIdentity(anonUID uint32, anonGID uint32) {
+	globalAnonUID = anonUID
+	globalAnonGID = anonGID
+}
+
+func setIdentityMapping(identityMapping *IdentityMappingStruct) {
+	globalIdentityMapping = copyIdentityMapping(identityMapping)
+}
+
+func setReadOnly(readOnly bool) {
+	globalReadOnly = readOnly
+}
+
+func enableDuplicateRequestCache(maxEntries int, maxAge time.Duration) {
+	globalDRC = newDRC(maxEntries, maxAge)
+}
+
+func disableDuplicateRequestCache() {
+	globalDRC = nil
+}
+
+func duplicateRequestCacheStats() (drcStats DRCStatsStruct) {
+	drcStats = globalDRC.getStats()
+	return
+}
+
+func setExportTable(exportTable *ExportTableStruct) (err error) {
+	if nil == exportTable {
+		globalExportTable = nil
+		return
+	}
+
+	globalExportTable, err = newExportTable(exportTable)
+
+	return
+}
+
+func parseExportTable(exportsReader io.Reader, netgroupReader io.Reader) (exportTable *ExportTableStruct, err error) {
+	exportTable = &ExportTableStruct{}
+
+	exportTable.Exports, err = parseExports(exportsReader)
+	if nil != err {
+		exportTable = nil
+		return
+	}
+
+	if nil == netgroupReader {
+		exportTable.Netgroups = make(map[string][]string)
+	} else {
+		exportTable.Netgroups, err = parseNetgroups(netgroupReader)
+		if nil != err {
+			exportTable = nil
+			return
+		}
+	}
+
+	return
+}
+
+func enableRPCSecGSS(mechanism GSSMechanismInterface, mapPrincipal GSSPrincipalMapper) {
+	globalGSS = newGSS(mechanism, mapPrincipal)
+}
+
+func disableRPCSecGSS() {
+	globalGSS = nil
+}
+
+// startServer launches handler via this package's own transport (rather than oncserver) such that it may support
+// RPCSEC_GSS, learn the address of each client for matching against the export table, and be drained
+func startServer(prot uint32, port uint16, prog uint32, handler rpcProgramInterface, errorLog func(err error)) (err error) {
+	err = startRPCServer(&rpcServerStruct{network: ipv4Network(prot), port: port, prog: prog, vers: 3, program: handler, errorLog: errorLog, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS})
+	return
+}
+
+// ipv4Network returns the network (as supplied to net.Listen or net.ListenPacket) served by
+// StartIPv4{TCP|UDP}{MountV3|NFSv3}Server for prot
+func ipv4Network(prot uint32) (network string) {
+	if onc.IPProtoTCP == prot {
+		network = "tcp4"
+	} else {
+		network = "udp4"
+	}
+	return
+}
+
+// stopServer halts a server launched via startServer()
+func stopServer(prot uint32, port uint16) (err error) {
+	var (
+		found bool
+	)
+
+	found, err = stopRPCServer(rpcServerKeyStruct{network: ipv4Network(prot), port: port})
+	if (nil == err) && !found {
+		err = fmt.Errorf("no server on %s port %v", ipv4Network(prot), port)
+	}
+
+	return
+}
+
+// drainServer gracefully halts a server launched via startServer() (see rpcServerStruct.shutdown())
+func drainServer(ctx context.Context, prot uint32, port uint16, jukebox bool) (err error) {
+	var (
+		found bool
+	)
+
+	found, err = drainRPCServer(ctx, rpcServerKeyStruct{network: ipv4Network(prot), port: port}, jukebox)
+	if (nil == err) && !found {
+		err = fmt.Errorf("no server on %s port %v", ipv4Network(prot), port)
+	}
+
+	return
+}
+
 func startIPv4TCPMountV3Server(port uint16, publish bool, callbacks MountV3Interface) (published bool, err error) {
 	published = false
 
-	err = oncserver.StartServer(onc.IPProtoTCP, port, []oncserver.ProgVersStruct{{Prog: onc.ProgNumMount, VersList: []uint32{3}}}, &mountRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoTCP, port: port})
+	err = startServer(onc.IPProtoTCP, port, onc.ProgNumMount, &mountRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoTCP, port: port, mountTable: globalMountTable, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS, exportTable: globalExportTable}, callbacks.ErrorLog)
 	if nil != err {
 		return
 	}
@@ -25,7 +153,7 @@ func startIPv4TCPMountV3Server(port uint16, publish bool, callbacks MountV3Inter
 func startIPv4UDPMountV3Server(port uint16, publish bool, callbacks MountV3Interface) (published bool, err error) {
 	published = false
 
-	err = oncserver.StartServer(onc.IPProtoUDP, port, []oncserver.ProgVersStruct{{Prog: onc.ProgNumMount, VersList: []uint32{3}}}, &mountRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoUDP, port: port})
+	err = startServer(onc.IPProtoUDP, port, onc.ProgNumMount, &mountRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoUDP, port: port, mountTable: globalMountTable, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS, exportTable: globalExportTable}, callbacks.ErrorLog)
 	if nil != err {
 		return
 	}
@@ -46,7 +174,7 @@ func stopIPv4TCPMountV3Server(port uint16, unpublish bool) (unpublished bool, er
 		unpublished = false
 	}
 
-	err = oncserver.StopServer(onc.IPProtoTCP, port)
+	err = stopServer(onc.IPProtoTCP, port)
 
 	return
 }
@@ -59,7 +187,7 @@ func stopIPv4UDPMountV3Server(port uint16, unpublish bool) (unpublished bool, er
 		unpublished = false
 	}
 
-	err = oncserver.StopServer(onc.IPProtoUDP, port)
+	err = stopServer(onc.IPProtoUDP, port)
 
 	return
 }
@@ -67,7 +195,7 @@ func stopIPv4UDPMountV3Server(port uint16, unpublish bool) (unpublished bool, er
 func startIPv4TCPNFSv3Server(port uint16, publish bool, callbacks NFSv3Interface) (published bool, err error) {
 	published = false
 
-	err = oncserver.StartServer(onc.IPProtoTCP, port, []oncserver.ProgVersStruct{{Prog: onc.ProgNumNFS, VersList: []uint32{3}}}, &nfsRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoTCP, port: port})
+	err = startServer(onc.IPProtoTCP, port, onc.ProgNumNFS, &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks), prot: onc.IPProtoTCP, port: port, anonUID: globalAnonUID, anonGID: globalAnonGID, identityMapping: globalIdentityMapping, exportTable: globalExportTable, readOnly: globalReadOnly, drc: globalDRC}, callbacks.ErrorLog)
 	if nil != err {
 		return
 	}
@@ -83,7 +211,7 @@ func startIPv4TCPNFSv3Server(port uint16, publish bool, callbacks NFSv3Interface
 func startIPv4UDPNFSv3Server(port uint16, publish bool, callbacks NFSv3Interface) (published bool, err error) {
 	published = false
 
-	err = oncserver.StartServer(onc.IPProtoUDP, port, []oncserver.ProgVersStruct{{Prog: onc.ProgNumNFS, VersList: []uint32{3}}}, &nfsRequestHandlerStruct{callbacks: callbacks, prot: onc.IPProtoUDP, port: port})
+	err = startServer(onc.IPProtoUDP, port, onc.ProgNumNFS, &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks), prot: onc.IPProtoUDP, port: port, anonUID: globalAnonUID, anonGID: globalAnonGID, identityMapping: globalIdentityMapping, exportTable: globalExportTable, readOnly: globalReadOnly, drc: globalDRC}, callbacks.ErrorLog)
 	if nil != err {
 		return
 	}
@@ -104,7 +232,7 @@ func stopIPv4TCPNFSv3Server(port uint16, unpublish bool) (unpublished bool, err
 		unpublished = false
 	}
 
-	err = oncserver.StopServer(onc.IPProtoTCP, port)
+	err = stopServer(onc.IPProtoTCP, port)
 
 	return
 }
@@ -117,7 +245,292 @@ func stopIPv4UDPNFSv3Server(port uint16, unpublish bool) (unpublished bool, err
 		unpublished = false
 	}
 
-	err = oncserver.StopServer(onc.IPProtoUDP, port)
+	err = stopServer(onc.IPProtoUDP, port)
+
+	return
+}
+
+func drainIPv4TCPNFSv3Server(ctx context.Context, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	if unpublish {
+		unpublishErr := oncclient.DoPmapProcUnset(onc.ProgNumNFS, 3, onc.IPProtoTCP)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	err = drainServer(ctx, onc.IPProtoTCP, port, jukebox)
+
+	return
+}
+
+func drainIPv4UDPNFSv3Server(ctx context.Context, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	if unpublish {
+		unpublishErr := oncclient.DoPmapProcUnset(onc.ProgNumNFS, 3, onc.IPProtoUDP)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	err = drainServer(ctx, onc.IPProtoUDP, port, jukebox)
+
+	return
+}
+
+func startMountV3Server(network string, bindAddr string, port uint16, publish bool, callbacks MountV3Interface) (published bool, err error) {
+	var (
+		prot uint32
+	)
+
+	published = false
+
+	_, prot, err = parseRPCNetwork(network)
+	if nil != err {
+		return
+	}
+
+	err = startRPCServer(&rpcServerStruct{network: network, bindAddr: bindAddr, port: port, prog: onc.ProgNumMount, vers: 3, program: &mountRequestHandlerStruct{callbacks: callbacks, prot: prot, port: port, mountTable: globalMountTable, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS, exportTable: globalExportTable}, errorLog: callbacks.ErrorLog, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS})
+	if nil != err {
+		return
+	}
+
+	if publish {
+		publishErr := rpcbSet(onc.ProgNumMount, 3, network, bindAddr, port)
+		published = (nil == publishErr)
+	}
+
+	return
+}
+
+func stopMountV3Server(network string, bindAddr string, port uint16, unpublish bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := rpcbUnset(onc.ProgNumMount, 3, network, bindAddr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = stopRPCServer(rpcServerKeyStruct{network: network, bindAddr: bindAddr, port: port})
+	if (nil == err) && !found {
+		err = fmt.Errorf("no Mount V3 server on %s %s", network, net.JoinHostPort(bindAddr, strconv.Itoa(int(port))))
+	}
+
+	return
+}
+
+func startNFSv3Server(network string, bindAddr string, port uint16, publish bool, callbacks NFSv3Interface) (published bool, err error) {
+	var (
+		prot uint32
+	)
+
+	published = false
+
+	_, prot, err = parseRPCNetwork(network)
+	if nil != err {
+		return
+	}
+
+	err = startRPCServer(&rpcServerStruct{network: network, bindAddr: bindAddr, port: port, prog: onc.ProgNumNFS, vers: 3, program: &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks), prot: prot, port: port, anonUID: globalAnonUID, anonGID: globalAnonGID, identityMapping: globalIdentityMapping, exportTable: globalExportTable, readOnly: globalReadOnly, drc: globalDRC}, errorLog: callbacks.ErrorLog, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS})
+	if nil != err {
+		return
+	}
+
+	if publish {
+		publishErr := rpcbSet(onc.ProgNumNFS, 3, network, bindAddr, port)
+		published = (nil == publishErr)
+	}
+
+	return
+}
+
+func stopNFSv3Server(network string, bindAddr string, port uint16, unpublish bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := rpcbUnset(onc.ProgNumNFS, 3, network, bindAddr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = stopRPCServer(rpcServerKeyStruct{network: network, bindAddr: bindAddr, port: port})
+	if (nil == err) && !found {
+		err = fmt.Errorf("no NFSv3 server on %s %s", network, net.JoinHostPort(bindAddr, strconv.Itoa(int(port))))
+	}
+
+	return
+}
+
+func drainNFSv3Server(ctx context.Context, network string, bindAddr string, port uint16, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := rpcbUnset(onc.ProgNumNFS, 3, network, bindAddr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = drainRPCServer(ctx, rpcServerKeyStruct{network: network, bindAddr: bindAddr, port: port}, jukebox)
+	if (nil == err) && !found {
+		err = fmt.Errorf("no NFSv3 server on %s %s", network, net.JoinHostPort(bindAddr, strconv.Itoa(int(port))))
+	}
+
+	return
+}
+
+// publishAddr publishes prog:vers (via rpcbind versions 4 & 3) for a server listening on addr
+func publishAddr(prog uint32, addr net.Addr) (err error) {
+	var (
+		bindAddr string
+		network  string
+		port     uint16
+	)
+
+	network, bindAddr, port, err = rpcbAddrArgs(addr)
+	if nil == err {
+		err = rpcbSet(prog, 3, network, bindAddr, port)
+	}
+
+	return
+}
+
+// unpublishAddr reverses publishAddr()
+func unpublishAddr(prog uint32, addr net.Addr) (err error) {
+	var (
+		bindAddr string
+		network  string
+	)
+
+	network, bindAddr, _, err = rpcbAddrArgs(addr)
+	if nil == err {
+		err = rpcbUnset(prog, 3, network, bindAddr)
+	}
+
+	return
+}
+
+func startMountV3ServerOn(listener net.Listener, packetConn net.PacketConn, publish bool, callbacks MountV3Interface) (published bool, err error) {
+	var (
+		addr net.Addr
+		prot uint32
+	)
+
+	published = false
+
+	if nil != listener {
+		addr = listener.Addr()
+		prot = onc.IPProtoTCP
+	} else {
+		addr = packetConn.LocalAddr()
+		prot = onc.IPProtoUDP
+	}
+
+	err = startRPCServer(&rpcServerStruct{listener: listener, packetConn: packetConn, prog: onc.ProgNumMount, vers: 3, program: &mountRequestHandlerStruct{callbacks: callbacks, prot: prot, port: addrPort(addr), mountTable: globalMountTable, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS, exportTable: globalExportTable}, errorLog: callbacks.ErrorLog, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS})
+	if nil != err {
+		return
+	}
+
+	if publish {
+		publishErr := publishAddr(onc.ProgNumMount, addr)
+		published = (nil == publishErr)
+	}
+
+	return
+}
+
+func stopMountV3ServerOn(addr net.Addr, unpublish bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := unpublishAddr(onc.ProgNumMount, addr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = stopRPCServer(addrKey(addr))
+	if (nil == err) && !found {
+		err = fmt.Errorf("no Mount V3 server on %s %s", addr.Network(), addr.String())
+	}
+
+	return
+}
+
+func startNFSv3ServerOn(listener net.Listener, packetConn net.PacketConn, publish bool, callbacks NFSv3Interface) (published bool, err error) {
+	var (
+		addr net.Addr
+		prot uint32
+	)
+
+	published = false
+
+	if nil != listener {
+		addr = listener.Addr()
+		prot = onc.IPProtoTCP
+	} else {
+		addr = packetConn.LocalAddr()
+		prot = onc.IPProtoUDP
+	}
+
+	err = startRPCServer(&rpcServerStruct{listener: listener, packetConn: packetConn, prog: onc.ProgNumNFS, vers: 3, program: &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks), prot: prot, port: addrPort(addr), anonUID: globalAnonUID, anonGID: globalAnonGID, identityMapping: globalIdentityMapping, exportTable: globalExportTable, readOnly: globalReadOnly, drc: globalDRC}, errorLog: callbacks.ErrorLog, anonUID: globalAnonUID, anonGID: globalAnonGID, gss: globalGSS})
+	if nil != err {
+		return
+	}
+
+	if publish {
+		publishErr := publishAddr(onc.ProgNumNFS, addr)
+		published = (nil == publishErr)
+	}
+
+	return
+}
+
+func stopNFSv3ServerOn(addr net.Addr, unpublish bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := unpublishAddr(onc.ProgNumNFS, addr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = stopRPCServer(addrKey(addr))
+	if (nil == err) && !found {
+		err = fmt.Errorf("no NFSv3 server on %s %s", addr.Network(), addr.String())
+	}
+
+	return
+}
+
+func drainNFSv3ServerOn(ctx context.Context, addr net.Addr, unpublish bool, jukebox bool) (unpublished bool, err error) {
+	var (
+		found bool
+	)
+
+	if unpublish {
+		unpublishErr := unpublishAddr(onc.ProgNumNFS, addr)
+		unpublished = (nil == unpublishErr)
+	} else {
+		unpublished = false
+	}
+
+	found, err = drainRPCServer(ctx, addrKey(addr), jukebox)
+	if (nil == err) && !found {
+		err = fmt.Errorf("no NFSv3 server on %s %s", addr.Network(), addr.String())
+	}
 
 	return
 }
@@ -0,0 +1,331 @@
+package nfsd
+
+import (
+	"context"
+)
+
+// Defaults with which FSINFO & PATHCONF are answered on behalf of a backend not implementing NFSv3FSInfoInterface
+// & NFSv3PathConfInterface respectively (and which backends implementing them may answer with as well)
+const (
+	DefaultTransferMax  = uint32(1 << 20) // RTMax, RTPref, WTMax, & WTPref
+	DefaultTransferMult = uint32(4096)    // RTMult & WTMult
+	DefaultDirPref      = uint32(1 << 16) // DTPref
+	DefaultNameMax      = uint32(255)     // NameMax
+)
+
+// nfsv3BackendAdapterStruct satisfies NFSv3ContextInterface on behalf of an NFSv3BackendInterface by invoking
+// those callbacks the backend implements and answering the remaining procedures itself
+type nfsv3BackendAdapterStruct struct {
+	backend       NFSv3BackendInterface
+	nuller        NFSv3NullInterface          // nil if the backend does not implement NFSv3NullInterface
+	accesser      NFSv3AccessInterface        // nil if the backend does not implement NFSv3AccessInterface
+	attrSetter    NFSv3AttrSetterInterface    // nil if the backend does not implement NFSv3AttrSetterInterface
+	readLinker    NFSv3ReadLinkerInterface    // nil if the backend does not implement NFSv3ReadLinkerInterface
+	reader        NFSv3ReaderInterface        // nil if the backend does not implement NFSv3ReaderInterface
+	writer        NFSv3WriterInterface        // nil if the backend does not implement NFSv3WriterInterface
+	creator       NFSv3CreatorInterface       // nil if the backend does not implement NFSv3CreatorInterface
+	symLinker     NFSv3SymLinkerInterface     // nil if the backend does not implement NFSv3SymLinkerInterface
+	remover       NFSv3RemoverInterface       // nil if the backend does not implement NFSv3RemoverInterface
+	renamer       NFSv3RenamerInterface       // nil if the backend does not implement NFSv3RenamerInterface
+	linker        NFSv3LinkerInterface        // nil if the backend does not implement NFSv3LinkerInterface
+	dirReader     NFSv3DirReaderInterface     // nil if the backend does not implement NFSv3DirReaderInterface
+	dirPlusReader NFSv3DirPlusReaderInterface // nil if the backend does not implement NFSv3DirPlusReaderInterface
+	fsStater      NFSv3FSStatInterface        // nil if the backend does not implement NFSv3FSStatInterface
+	fsInfoer      NFSv3FSInfoInterface        // nil if the backend does not implement NFSv3FSInfoInterface
+	pathConfer    NFSv3PathConfInterface      // nil if the backend does not implement NFSv3PathConfInterface
+}
+
+// newNFSv3BackendAdapter returns an NFSv3ContextInterface invoking backend (or backend itself should it
+// implement all of NFSv3ContextInterface)
+func newNFSv3BackendAdapter(backend NFSv3BackendInterface) (contextCallbacks NFSv3ContextInterface) {
+	var (
+		adapter *nfsv3BackendAdapterStruct
+		ok      bool
+	)
+
+	contextCallbacks, ok = backend.(NFSv3ContextInterface)
+	if ok {
+		return
+	}
+
+	adapter = &nfsv3BackendAdapterStruct{backend: backend}
+
+	adapter.nuller, _ = backend.(NFSv3NullInterface)
+	adapter.accesser, _ = backend.(NFSv3AccessInterface)
+	adapter.attrSetter, _ = backend.(NFSv3AttrSetterInterface)
+	adapter.readLinker, _ = backend.(NFSv3ReadLinkerInterface)
+	adapter.reader, _ = backend.(NFSv3ReaderInterface)
+	adapter.writer, _ = backend.(NFSv3WriterInterface)
+	adapter.creator, _ = backend.(NFSv3CreatorInterface)
+	adapter.symLinker, _ = backend.(NFSv3SymLinkerInterface)
+	adapter.remover, _ = backend.(NFSv3RemoverInterface)
+	adapter.renamer, _ = backend.(NFSv3RenamerInterface)
+	adapter.linker, _ = backend.(NFSv3LinkerInterface)
+	adapter.dirReader, _ = backend.(NFSv3DirReaderInterface)
+	adapter.dirPlusReader, _ = backend.(NFSv3DirPlusReaderInterface)
+	adapter.fsStater, _ = backend.(NFSv3FSStatInterface)
+	adapter.fsInfoer, _ = backend.(NFSv3FSInfoInterface)
+	adapter.pathConfer, _ = backend.(NFSv3PathConfInterface)
+
+	contextCallbacks = adapter
+
+	return
+}
+
+// mutable returns whether or not the backend implements any procedure that would modify the file system
+func (adapter *nfsv3BackendAdapterStruct) mutable() (mutable bool) {
+	mutable = (nil != adapter.attrSetter) || (nil != adapter.writer) || (nil != adapter.creator) || (nil != adapter.symLinker) ||
+		(nil != adapter.remover) || (nil != adapter.renamer) || (nil != adapter.linker)
+	return
+}
+
+// unimplementedMutation returns the status with which to answer a procedure that would modify the file system
+// should the backend not implement it
+func (adapter *nfsv3BackendAdapterStruct) unimplementedMutation() (status uint32) {
+	if adapter.mutable() {
+		status = NFS3ErrNOTSUPP
+	} else {
+		status = NFS3ErrROFS
+	}
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) ErrorLog(err error) {
+	adapter.backend.ErrorLog(err)
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Null(ctx context.Context) {
+	if nil != adapter.nuller {
+		adapter.nuller.NFSProc3Null(ctx)
+	}
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	nfsProc3GetAttrResults = adapter.backend.NFSProc3GetAttr(ctx, nfsProc3GetAttrArgs)
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct) {
+	nfsProc3LookupResults = adapter.backend.NFSProc3Lookup(ctx, nfsProc3LookupArgs)
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Access(ctx context.Context, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct) {
+	if nil != adapter.accesser {
+		nfsProc3AccessResults = adapter.accesser.NFSProc3Access(ctx, nfsProc3AccessArgs)
+		return
+	}
+
+	nfsProc3AccessResults = &NFSProc3AccessResultsStruct{Status: OK, Access: nfsProc3AccessArgs.Access}
+	if !adapter.mutable() {
+		nfsProc3AccessResults.Access &= readOnlyAccessMask
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3SetAttr(ctx context.Context, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct) {
+	if nil != adapter.attrSetter {
+		nfsProc3SetAttrResults = adapter.attrSetter.NFSProc3SetAttr(ctx, nfsProc3SetAttrArgs)
+	} else {
+		nfsProc3SetAttrResults = &NFSProc3SetAttrResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3ReadLink(ctx context.Context, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct) {
+	if nil != adapter.readLinker {
+		nfsProc3ReadLinkResults = adapter.readLinker.NFSProc3ReadLink(ctx, nfsProc3ReadLinkArgs)
+	} else {
+		nfsProc3ReadLinkResults = &NFSProc3ReadLinkResultsStruct{Status: NFS3ErrNOTSUPP}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Read(ctx context.Context, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct) {
+	if nil != adapter.reader {
+		nfsProc3ReadResults = adapter.reader.NFSProc3Read(ctx, nfsProc3ReadArgs)
+	} else {
+		nfsProc3ReadResults = &NFSProc3ReadResultsStruct{Status: NFS3ErrNOTSUPP}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Write(ctx context.Context, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct) {
+	if nil != adapter.writer {
+		nfsProc3WriteResults = adapter.writer.NFSProc3Write(ctx, nfsProc3WriteArgs)
+	} else {
+		nfsProc3WriteResults = &NFSProc3WriteResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Create(ctx context.Context, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct) {
+	if nil != adapter.creator {
+		nfsProc3CreateResults = adapter.creator.NFSProc3Create(ctx, nfsProc3CreateArgs)
+	} else {
+		nfsProc3CreateResults = &NFSProc3CreateResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3MKDir(ctx context.Context, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct) {
+	if nil != adapter.creator {
+		nfsProc3MKDirResults = adapter.creator.NFSProc3MKDir(ctx, nfsProc3MKDirArgs)
+	} else {
+		nfsProc3MKDirResults = &NFSProc3MKDirResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3SymLink(ctx context.Context, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct) {
+	if nil != adapter.symLinker {
+		nfsProc3SymLinkResults = adapter.symLinker.NFSProc3SymLink(ctx, nfsProc3SymLinkArgs)
+	} else {
+		nfsProc3SymLinkResults = &NFSProc3SymLinkResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Remove(ctx context.Context, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) {
+	if nil != adapter.remover {
+		nfsProc3RemoveResults = adapter.remover.NFSProc3Remove(ctx, nfsProc3RemoveArgs)
+	} else {
+		nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3RMDir(ctx context.Context, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct) {
+	if nil != adapter.remover {
+		nfsProc3RMDirResults = adapter.remover.NFSProc3RMDir(ctx, nfsProc3RMDirArgs)
+	} else {
+		nfsProc3RMDirResults = &NFSProc3RMDirResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Rename(ctx context.Context, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct) {
+	if nil != adapter.renamer {
+		nfsProc3RenameResults = adapter.renamer.NFSProc3Rename(ctx, nfsProc3RenameArgs)
+	} else {
+		nfsProc3RenameResults = &NFSProc3RenameResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct) {
+	if nil != adapter.linker {
+		nfsProc3LinkResults = adapter.linker.NFSProc3Link(ctx, nfsProc3LinkArgs)
+	} else {
+		nfsProc3LinkResults = &NFSProc3LinkResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3ReadDir(ctx context.Context, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) {
+	if nil != adapter.dirReader {
+		nfsProc3ReadDirResults = adapter.dirReader.NFSProc3ReadDir(ctx, nfsProc3ReadDirArgs)
+	} else {
+		nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: NFS3ErrNOTSUPP}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3ReadDirPlus(ctx context.Context, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) {
+	if nil != adapter.dirPlusReader {
+		nfsProc3ReadDirPlusResults = adapter.dirPlusReader.NFSProc3ReadDirPlus(ctx, nfsProc3ReadDirPlusArgs)
+	} else {
+		nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{Status: NFS3ErrNOTSUPP}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3FSStat(ctx context.Context, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct) {
+	if nil != adapter.fsStater {
+		nfsProc3FSStatResults = adapter.fsStater.NFSProc3FSStat(ctx, nfsProc3FSStatArgs)
+	} else {
+		nfsProc3FSStatResults = &NFSProc3FSStatResultsStruct{Status: NFS3ErrNOTSUPP}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3Commit(ctx context.Context, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct) {
+	if nil != adapter.writer {
+		nfsProc3CommitResults = adapter.writer.NFSProc3Commit(ctx, nfsProc3CommitArgs)
+	} else {
+		nfsProc3CommitResults = &NFSProc3CommitResultsStruct{Status: adapter.unimplementedMutation()}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3FSInfo(ctx context.Context, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct) {
+	if nil != adapter.fsInfoer {
+		nfsProc3FSInfoResults = adapter.fsInfoer.NFSProc3FSInfo(ctx, nfsProc3FSInfoArgs)
+	} else {
+		nfsProc3FSInfoResults = &NFSProc3FSInfoResultsStruct{
+			Status:      OK,
+			RTMax:       DefaultTransferMax,
+			RTPref:      DefaultTransferMax,
+			RTMult:      DefaultTransferMult,
+			WTMax:       DefaultTransferMax,
+			WTPref:      DefaultTransferMax,
+			WTMult:      DefaultTransferMult,
+			DTPref:      DefaultDirPref,
+			MaxFileSize: uint64(1<<63 - 1),
+			TimeDelta:   NFSTime3Struct{Seconds: 0, NSeconds: 1},
+			Properties:  FSF3Homogeneous | FSF3Link | FSF3SymLink, // FSF3Link & FSF3SymLink cleared below as appropriate
+		}
+		if nil != adapter.attrSetter {
+			nfsProc3FSInfoResults.Properties |= FSF3CanSetTime
+		}
+	}
+
+	if OK == nfsProc3FSInfoResults.Status {
+		if nil == adapter.linker {
+			nfsProc3FSInfoResults.Properties &^= FSF3Link
+		}
+		if nil == adapter.symLinker {
+			nfsProc3FSInfoResults.Properties &^= FSF3SymLink
+		}
+	}
+
+	return
+}
+
+func (adapter *nfsv3BackendAdapterStruct) NFSProc3PathConf(ctx context.Context, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct) {
+	if nil != adapter.pathConfer {
+		nfsProc3PathConfResults = adapter.pathConfer.NFSProc3PathConf(ctx, nfsProc3PathConfArgs)
+	} else {
+		nfsProc3PathConfResults = &NFSProc3PathConfResultsStruct{
+			Status:          OK,
+			LinkMax:         ^uint32(0), // reduced below if hard links are not supported
+			NameMax:         DefaultNameMax,
+			NoTrunc:         true,
+			ChOwnRestricted: true,
+			CaseInsensitive: false,
+			CasePreserving:  true,
+		}
+	}
+
+	if (OK == nfsProc3PathConfResults.Status) && (nil == adapter.linker) {
+		nfsProc3PathConfResults.LinkMax = 1
+	}
+
+	return
+}
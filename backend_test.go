@@ -0,0 +1,92 @@
+package nfsd
+
+import (
+	"context"
+	"testing"
+)
+
+// testLookupOnlyBackendStruct implements only NFSv3BackendInterface
+type testLookupOnlyBackendStruct struct {
+	t *testing.T
+}
+
+func (testLookupOnlyBackend *testLookupOnlyBackendStruct) ErrorLog(err error) {
+	testLookupOnlyBackend.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testLookupOnlyBackend *testLookupOnlyBackendStruct) NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{Status: OK}
+	return
+}
+
+func (testLookupOnlyBackend *testLookupOnlyBackendStruct) NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct) {
+	nfsProc3LookupResults = &NFSProc3LookupResultsStruct{Status: NFS3ErrNOENT}
+	return
+}
+
+// testLinkerBackendStruct additionally implements NFSv3LinkerInterface
+type testLinkerBackendStruct struct {
+	testLookupOnlyBackendStruct
+}
+
+func (testLinkerBackend *testLinkerBackendStruct) NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct) {
+	nfsProc3LinkResults = &NFSProc3LinkResultsStruct{Status: OK}
+	return
+}
+
+func TestBackendAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	lookupOnly := newNFSv3BackendAdapter(&testLookupOnlyBackendStruct{t: t})
+
+	if NFS3ErrNOENT != lookupOnly.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{}).Status {
+		t.Fatalf("LOOKUP not passed to backend")
+	}
+	if NFS3ErrNOTSUPP != lookupOnly.NFSProc3Read(ctx, &NFSProc3ReadArgsStruct{}).Status {
+		t.Fatalf("READ not answered with NFS3ErrNOTSUPP")
+	}
+	if NFS3ErrNOTSUPP != lookupOnly.NFSProc3ReadDirPlus(ctx, &NFSProc3ReadDirPlusArgsStruct{}).Status {
+		t.Fatalf("READDIRPLUS not answered with NFS3ErrNOTSUPP")
+	}
+	if NFS3ErrROFS != lookupOnly.NFSProc3Write(ctx, &NFSProc3WriteArgsStruct{}).Status {
+		t.Fatalf("WRITE to a backend lacking any mutating procedure not answered with NFS3ErrROFS")
+	}
+	if NFS3ErrROFS != lookupOnly.NFSProc3Link(ctx, &NFSProc3LinkArgsStruct{}).Status {
+		t.Fatalf("LINK to a backend lacking any mutating procedure not answered with NFS3ErrROFS")
+	}
+
+	accessResults := lookupOnly.NFSProc3Access(ctx, &NFSProc3AccessArgsStruct{Access: Access3Read | Access3Modify | Access3Lookup})
+	if (OK != accessResults.Status) || ((Access3Read | Access3Lookup) != accessResults.Access) {
+		t.Fatalf("ACCESS returned %+v... expected Access3Read|Access3Lookup", accessResults)
+	}
+
+	fsInfoResults := lookupOnly.NFSProc3FSInfo(ctx, &NFSProc3FSInfoArgsStruct{})
+	if (OK != fsInfoResults.Status) || (0 == fsInfoResults.RTMax) || (FSF3Homogeneous != fsInfoResults.Properties) {
+		t.Fatalf("FSINFO returned %+v... expected (only) FSF3Homogeneous", fsInfoResults)
+	}
+
+	pathConfResults := lookupOnly.NFSProc3PathConf(ctx, &NFSProc3PathConfArgsStruct{})
+	if (OK != pathConfResults.Status) || (1 != pathConfResults.LinkMax) {
+		t.Fatalf("PATHCONF returned %+v... expected LinkMax == 1", pathConfResults)
+	}
+
+	linker := newNFSv3BackendAdapter(&testLinkerBackendStruct{testLookupOnlyBackendStruct{t: t}})
+
+	if OK != linker.NFSProc3Link(ctx, &NFSProc3LinkArgsStruct{}).Status {
+		t.Fatalf("LINK not passed to backend")
+	}
+	if NFS3ErrNOTSUPP != linker.NFSProc3Write(ctx, &NFSProc3WriteArgsStruct{}).Status {
+		t.Fatalf("WRITE to a backend implementing a mutating procedure not answered with NFS3ErrNOTSUPP")
+	}
+	if (FSF3Homogeneous | FSF3Link) != linker.NFSProc3FSInfo(ctx, &NFSProc3FSInfoArgsStruct{}).Properties {
+		t.Fatalf("FSINFO should have advertised FSF3Link but not FSF3SymLink")
+	}
+	if 1 == linker.NFSProc3PathConf(ctx, &NFSProc3PathConfArgsStruct{}).LinkMax {
+		t.Fatalf("PATHCONF should have advertised LinkMax > 1")
+	}
+
+	full := &testContextNFSv3Struct{t: t}
+	if NFSv3ContextInterface(full) != newNFSv3BackendAdapter(full) {
+		t.Fatalf("newNFSv3BackendAdapter() should have returned a complete NFSv3ContextInterface as is")
+	}
+}
@@ -16,6 +16,22 @@ const ( // Common
 	ProcNULL = uint32(0)
 )
 
+const ( // enum auth_flavor
+	AuthNone  = uint32(0) // AUTH_NONE
+	AuthSys   = uint32(1) // AUTH_SYS (a.k.a. AUTH_UNIX)
+	RPCSecGSS = uint32(6) // RPCSEC_GSS
+
+	AuthKrb5  = uint32(390003) // RPCSEC_GSS pseudo-flavor for Kerberos V5 with RPCGSSSvcNone
+	AuthKrb5I = uint32(390004) // RPCSEC_GSS pseudo-flavor for Kerberos V5 with RPCGSSSvcIntegrity
+	AuthKrb5P = uint32(390005) // RPCSEC_GSS pseudo-flavor for Kerberos V5 with RPCGSSSvcPrivacy
+)
+
+const ( // enum rpc_gss_service_t
+	RPCGSSSvcNone      = uint32(1) // requests & replies are authenticated only
+	RPCGSSSvcIntegrity = uint32(2) // requests & replies are additionally protected by a checksum
+	RPCGSSSvcPrivacy   = uint32(3) // requests & replies are additionally encrypted
+)
+
 const ( // Mount-specific
 	MntPathLen = uint32(1024) // Maximum bytes in a path name
 	MntNameLen = uint32(255)  // Maximum bytes in a name
@@ -71,8 +87,11 @@ const ( // enum nfsstat3
 )
 
 const ( // program MOUNT_PROGRAM version MOUNT_V3
-	MOUNTPROC3MNT  = uint32(1)
-	MOUNTPROC3UMNT = uint32(3)
+	MOUNTPROC3MNT     = uint32(1)
+	MOUNTPROC3DUMP    = uint32(2)
+	MOUNTPROC3UMNT    = uint32(3)
+	MOUNTPROC3UMNTALL = uint32(4)
+	MOUNTPROC3EXPORT  = uint32(5)
 )
 
 const ( // program NFS_PROGRAM version NFS_V3
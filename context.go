@@ -0,0 +1,188 @@
+package nfsd
+
+import (
+	"context"
+	"time"
+)
+
+// requestInfoKeyType is the type of the key under which the *RequestInfoStruct supplied to each
+// NFSv3ContextInterface callback is stored in its context
+type requestInfoKeyType struct{}
+
+func requestInfoFromContext(ctx context.Context) (requestInfo *RequestInfoStruct) {
+	var (
+		ok bool
+	)
+
+	requestInfo, ok = ctx.Value(requestInfoKeyType{}).(*RequestInfoStruct)
+	if !ok {
+		requestInfo = nil
+	}
+
+	return
+}
+
+// requestContext returns the context supplied to the callback invoked on behalf of the call identified by
+// connHandle & xid. Should the call have been received via a connection, the context is cancelled once the
+// connection has been closed.
+func (nfsRequestHandler *nfsRequestHandlerStruct) requestContext(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, export *exportStruct) (ctx context.Context) {
+	var (
+		ok          bool
+		replier     *rpcReplierStruct
+		requestInfo = &RequestInfoStruct{XID: xid, Prot: nfsRequestHandler.prot, LocalPort: nfsRequestHandler.port, Credential: credential}
+	)
+
+	replier, ok = rpcReplier(connHandle)
+	if ok && (nil != replier.ctx) {
+		ctx = replier.ctx
+		requestInfo.LocalPort = replier.server.port // in case the port was chosen by the system
+		requestInfo.RemoteAddr = replier.remoteAddr
+		requestInfo.ReceiveTime = replier.received
+	} else {
+		ctx = context.Background()
+		requestInfo.RemoteAddr = connHandle.RemoteAddr()
+		requestInfo.ReceiveTime = time.Now()
+	}
+
+	if nil != export {
+		requestInfo.ExportPath = export.path
+	}
+
+	ctx = context.WithValue(ctx, requestInfoKeyType{}, requestInfo)
+
+	return
+}
+
+// nfsv3ContextAdapterStruct satisfies NFSv3ContextInterface by invoking the corresponding NFSv3Interface
+// callback with the credential of the request (as conveyed via the context of each callback)
+type nfsv3ContextAdapterStruct struct {
+	callbacks NFSv3Interface
+}
+
+func newNFSv3ContextAdapter(callbacks NFSv3Interface) (contextCallbacks NFSv3ContextInterface) {
+	contextCallbacks = &nfsv3ContextAdapterStruct{callbacks: callbacks}
+	return
+}
+
+// credential returns the credential of the request on whose behalf a callback was invoked (or nil if not known)
+func (adapter *nfsv3ContextAdapterStruct) credential(ctx context.Context) (credential *CredentialStruct) {
+	var (
+		requestInfo = requestInfoFromContext(ctx)
+	)
+
+	if nil == requestInfo {
+		credential = nil
+	} else {
+		credential = requestInfo.Credential
+	}
+
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) ErrorLog(err error) {
+	adapter.callbacks.ErrorLog(err)
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Null(ctx context.Context) {
+	adapter.callbacks.NFSProc3Null(adapter.credential(ctx))
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	nfsProc3GetAttrResults = adapter.callbacks.NFSProc3GetAttr(adapter.credential(ctx), nfsProc3GetAttrArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3SetAttr(ctx context.Context, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct) {
+	nfsProc3SetAttrResults = adapter.callbacks.NFSProc3SetAttr(adapter.credential(ctx), nfsProc3SetAttrArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct) {
+	nfsProc3LookupResults = adapter.callbacks.NFSProc3Lookup(adapter.credential(ctx), nfsProc3LookupArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Access(ctx context.Context, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct) {
+	nfsProc3AccessResults = adapter.callbacks.NFSProc3Access(adapter.credential(ctx), nfsProc3AccessArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3ReadLink(ctx context.Context, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct) {
+	nfsProc3ReadLinkResults = adapter.callbacks.NFSProc3ReadLink(adapter.credential(ctx), nfsProc3ReadLinkArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Read(ctx context.Context, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct) {
+	nfsProc3ReadResults = adapter.callbacks.NFSProc3Read(adapter.credential(ctx), nfsProc3ReadArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Write(ctx context.Context, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct) {
+	nfsProc3WriteResults = adapter.callbacks.NFSProc3Write(adapter.credential(ctx), nfsProc3WriteArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Create(ctx context.Context, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct) {
+	nfsProc3CreateResults = adapter.callbacks.NFSProc3Create(adapter.credential(ctx), nfsProc3CreateArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3MKDir(ctx context.Context, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct) {
+	nfsProc3MKDirResults = adapter.callbacks.NFSProc3MKDir(adapter.credential(ctx), nfsProc3MKDirArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3SymLink(ctx context.Context, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct) {
+	nfsProc3SymLinkResults = adapter.callbacks.NFSProc3SymLink(adapter.credential(ctx), nfsProc3SymLinkArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Remove(ctx context.Context, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) {
+	nfsProc3RemoveResults = adapter.callbacks.NFSProc3Remove(adapter.credential(ctx), nfsProc3RemoveArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3RMDir(ctx context.Context, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct) {
+	nfsProc3RMDirResults = adapter.callbacks.NFSProc3RMDir(adapter.credential(ctx), nfsProc3RMDirArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Rename(ctx context.Context, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct) {
+	nfsProc3RenameResults = adapter.callbacks.NFSProc3Rename(adapter.credential(ctx), nfsProc3RenameArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct) {
+	nfsProc3LinkResults = adapter.callbacks.NFSProc3Link(adapter.credential(ctx), nfsProc3LinkArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3ReadDir(ctx context.Context, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) {
+	nfsProc3ReadDirResults = adapter.callbacks.NFSProc3ReadDir(adapter.credential(ctx), nfsProc3ReadDirArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3ReadDirPlus(ctx context.Context, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) {
+	nfsProc3ReadDirPlusResults = adapter.callbacks.NFSProc3ReadDirPlus(adapter.credential(ctx), nfsProc3ReadDirPlusArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3FSStat(ctx context.Context, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct) {
+	nfsProc3FSStatResults = adapter.callbacks.NFSProc3FSStat(adapter.credential(ctx), nfsProc3FSStatArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3FSInfo(ctx context.Context, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct) {
+	nfsProc3FSInfoResults = adapter.callbacks.NFSProc3FSInfo(adapter.credential(ctx), nfsProc3FSInfoArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3PathConf(ctx context.Context, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct) {
+	nfsProc3PathConfResults = adapter.callbacks.NFSProc3PathConf(adapter.credential(ctx), nfsProc3PathConfArgs)
+	return
+}
+
+func (adapter *nfsv3ContextAdapterStruct) NFSProc3Commit(ctx context.Context, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct) {
+	nfsProc3CommitResults = adapter.callbacks.NFSProc3Commit(adapter.credential(ctx), nfsProc3CommitArgs)
+	return
+}
@@ -0,0 +1,97 @@
+package nfsd
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/onc"
+)
+
+// testContextNFSv3Struct reports the RequestInfoStruct of each GETATTR and then awaits its cancellation (any other
+// callback but NULL panics via the nil embedded interface)
+type testContextNFSv3Struct struct {
+	NFSv3ContextInterface
+	t           *testing.T
+	requestInfo chan *RequestInfoStruct
+	cancelled   chan error
+}
+
+func (testContextNFSv3 *testContextNFSv3Struct) ErrorLog(err error) {
+	testContextNFSv3.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testContextNFSv3 *testContextNFSv3Struct) NFSProc3Null(ctx context.Context) {}
+
+func (testContextNFSv3 *testContextNFSv3Struct) NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	testContextNFSv3.requestInfo <- RequestInfoFromContext(ctx)
+
+	select {
+	case <-ctx.Done():
+		testContextNFSv3.cancelled <- ctx.Err()
+	case <-time.After(5 * time.Second):
+		testContextNFSv3.cancelled <- nil
+	}
+
+	nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{Status: NFS3ErrIO}
+	return
+}
+
+func TestRequestContext(t *testing.T) {
+	callbacks := &testContextNFSv3Struct{t: t, requestInfo: make(chan *RequestInfoStruct, 1), cancelled: make(chan error, 1)}
+
+	server, err := NewServer(&ServerConfigStruct{
+		Listeners:           []ListenerConfigStruct{{Network: "tcp4", BindAddr: "127.0.0.1"}},
+		MountCallbacks:      &testNullMountV3Struct{t: t},
+		NFSContextCallbacks: callbacks,
+	})
+	if nil != err {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+
+	go func() {
+		_ = server.Serve(context.Background())
+	}()
+	defer server.Shutdown(context.Background())
+
+	nfsPort := testServerNFSPorts(t, server)[0]
+
+	conn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(nfsPort))))
+	if nil != err {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+
+	beforeCall := time.Now()
+
+	testSendGetAttr(t, conn, 17)
+
+	requestInfo := <-callbacks.requestInfo
+	if nil == requestInfo {
+		t.Fatalf("RequestInfoFromContext() returned nil")
+	}
+	if (17 != requestInfo.XID) || (onc.IPProtoTCP != requestInfo.Prot) || (nfsPort != requestInfo.LocalPort) {
+		t.Fatalf("RequestInfoFromContext() returned %+v", requestInfo)
+	}
+	if (nil == requestInfo.RemoteAddr) || (conn.LocalAddr().String() != requestInfo.RemoteAddr.String()) {
+		t.Fatalf("RequestInfoStruct.RemoteAddr was %v... expected %v", requestInfo.RemoteAddr, conn.LocalAddr())
+	}
+	if (nil == requestInfo.Credential) || (AuthNone != requestInfo.Credential.Flavor) {
+		t.Fatalf("RequestInfoStruct.Credential was %+v", requestInfo.Credential)
+	}
+	if requestInfo.ReceiveTime.Before(beforeCall) {
+		t.Fatalf("RequestInfoStruct.ReceiveTime (%v) precedes the call (%v)", requestInfo.ReceiveTime, beforeCall)
+	}
+
+	_ = conn.Close()
+
+	err = <-callbacks.cancelled
+	if context.Canceled != err {
+		t.Fatalf("context of callback in flight when its connection was closed returned %v... expected context.Canceled", err)
+	}
+
+	if nil != RequestInfoFromContext(context.Background()) {
+		t.Fatalf("RequestInfoFromContext() of a context not supplied to a callback should have returned nil")
+	}
+}
@@ -0,0 +1,62 @@
+package nfsd
+
+import (
+	"github.com/swiftstack/onc"
+)
+
+// Identity to which AUTH_NONE requests are mapped unless overridden via SetAnonymousIdentity()
+const (
+	DefaultAnonUID = uint32(65534) // nobody
+	DefaultAnonGID = uint32(65534) // nogroup
+)
+
+var (
+	globalAnonUID = DefaultAnonUID // used by servers launched via StartIPv4{TCP|UDP}{MountV3|NFSv3}Server
+	globalAnonGID = DefaultAnonGID // used by servers launched via StartIPv4{TCP|UDP}{MountV3|NFSv3}Server
+)
+
+// newCredential converts an AUTH_SYS credential body into a CredentialStruct. A nil authSysBody denotes a request
+// whose credential flavor was AUTH_NONE (as passed by rpcServerStruct.handleCall) and is mapped to the anonymous
+// identity specified by anonUID & anonGID.
+func newCredential(authSysBody *onc.AuthSysBodyStruct, anonUID uint32, anonGID uint32) (credential *CredentialStruct) {
+	if nil == authSysBody {
+		credential = &CredentialStruct{
+			Flavor: AuthNone,
+			UID:    anonUID,
+			GID:    anonGID,
+			GIDs:   []uint32{},
+		}
+	} else {
+		credential = &CredentialStruct{
+			Flavor:      AuthSys,
+			MachineName: authSysBody.MachineName,
+			UID:         authSysBody.UID,
+			GID:         authSysBody.GID,
+			GIDs:        authSysBody.GIDs,
+		}
+	}
+
+	return
+}
+
+// authFlavorSupported indicates whether or not this package is able to accept requests of the specified flavor.
+// RPCSEC_GSS (along with its Kerberos V5 pseudo-flavors) is only supported if gss is non-nil.
+func authFlavorSupported(authFlavor uint32, gss *gssStruct) (supported bool) {
+	switch authFlavor {
+	case AuthNone:
+		supported = true
+	case AuthSys:
+		supported = true
+	case RPCSecGSS:
+		supported = (nil != gss)
+	case AuthKrb5:
+		supported = (nil != gss)
+	case AuthKrb5I:
+		supported = (nil != gss)
+	case AuthKrb5P:
+		supported = (nil != gss)
+	default:
+		supported = false
+	}
+	return
+}
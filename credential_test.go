@@ -0,0 +1,105 @@
+package nfsd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+func TestCredential(t *testing.T) {
+	var (
+		authSysBody []byte
+		echoProgram = &echoProgramStruct{}
+		err         error
+		reply       *gssTestReplyStruct
+	)
+
+	client := &gssTestClientStruct{
+		t:          t,
+		server:     &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: echoProgram, errorLog: func(err error) {}, anonUID: 1234, anonGID: 5678},
+		gssContext: &fakeGSSContextStruct{},
+	}
+
+	reply = client.call(AuthNone, []byte{}, AuthNone, []byte{}, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("AUTH_NONE not accepted: %+v", reply)
+	}
+	if !reflect.DeepEqual(&CredentialStruct{Flavor: AuthNone, UID: 1234, GID: 5678, GIDs: []uint32{}}, echoProgram.credential) {
+		t.Fatalf("AUTH_NONE mapped to %+v", echoProgram.credential)
+	}
+
+	authSysBody, err = xdr.Pack(&onc.AuthSysBodyStruct{Stamp: 1, MachineName: "client", UID: 1000, GID: 100, GIDs: []uint32{4, 24}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	echoProgram.credential = nil
+
+	reply = client.call(AuthSys, authSysBody, AuthNone, []byte{}, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("AUTH_SYS not accepted: %+v", reply)
+	}
+	if !reflect.DeepEqual(&CredentialStruct{Flavor: AuthSys, MachineName: "client", UID: 1000, GID: 100, GIDs: []uint32{4, 24}}, echoProgram.credential) {
+		t.Fatalf("AUTH_SYS passed as %+v", echoProgram.credential)
+	}
+
+	echoProgram.credential = nil
+
+	reply = client.call(AuthSys, authSysBody[:len(authSysBody)-4], AuthNone, []byte{}, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatBadCred != reply.authStat) {
+		t.Fatalf("truncated AUTH_SYS not rejected: %+v", reply)
+	}
+
+	reply = client.call(AuthKrb5, []byte{}, AuthNone, []byte{}, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatBadCred != reply.authStat) {
+		t.Fatalf("unsupported flavor not rejected: %+v", reply)
+	}
+
+	if nil != echoProgram.credential {
+		t.Fatalf("rejected calls dispatched with %+v", echoProgram.credential)
+	}
+}
+
+func TestCredentialVerifier(t *testing.T) {
+	var (
+		authSysBody []byte
+		echoProgram = &echoProgramStruct{}
+		err         error
+		reply       *gssTestReplyStruct
+	)
+
+	client := &gssTestClientStruct{
+		t:          t,
+		server:     &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: echoProgram, errorLog: func(err error) {}},
+		gssContext: &fakeGSSContextStruct{},
+	}
+
+	authSysBody, err = xdr.Pack(&onc.AuthSysBodyStruct{MachineName: "client", GIDs: []uint32{}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	for _, cred := range []struct {
+		flavor uint32
+		body   []byte
+	}{
+		{AuthNone, []byte{}},
+		{AuthSys, authSysBody},
+	} {
+		reply = client.call(cred.flavor, cred.body, AuthSys, authSysBody, ProcNULL, []byte{})
+		if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatBadVerf != reply.authStat) {
+			t.Fatalf("flavor %v call bearing an AUTH_SYS verifier not rejected: %+v", cred.flavor, reply)
+		}
+
+		reply = client.call(cred.flavor, cred.body, AuthNone, []byte{1, 2, 3, 4}, ProcNULL, []byte{})
+		if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatBadVerf != reply.authStat) {
+			t.Fatalf("flavor %v call bearing a non-empty AUTH_NONE verifier not rejected: %+v", cred.flavor, reply)
+		}
+	}
+
+	if nil != echoProgram.credential {
+		t.Fatalf("rejected calls dispatched with %+v", echoProgram.credential)
+	}
+}
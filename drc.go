@@ -0,0 +1,248 @@
+package nfsd
+
+import (
+	"container/list"
+	"hash/crc32"
+	"net"
+	"sync"
+	"time"
+)
+
+// Limits of a duplicate request cache unless otherwise specified
+const (
+	DefaultDRCMaxEntries = 1024
+	DefaultDRCMaxAge     = 2 * time.Minute
+)
+
+// drcProcs are the NFSv3 procedures whose retransmission, were it to be executed again, could return a different
+// result than the original (e.g. a retried REMOVE answered with NFS3ErrNOENT)
+var drcProcs = map[uint32]bool{
+	NFSPROC3SETATTR: true,
+	NFSPROC3CREATE:  true,
+	NFSPROC3MKDIR:   true,
+	NFSPROC3SYMLINK: true,
+	NFSPROC3REMOVE:  true,
+	NFSPROC3RMDIR:   true,
+	NFSPROC3RENAME:  true,
+	NFSPROC3LINK:    true,
+}
+
+var globalDRC *drcStruct // used by NFSv3 servers launched via StartIPv4{TCP|UDP}NFSv3Server (if non-nil)
+
+type drcStatusType int
+
+const (
+	drcUncached   drcStatusType = iota // the call is not subject to the cache
+	drcMiss                            // the call is to be executed & its reply recorded via the returned drcReplier
+	drcReplay                          // the call is a retransmission of one already answered with the returned results
+	drcInProgress                      // the call is a retransmission of one not yet answered & is to be dropped
+)
+
+type drcKeyStruct struct {
+	clientAddr string // IP address (sans port) of the client such that retransmissions via a new connection match
+	xid        uint32
+	proc       uint32
+	parmsLen   int
+	parmsCRC   uint32 // guards against a client reusing an xid (e.g. following a reboot) for a different call
+}
+
+type drcEntryStruct struct {
+	key        drcKeyStruct
+	inProgress bool      // set until the original call has been answered
+	results    []byte    // only used/valid if inProgress == false
+	created    time.Time //
+	element    *list.Element
+}
+
+type drcStruct struct {
+	sync.Mutex
+	maxEntries int
+	maxAge     time.Duration
+	entries    map[drcKeyStruct]*drcEntryStruct
+	lru        *list.List // of *drcEntryStruct from oldest to newest
+	stats      DRCStatsStruct
+}
+
+// drcReplierStruct is supplied to the procedure handling a call in place of the connHandle via which it was
+// received such that the results of its reply may be recorded in the cache
+type drcReplierStruct struct {
+	connHandle connHandleInterface
+	xid        uint32
+	entry      *drcEntryStruct
+	results    []byte // set once replied to via sendAcceptedSuccess()
+	replied    bool   //
+}
+
+func newDRC(maxEntries int, maxAge time.Duration) (drc *drcStruct) {
+	if 0 >= maxEntries {
+		maxEntries = DefaultDRCMaxEntries
+	}
+	if 0 >= maxAge {
+		maxAge = DefaultDRCMaxAge
+	}
+
+	drc = &drcStruct{
+		maxEntries: maxEntries,
+		maxAge:     maxAge,
+		entries:    make(map[drcKeyStruct]*drcEntryStruct),
+		lru:        list.New(),
+	}
+
+	return
+}
+
+// lookup determines how a call is to be handled. Note that drc may be nil (in which case no call is cached).
+func (drc *drcStruct) lookup(connHandle connHandleInterface, xid uint32, proc uint32, parms []byte) (drcReplier *drcReplierStruct, results []byte, status drcStatusType) {
+	var (
+		entry *drcEntryStruct
+		key   drcKeyStruct
+		ok    bool
+	)
+
+	if (nil == drc) || !drcProcs[proc] || callDeferred(connHandle) {
+		status = drcUncached
+		return
+	}
+
+	key = drcKeyStruct{clientAddr: clientAddr(connHandle), xid: xid, proc: proc, parmsLen: len(parms), parmsCRC: crc32.ChecksumIEEE(parms)}
+	if "" == key.clientAddr {
+		status = drcUncached
+		return
+	}
+
+	drc.Lock()
+	defer drc.Unlock()
+
+	drc.prune(time.Now())
+
+	entry, ok = drc.entries[key]
+	if ok {
+		if entry.inProgress {
+			drc.stats.InProgress++
+			status = drcInProgress
+		} else {
+			drc.stats.Hits++
+			results = entry.results
+			status = drcReplay
+		}
+		return
+	}
+
+	drc.stats.Misses++
+
+	entry = &drcEntryStruct{key: key, inProgress: true, created: time.Now()}
+	entry.element = drc.lru.PushBack(entry)
+	drc.entries[key] = entry
+
+	drc.prune(entry.created)
+
+	drcReplier = &drcReplierStruct{connHandle: connHandle, xid: xid, entry: entry}
+	status = drcMiss
+
+	return
+}
+
+// complete records the results of the reply sent via drcReplier such that retransmissions are answered with
+// them. If the call was instead rejected (or not answered at all), it is forgotten such that a retransmission
+// is executed anew.
+func (drc *drcStruct) complete(drcReplier *drcReplierStruct) {
+	var (
+		entry *drcEntryStruct
+		ok    bool
+	)
+
+	drc.Lock()
+	defer drc.Unlock()
+
+	entry, ok = drc.entries[drcReplier.entry.key]
+	if !ok || (entry != drcReplier.entry) {
+		return // evicted while in progress
+	}
+
+	if drcReplier.replied {
+		entry.inProgress = false
+		entry.results = drcReplier.results
+	} else {
+		drc.remove(entry)
+	}
+}
+
+// prune evicts entries beyond drc.maxEntries or older than drc.maxAge (oldest first). Must be called with drc locked.
+func (drc *drcStruct) prune(now time.Time) {
+	var (
+		entry *drcEntryStruct
+	)
+
+	for 0 < drc.lru.Len() {
+		entry = drc.lru.Front().Value.(*drcEntryStruct)
+		if (drc.lru.Len() <= drc.maxEntries) && (now.Sub(entry.created) < drc.maxAge) {
+			return
+		}
+		drc.remove(entry)
+		drc.stats.Evictions++
+	}
+}
+
+// remove must be called with drc locked
+func (drc *drcStruct) remove(entry *drcEntryStruct) {
+	drc.lru.Remove(entry.element)
+	delete(drc.entries, entry.key)
+}
+
+// getStats returns a snapshot of the counters of drc (which may be nil)
+func (drc *drcStruct) getStats() (stats DRCStatsStruct) {
+	if nil == drc {
+		return
+	}
+
+	drc.Lock()
+	stats = drc.stats
+	stats.Entries = drc.lru.Len()
+	drc.Unlock()
+
+	return
+}
+
+func (drcReplier *drcReplierStruct) RemoteAddr() (remoteAddr net.Addr) {
+	remoteAddr = drcReplier.connHandle.RemoteAddr()
+	return
+}
+
+func (drcReplier *drcReplierStruct) sendAcceptedSuccess(results []byte) (err error) {
+	drcReplier.results = results
+	drcReplier.replied = true
+	err = drcReplier.connHandle.sendAcceptedSuccess(results)
+	return
+}
+
+func (drcReplier *drcReplierStruct) sendAcceptedOtherErrorReply(acceptStat uint32) (err error) {
+	err = drcReplier.connHandle.sendAcceptedOtherErrorReply(acceptStat)
+	return
+}
+
+func (drcReplier *drcReplierStruct) sendAuthErrorReply(authStat uint32) (err error) {
+	err = drcReplier.connHandle.sendAuthErrorReply(authStat)
+	return
+}
+
+func (drcReplier *drcReplierStruct) sentReply() (sent bool) {
+	sent = drcReplier.connHandle.sentReply()
+	return
+}
+
+// rpcReplier returns the *rpcReplierStruct via which a call was received by this package's own transport (looking
+// through any drcReplierStruct in which it was wrapped)
+func rpcReplier(connHandle connHandleInterface) (replier *rpcReplierStruct, ok bool) {
+	var (
+		drcReplier *drcReplierStruct
+	)
+
+	drcReplier, ok = connHandle.(*drcReplierStruct)
+	if ok {
+		connHandle = drcReplier.connHandle
+	}
+
+	replier, ok = connHandle.(*rpcReplierStruct)
+
+	return
+}
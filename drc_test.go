@@ -0,0 +1,120 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/xdr"
+)
+
+// testDRCReplierStruct is a testReplierStruct also reporting the address of the client
+type testDRCReplierStruct struct {
+	testReplierStruct
+	addr net.Addr
+}
+
+func (testDRCReplier *testDRCReplierStruct) RemoteAddr() net.Addr {
+	return testDRCReplier.addr
+}
+
+// testRemovingNFSv3Struct answers the first REMOVE of each name with OK & any subsequent one with NFS3ErrNOENT
+type testRemovingNFSv3Struct struct {
+	NFSv3Interface
+	t       *testing.T
+	removed map[string]int
+}
+
+func (testRemovingNFSv3 *testRemovingNFSv3Struct) ErrorLog(err error) {
+	testRemovingNFSv3.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testRemovingNFSv3 *testRemovingNFSv3Struct) NFSProc3Remove(credential *CredentialStruct, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) {
+	testRemovingNFSv3.removed[nfsProc3RemoveArgs.Where.Name]++
+	if 1 == testRemovingNFSv3.removed[nfsProc3RemoveArgs.Where.Name] {
+		nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{Status: OK}
+	} else {
+		nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{Status: NFS3ErrNOENT}
+	}
+	return
+}
+
+func testRemoveParms(t *testing.T, name string) (parms []byte) {
+	parms, err := xdr.Pack(&NFSProc3RemoveArgsStruct{Where: DirOpArgs3Struct{Dir: []byte{1, 2}, Name: name}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+	return
+}
+
+func testRemoveStatus(t *testing.T, nfsRequestHandler *nfsRequestHandlerStruct, port int, xid uint32, name string) (status uint32) {
+	testReplier := &testDRCReplierStruct{addr: &net.UDPAddr{IP: net.ParseIP("192.168.1.77"), Port: port}}
+
+	nfsRequestHandler.dispatch(testReplier, xid, NFSPROC3REMOVE, &CredentialStruct{Flavor: AuthSys}, testRemoveParms(t, name))
+	if 4 > len(testReplier.results) {
+		t.Fatalf("REMOVE (xid 0x%08X) returned %v", xid, testReplier.results)
+	}
+
+	status = binary.BigEndian.Uint32(testReplier.results)
+
+	return
+}
+
+func TestDRC(t *testing.T) {
+	callbacks := &testRemovingNFSv3Struct{t: t, removed: make(map[string]int)}
+	nfsRequestHandler := &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks), drc: newDRC(2, time.Minute)}
+
+	if OK != testRemoveStatus(t, nfsRequestHandler, 700, 1, "victim") {
+		t.Fatalf("original REMOVE should have returned OK")
+	}
+	if OK != testRemoveStatus(t, nfsRequestHandler, 701, 1, "victim") {
+		t.Fatalf("retransmitted REMOVE (via a new connection) should have been answered with the cached OK")
+	}
+	if 1 != callbacks.removed["victim"] {
+		t.Fatalf("retransmitted REMOVE should not have invoked NFSProc3Remove")
+	}
+	if NFS3ErrNOENT != testRemoveStatus(t, nfsRequestHandler, 700, 2, "victim") {
+		t.Fatalf("REMOVE bearing a new xid should have been executed")
+	}
+	if OK != testRemoveStatus(t, nfsRequestHandler, 700, 2, "other") {
+		t.Fatalf("REMOVE reusing an xid with different arguments should have been executed")
+	}
+
+	drcStats := nfsRequestHandler.drc.getStats()
+	if (1 != drcStats.Hits) || (3 != drcStats.Misses) || (1 != drcStats.Evictions) || (2 != drcStats.Entries) {
+		t.Fatalf("getStats() returned %+v", drcStats)
+	}
+
+	connHandle := &testDRCReplierStruct{addr: &net.UDPAddr{IP: net.ParseIP("192.168.1.78"), Port: 700}}
+	parms := testRemoveParms(t, "slow")
+
+	drcReplier, _, drcStatus := nfsRequestHandler.drc.lookup(connHandle, 3, NFSPROC3REMOVE, parms)
+	if drcMiss != drcStatus {
+		t.Fatalf("lookup() of a new call returned %v... expected drcMiss", drcStatus)
+	}
+	_, _, drcStatus = nfsRequestHandler.drc.lookup(connHandle, 3, NFSPROC3REMOVE, parms)
+	if drcInProgress != drcStatus {
+		t.Fatalf("lookup() of a call still in progress returned %v... expected drcInProgress", drcStatus)
+	}
+	nfsRequestHandler.drc.complete(drcReplier)
+	_, _, drcStatus = nfsRequestHandler.drc.lookup(connHandle, 3, NFSPROC3REMOVE, parms)
+	if drcMiss != drcStatus {
+		t.Fatalf("lookup() of a call completed without a reply returned %v... expected drcMiss", drcStatus)
+	}
+
+	_, _, drcStatus = nfsRequestHandler.drc.lookup(connHandle, 4, NFSPROC3GETATTR, parms)
+	if drcUncached != drcStatus {
+		t.Fatalf("lookup() of an idempotent procedure returned %v... expected drcUncached", drcStatus)
+	}
+
+	aging := newDRC(0, time.Millisecond)
+	drcReplier, _, _ = aging.lookup(connHandle, 5, NFSPROC3REMOVE, parms)
+	_ = drcReplier.sendAcceptedSuccess([]byte{0, 0, 0, 0})
+	aging.complete(drcReplier)
+	time.Sleep(10 * time.Millisecond)
+	_, _, drcStatus = aging.lookup(connHandle, 5, NFSPROC3REMOVE, parms)
+	if drcMiss != drcStatus {
+		t.Fatalf("lookup() of a call whose reply has aged out returned %v... expected drcMiss", drcStatus)
+	}
+}
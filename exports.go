@@ -0,0 +1,575 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// When an export table is in effect (see SetExportTable), each file handle returned to clients is prefixed by
+// the exportIDSize byte ID of the export it belongs to. The prefix is stripped before handles are supplied to
+// callbacks (which may thus return handles of at most FHSize3 - exportIDSize bytes). Export IDs are derived
+// from the export's Path such that handles remain valid across restarts (and reorderings of the table).
+
+const (
+	exportIDSize = 4
+
+	exportMatchCacheTTL     = 60 * time.Second // how long a client's match (or lack thereof) against an export is remembered
+	exportMatchCacheMaxSize = 1 << 14          // limit on the number of remembered matches
+)
+
+type exportClientMatcherKind int
+
+const (
+	exportClientMatchAny exportClientMatcherKind = iota
+	exportClientMatchIP
+	exportClientMatchIPNet
+	exportClientMatchHostName
+	exportClientMatchWildcard
+	exportClientMatchNetgroup
+)
+
+type exportClientStruct struct {
+	kind    exportClientMatcherKind
+	pattern string     // lower-cased host name, wildcard, or netgroup name (sans "@")
+	ip      net.IP     // only used/valid if kind == exportClientMatchIP
+	ipNet   *net.IPNet // only used/valid if kind == exportClientMatchIPNet
+	options *ExportOptionsStruct
+}
+
+type exportStruct struct {
+	id      uint32
+	path    string
+	clients []*exportClientStruct
+	groups  []string // the Pattern of each client (as reported via MOUNTPROC3_EXPORT)
+}
+
+type exportMatchCacheKeyStruct struct {
+	exportID   uint32
+	clientAddr string
+}
+
+type exportMatchCacheEntryStruct struct {
+	options *ExportOptionsStruct // if nil, the client did not match
+	expires time.Time
+}
+
+type exportTableStruct struct {
+	sync.Mutex                                                            // protects matchCache
+	exports     []*exportStruct                                           // sorted by descending len(path)
+	exportsByID map[uint32]*exportStruct                                  // keyed by the ID prefixing each file handle
+	netgroups   map[string][]string                                       // netgroup name to (fully expanded) host names
+	matchCache  map[exportMatchCacheKeyStruct]exportMatchCacheEntryStruct // remembers recent results of matchClient()
+	lookupAddr  func(addr string) (names []string, err error)             // reverse DNS (replaceable for testing)
+	lookupHost  func(host string) (addrs []string, err error)             // forward DNS (replaceable for testing)
+}
+
+var globalExportTable *exportTableStruct // used by servers launched via StartIPv4{TCP|UDP}{MountV3|NFSv3}Server (if non-nil)
+
+func exportID(exportPath string) (id uint32) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(exportPath))
+	id = hash.Sum32()
+	return
+}
+
+// newExportTable validates and compiles the supplied ExportTableStruct
+func newExportTable(exportTable *ExportTableStruct) (table *exportTableStruct, err error) {
+	var (
+		compiledClient *exportClientStruct
+		export         *exportStruct
+		exportClient   ExportClientStruct
+		exportIn       ExportStruct
+		id             uint32
+		members        []string
+		name           string
+		ok             bool
+		options        *ExportOptionsStruct
+		otherExport    *exportStruct
+	)
+
+	table = &exportTableStruct{
+		exports:     make([]*exportStruct, 0, len(exportTable.Exports)),
+		exportsByID: make(map[uint32]*exportStruct),
+		netgroups:   make(map[string][]string),
+		matchCache:  make(map[exportMatchCacheKeyStruct]exportMatchCacheEntryStruct),
+		lookupAddr:  net.LookupAddr,
+		lookupHost:  net.LookupHost,
+	}
+
+	for name, members = range exportTable.Netgroups {
+		table.netgroups[name] = append([]string{}, members...)
+	}
+
+	for _, exportIn = range exportTable.Exports {
+		if !strings.HasPrefix(exportIn.Path, "/") || (MntPathLen < uint32(len(exportIn.Path))) {
+			err = fmt.Errorf("export path \"%s\" invalid", exportIn.Path)
+			table = nil
+			return
+		}
+
+		id = exportID(path.Clean(exportIn.Path))
+
+		otherExport, ok = table.exportsByID[id]
+		if ok {
+			if otherExport.path == path.Clean(exportIn.Path) {
+				err = fmt.Errorf("export path \"%s\" listed more than once", exportIn.Path)
+			} else {
+				err = fmt.Errorf("export paths \"%s\" & \"%s\" collide", otherExport.path, exportIn.Path)
+			}
+			table = nil
+			return
+		}
+
+		export = &exportStruct{
+			id:      id,
+			path:    path.Clean(exportIn.Path),
+			clients: make([]*exportClientStruct, 0, len(exportIn.Clients)),
+			groups:  make([]string, 0, len(exportIn.Clients)),
+		}
+
+		for _, exportClient = range exportIn.Clients {
+			options = &ExportOptionsStruct{}
+			*options = exportClient.Options
+			options.IdentityMapping = *copyIdentityMapping(&exportClient.Options.IdentityMapping)
+			options.AuthFlavors = append([]uint32{}, exportClient.Options.AuthFlavors...)
+			if 0 == len(options.AuthFlavors) {
+				options.AuthFlavors = []uint32{AuthSys}
+			}
+
+			compiledClient, err = table.compileClient(exportClient.Pattern)
+			if nil != err {
+				err = fmt.Errorf("export \"%s\": %v", exportIn.Path, err)
+				table = nil
+				return
+			}
+			compiledClient.options = options
+
+			export.clients = append(export.clients, compiledClient)
+			export.groups = append(export.groups, exportClient.Pattern)
+		}
+
+		table.exports = append(table.exports, export)
+		table.exportsByID[id] = export
+	}
+
+	sort.SliceStable(table.exports, func(i int, j int) bool {
+		return len(table.exports[i].path) > len(table.exports[j].path)
+	})
+
+	return
+}
+
+func (table *exportTableStruct) compileClient(pattern string) (exportClient *exportClientStruct, err error) {
+	var (
+		ip    net.IP
+		ipNet *net.IPNet
+		mask  net.IP
+		ok    bool
+		parts []string
+	)
+
+	exportClient = &exportClientStruct{}
+
+	switch {
+	case ("" == pattern) || ("*" == pattern):
+		exportClient.kind = exportClientMatchAny
+	case strings.HasPrefix(pattern, "@"):
+		exportClient.kind = exportClientMatchNetgroup
+		exportClient.pattern = pattern[1:]
+		_, ok = table.netgroups[exportClient.pattern]
+		if !ok {
+			err = fmt.Errorf("netgroup \"%s\" not defined", exportClient.pattern)
+		}
+	case strings.Contains(pattern, "/"):
+		exportClient.kind = exportClientMatchIPNet
+		_, ipNet, err = net.ParseCIDR(pattern)
+		if nil != err {
+			parts = strings.SplitN(pattern, "/", 2)
+			ip = net.ParseIP(parts[0])
+			mask = net.ParseIP(parts[1])
+			if (nil == ip) || (nil == ip.To4()) || (nil == mask) || (nil == mask.To4()) {
+				err = fmt.Errorf("client pattern \"%s\" is not a valid IP network", pattern)
+				return
+			}
+			ipNet = &net.IPNet{IP: ip.To4().Mask(net.IPMask(mask.To4())), Mask: net.IPMask(mask.To4())}
+			err = nil
+		}
+		exportClient.ipNet = ipNet
+	case nil != net.ParseIP(pattern):
+		exportClient.kind = exportClientMatchIP
+		exportClient.ip = net.ParseIP(pattern)
+	case strings.ContainsAny(pattern, "*?["):
+		exportClient.kind = exportClientMatchWildcard
+		exportClient.pattern = strings.ToLower(pattern)
+		_, err = path.Match(exportClient.pattern, "")
+		if nil != err {
+			err = fmt.Errorf("client pattern \"%s\" malformed: %v", pattern, err)
+		}
+	default:
+		exportClient.kind = exportClientMatchHostName
+		exportClient.pattern = strings.ToLower(pattern)
+	}
+
+	return
+}
+
+// lookupPath returns the export containing dirPath (i.e. the one with the longest matching Path)
+func (table *exportTableStruct) lookupPath(dirPath string) (export *exportStruct) {
+	dirPath = path.Clean(dirPath)
+
+	for _, export = range table.exports {
+		if (export.path == dirPath) || ("/" == export.path) || strings.HasPrefix(dirPath, export.path+"/") {
+			return
+		}
+	}
+
+	export = nil
+	return
+}
+
+// matchClient returns the options applying to clientAddr for export (or nil if it is not permitted access)
+func (table *exportTableStruct) matchClient(export *exportStruct, clientAddr string) (options *ExportOptionsStruct) {
+	var (
+		cacheEntry exportMatchCacheEntryStruct
+		cacheKey   = exportMatchCacheKeyStruct{exportID: export.id, clientAddr: clientAddr}
+		ok         bool
+		reverse    *[]string // lazily populated reverse DNS names of clientAddr
+	)
+
+	table.Lock()
+	cacheEntry, ok = table.matchCache[cacheKey]
+	table.Unlock()
+	if ok && time.Now().Before(cacheEntry.expires) {
+		options = cacheEntry.options
+		return
+	}
+
+	reverse = nil
+
+	for _, exportClient := range export.clients {
+		if table.clientMatches(exportClient, clientAddr, &reverse) {
+			options = exportClient.options
+			break
+		}
+	}
+
+	table.Lock()
+	if exportMatchCacheMaxSize <= len(table.matchCache) {
+		table.matchCache = make(map[exportMatchCacheKeyStruct]exportMatchCacheEntryStruct)
+	}
+	table.matchCache[cacheKey] = exportMatchCacheEntryStruct{options: options, expires: time.Now().Add(exportMatchCacheTTL)}
+	table.Unlock()
+
+	return
+}
+
+func (table *exportTableStruct) clientMatches(exportClient *exportClientStruct, clientAddr string, reverse **[]string) (matches bool) {
+	var (
+		hostName string
+		ip       = net.ParseIP(clientAddr)
+	)
+
+	switch exportClient.kind {
+	case exportClientMatchAny:
+		matches = true
+	case exportClientMatchIP:
+		matches = (nil != ip) && exportClient.ip.Equal(ip)
+	case exportClientMatchIPNet:
+		matches = (nil != ip) && exportClient.ipNet.Contains(ip)
+	case exportClientMatchHostName:
+		matches = table.hostNameMatches(exportClient.pattern, clientAddr, ip, reverse)
+	case exportClientMatchWildcard:
+		for _, hostName = range table.reverseNames(clientAddr, ip, reverse) {
+			matches, _ = path.Match(exportClient.pattern, hostName)
+			if matches {
+				break
+			}
+		}
+	case exportClientMatchNetgroup:
+		for _, hostName = range table.netgroups[exportClient.pattern] {
+			if ("" == hostName) || table.hostNameMatches(strings.ToLower(hostName), clientAddr, ip, reverse) {
+				matches = true
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// hostNameMatches determines if hostName resolves to clientAddr (or clientAddr reverse resolves to hostName)
+func (table *exportTableStruct) hostNameMatches(hostName string, clientAddr string, ip net.IP, reverse **[]string) (matches bool) {
+	var (
+		addr  string
+		addrs []string
+		err   error
+		name  string
+	)
+
+	if nil == ip {
+		return
+	}
+
+	for _, name = range table.reverseNames(clientAddr, ip, reverse) {
+		if name == hostName {
+			matches = true
+			return
+		}
+	}
+
+	addrs, err = table.lookupHost(hostName)
+	if nil != err {
+		return
+	}
+
+	for _, addr = range addrs {
+		if ip.Equal(net.ParseIP(addr)) {
+			matches = true
+			return
+		}
+	}
+
+	return
+}
+
+func (table *exportTableStruct) reverseNames(clientAddr string, ip net.IP, reverse **[]string) (names []string) {
+	var (
+		err           error
+		i             int
+		lookedUpNames []string
+	)
+
+	if nil != *reverse {
+		names = **reverse
+		return
+	}
+
+	if nil != ip {
+		lookedUpNames, err = table.lookupAddr(clientAddr)
+		if nil != err {
+			lookedUpNames = nil
+		}
+	}
+
+	names = make([]string, 0, len(lookedUpNames))
+	for i = range lookedUpNames {
+		names = append(names, strings.ToLower(strings.TrimSuffix(lookedUpNames[i], ".")))
+	}
+
+	*reverse = &names
+
+	return
+}
+
+// authFlavorPermitted determines if a credential's flavor is among those permitted by options. Note that
+// AUTH_NONE requests are permitted wherever AUTH_SYS requests are (as they are mapped to the anonymous identity).
+func (options *ExportOptionsStruct) authFlavorPermitted(credential *CredentialStruct) (permitted bool) {
+	var (
+		authFlavor uint32
+		flavor     uint32
+	)
+
+	switch credential.Flavor {
+	case RPCSecGSS:
+		switch credential.Service {
+		case RPCGSSSvcNone:
+			flavor = AuthKrb5
+		case RPCGSSSvcIntegrity:
+			flavor = AuthKrb5I
+		case RPCGSSSvcPrivacy:
+			flavor = AuthKrb5P
+		}
+	default:
+		flavor = credential.Flavor
+	}
+
+	for _, authFlavor = range options.AuthFlavors {
+		if (authFlavor == flavor) || ((AuthNone == flavor) && (AuthSys == authFlavor)) {
+			permitted = true
+			return
+		}
+	}
+
+	permitted = false
+	return
+}
+
+// wrapFHandle prefixes a file handle returned by a callback with the ID of export. If export is nil (i.e. no
+// export table is in effect), fHandle is returned as is.
+func (export *exportStruct) wrapFHandle(fHandle []byte) (wrappedFHandle []byte, err error) {
+	if nil == export {
+		wrappedFHandle = fHandle
+		return
+	}
+
+	if (FHSize3 - exportIDSize) < uint32(len(fHandle)) {
+		err = fmt.Errorf("file handle length (%v) exceeds FHSize3 - %v (%v) when an export table is in effect", len(fHandle), exportIDSize, FHSize3-exportIDSize)
+		return
+	}
+
+	wrappedFHandle = make([]byte, exportIDSize, exportIDSize+len(fHandle))
+	binary.BigEndian.PutUint32(wrappedFHandle, export.id)
+	wrappedFHandle = append(wrappedFHandle, fHandle...)
+
+	return
+}
+
+// unwrapFHandle returns the export to which a (prefixed) file handle received from a client belongs
+// along with the file handle to supply to callbacks (or nil if the file handle is not recognized)
+func (table *exportTableStruct) unwrapFHandle(wrappedFHandle []byte) (export *exportStruct, fHandle []byte) {
+	var (
+		ok bool
+	)
+
+	if exportIDSize > len(wrappedFHandle) {
+		return
+	}
+
+	export, ok = table.exportsByID[binary.BigEndian.Uint32(wrappedFHandle)]
+	if !ok {
+		export = nil
+		return
+	}
+
+	fHandle = wrappedFHandle[exportIDSize:]
+
+	return
+}
+
+// exportsResults returns the exports list reported via MOUNTPROC3_EXPORT
+func (table *exportTableStruct) exportsResults() (mountProc3ExportResults *MountProc3ExportResultsStruct) {
+	var (
+		export *exportStruct
+	)
+
+	mountProc3ExportResults = &MountProc3ExportResultsStruct{Exports: make([]ExportNodeStruct, 0, len(table.exports))}
+
+	for _, export = range table.exports {
+		mountProc3ExportResults.Exports = append(mountProc3ExportResults.Exports, ExportNodeStruct{Dir: export.path, Groups: export.groups})
+	}
+
+	sort.Slice(mountProc3ExportResults.Exports, func(i int, j int) bool {
+		return mountProc3ExportResults.Exports[i].Dir < mountProc3ExportResults.Exports[j].Dir
+	})
+
+	return
+}
+
+// admit determines whether the client issuing an NFSv3 request may access the export to which each of the
+// supplied file handles belongs. If so, each file handle is stripped of its export ID (in place) and the
+// credential to supply to callbacks is returned along with the export and whether or not the client's access
+// is read-only. Otherwise, status indicates why not (including NFS3ErrJUKEBOX if the server is draining and
+// NFS3ErrACCES if a secure export's client is not known to have used a privileged port).
+func (nfsRequestHandler *nfsRequestHandlerStruct) admit(connHandle connHandleInterface, credential *CredentialStruct, fHandles ...*[]byte) (export *exportStruct, admittedCredential *CredentialStruct, readOnly bool, status uint32) {
+	var (
+		fHandle       *[]byte
+		fHandleExport *exportStruct
+		options       *ExportOptionsStruct
+		table         = nfsRequestHandler.exportTable
+		unwrapped     []byte
+	)
+
+	if callDeferred(connHandle) && (0 != len(fHandles)) { // NULL (supplying no file handles) is answered regardless
+		status = NFS3ErrJUKEBOX
+		return
+	}
+
+	if nil == table {
+		admittedCredential = nfsRequestHandler.identityMapping.apply(credential)
+		readOnly = nfsRequestHandler.readOnly
+		status = OK
+		return
+	}
+
+	if 0 == len(fHandles) {
+		admittedCredential = credential
+		readOnly = nfsRequestHandler.readOnly
+		status = OK
+		return
+	}
+
+	for _, fHandle = range fHandles {
+		if exportIDSize > len(*fHandle) {
+			status = NFS3ErrBADHANDLE
+			return
+		}
+		fHandleExport, unwrapped = table.unwrapFHandle(*fHandle)
+		if nil == fHandleExport {
+			status = NFS3ErrSTALE
+			return
+		}
+		if (nil != export) && (export != fHandleExport) {
+			status = NFS3ErrXDEV
+			return
+		}
+		export = fHandleExport
+		*fHandle = unwrapped
+	}
+
+	options = table.matchClient(export, clientAddr(connHandle))
+	if (nil == options) || !options.authFlavorPermitted(credential) || (options.Secure && !privilegedPort(connHandle)) {
+		status = NFS3ErrACCES
+		return
+	}
+
+	admittedCredential = options.IdentityMapping.apply(credential)
+	readOnly = nfsRequestHandler.readOnly || options.ReadOnly
+	status = OK
+
+	return
+}
+
+// wrapPostOpFh3 prefixes the file handle (if any) of a post_op_fh3 returned by a callback (see wrapFHandle)
+func (export *exportStruct) wrapPostOpFh3(postOpFh3 *PostOpFh3Struct) (err error) {
+	if postOpFh3.HandleFollows {
+		postOpFh3.Handle, err = export.wrapFHandle(postOpFh3.Handle)
+	}
+	return
+}
+
+// portPermitted determines whether the client issuing an NFSv3 request may do so from its source port. As the
+// arguments of every NFSv3 procedure (other than NULL) begin with an nfs_fh3, the export is determined from
+// the leading file handle in parms. Requests whose export cannot be determined are permitted here (so as to
+// be answered by the procedure's handler with the appropriate nfsstat3).
+func (nfsRequestHandler *nfsRequestHandlerStruct) portPermitted(connHandle connHandleInterface, parms []byte) (permitted bool) {
+	var (
+		export        *exportStruct
+		fHandleLength uint32
+		options       *ExportOptionsStruct
+		table         = nfsRequestHandler.exportTable
+	)
+
+	if nil == table {
+		permitted = true
+		return
+	}
+
+	if 4 > len(parms) {
+		permitted = true
+		return
+	}
+
+	fHandleLength = binary.BigEndian.Uint32(parms)
+	if (FHSize3 < fHandleLength) || (uint32(len(parms)-4) < fHandleLength) {
+		permitted = true
+		return
+	}
+
+	export, _ = table.unwrapFHandle(parms[4 : 4+fHandleLength])
+	if nil == export {
+		permitted = true
+		return
+	}
+
+	options = table.matchClient(export, clientAddr(connHandle))
+	permitted = (nil == options) || !options.Secure || privilegedPort(connHandle)
+
+	return
+}
@@ -0,0 +1,420 @@
+package nfsd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func defaultExportOptions() (exportOptions ExportOptionsStruct) {
+	exportOptions = ExportOptionsStruct{
+		ReadOnly: true,
+		Secure:   true,
+		Sync:     true,
+		IdentityMapping: IdentityMappingStruct{
+			RootSquash: true,
+			AllSquash:  false,
+			AnonUID:    DefaultAnonUID,
+			AnonGID:    DefaultAnonGID,
+		},
+		AuthFlavors: []uint32{AuthSys},
+	}
+	return
+}
+
+// readLogicalLines returns the lines of an exports(5) or netgroup(5) formatted file with comments stripped
+// and backslash-newline continuations joined. Each line is returned along with its (first) line number.
+func readLogicalLines(reader io.Reader) (lines []string, lineNumbers []int, err error) {
+	var (
+		commentIndex int
+		line         string
+		lineNumber   int
+		pending      string
+		pendingStart int
+		scanner      = bufio.NewScanner(reader)
+	)
+
+	for scanner.Scan() {
+		lineNumber++
+		line = scanner.Text()
+
+		commentIndex = strings.Index(line, "#")
+		if 0 <= commentIndex {
+			line = line[:commentIndex]
+		}
+
+		if "" == pending {
+			pendingStart = lineNumber
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			pending += line[:len(line)-1] + " "
+			continue
+		}
+
+		line = strings.TrimSpace(pending + line)
+		pending = ""
+
+		if "" != line {
+			lines = append(lines, line)
+			lineNumbers = append(lineNumbers, pendingStart)
+		}
+	}
+
+	err = scanner.Err()
+	if (nil == err) && ("" != strings.TrimSpace(pending)) {
+		lines = append(lines, strings.TrimSpace(pending))
+		lineNumbers = append(lineNumbers, pendingStart)
+	}
+
+	return
+}
+
+// splitExportsLine splits an exports(5) line into whitespace separated fields honoring double quotes
+// (e.g. around a path containing spaces) and \ooo octal escapes
+func splitExportsLine(line string) (fields []string, err error) {
+	var (
+		field    strings.Builder
+		i        int
+		inField  bool
+		inQuotes bool
+		octal    uint64
+	)
+
+	for i = 0; i < len(line); i++ {
+		switch {
+		case '"' == line[i]:
+			inQuotes = !inQuotes
+			inField = true
+		case ('\\' == line[i]) && (i+3 < len(line)) && isOctalDigits(line[i+1:i+4]):
+			octal, _ = strconv.ParseUint(line[i+1:i+4], 8, 8)
+			field.WriteByte(byte(octal))
+			inField = true
+			i += 3
+		case !inQuotes && ((' ' == line[i]) || ('\t' == line[i])):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteByte(line[i])
+			inField = true
+		}
+	}
+
+	if inQuotes {
+		err = fmt.Errorf("unterminated quote")
+		return
+	}
+
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return
+}
+
+func isOctalDigits(s string) bool {
+	var (
+		i int
+	)
+
+	for i = 0; i < len(s); i++ {
+		if ('0' > s[i]) || ('7' < s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyExportOptions applies a comma separated list of exports(5) options
+func applyExportOptions(exportOptions *ExportOptionsStruct, optionList string) (err error) {
+	var (
+		flavor      string
+		flavors     []uint32
+		id          uint64
+		option      string
+		optionName  string
+		optionValue string
+	)
+
+	for _, option = range strings.Split(optionList, ",") {
+		option = strings.TrimSpace(option)
+		optionName = option
+		optionValue = ""
+		if strings.Contains(option, "=") {
+			optionName = option[:strings.Index(option, "=")]
+			optionValue = option[strings.Index(option, "=")+1:]
+		}
+
+		switch optionName {
+		case "":
+			// tolerate empty options (e.g. "rw,,sync" or "()")
+		case "rw":
+			exportOptions.ReadOnly = false
+		case "ro":
+			exportOptions.ReadOnly = true
+		case "secure":
+			exportOptions.Secure = true
+		case "insecure":
+			exportOptions.Secure = false
+		case "sync":
+			exportOptions.Sync = true
+		case "async":
+			exportOptions.Sync = false
+		case "root_squash":
+			exportOptions.IdentityMapping.RootSquash = true
+		case "no_root_squash":
+			exportOptions.IdentityMapping.RootSquash = false
+		case "all_squash":
+			exportOptions.IdentityMapping.AllSquash = true
+		case "no_all_squash":
+			exportOptions.IdentityMapping.AllSquash = false
+		case "anonuid":
+			id, err = strconv.ParseUint(optionValue, 10, 32)
+			if nil != err {
+				err = fmt.Errorf("option \"%s\" malformed", option)
+				return
+			}
+			exportOptions.IdentityMapping.AnonUID = uint32(id)
+			exportOptions.IdentityMapping.AnonUIDSet = true
+		case "anongid":
+			id, err = strconv.ParseUint(optionValue, 10, 32)
+			if nil != err {
+				err = fmt.Errorf("option \"%s\" malformed", option)
+				return
+			}
+			exportOptions.IdentityMapping.AnonGID = uint32(id)
+			exportOptions.IdentityMapping.AnonGIDSet = true
+		case "sec":
+			flavors = make([]uint32, 0)
+			for _, flavor = range strings.Split(optionValue, ":") {
+				switch flavor {
+				case "none":
+					flavors = append(flavors, AuthNone)
+				case "sys":
+					flavors = append(flavors, AuthSys)
+				case "krb5":
+					flavors = append(flavors, AuthKrb5)
+				case "krb5i":
+					flavors = append(flavors, AuthKrb5I)
+				case "krb5p":
+					flavors = append(flavors, AuthKrb5P)
+				default:
+					err = fmt.Errorf("option \"%s\" specifies unsupported flavor \"%s\"", option, flavor)
+					return
+				}
+			}
+			exportOptions.AuthFlavors = flavors
+		case "subtree_check", "no_subtree_check", "wdelay", "no_wdelay", "hide", "nohide", "crossmnt", "nocrossmnt",
+			"auth_nlm", "no_auth_nlm", "secure_locks", "insecure_locks", "acl", "no_acl", "fsid", "mountpoint", "mp",
+			"pnfs", "no_pnfs", "security_label":
+			// accepted for compatibility but not applicable to this package
+		default:
+			err = fmt.Errorf("option \"%s\" not recognized", option)
+			return
+		}
+	}
+
+	return
+}
+
+// parseExportClient parses a client specification of the form pattern, pattern(options), or (options)
+func parseExportClient(field string, lineDefaults ExportOptionsStruct) (exportClient ExportClientStruct, err error) {
+	var (
+		openIndex int
+	)
+
+	exportClient.Options = lineDefaults
+	exportClient.Options.AuthFlavors = append([]uint32{}, lineDefaults.AuthFlavors...)
+
+	openIndex = strings.Index(field, "(")
+	if 0 > openIndex {
+		exportClient.Pattern = field
+	} else {
+		if !strings.HasSuffix(field, ")") {
+			err = fmt.Errorf("client specification \"%s\" malformed", field)
+			return
+		}
+		exportClient.Pattern = field[:openIndex]
+		err = applyExportOptions(&exportClient.Options, field[openIndex+1:len(field)-1])
+		if nil != err {
+			return
+		}
+	}
+
+	if "" == exportClient.Pattern {
+		exportClient.Pattern = "*"
+	}
+
+	return
+}
+
+func parseExports(reader io.Reader) (exports []ExportStruct, err error) {
+	var (
+		export       ExportStruct
+		exportClient ExportClientStruct
+		field        string
+		fields       []string
+		line         string
+		lineDefaults ExportOptionsStruct
+		lineIndex    int
+		lineNumbers  []int
+		lines        []string
+	)
+
+	lines, lineNumbers, err = readLogicalLines(reader)
+	if nil != err {
+		return
+	}
+
+	exports = make([]ExportStruct, 0, len(lines))
+
+	for lineIndex, line = range lines {
+		fields, err = splitExportsLine(line)
+		if nil != err {
+			err = fmt.Errorf("exports line %d: %v", lineNumbers[lineIndex], err)
+			return
+		}
+
+		if !strings.HasPrefix(fields[0], "/") {
+			err = fmt.Errorf("exports line %d: path \"%s\" not absolute", lineNumbers[lineIndex], fields[0])
+			return
+		}
+
+		export = ExportStruct{Path: fields[0], Clients: make([]ExportClientStruct, 0, len(fields)-1)}
+		lineDefaults = defaultExportOptions()
+
+		for _, field = range fields[1:] {
+			if strings.HasPrefix(field, "-") {
+				err = applyExportOptions(&lineDefaults, field[1:])
+				if nil != err {
+					err = fmt.Errorf("exports line %d: %v", lineNumbers[lineIndex], err)
+					return
+				}
+				continue
+			}
+
+			exportClient, err = parseExportClient(field, lineDefaults)
+			if nil != err {
+				err = fmt.Errorf("exports line %d: %v", lineNumbers[lineIndex], err)
+				return
+			}
+
+			export.Clients = append(export.Clients, exportClient)
+		}
+
+		if 0 == len(export.Clients) {
+			// As with exportfs, a path listed without any clients is exported to all with the default options
+
+			export.Clients = append(export.Clients, ExportClientStruct{Pattern: "*", Options: lineDefaults})
+		}
+
+		exports = append(exports, export)
+	}
+
+	return
+}
+
+func parseNetgroups(reader io.Reader) (netgroups map[string][]string, err error) {
+	var (
+		closeIndex  int
+		lineIndex   int
+		lineNumbers []int
+		lines       []string
+		member      string
+		members     map[string][]string // netgroup name to member triple hosts (prefixed by "(") or netgroup names
+		name        string
+		rest        string
+		triple      []string
+	)
+
+	lines, lineNumbers, err = readLogicalLines(reader)
+	if nil != err {
+		return
+	}
+
+	members = make(map[string][]string)
+
+	for lineIndex = range lines {
+		rest = lines[lineIndex]
+		name = strings.Fields(rest)[0]
+		rest = strings.TrimSpace(rest[len(name):])
+
+		members[name] = make([]string, 0)
+
+		for "" != rest {
+			if strings.HasPrefix(rest, "(") {
+				closeIndex = strings.Index(rest, ")")
+				if 0 > closeIndex {
+					err = fmt.Errorf("netgroup line %d: triple malformed", lineNumbers[lineIndex])
+					return
+				}
+				triple = strings.Split(rest[1:closeIndex], ",")
+				if 3 != len(triple) {
+					err = fmt.Errorf("netgroup line %d: triple \"%s\" malformed", lineNumbers[lineIndex], rest[:closeIndex+1])
+					return
+				}
+				member = strings.TrimSpace(triple[0])
+				if "-" != member { // "-" indicates no valid host
+					members[name] = append(members[name], "("+member)
+				}
+				rest = strings.TrimSpace(rest[closeIndex+1:])
+			} else {
+				member = strings.Fields(rest)[0]
+				members[name] = append(members[name], member)
+				rest = strings.TrimSpace(rest[len(member):])
+			}
+		}
+	}
+
+	netgroups = make(map[string][]string)
+
+	for name = range members {
+		netgroups[name] = make([]string, 0)
+		err = expandNetgroup(members, name, netgroups, name, make(map[string]bool))
+		if nil != err {
+			return
+		}
+	}
+
+	return
+}
+
+// expandNetgroup appends to netgroups[root] the hosts of netgroup name (recursing into member netgroups)
+func expandNetgroup(members map[string][]string, name string, netgroups map[string][]string, root string, visiting map[string]bool) (err error) {
+	var (
+		member string
+		ok     bool
+	)
+
+	if visiting[name] {
+		err = fmt.Errorf("netgroup \"%s\" is recursively defined", name)
+		return
+	}
+	visiting[name] = true
+
+	for _, member = range members[name] {
+		if strings.HasPrefix(member, "(") {
+			netgroups[root] = append(netgroups[root], member[1:])
+			continue
+		}
+		_, ok = members[member]
+		if !ok {
+			err = fmt.Errorf("netgroup \"%s\" references unknown netgroup \"%s\"", name, member)
+			return
+		}
+		err = expandNetgroup(members, member, netgroups, root, visiting)
+		if nil != err {
+			return
+		}
+	}
+
+	delete(visiting, name)
+
+	return
+}
@@ -0,0 +1,375 @@
+package nfsd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/swiftstack/xdr"
+)
+
+const testExports = `
+# sample exports file
+/srv/public                         # exported to the world with default options
+/srv/data   -rw,no_root_squash  192.168.1.0/24 10.0.0.0/255.0.0.0(ro) \
+            *.example.com(all_squash,anonuid=1000,anongid=1001) @trusted(sec=krb5:krb5p)
+"/srv/with space"  (rw,insecure,async)
+/srv/data/nested   host1.example.com(rw)
+`
+
+const testNetgroup = `
+trusted    (host2.example.com,,) (-,alice,) admins
+admins     (host3.example.com,-,)
+`
+
+func testNewExportTable(t *testing.T) (table *exportTableStruct) {
+	var (
+		err         error
+		exportTable *ExportTableStruct
+	)
+
+	exportTable, err = parseExportTable(strings.NewReader(testExports), strings.NewReader(testNetgroup))
+	if nil != err {
+		t.Fatalf("parseExportTable() failed: %v", err)
+	}
+
+	table, err = newExportTable(exportTable)
+	if nil != err {
+		t.Fatalf("newExportTable() failed: %v", err)
+	}
+
+	table.lookupAddr = func(addr string) (names []string, err error) {
+		switch addr {
+		case "172.16.0.5":
+			names = []string{"ws5.example.com."}
+		case "172.16.0.6":
+			names = []string{"host1.example.com."}
+		default:
+			err = fmt.Errorf("no PTR record for %s", addr)
+		}
+		return
+	}
+	table.lookupHost = func(host string) (addrs []string, err error) {
+		switch host {
+		case "host2.example.com":
+			addrs = []string{"172.16.0.2"}
+		case "host3.example.com":
+			addrs = []string{"172.16.0.3"}
+		default:
+			err = fmt.Errorf("no A record for %s", host)
+		}
+		return
+	}
+
+	return
+}
+
+func TestParseExports(t *testing.T) {
+	var (
+		err         error
+		exportTable *ExportTableStruct
+	)
+
+	exportTable, err = parseExportTable(strings.NewReader(testExports), strings.NewReader(testNetgroup))
+	if nil != err {
+		t.Fatalf("parseExportTable() failed: %v", err)
+	}
+
+	if 4 != len(exportTable.Exports) {
+		t.Fatalf("got %d exports... expected 4", len(exportTable.Exports))
+	}
+
+	public := exportTable.Exports[0]
+	if ("/srv/public" != public.Path) || (1 != len(public.Clients)) || ("*" != public.Clients[0].Pattern) {
+		t.Fatalf("got %+v for /srv/public", public)
+	}
+	if !reflect.DeepEqual(defaultExportOptions(), public.Clients[0].Options) {
+		t.Fatalf("got %+v for /srv/public options... expected defaults", public.Clients[0].Options)
+	}
+
+	data := exportTable.Exports[1]
+	if 4 != len(data.Clients) {
+		t.Fatalf("got %d clients for /srv/data... expected 4", len(data.Clients))
+	}
+	if ("192.168.1.0/24" != data.Clients[0].Pattern) || data.Clients[0].Options.ReadOnly || data.Clients[0].Options.IdentityMapping.RootSquash {
+		t.Fatalf("line defaults not applied to %+v", data.Clients[0])
+	}
+	if !data.Clients[1].Options.ReadOnly || data.Clients[1].Options.IdentityMapping.RootSquash {
+		t.Fatalf("client options not applied over line defaults for %+v", data.Clients[1])
+	}
+	squash := data.Clients[2].Options.IdentityMapping
+	if !squash.AllSquash || (1000 != squash.AnonUID) || (1001 != squash.AnonGID) {
+		t.Fatalf("squash options not applied for %+v", data.Clients[2])
+	}
+	if !reflect.DeepEqual([]uint32{AuthKrb5, AuthKrb5P}, data.Clients[3].Options.AuthFlavors) {
+		t.Fatalf("sec= not applied for %+v", data.Clients[3])
+	}
+
+	spaced := exportTable.Exports[2]
+	if ("/srv/with space" != spaced.Path) || ("*" != spaced.Clients[0].Pattern) {
+		t.Fatalf("got %+v for quoted path", spaced)
+	}
+	if spaced.Clients[0].Options.ReadOnly || spaced.Clients[0].Options.Secure || spaced.Clients[0].Options.Sync {
+		t.Fatalf("got %+v for (rw,insecure,async)", spaced.Clients[0].Options)
+	}
+
+	if !reflect.DeepEqual([]string{"host2.example.com", "host3.example.com"}, exportTable.Netgroups["trusted"]) {
+		t.Fatalf("got %v for netgroup trusted", exportTable.Netgroups["trusted"])
+	}
+
+	for _, badExports := range []string{
+		"srv/relative *(rw)",
+		"/srv *(bogus_option)",
+		"/srv *(rw",
+		"/srv *(sec=ntlm)",
+		"/srv *(anonuid=nobody)",
+		"\"/srv *(rw)",
+	} {
+		_, err = parseExports(strings.NewReader(badExports))
+		if nil == err {
+			t.Fatalf("parseExports(\"%s\") should have failed", badExports)
+		}
+	}
+
+	// anonuid=0 & anongid=0 (unlike omitting them) map squashed requests to root
+
+	rootExports, err := parseExports(strings.NewReader("/srv *(all_squash,anonuid=0,anongid=0)"))
+	if nil != err {
+		t.Fatalf("parseExports() of anonuid=0 & anongid=0 failed: %v", err)
+	}
+	rootMapping := copyIdentityMapping(&rootExports[0].Clients[0].Options.IdentityMapping)
+	if (0 != rootMapping.AnonUID) || (0 != rootMapping.AnonGID) {
+		t.Fatalf("anonuid=0 & anongid=0 mapped to %v:%v", rootMapping.AnonUID, rootMapping.AnonGID)
+	}
+
+	for _, badNetgroup := range []string{
+		"a (h,,) b",
+		"a b\nb a",
+		"a (h,)",
+	} {
+		_, err = parseNetgroups(strings.NewReader(badNetgroup))
+		if nil == err {
+			t.Fatalf("parseNetgroups(\"%s\") should have failed", badNetgroup)
+		}
+	}
+
+	_, err = newExportTable(&ExportTableStruct{Exports: []ExportStruct{{Path: "/x", Clients: []ExportClientStruct{{Pattern: "@missing"}}}}})
+	if nil == err {
+		t.Fatalf("newExportTable() with undefined netgroup should have failed")
+	}
+}
+
+func TestExportMatching(t *testing.T) {
+	var (
+		table = testNewExportTable(t)
+	)
+
+	if "/srv/data/nested" != table.lookupPath("/srv/data/nested/deeper").path {
+		t.Fatalf("lookupPath() should have chosen the longest matching export")
+	}
+	if "/srv/data" != table.lookupPath("/srv/data/other").path {
+		t.Fatalf("lookupPath(\"/srv/data/other\") should have found /srv/data")
+	}
+	if nil != table.lookupPath("/srv/database") {
+		t.Fatalf("lookupPath(\"/srv/database\") should not have matched /srv/data")
+	}
+
+	data := table.lookupPath("/srv/data")
+
+	for _, testCase := range []struct {
+		clientAddr     string
+		expectedClient int // index into data.clients (or -1 if no match is expected)
+	}{
+		{"192.168.1.77", 0},
+		{"10.1.2.3", 1},
+		{"172.16.0.5", 2},
+		{"172.16.0.2", 3},
+		{"172.16.0.3", 3},
+		{"172.16.0.9", -1},
+		{"", -1},
+	} {
+		options := table.matchClient(data, testCase.clientAddr)
+		if -1 == testCase.expectedClient {
+			if nil != options {
+				t.Fatalf("client %s should not have matched", testCase.clientAddr)
+			}
+		} else if data.clients[testCase.expectedClient].options != options {
+			t.Fatalf("client %s should have matched %s", testCase.clientAddr, data.groups[testCase.expectedClient])
+		}
+	}
+
+	nested := table.lookupPath("/srv/data/nested")
+	if nil == table.matchClient(nested, "172.16.0.6") {
+		t.Fatalf("client 172.16.0.6 should have matched host1.example.com via reverse lookup")
+	}
+}
+
+func TestExportFHandles(t *testing.T) {
+	var (
+		admittedCredential *CredentialStruct
+		err                error
+		export             *exportStruct
+		fHandle            []byte
+		otherFHandle       []byte
+		readOnly           bool
+		status             uint32
+		table              = testNewExportTable(t)
+	)
+
+	data := table.lookupPath("/srv/data")
+	public := table.lookupPath("/srv/public")
+
+	fHandle, err = data.wrapFHandle([]byte{1, 2, 3})
+	if nil != err {
+		t.Fatalf("wrapFHandle() failed: %v", err)
+	}
+	if (exportIDSize+3 != len(fHandle)) || !bytes.Equal([]byte{1, 2, 3}, fHandle[exportIDSize:]) {
+		t.Fatalf("wrapFHandle() returned %v", fHandle)
+	}
+
+	_, err = data.wrapFHandle(make([]byte, FHSize3))
+	if nil == err {
+		t.Fatalf("wrapFHandle() of a maximal length handle should have failed")
+	}
+
+	nfsRequestHandler := &nfsRequestHandlerStruct{exportTable: table}
+	connHandle := &testReplierStruct{addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.77"), Port: 700}}
+	credential := &CredentialStruct{Flavor: AuthSys, UID: 0, GID: 0, GIDs: []uint32{}}
+
+	export, admittedCredential, _, status = nfsRequestHandler.admit(connHandle, credential, &fHandle)
+	if (OK != status) || (data != export) || !bytes.Equal([]byte{1, 2, 3}, fHandle) {
+		t.Fatalf("admit() returned status %v, export %v, & fHandle %v", status, export, fHandle)
+	}
+	if 0 != admittedCredential.UID {
+		t.Fatalf("no_root_squash export should not have squashed root: %+v", admittedCredential)
+	}
+
+	fHandle, _ = public.wrapFHandle([]byte{1, 2, 3})
+	_, admittedCredential, readOnly, status = nfsRequestHandler.admit(connHandle, credential, &fHandle)
+	if (OK != status) || (DefaultAnonUID != admittedCredential.UID) {
+		t.Fatalf("root_squash export should have squashed root (status %v)", status)
+	}
+	if !readOnly {
+		t.Fatalf("export with default options should have been read-only")
+	}
+
+	fHandle, _ = public.wrapFHandle([]byte{1, 2, 3})
+	_, _, status = nfsRequestHandler.admitMutation(connHandle, credential, &fHandle)
+	if NFS3ErrROFS != status {
+		t.Fatalf("admitMutation() to read-only export returned %v... expected NFS3ErrROFS", status)
+	}
+
+	fHandle, _ = public.wrapFHandle([]byte{1})
+	otherFHandle, _ = data.wrapFHandle([]byte{2})
+	_, _, _, status = nfsRequestHandler.admit(connHandle, credential, &fHandle, &otherFHandle)
+	if NFS3ErrXDEV != status {
+		t.Fatalf("admit() of handles in different exports returned %v... expected NFS3ErrXDEV", status)
+	}
+
+	_, _, _, status = nfsRequestHandler.admit(connHandle, credential, &[]byte{0xFF, 0xFF, 0xFF, 0xFF, 1})
+	if NFS3ErrSTALE != status {
+		t.Fatalf("admit() of handle in unknown export returned %v... expected NFS3ErrSTALE", status)
+	}
+
+	_, _, _, status = nfsRequestHandler.admit(connHandle, credential, &[]byte{1})
+	if NFS3ErrBADHANDLE != status {
+		t.Fatalf("admit() of short handle returned %v... expected NFS3ErrBADHANDLE", status)
+	}
+
+	fHandle, _ = data.wrapFHandle([]byte{1})
+	connHandle.addr = &net.TCPAddr{IP: net.ParseIP("172.16.0.9"), Port: 700}
+	_, _, _, status = nfsRequestHandler.admit(connHandle, credential, &fHandle)
+	if NFS3ErrACCES != status {
+		t.Fatalf("admit() of unlisted client returned %v... expected NFS3ErrACCES", status)
+	}
+
+	fHandle, _ = data.wrapFHandle([]byte{1})
+	connHandle.addr = &net.TCPAddr{IP: net.ParseIP("172.16.0.2"), Port: 700}
+	_, _, _, status = nfsRequestHandler.admit(connHandle, credential, &fHandle)
+	if NFS3ErrACCES != status {
+		t.Fatalf("admit() of AUTH_SYS request to sec=krb5:krb5p client returned %v... expected NFS3ErrACCES", status)
+	}
+
+	fHandle, _ = data.wrapFHandle([]byte{1})
+	_, _, _, status = nfsRequestHandler.admit(connHandle, &CredentialStruct{Flavor: RPCSecGSS, Service: RPCGSSSvcPrivacy}, &fHandle)
+	if OK != status {
+		t.Fatalf("admit() of RPCSEC_GSS privacy request to sec=krb5:krb5p client returned %v... expected OK", status)
+	}
+}
+
+func TestExportSecure(t *testing.T) {
+	var (
+		err   error
+		parms []byte
+		table = testNewExportTable(t)
+	)
+
+	nfsRequestHandler := &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(&testReadOnlyNFSv3Struct{t: t}), exportTable: table}
+	credential := &CredentialStruct{Flavor: AuthSys}
+
+	for _, testCase := range []struct {
+		exportPath       string
+		port             int
+		expectedAuthStat uint32
+	}{
+		{"/srv/public", 700, rpcAuthStatOK},
+		{"/srv/public", 40000, rpcAuthStatTooWeak},
+		{"/srv/with space", 40000, rpcAuthStatOK},
+	} {
+		fHandle, _ := table.lookupPath(testCase.exportPath).wrapFHandle([]byte{1})
+		parms, err = xdr.Pack(&NFSProc3AccessArgsStruct{Object: fHandle, Access: Access3Read})
+		if nil != err {
+			t.Fatalf("xdr.Pack() failed: %v", err)
+		}
+
+		connHandle := &testReplierStruct{authRejectOK: true}
+		connHandle.addr = &net.UDPAddr{IP: net.ParseIP("192.168.1.77"), Port: testCase.port}
+
+		nfsRequestHandler.dispatch(connHandle, 1, NFSPROC3ACCESS, credential, parms)
+		if testCase.expectedAuthStat != connHandle.authStat {
+			t.Fatalf("%s from port %d got auth_stat %v... expected %v", testCase.exportPath, testCase.port, connHandle.authStat, testCase.expectedAuthStat)
+		}
+		if (rpcAuthStatOK == testCase.expectedAuthStat) && ((0 == len(connHandle.results)) || (OK != binary.BigEndian.Uint32(connHandle.results))) {
+			t.Fatalf("%s from port %d got results %v", testCase.exportPath, testCase.port, connHandle.results)
+		}
+	}
+}
+
+func TestExportSecureWithoutAuthReject(t *testing.T) {
+	var (
+		err   error
+		parms []byte
+		table = testNewExportTable(t)
+	)
+
+	nfsRequestHandler := &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(&testReadOnlyNFSv3Struct{t: t}), exportTable: table}
+	credential := &CredentialStruct{Flavor: AuthSys}
+
+	for _, testCase := range []struct {
+		exportPath     string
+		addr           net.Addr
+		expectedStatus uint32
+	}{
+		{"/srv/public", &net.UDPAddr{IP: net.ParseIP("192.168.1.77"), Port: 700}, OK},
+		{"/srv/public", &net.UDPAddr{IP: net.ParseIP("192.168.1.77"), Port: 40000}, NFS3ErrACCES},
+		{"/srv/public", nil, NFS3ErrACCES}, // i.e. neither reporting the client's address nor able to reject the call
+		{"/srv/with space", &net.UDPAddr{IP: net.ParseIP("192.168.1.77"), Port: 40000}, OK},
+	} {
+		fHandle, _ := table.lookupPath(testCase.exportPath).wrapFHandle([]byte{1})
+		parms, err = xdr.Pack(&NFSProc3AccessArgsStruct{Object: fHandle, Access: Access3Read})
+		if nil != err {
+			t.Fatalf("xdr.Pack() failed: %v", err)
+		}
+
+		connHandle := &testReplierStruct{addr: testCase.addr}
+
+		nfsRequestHandler.dispatch(connHandle, 1, NFSPROC3ACCESS, credential, parms)
+		if (0 == len(connHandle.results)) || (testCase.expectedStatus != binary.BigEndian.Uint32(connHandle.results)) {
+			t.Fatalf("%s from %v got results %v... expected status %v", testCase.exportPath, testCase.addr, connHandle.results, testCase.expectedStatus)
+		}
+	}
+}
@@ -0,0 +1,1045 @@
+package nfsd
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// Each file handle minted by a fileSystemAdapterStruct consists of a version byte followed by the (big-endian)
+// fsid, inode number, & generation of the object
+const (
+	fileSystemFHandleVersion = byte(1)
+	fileSystemFHandleSize    = 1 + 8 + 8 + 4
+)
+
+// fileSystemAdapterStruct satisfies both NFSv3ContextInterface & MountV3Interface on behalf of a
+// FileSystemInterface by minting & resolving file handles and invoking those callbacks the file system implements
+// (the NFSv3ContextInterface being the nfsv3BackendAdapterStruct wrapping it, which answers those procedures the
+// file system does not implement)
+type fileSystemAdapterStruct struct {
+	fileSystem FileSystemInterface
+	rootIno    uint64
+	fsid       uint64
+	writeVerf  [NFS3WriteVerfSize]byte       // returned by WRITE & COMMIT (distinct for each adapter)
+	mutable    bool                          // set if the file system implements any procedure that would modify it
+	attrSetter FileSystemAttrSetterInterface // nil if the file system does not implement FileSystemAttrSetterInterface
+	readLinker FileSystemReadLinkerInterface // nil if the file system does not implement FileSystemReadLinkerInterface
+	writer     FileSystemWriterInterface     // nil if the file system does not implement FileSystemWriterInterface
+	creator    FileSystemCreatorInterface    // nil if the file system does not implement FileSystemCreatorInterface
+	symLinker  FileSystemSymLinkerInterface  // nil if the file system does not implement FileSystemSymLinkerInterface
+	remover    FileSystemRemoverInterface    // nil if the file system does not implement FileSystemRemoverInterface
+	renamer    FileSystemRenamerInterface    // nil if the file system does not implement FileSystemRenamerInterface
+	linker     FileSystemLinkerInterface     // nil if the file system does not implement FileSystemLinkerInterface
+	statter    FileSystemStatterInterface    // nil if the file system does not implement FileSystemStatterInterface
+}
+
+func newFileSystemAdapter(fileSystem FileSystemInterface, rootIno uint64, fsid uint64) (contextCallbacks NFSv3ContextInterface, mountCallbacks MountV3Interface) {
+	var (
+		adapter        *fileSystemAdapterStruct
+		backendAdapter *nfsv3BackendAdapterStruct
+		ok             bool
+	)
+
+	adapter = &fileSystemAdapterStruct{fileSystem: fileSystem, rootIno: rootIno, fsid: fsid}
+
+	binary.BigEndian.PutUint64(adapter.writeVerf[:], uint64(time.Now().UnixNano()))
+
+	// Those procedures the adapter answers itself are always passed along by the backend adapter while the
+	// remainder are passed along only should the file system implement the corresponding interface
+
+	backendAdapter = &nfsv3BackendAdapterStruct{
+		backend:       adapter,
+		nuller:        adapter,
+		accesser:      adapter,
+		reader:        adapter,
+		dirReader:     adapter,
+		dirPlusReader: adapter,
+		fsInfoer:      adapter,
+		pathConfer:    adapter,
+	}
+
+	adapter.attrSetter, ok = fileSystem.(FileSystemAttrSetterInterface)
+	if ok {
+		backendAdapter.attrSetter = adapter
+	}
+	adapter.readLinker, ok = fileSystem.(FileSystemReadLinkerInterface)
+	if ok {
+		backendAdapter.readLinker = adapter
+	}
+	adapter.writer, ok = fileSystem.(FileSystemWriterInterface)
+	if ok {
+		backendAdapter.writer = adapter
+	}
+	adapter.creator, ok = fileSystem.(FileSystemCreatorInterface)
+	if ok {
+		backendAdapter.creator = adapter
+	}
+	adapter.symLinker, ok = fileSystem.(FileSystemSymLinkerInterface)
+	if ok {
+		backendAdapter.symLinker = adapter
+	}
+	adapter.remover, ok = fileSystem.(FileSystemRemoverInterface)
+	if ok {
+		backendAdapter.remover = adapter
+	}
+	adapter.renamer, ok = fileSystem.(FileSystemRenamerInterface)
+	if ok {
+		backendAdapter.renamer = adapter
+	}
+	adapter.linker, ok = fileSystem.(FileSystemLinkerInterface)
+	if ok {
+		backendAdapter.linker = adapter
+	}
+	adapter.statter, ok = fileSystem.(FileSystemStatterInterface)
+	if ok {
+		backendAdapter.fsStater = adapter
+	}
+
+	adapter.mutable = backendAdapter.mutable()
+
+	contextCallbacks = backendAdapter
+	mountCallbacks = adapter
+
+	return
+}
+
+// fHandleOf returns the file handle of inode ino bearing generation
+func (adapter *fileSystemAdapterStruct) fHandleOf(ino uint64, generation uint32) (fHandle []byte) {
+	fHandle = make([]byte, fileSystemFHandleSize)
+	fHandle[0] = fileSystemFHandleVersion
+	binary.BigEndian.PutUint64(fHandle[1:9], adapter.fsid)
+	binary.BigEndian.PutUint64(fHandle[9:17], ino)
+	binary.BigEndian.PutUint32(fHandle[17:21], generation)
+	return
+}
+
+// getAttr returns the attributes of inode ino (mapping NFS3ErrNOENT to NFS3ErrSTALE as ino was previously
+// returned by the file system)
+func (adapter *fileSystemAdapterStruct) getAttr(ctx context.Context, ino uint64) (attr *FileSystemAttrStruct, status uint32) {
+	attr, status = adapter.fileSystem.GetAttr(ctx, ino)
+	if NFS3ErrNOENT == status {
+		status = NFS3ErrSTALE
+	}
+	return
+}
+
+// resolve returns the inode number (and current attributes) of the object identified by fHandle
+func (adapter *fileSystemAdapterStruct) resolve(ctx context.Context, fHandle []byte) (ino uint64, attr *FileSystemAttrStruct, status uint32) {
+	if (fileSystemFHandleSize != len(fHandle)) || (fileSystemFHandleVersion != fHandle[0]) {
+		status = NFS3ErrBADHANDLE
+		return
+	}
+	if adapter.fsid != binary.BigEndian.Uint64(fHandle[1:9]) {
+		status = NFS3ErrSTALE
+		return
+	}
+
+	ino = binary.BigEndian.Uint64(fHandle[9:17])
+
+	attr, status = adapter.getAttr(ctx, ino)
+	if OK != status {
+		return
+	}
+	if attr.Generation != binary.BigEndian.Uint32(fHandle[17:21]) {
+		status = NFS3ErrSTALE
+	}
+
+	return
+}
+
+// resolveDir is like resolve but also requires that the object be a directory
+func (adapter *fileSystemAdapterStruct) resolveDir(ctx context.Context, fHandle []byte) (ino uint64, attr *FileSystemAttrStruct, status uint32) {
+	ino, attr, status = adapter.resolve(ctx, fHandle)
+	if (OK == status) && (FTypeDIR != attr.Type) {
+		status = NFS3ErrNOTDIR
+	}
+	return
+}
+
+// checkName returns the status with which to answer a procedure naming name within a directory (dotStatus
+// should name be "." or "..")
+func checkName(name string, dotStatus uint32) (status uint32) {
+	switch {
+	case "" == name:
+		status = NFS3ErrNOENT
+	case DefaultNameMax < uint32(len(name)):
+		status = NFS3ErrNAMETOOLONG
+	case strings.ContainsAny(name, "/\x00"):
+		status = NFS3ErrINVAL
+	case ("." == name) || (".." == name):
+		status = dotStatus
+	default:
+		status = OK
+	}
+	return
+}
+
+// lookup returns the inode number (and attributes) of name within directory dirIno (".." of the root directory
+// being the root directory itself such that neither LOOKUP nor MNT ascend above it)
+func (adapter *fileSystemAdapterStruct) lookup(ctx context.Context, dirIno uint64, name string) (ino uint64, attr *FileSystemAttrStruct, status uint32) {
+	if ("." == name) || ((".." == name) && (adapter.rootIno == dirIno)) {
+		ino = dirIno
+	} else {
+		status = checkName(name, OK)
+		if OK != status {
+			return
+		}
+		ino, status = adapter.fileSystem.Lookup(ctx, dirIno, name)
+		if OK != status {
+			return
+		}
+	}
+
+	attr, status = adapter.getAttr(ctx, ino)
+
+	return
+}
+
+func nfsTime(t time.Time) (nfsTime3 NFSTime3Struct) {
+	nfsTime3 = NFSTime3Struct{Seconds: uint32(t.Unix()), NSeconds: uint32(t.Nanosecond())}
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) fAttr(ino uint64, attr *FileSystemAttrStruct) (fAttr3 FAttr3Struct) {
+	fAttr3 = FAttr3Struct{
+		Type:   attr.Type,
+		Mode:   attr.Mode & 07777,
+		NLink:  attr.NLink,
+		UID:    attr.UID,
+		GID:    attr.GID,
+		Size:   attr.Size,
+		Used:   attr.Used,
+		RDev:   attr.RDev,
+		FSID:   adapter.fsid,
+		FileID: ino,
+		ATime:  nfsTime(attr.ATime),
+		MTime:  nfsTime(attr.MTime),
+		CTime:  nfsTime(attr.CTime),
+	}
+	if 0 == fAttr3.Used {
+		fAttr3.Used = attr.Size
+	}
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) postOpAttrOf(ino uint64, attr *FileSystemAttrStruct) (postOpAttr PostOpAttrStruct) {
+	postOpAttr = PostOpAttrStruct{AttributesFollow: true, Attributes: adapter.fAttr(ino, attr)}
+	return
+}
+
+// postOpAttr returns the current attributes of inode ino (if available)
+func (adapter *fileSystemAdapterStruct) postOpAttr(ctx context.Context, ino uint64) (postOpAttr PostOpAttrStruct) {
+	var (
+		attr   *FileSystemAttrStruct
+		status uint32
+	)
+
+	attr, status = adapter.fileSystem.GetAttr(ctx, ino)
+	if OK == status {
+		postOpAttr = adapter.postOpAttrOf(ino, attr)
+	}
+
+	return
+}
+
+// wcc returns the weak cache consistency data of inode ino given its attributes (before) prior to a procedure
+func (adapter *fileSystemAdapterStruct) wcc(ctx context.Context, ino uint64, before *FileSystemAttrStruct) (wccData WCCDataStruct) {
+	wccData.Before = PreOpAttrStruct{
+		AttributesFollow: true,
+		Attributes: WCCAttrStruct{
+			Size:  before.Size,
+			MTime: nfsTime(before.MTime),
+			CTime: nfsTime(before.CTime),
+		},
+	}
+	wccData.After = adapter.postOpAttr(ctx, ino)
+	return
+}
+
+// obj returns the file handle & attributes of an object just created
+func (adapter *fileSystemAdapterStruct) obj(ctx context.Context, ino uint64) (obj PostOpFh3Struct, objAttributes PostOpAttrStruct) {
+	var (
+		attr   *FileSystemAttrStruct
+		status uint32
+	)
+
+	attr, status = adapter.fileSystem.GetAttr(ctx, ino)
+	if OK == status {
+		obj = PostOpFh3Struct{HandleFollows: true, Handle: adapter.fHandleOf(ino, attr.Generation)}
+		objAttributes = adapter.postOpAttrOf(ino, attr)
+	}
+
+	return
+}
+
+// access returns those of want (a combination of Access3* bits) granted by the Mode, UID, & GID of attr to the
+// requester (all of want should the requester not be known). As for a host, root is granted everything but
+// execution of a non-directory lacking any execute permission bit.
+func (adapter *fileSystemAdapterStruct) access(ctx context.Context, attr *FileSystemAttrStruct, want uint32) (granted uint32) {
+	var (
+		credential  *CredentialStruct
+		gid         uint32
+		permissions uint32
+		requestInfo *RequestInfoStruct
+	)
+
+	requestInfo = requestInfoFromContext(ctx)
+	if (nil == requestInfo) || (nil == requestInfo.Credential) {
+		granted = want
+	} else {
+		credential = requestInfo.Credential
+
+		switch {
+		case 0 == credential.UID:
+			permissions = 06
+			if (FTypeDIR == attr.Type) || (0 != (attr.Mode & 0111)) {
+				permissions |= 01
+			}
+		case credential.UID == attr.UID:
+			permissions = (attr.Mode >> 6) & 07
+		default:
+			permissions = attr.Mode & 07
+			if credential.GID == attr.GID {
+				permissions = (attr.Mode >> 3) & 07
+			}
+			for _, gid = range credential.GIDs {
+				if gid == attr.GID {
+					permissions = (attr.Mode >> 3) & 07
+				}
+			}
+		}
+
+		if 0 != (permissions & 04) {
+			granted |= Access3Read
+		}
+		if 0 != (permissions & 02) {
+			granted |= Access3Modify | Access3Extend
+			if FTypeDIR == attr.Type {
+				granted |= Access3Delete
+			}
+		}
+		if 0 != (permissions & 01) {
+			if FTypeDIR == attr.Type {
+				granted |= Access3Lookup
+			} else {
+				granted |= Access3Execute
+			}
+		}
+
+		granted &= want
+	}
+
+	if !adapter.mutable {
+		granted &= readOnlyAccessMask
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) ErrorLog(err error) {
+	adapter.fileSystem.ErrorLog(err)
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Null(ctx context.Context) {}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3GetAttr(ctx context.Context, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{}
+
+	ino, attr, nfsProc3GetAttrResults.Status = adapter.resolve(ctx, nfsProc3GetAttrArgs.Object)
+	if OK == nfsProc3GetAttrResults.Status {
+		nfsProc3GetAttrResults.Attributes = adapter.fAttr(ino, attr)
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3SetAttr(ctx context.Context, nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3SetAttrResults = &NFSProc3SetAttrResultsStruct{}
+
+	ino, attr, nfsProc3SetAttrResults.Status = adapter.resolve(ctx, nfsProc3SetAttrArgs.Object)
+	if OK != nfsProc3SetAttrResults.Status {
+		return
+	}
+
+	if nfsProc3SetAttrArgs.Guard.CheckCTime && (nfsProc3SetAttrArgs.Guard.CTime != nfsTime(attr.CTime)) {
+		nfsProc3SetAttrResults.Status = NFS3ErrNOTSYNC
+	} else {
+		nfsProc3SetAttrResults.Status = adapter.attrSetter.SetAttr(ctx, ino, &nfsProc3SetAttrArgs.NewAttributes)
+	}
+
+	nfsProc3SetAttrResults.WCC = adapter.wcc(ctx, ino, attr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Lookup(ctx context.Context, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct) {
+	var (
+		attr    *FileSystemAttrStruct
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		ino     uint64
+	)
+
+	nfsProc3LookupResults = &NFSProc3LookupResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3LookupResults.Status = adapter.resolveDir(ctx, nfsProc3LookupArgs.What.Dir)
+	if OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.DirAttributes = adapter.postOpAttrOf(dirIno, dirAttr)
+
+	ino, attr, nfsProc3LookupResults.Status = adapter.lookup(ctx, dirIno, nfsProc3LookupArgs.What.Name)
+	if OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.Object = adapter.fHandleOf(ino, attr.Generation)
+	nfsProc3LookupResults.ObjAttributes = adapter.postOpAttrOf(ino, attr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Access(ctx context.Context, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3AccessResults = &NFSProc3AccessResultsStruct{}
+
+	ino, attr, nfsProc3AccessResults.Status = adapter.resolve(ctx, nfsProc3AccessArgs.Object)
+	if OK != nfsProc3AccessResults.Status {
+		return
+	}
+
+	nfsProc3AccessResults.ObjAttributes = adapter.postOpAttrOf(ino, attr)
+	nfsProc3AccessResults.Access = adapter.access(ctx, attr, nfsProc3AccessArgs.Access)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3ReadLink(ctx context.Context, nfsProc3ReadLinkArgs *NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct) {
+	var (
+		attr   *FileSystemAttrStruct
+		ino    uint64
+		target string
+	)
+
+	nfsProc3ReadLinkResults = &NFSProc3ReadLinkResultsStruct{}
+
+	ino, attr, nfsProc3ReadLinkResults.Status = adapter.resolve(ctx, nfsProc3ReadLinkArgs.SymLink)
+	if OK != nfsProc3ReadLinkResults.Status {
+		return
+	}
+
+	nfsProc3ReadLinkResults.SymLinkAttributes = adapter.postOpAttrOf(ino, attr)
+
+	if FTypeLNK != attr.Type {
+		nfsProc3ReadLinkResults.Status = NFS3ErrINVAL
+		return
+	}
+
+	target, nfsProc3ReadLinkResults.Status = adapter.readLinker.ReadLink(ctx, ino)
+	if OK == nfsProc3ReadLinkResults.Status {
+		nfsProc3ReadLinkResults.Path = []byte(target)
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Read(ctx context.Context, nfsProc3ReadArgs *NFSProc3ReadArgsStruct) (nfsProc3ReadResults *NFSProc3ReadResultsStruct) {
+	var (
+		attr  *FileSystemAttrStruct
+		buf   []byte
+		count int
+		eof   bool
+		ino   uint64
+	)
+
+	nfsProc3ReadResults = &NFSProc3ReadResultsStruct{}
+
+	ino, attr, nfsProc3ReadResults.Status = adapter.resolve(ctx, nfsProc3ReadArgs.File)
+	if OK != nfsProc3ReadResults.Status {
+		return
+	}
+
+	switch attr.Type {
+	case FTypeREG:
+	case FTypeDIR:
+		nfsProc3ReadResults.Status = NFS3ErrISDIR
+	default:
+		nfsProc3ReadResults.Status = NFS3ErrINVAL
+	}
+	if OK != nfsProc3ReadResults.Status {
+		nfsProc3ReadResults.FileAttributes = adapter.postOpAttrOf(ino, attr)
+		return
+	}
+
+	if DefaultTransferMax < nfsProc3ReadArgs.Count {
+		buf = make([]byte, DefaultTransferMax)
+	} else {
+		buf = make([]byte, nfsProc3ReadArgs.Count)
+	}
+
+	count, eof, nfsProc3ReadResults.Status = adapter.fileSystem.ReadAt(ctx, ino, buf, nfsProc3ReadArgs.Offset)
+
+	nfsProc3ReadResults.FileAttributes = adapter.postOpAttr(ctx, ino)
+
+	if OK == nfsProc3ReadResults.Status {
+		nfsProc3ReadResults.Count = uint32(count)
+		nfsProc3ReadResults.EOF = eof
+		nfsProc3ReadResults.Data = buf[:count]
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Write(ctx context.Context, nfsProc3WriteArgs *NFSProc3WriteArgsStruct) (nfsProc3WriteResults *NFSProc3WriteResultsStruct) {
+	var (
+		attr  *FileSystemAttrStruct
+		count int
+		data  = nfsProc3WriteArgs.Data
+		ino   uint64
+	)
+
+	nfsProc3WriteResults = &NFSProc3WriteResultsStruct{}
+
+	ino, attr, nfsProc3WriteResults.Status = adapter.resolve(ctx, nfsProc3WriteArgs.File)
+	if OK != nfsProc3WriteResults.Status {
+		return
+	}
+
+	switch attr.Type {
+	case FTypeREG:
+		if uint32(len(data)) > nfsProc3WriteArgs.Count {
+			data = data[:nfsProc3WriteArgs.Count]
+		}
+		count, nfsProc3WriteResults.Status = adapter.writer.WriteAt(ctx, ino, data, nfsProc3WriteArgs.Offset)
+	case FTypeDIR:
+		nfsProc3WriteResults.Status = NFS3ErrISDIR
+	default:
+		nfsProc3WriteResults.Status = NFS3ErrINVAL
+	}
+
+	nfsProc3WriteResults.FileWCC = adapter.wcc(ctx, ino, attr)
+
+	if OK == nfsProc3WriteResults.Status {
+		nfsProc3WriteResults.Count = uint32(count)
+		nfsProc3WriteResults.Committed = FileSync
+		nfsProc3WriteResults.Verf = adapter.writeVerf
+	}
+
+	return
+}
+
+// createVerf returns the (whole second) ATime & MTime with which an exclusive CREATE records verf
+func createVerf(verf [NFS3CreateVerfSize]byte) (aTime NFSTime3Struct, mTime NFSTime3Struct) {
+	aTime = NFSTime3Struct{Seconds: binary.BigEndian.Uint32(verf[0:4])}
+	mTime = NFSTime3Struct{Seconds: binary.BigEndian.Uint32(verf[4:8])}
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Create(ctx context.Context, nfsProc3CreateArgs *NFSProc3CreateArgsStruct) (nfsProc3CreateResults *NFSProc3CreateResultsStruct) {
+	var (
+		attr    *FileSystemAttrStruct
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		how     = &nfsProc3CreateArgs.How
+		ino     uint64
+		sAttr3  SAttr3Struct
+	)
+
+	nfsProc3CreateResults = &NFSProc3CreateResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3CreateResults.Status = adapter.resolveDir(ctx, nfsProc3CreateArgs.Where.Dir)
+	if OK != nfsProc3CreateResults.Status {
+		return
+	}
+
+	nfsProc3CreateResults.Status = checkName(nfsProc3CreateArgs.Where.Name, NFS3ErrEXIST)
+
+	if OK == nfsProc3CreateResults.Status {
+		if Exclusive == how.Mode {
+			sAttr3.SetATime = SetToClientTime
+			sAttr3.SetMTime = SetToClientTime
+			sAttr3.ATime, sAttr3.MTime = createVerf(how.Verf)
+		} else {
+			sAttr3 = how.ObjAttributes
+		}
+
+		ino, nfsProc3CreateResults.Status = adapter.creator.Create(ctx, dirIno, nfsProc3CreateArgs.Where.Name, &sAttr3)
+	}
+
+	if (NFS3ErrEXIST == nfsProc3CreateResults.Status) && (Guarded != how.Mode) {
+		// An UNCHECKED CREATE of an existing file succeeds (as does the retransmission of an EXCLUSIVE CREATE)
+
+		ino, attr, nfsProc3CreateResults.Status = adapter.lookup(ctx, dirIno, nfsProc3CreateArgs.Where.Name)
+		if OK == nfsProc3CreateResults.Status {
+			switch {
+			case FTypeREG != attr.Type:
+				nfsProc3CreateResults.Status = NFS3ErrEXIST
+			case Exclusive == how.Mode:
+				sAttr3.ATime, sAttr3.MTime = createVerf(how.Verf)
+				if (sAttr3.ATime.Seconds != uint32(attr.ATime.Unix())) || (sAttr3.MTime.Seconds != uint32(attr.MTime.Unix())) {
+					nfsProc3CreateResults.Status = NFS3ErrEXIST
+				}
+			case how.ObjAttributes.SetSize:
+				if nil != adapter.attrSetter {
+					nfsProc3CreateResults.Status = adapter.attrSetter.SetAttr(ctx, ino, &SAttr3Struct{SetSize: true, Size: how.ObjAttributes.Size})
+				} else {
+					nfsProc3CreateResults.Status = NFS3ErrNOTSUPP
+				}
+			}
+		}
+	}
+
+	if OK == nfsProc3CreateResults.Status {
+		nfsProc3CreateResults.Obj, nfsProc3CreateResults.ObjAttributes = adapter.obj(ctx, ino)
+	}
+
+	nfsProc3CreateResults.DirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3MKDir(ctx context.Context, nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct) {
+	var (
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		ino     uint64
+	)
+
+	nfsProc3MKDirResults = &NFSProc3MKDirResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3MKDirResults.Status = adapter.resolveDir(ctx, nfsProc3MKDirArgs.Where.Dir)
+	if OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	nfsProc3MKDirResults.Status = checkName(nfsProc3MKDirArgs.Where.Name, NFS3ErrEXIST)
+	if OK == nfsProc3MKDirResults.Status {
+		ino, nfsProc3MKDirResults.Status = adapter.creator.MKDir(ctx, dirIno, nfsProc3MKDirArgs.Where.Name, &nfsProc3MKDirArgs.Attributes)
+	}
+	if OK == nfsProc3MKDirResults.Status {
+		nfsProc3MKDirResults.Obj, nfsProc3MKDirResults.ObjAttributes = adapter.obj(ctx, ino)
+	}
+
+	nfsProc3MKDirResults.DirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3SymLink(ctx context.Context, nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct) {
+	var (
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		ino     uint64
+	)
+
+	nfsProc3SymLinkResults = &NFSProc3SymLinkResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3SymLinkResults.Status = adapter.resolveDir(ctx, nfsProc3SymLinkArgs.Where.Dir)
+	if OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	nfsProc3SymLinkResults.Status = checkName(nfsProc3SymLinkArgs.Where.Name, NFS3ErrEXIST)
+	if OK == nfsProc3SymLinkResults.Status {
+		ino, nfsProc3SymLinkResults.Status = adapter.symLinker.SymLink(ctx, dirIno, nfsProc3SymLinkArgs.Where.Name, string(nfsProc3SymLinkArgs.SymLinkData), &nfsProc3SymLinkArgs.SymLinkAttributes)
+	}
+	if OK == nfsProc3SymLinkResults.Status {
+		nfsProc3SymLinkResults.Obj, nfsProc3SymLinkResults.ObjAttributes = adapter.obj(ctx, ino)
+	}
+
+	nfsProc3SymLinkResults.DirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Remove(ctx context.Context, nfsProc3RemoveArgs *NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) {
+	var (
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+	)
+
+	nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3RemoveResults.Status = adapter.resolveDir(ctx, nfsProc3RemoveArgs.Where.Dir)
+	if OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	nfsProc3RemoveResults.Status = checkName(nfsProc3RemoveArgs.Where.Name, NFS3ErrINVAL)
+	if OK == nfsProc3RemoveResults.Status {
+		nfsProc3RemoveResults.Status = adapter.remover.Remove(ctx, dirIno, nfsProc3RemoveArgs.Where.Name)
+	}
+
+	nfsProc3RemoveResults.DirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3RMDir(ctx context.Context, nfsProc3RMDirArgs *NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct) {
+	var (
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+	)
+
+	nfsProc3RMDirResults = &NFSProc3RMDirResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3RMDirResults.Status = adapter.resolveDir(ctx, nfsProc3RMDirArgs.Where.Dir)
+	if OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	nfsProc3RMDirResults.Status = checkName(nfsProc3RMDirArgs.Where.Name, NFS3ErrINVAL)
+	if OK == nfsProc3RMDirResults.Status {
+		nfsProc3RMDirResults.Status = adapter.remover.RMDir(ctx, dirIno, nfsProc3RMDirArgs.Where.Name)
+	}
+
+	nfsProc3RMDirResults.DirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Rename(ctx context.Context, nfsProc3RenameArgs *NFSProc3RenameArgsStruct) (nfsProc3RenameResults *NFSProc3RenameResultsStruct) {
+	var (
+		fromDirAttr *FileSystemAttrStruct
+		fromDirIno  uint64
+		toDirAttr   *FileSystemAttrStruct
+		toDirIno    uint64
+	)
+
+	nfsProc3RenameResults = &NFSProc3RenameResultsStruct{}
+
+	fromDirIno, fromDirAttr, nfsProc3RenameResults.Status = adapter.resolveDir(ctx, nfsProc3RenameArgs.From.Dir)
+	if OK != nfsProc3RenameResults.Status {
+		return
+	}
+	toDirIno, toDirAttr, nfsProc3RenameResults.Status = adapter.resolveDir(ctx, nfsProc3RenameArgs.To.Dir)
+	if OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	nfsProc3RenameResults.Status = checkName(nfsProc3RenameArgs.From.Name, NFS3ErrINVAL)
+	if OK == nfsProc3RenameResults.Status {
+		nfsProc3RenameResults.Status = checkName(nfsProc3RenameArgs.To.Name, NFS3ErrINVAL)
+	}
+	if OK == nfsProc3RenameResults.Status {
+		nfsProc3RenameResults.Status = adapter.renamer.Rename(ctx, fromDirIno, nfsProc3RenameArgs.From.Name, toDirIno, nfsProc3RenameArgs.To.Name)
+	}
+
+	nfsProc3RenameResults.FromDirWCC = adapter.wcc(ctx, fromDirIno, fromDirAttr)
+	nfsProc3RenameResults.ToDirWCC = adapter.wcc(ctx, toDirIno, toDirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Link(ctx context.Context, nfsProc3LinkArgs *NFSProc3LinkArgsStruct) (nfsProc3LinkResults *NFSProc3LinkResultsStruct) {
+	var (
+		attr    *FileSystemAttrStruct
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		ino     uint64
+	)
+
+	nfsProc3LinkResults = &NFSProc3LinkResultsStruct{}
+
+	ino, attr, nfsProc3LinkResults.Status = adapter.resolve(ctx, nfsProc3LinkArgs.File)
+	if OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	nfsProc3LinkResults.FileAttributes = adapter.postOpAttrOf(ino, attr)
+
+	dirIno, dirAttr, nfsProc3LinkResults.Status = adapter.resolveDir(ctx, nfsProc3LinkArgs.Link.Dir)
+	if OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	switch {
+	case FTypeDIR == attr.Type:
+		nfsProc3LinkResults.Status = NFS3ErrISDIR
+	default:
+		nfsProc3LinkResults.Status = checkName(nfsProc3LinkArgs.Link.Name, NFS3ErrEXIST)
+	}
+	if OK == nfsProc3LinkResults.Status {
+		nfsProc3LinkResults.Status = adapter.linker.Link(ctx, ino, dirIno, nfsProc3LinkArgs.Link.Name)
+	}
+
+	nfsProc3LinkResults.FileAttributes = adapter.postOpAttr(ctx, ino)
+	nfsProc3LinkResults.LinkDirWCC = adapter.wcc(ctx, dirIno, dirAttr)
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3ReadDir(ctx context.Context, nfsProc3ReadDirArgs *NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) {
+	var (
+		dirAttr *FileSystemAttrStruct
+		dirIno  uint64
+		entries []FileSystemDirEntryStruct
+		entry   FileSystemDirEntryStruct
+	)
+
+	nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3ReadDirResults.Status = adapter.resolveDir(ctx, nfsProc3ReadDirArgs.Dir)
+	if OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.DirAttributes = adapter.postOpAttrOf(dirIno, dirAttr)
+
+	entries, nfsProc3ReadDirResults.EOF, nfsProc3ReadDirResults.Status = adapter.fileSystem.ReadDir(ctx, dirIno, nfsProc3ReadDirArgs.Cookie)
+	if OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.Entries = make([]DirListEntryStruct, 0, len(entries))
+
+	for _, entry = range entries {
+		nfsProc3ReadDirResults.Entries = append(nfsProc3ReadDirResults.Entries, DirListEntryStruct{FileID: entry.Ino, Name: entry.Name, Cookie: entry.Cookie})
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3ReadDirPlus(ctx context.Context, nfsProc3ReadDirPlusArgs *NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) {
+	var (
+		attr      *FileSystemAttrStruct
+		dirAttr   *FileSystemAttrStruct
+		dirCount  uint32
+		dirIno    uint64
+		entries   []FileSystemDirEntryStruct
+		entry     FileSystemDirEntryStruct
+		entryPlus DirListEntryPlusStruct
+		status    uint32
+	)
+
+	nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{}
+
+	dirIno, dirAttr, nfsProc3ReadDirPlusResults.Status = adapter.resolveDir(ctx, nfsProc3ReadDirPlusArgs.Dir)
+	if OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.DirAttributes = adapter.postOpAttrOf(dirIno, dirAttr)
+
+	entries, nfsProc3ReadDirPlusResults.EOF, nfsProc3ReadDirPlusResults.Status = adapter.fileSystem.ReadDir(ctx, dirIno, nfsProc3ReadDirPlusArgs.Cookie)
+	if OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.Entries = make([]DirListEntryPlusStruct, 0, len(entries))
+
+	for _, entry = range entries {
+		// Avoid fetching the attributes of entries certain to be trimmed to fit dirCount
+
+		dirCount += dirInfoSize(entry.Name)
+		if (dirCount > nfsProc3ReadDirPlusArgs.DirCount) && (0 < len(nfsProc3ReadDirPlusResults.Entries)) {
+			nfsProc3ReadDirPlusResults.EOF = false
+			break
+		}
+
+		entryPlus = DirListEntryPlusStruct{FileID: entry.Ino, Name: entry.Name, Cookie: entry.Cookie}
+
+		attr, status = adapter.fileSystem.GetAttr(ctx, entry.Ino)
+		if OK == status {
+			entryPlus.NameAttributes = adapter.postOpAttrOf(entry.Ino, attr)
+			entryPlus.NameHandle = PostOpFh3Struct{HandleFollows: true, Handle: adapter.fHandleOf(entry.Ino, attr.Generation)}
+		}
+
+		nfsProc3ReadDirPlusResults.Entries = append(nfsProc3ReadDirPlusResults.Entries, entryPlus)
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3FSStat(ctx context.Context, nfsProc3FSStatArgs *NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3FSStatResults = &NFSProc3FSStatResultsStruct{}
+
+	ino, attr, nfsProc3FSStatResults.Status = adapter.resolve(ctx, nfsProc3FSStatArgs.FSRoot)
+	if OK != nfsProc3FSStatResults.Status {
+		return
+	}
+
+	nfsProc3FSStatResults.ObjAttributes = adapter.postOpAttrOf(ino, attr)
+
+	nfsProc3FSStatResults.TBytes, nfsProc3FSStatResults.FBytes, nfsProc3FSStatResults.TFiles, nfsProc3FSStatResults.FFiles, nfsProc3FSStatResults.Status = adapter.statter.StatFS(ctx)
+
+	nfsProc3FSStatResults.ABytes = nfsProc3FSStatResults.FBytes
+	nfsProc3FSStatResults.AFiles = nfsProc3FSStatResults.FFiles
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3FSInfo(ctx context.Context, nfsProc3FSInfoArgs *NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3FSInfoResults = &NFSProc3FSInfoResultsStruct{}
+
+	ino, attr, nfsProc3FSInfoResults.Status = adapter.resolve(ctx, nfsProc3FSInfoArgs.FSRoot)
+	if OK != nfsProc3FSInfoResults.Status {
+		return
+	}
+
+	nfsProc3FSInfoResults.ObjAttributes = adapter.postOpAttrOf(ino, attr)
+	nfsProc3FSInfoResults.RTMax = DefaultTransferMax
+	nfsProc3FSInfoResults.RTPref = DefaultTransferMax
+	nfsProc3FSInfoResults.RTMult = DefaultTransferMult
+	nfsProc3FSInfoResults.WTMax = DefaultTransferMax
+	nfsProc3FSInfoResults.WTPref = DefaultTransferMax
+	nfsProc3FSInfoResults.WTMult = DefaultTransferMult
+	nfsProc3FSInfoResults.DTPref = DefaultDirPref
+	nfsProc3FSInfoResults.MaxFileSize = uint64(1<<63 - 1)
+	nfsProc3FSInfoResults.TimeDelta = NFSTime3Struct{Seconds: 0, NSeconds: 1}
+	nfsProc3FSInfoResults.Properties = FSF3Homogeneous | FSF3Link | FSF3SymLink // FSF3Link & FSF3SymLink cleared by the backend adapter as appropriate
+
+	if nil != adapter.attrSetter {
+		nfsProc3FSInfoResults.Properties |= FSF3CanSetTime
+	}
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3PathConf(ctx context.Context, nfsProc3PathConfArgs *NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3PathConfResults = &NFSProc3PathConfResultsStruct{}
+
+	ino, attr, nfsProc3PathConfResults.Status = adapter.resolve(ctx, nfsProc3PathConfArgs.Object)
+	if OK != nfsProc3PathConfResults.Status {
+		return
+	}
+
+	nfsProc3PathConfResults.ObjAttributes = adapter.postOpAttrOf(ino, attr)
+	nfsProc3PathConfResults.LinkMax = ^uint32(0) // reduced by the backend adapter if hard links are not supported
+	nfsProc3PathConfResults.NameMax = DefaultNameMax
+	nfsProc3PathConfResults.NoTrunc = true
+	nfsProc3PathConfResults.ChOwnRestricted = true
+	nfsProc3PathConfResults.CaseInsensitive = false
+	nfsProc3PathConfResults.CasePreserving = true
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) NFSProc3Commit(ctx context.Context, nfsProc3CommitArgs *NFSProc3CommitArgsStruct) (nfsProc3CommitResults *NFSProc3CommitResultsStruct) {
+	var (
+		attr *FileSystemAttrStruct
+		ino  uint64
+	)
+
+	nfsProc3CommitResults = &NFSProc3CommitResultsStruct{}
+
+	ino, attr, nfsProc3CommitResults.Status = adapter.resolve(ctx, nfsProc3CommitArgs.File)
+	if OK != nfsProc3CommitResults.Status {
+		return
+	}
+
+	// As each WRITE was answered with FileSync, there is nothing to commit
+
+	nfsProc3CommitResults.FileWCC = adapter.wcc(ctx, ino, attr)
+	nfsProc3CommitResults.Verf = adapter.writeVerf
+
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) MountProc3Null(credential *CredentialStruct) {}
+
+// MountProc3Mnt returns the file handle of the directory at DirPath (resolved component by component from the
+// root directory via Lookup)
+func (adapter *fileSystemAdapterStruct) MountProc3Mnt(credential *CredentialStruct, mountProc3MntArgs *MountProc3MntArgsStruct) (mountProc3MntResults *MountProc3MntResultsStruct) {
+	var (
+		attr   *FileSystemAttrStruct
+		ctx    context.Context
+		ino    = adapter.rootIno
+		name   string
+		status uint32
+	)
+
+	mountProc3MntResults = &MountProc3MntResultsStruct{}
+
+	ctx = context.WithValue(context.Background(), requestInfoKeyType{}, &RequestInfoStruct{Credential: credential, ReceiveTime: time.Now()})
+
+	attr, status = adapter.getAttr(ctx, ino)
+
+	for _, name = range strings.Split(mountProc3MntArgs.DirPath, "/") {
+		if OK != status {
+			break
+		}
+		if ("" == name) || ("." == name) {
+			continue
+		}
+		if FTypeDIR != attr.Type {
+			status = NFS3ErrNOTDIR
+			break
+		}
+		ino, attr, status = adapter.lookup(ctx, ino, name)
+	}
+
+	if (OK == status) && (FTypeDIR != attr.Type) {
+		status = NFS3ErrNOTDIR
+	}
+
+	switch status {
+	case OK:
+		mountProc3MntResults.Status = OK
+		mountProc3MntResults.FHandle = adapter.fHandleOf(ino, attr.Generation)
+		mountProc3MntResults.AuthFlavors = []uint32{AuthSys, AuthNone}
+	case NFS3ErrNOENT, NFS3ErrSTALE:
+		mountProc3MntResults.Status = MNT3ErrNOENT
+	case NFS3ErrNOTDIR:
+		mountProc3MntResults.Status = MNT3ErrNOTDIR
+	case NFS3ErrACCES:
+		mountProc3MntResults.Status = MNT3ErrACCES
+	case NFS3ErrNAMETOOLONG:
+		mountProc3MntResults.Status = MNT3ErrNAMETOOLONG
+	case NFS3ErrINVAL:
+		mountProc3MntResults.Status = MNT3ErrINVAL
+	default:
+		mountProc3MntResults.Status = MNT3ErrIO
+	}
+
+	return
+}
+
+// MountProc3Dump returns an empty list as mounts are not tracked by the adapter (see EnableMountTable)
+func (adapter *fileSystemAdapterStruct) MountProc3Dump(credential *CredentialStruct) (mountProc3DumpResults *MountProc3DumpResultsStruct) {
+	mountProc3DumpResults = &MountProc3DumpResultsStruct{MountList: []MountBodyStruct{}}
+	return
+}
+
+func (adapter *fileSystemAdapterStruct) MountProc3Umnt(credential *CredentialStruct, mountProc3UmntArgs *MountProc3UmntArgsStruct) {
+}
+
+func (adapter *fileSystemAdapterStruct) MountProc3UmntAll(credential *CredentialStruct) {}
+
+// MountProc3Export returns "/" (i.e. the root directory) as exported to all clients
+func (adapter *fileSystemAdapterStruct) MountProc3Export(credential *CredentialStruct) (mountProc3ExportResults *MountProc3ExportResultsStruct) {
+	mountProc3ExportResults = &MountProc3ExportResultsStruct{Exports: []ExportNodeStruct{{Dir: "/", Groups: []string{}}}}
+	return
+}
@@ -0,0 +1,376 @@
+package nfsd
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+type testInodeStruct struct {
+	attr    FileSystemAttrStruct
+	parent  uint64
+	data    []byte
+	entries map[string]uint64
+}
+
+// testFileSystemStruct implements only FileSystemInterface
+type testFileSystemStruct struct {
+	t       *testing.T
+	inodes  map[uint64]*testInodeStruct
+	nextIno uint64
+}
+
+// testWritableFileSystemStruct additionally implements FileSystemWriterInterface, FileSystemAttrSetterInterface,
+// FileSystemCreatorInterface, & FileSystemRemoverInterface
+type testWritableFileSystemStruct struct {
+	*testFileSystemStruct
+}
+
+func newTestFileSystem(t *testing.T) (testFileSystem *testFileSystemStruct) {
+	testFileSystem = &testFileSystemStruct{t: t, inodes: make(map[uint64]*testInodeStruct), nextIno: 2}
+	testFileSystem.inodes[1] = &testInodeStruct{attr: FileSystemAttrStruct{Type: FTypeDIR, Mode: 0755, NLink: 2}, parent: 1, entries: make(map[string]uint64)}
+	return
+}
+
+func (testFileSystem *testFileSystemStruct) add(dirIno uint64, name string, fType uint32, mode uint32) (ino uint64) {
+	ino = testFileSystem.nextIno
+	testFileSystem.nextIno++
+	testFileSystem.inodes[ino] = &testInodeStruct{attr: FileSystemAttrStruct{Type: fType, Mode: mode, NLink: 1}, parent: dirIno, entries: make(map[string]uint64)}
+	testFileSystem.inodes[dirIno].entries[name] = ino
+	return
+}
+
+func (testFileSystem *testFileSystemStruct) ErrorLog(err error) {
+	testFileSystem.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testFileSystem *testFileSystemStruct) GetAttr(ctx context.Context, ino uint64) (attr *FileSystemAttrStruct, status uint32) {
+	inode, ok := testFileSystem.inodes[ino]
+	if !ok {
+		status = NFS3ErrNOENT
+		return
+	}
+	attr = &FileSystemAttrStruct{}
+	*attr = inode.attr
+	attr.Size = uint64(len(inode.data))
+	status = OK
+	return
+}
+
+func (testFileSystem *testFileSystemStruct) Lookup(ctx context.Context, dirIno uint64, name string) (ino uint64, status uint32) {
+	var ok bool
+	if ".." == name {
+		ino, status = testFileSystem.inodes[dirIno].parent, OK
+		return
+	}
+	ino, ok = testFileSystem.inodes[dirIno].entries[name]
+	if ok {
+		status = OK
+	} else {
+		status = NFS3ErrNOENT
+	}
+	return
+}
+
+func (testFileSystem *testFileSystemStruct) ReadAt(ctx context.Context, ino uint64, buf []byte, offset uint64) (count int, eof bool, status uint32) {
+	data := testFileSystem.inodes[ino].data
+	if offset < uint64(len(data)) {
+		count = copy(buf, data[offset:])
+	}
+	eof = (offset + uint64(count)) >= uint64(len(data))
+	status = OK
+	return
+}
+
+func (testFileSystem *testFileSystemStruct) ReadDir(ctx context.Context, dirIno uint64, cookie uint64) (entries []FileSystemDirEntryStruct, eof bool, status uint32) {
+	names := []string{}
+	for name := range testFileSystem.inodes[dirIno].entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for index, name := range names {
+		if uint64(index) >= cookie {
+			entries = append(entries, FileSystemDirEntryStruct{Ino: testFileSystem.inodes[dirIno].entries[name], Name: name, Cookie: uint64(index) + 1})
+		}
+	}
+	eof, status = true, OK
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) WriteAt(ctx context.Context, ino uint64, data []byte, offset uint64) (count int, status uint32) {
+	inode := testWritableFileSystem.inodes[ino]
+	if end := offset + uint64(len(data)); end > uint64(len(inode.data)) {
+		inode.data = append(inode.data, make([]byte, end-uint64(len(inode.data)))...)
+	}
+	count, status = copy(inode.data[offset:], data), OK
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) SetAttr(ctx context.Context, ino uint64, sAttr3 *SAttr3Struct) (status uint32) {
+	inode := testWritableFileSystem.inodes[ino]
+	if sAttr3.SetSize {
+		inode.data = append(inode.data[:0:0], inode.data[:sAttr3.Size]...)
+	}
+	if SetToClientTime == sAttr3.SetATime {
+		inode.attr.ATime = time.Unix(int64(sAttr3.ATime.Seconds), int64(sAttr3.ATime.NSeconds))
+	}
+	if SetToClientTime == sAttr3.SetMTime {
+		inode.attr.MTime = time.Unix(int64(sAttr3.MTime.Seconds), int64(sAttr3.MTime.NSeconds))
+	}
+	status = OK
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) Create(ctx context.Context, dirIno uint64, name string, sAttr3 *SAttr3Struct) (ino uint64, status uint32) {
+	if _, ok := testWritableFileSystem.inodes[dirIno].entries[name]; ok {
+		status = NFS3ErrEXIST
+		return
+	}
+	ino = testWritableFileSystem.add(dirIno, name, FTypeREG, 0644)
+	status = testWritableFileSystem.SetAttr(ctx, ino, sAttr3)
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) MKDir(ctx context.Context, dirIno uint64, name string, sAttr3 *SAttr3Struct) (ino uint64, status uint32) {
+	if _, ok := testWritableFileSystem.inodes[dirIno].entries[name]; ok {
+		status = NFS3ErrEXIST
+		return
+	}
+	ino, status = testWritableFileSystem.add(dirIno, name, FTypeDIR, 0755), OK
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) Remove(ctx context.Context, dirIno uint64, name string) (status uint32) {
+	ino, ok := testWritableFileSystem.inodes[dirIno].entries[name]
+	if !ok {
+		status = NFS3ErrNOENT
+		return
+	}
+	delete(testWritableFileSystem.inodes[dirIno].entries, name)
+	delete(testWritableFileSystem.inodes, ino)
+	status = OK
+	return
+}
+
+func (testWritableFileSystem *testWritableFileSystemStruct) RMDir(ctx context.Context, dirIno uint64, name string) (status uint32) {
+	status = testWritableFileSystem.Remove(ctx, dirIno, name)
+	return
+}
+
+func TestFileSystemAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	testFileSystem := newTestFileSystem(t)
+	subDirIno := testFileSystem.add(1, "sub", FTypeDIR, 0755)
+	fileIno := testFileSystem.add(subDirIno, "file", FTypeREG, 0640)
+	testFileSystem.inodes[fileIno].attr.UID = 1000
+	testFileSystem.inodes[fileIno].data = []byte("hello")
+
+	nfsCallbacks, mountCallbacks := NewFileSystemAdapter(testFileSystem, 1, 42)
+
+	mntResults := mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/sub"})
+	if OK != mntResults.Status {
+		t.Fatalf("MNT of /sub returned %v", mntResults.Status)
+	}
+	if MNT3ErrNOENT != mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/missing"}).Status {
+		t.Fatalf("MNT of /missing not answered with MNT3ErrNOENT")
+	}
+	if MNT3ErrNOTDIR != mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/sub/file"}).Status {
+		t.Fatalf("MNT of /sub/file not answered with MNT3ErrNOTDIR")
+	}
+	if (FHSize3 - exportIDSize) < uint32(len(mntResults.FHandle)) {
+		t.Fatalf("file handle of %v bytes leaves no room for an export ID", len(mntResults.FHandle))
+	}
+
+	lookupResults := nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: mntResults.FHandle, Name: "file"}})
+	if (OK != lookupResults.Status) || (fileIno != lookupResults.ObjAttributes.Attributes.FileID) || (42 != lookupResults.ObjAttributes.Attributes.FSID) {
+		t.Fatalf("LOOKUP of file returned %+v", lookupResults)
+	}
+	fileFHandle := lookupResults.Object
+
+	parentResults := nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: mntResults.FHandle, Name: ".."}})
+	if (OK != parentResults.Status) || (1 != parentResults.ObjAttributes.Attributes.FileID) {
+		t.Fatalf("LOOKUP of .. returned %+v", parentResults)
+	}
+	if NFS3ErrNOTDIR != nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: fileFHandle, Name: "x"}}).Status {
+		t.Fatalf("LOOKUP within a file not answered with NFS3ErrNOTDIR")
+	}
+
+	readResults := nfsCallbacks.NFSProc3Read(ctx, &NFSProc3ReadArgsStruct{File: fileFHandle, Offset: 1, Count: 100})
+	if (OK != readResults.Status) || ("ello" != string(readResults.Data)) || !readResults.EOF || (4 != readResults.Count) {
+		t.Fatalf("READ returned %+v", readResults)
+	}
+	if NFS3ErrISDIR != nfsCallbacks.NFSProc3Read(ctx, &NFSProc3ReadArgsStruct{File: mntResults.FHandle, Count: 100}).Status {
+		t.Fatalf("READ of a directory not answered with NFS3ErrISDIR")
+	}
+
+	readDirPlusResults := nfsCallbacks.NFSProc3ReadDirPlus(ctx, &NFSProc3ReadDirPlusArgsStruct{Dir: mntResults.FHandle, DirCount: 4096, MaxCount: 4096})
+	if (OK != readDirPlusResults.Status) || (1 != len(readDirPlusResults.Entries)) || !readDirPlusResults.EOF {
+		t.Fatalf("READDIRPLUS returned %+v", readDirPlusResults)
+	}
+	if !bytes.Equal(fileFHandle, readDirPlusResults.Entries[0].NameHandle.Handle) {
+		t.Fatalf("READDIRPLUS returned a file handle differing from that returned by LOOKUP")
+	}
+
+	badHandle := append([]byte{}, fileFHandle...)
+	badHandle[0]++
+	if NFS3ErrBADHANDLE != nfsCallbacks.NFSProc3GetAttr(ctx, &NFSProc3GetAttrArgsStruct{Object: badHandle}).Status {
+		t.Fatalf("GETATTR of an unrecognized file handle not answered with NFS3ErrBADHANDLE")
+	}
+	otherNFSCallbacks, _ := NewFileSystemAdapter(testFileSystem, 1, 43)
+	if NFS3ErrSTALE != otherNFSCallbacks.NFSProc3GetAttr(ctx, &NFSProc3GetAttrArgsStruct{Object: fileFHandle}).Status {
+		t.Fatalf("GETATTR of a file handle of another fsid not answered with NFS3ErrSTALE")
+	}
+	testFileSystem.inodes[fileIno].attr.Generation++
+	if NFS3ErrSTALE != nfsCallbacks.NFSProc3GetAttr(ctx, &NFSProc3GetAttrArgsStruct{Object: fileFHandle}).Status {
+		t.Fatalf("GETATTR of a file handle of a previous generation not answered with NFS3ErrSTALE")
+	}
+
+	if NFS3ErrROFS != nfsCallbacks.NFSProc3Write(ctx, &NFSProc3WriteArgsStruct{File: fileFHandle}).Status {
+		t.Fatalf("WRITE to a read-only file system not answered with NFS3ErrROFS")
+	}
+	if NFS3ErrNOTSUPP != nfsCallbacks.NFSProc3ReadLink(ctx, &NFSProc3ReadLinkArgsStruct{SymLink: fileFHandle}).Status {
+		t.Fatalf("READLINK not answered with NFS3ErrNOTSUPP")
+	}
+	fsInfoResults := nfsCallbacks.NFSProc3FSInfo(ctx, &NFSProc3FSInfoArgsStruct{FSRoot: mntResults.FHandle})
+	if (OK != fsInfoResults.Status) || (0 != (fsInfoResults.Properties & (FSF3Link | FSF3SymLink | FSF3CanSetTime))) {
+		t.Fatalf("FSINFO returned %+v", fsInfoResults)
+	}
+}
+
+func TestFileSystemAdapterDotDot(t *testing.T) {
+	ctx := context.Background()
+
+	testFileSystem := newTestFileSystem(t)
+	exportedIno := testFileSystem.add(1, "exported", FTypeDIR, 0755)
+	subDirIno := testFileSystem.add(exportedIno, "sub", FTypeDIR, 0755)
+	_ = testFileSystem.add(1, "secret", FTypeREG, 0600)
+
+	nfsCallbacks, mountCallbacks := NewFileSystemAdapter(testFileSystem, exportedIno, 42)
+
+	mntResults := mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/"})
+	if OK != mntResults.Status {
+		t.Fatalf("MNT of / returned %v", mntResults.Status)
+	}
+	rootFHandle := mntResults.FHandle
+
+	parentResults := nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: rootFHandle, Name: ".."}})
+	if (OK != parentResults.Status) || (exportedIno != parentResults.ObjAttributes.Attributes.FileID) || !bytes.Equal(rootFHandle, parentResults.Object) {
+		t.Fatalf("LOOKUP of .. of the root directory returned %+v", parentResults)
+	}
+
+	subResults := nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: rootFHandle, Name: "sub"}})
+	if (OK != subResults.Status) || (subDirIno != subResults.ObjAttributes.Attributes.FileID) {
+		t.Fatalf("LOOKUP of sub returned %+v", subResults)
+	}
+	parentResults = nfsCallbacks.NFSProc3Lookup(ctx, &NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: subResults.Object, Name: ".."}})
+	if (OK != parentResults.Status) || (exportedIno != parentResults.ObjAttributes.Attributes.FileID) {
+		t.Fatalf("LOOKUP of .. of sub returned %+v", parentResults)
+	}
+
+	for _, dirPath := range []string{"/..", "/../..", "/sub/../..", "/../exported"} {
+		mntResults = mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: dirPath})
+		switch {
+		case "/../exported" == dirPath:
+			if MNT3ErrNOENT != mntResults.Status {
+				t.Fatalf("MNT of %s returned %+v... expected MNT3ErrNOENT", dirPath, mntResults)
+			}
+		case (OK != mntResults.Status) || !bytes.Equal(rootFHandle, mntResults.FHandle):
+			t.Fatalf("MNT of %s returned %+v... expected the root directory", dirPath, mntResults)
+		}
+	}
+	if MNT3ErrNOENT != mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/../secret"}).Status {
+		t.Fatalf("MNT of /../secret should not have ascended above the root directory")
+	}
+}
+
+func TestFileSystemAdapterAccess(t *testing.T) {
+	testFileSystem := newTestFileSystem(t)
+	fileIno := testFileSystem.add(1, "file", FTypeREG, 0640)
+	testFileSystem.inodes[fileIno].attr.UID = 1000
+	testFileSystem.inodes[fileIno].attr.GID = 100
+
+	nfsCallbacks, mountCallbacks := NewFileSystemAdapter(&testWritableFileSystemStruct{testFileSystem}, 1, 1)
+	fileFHandle := mountCallbacks.(*fileSystemAdapterStruct).fHandleOf(fileIno, 0)
+	all := Access3Read | Access3Modify | Access3Extend | Access3Execute
+
+	for _, test := range []struct {
+		credential *CredentialStruct
+		expected   uint32
+	}{
+		{&CredentialStruct{UID: 1000, GID: 1000}, Access3Read | Access3Modify | Access3Extend},
+		{&CredentialStruct{UID: 1001, GID: 1001, GIDs: []uint32{100}}, Access3Read},
+		{&CredentialStruct{UID: 1001, GID: 1001}, 0},
+		{&CredentialStruct{UID: 0, GID: 0}, Access3Read | Access3Modify | Access3Extend},
+	} {
+		ctx := context.WithValue(context.Background(), requestInfoKeyType{}, &RequestInfoStruct{Credential: test.credential})
+		accessResults := nfsCallbacks.NFSProc3Access(ctx, &NFSProc3AccessArgsStruct{Object: fileFHandle, Access: all})
+		if (OK != accessResults.Status) || (test.expected != accessResults.Access) {
+			t.Fatalf("ACCESS by %+v returned %+v... expected %v", test.credential, accessResults, test.expected)
+		}
+	}
+}
+
+func TestFileSystemAdapterMutations(t *testing.T) {
+	ctx := context.Background()
+
+	testFileSystem := newTestFileSystem(t)
+
+	nfsCallbacks, mountCallbacks := NewFileSystemAdapter(&testWritableFileSystemStruct{testFileSystem}, 1, 1)
+	rootFHandle := mountCallbacks.MountProc3Mnt(&CredentialStruct{}, &MountProc3MntArgsStruct{DirPath: "/"}).FHandle
+
+	createArgs := &NFSProc3CreateArgsStruct{Where: DirOpArgs3Struct{Dir: rootFHandle, Name: "file"}, How: CreateHowStruct{Mode: Exclusive, Verf: [NFS3CreateVerfSize]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+	createResults := nfsCallbacks.NFSProc3Create(ctx, createArgs)
+	if (OK != createResults.Status) || !createResults.Obj.HandleFollows || !createResults.DirWCC.After.AttributesFollow {
+		t.Fatalf("exclusive CREATE returned %+v", createResults)
+	}
+	fileFHandle := createResults.Obj.Handle
+
+	if OK != nfsCallbacks.NFSProc3Create(ctx, createArgs).Status {
+		t.Fatalf("retransmitted exclusive CREATE not answered with OK")
+	}
+	createArgs.How.Verf[0]++
+	if NFS3ErrEXIST != nfsCallbacks.NFSProc3Create(ctx, createArgs).Status {
+		t.Fatalf("exclusive CREATE with another verifier not answered with NFS3ErrEXIST")
+	}
+	createArgs.How.Mode = Guarded
+	if NFS3ErrEXIST != nfsCallbacks.NFSProc3Create(ctx, createArgs).Status {
+		t.Fatalf("guarded CREATE of an existing file not answered with NFS3ErrEXIST")
+	}
+
+	writeResults := nfsCallbacks.NFSProc3Write(ctx, &NFSProc3WriteArgsStruct{File: fileFHandle, Offset: 0, Count: 5, Stable: Unstable, Data: []byte("hello world")})
+	if (OK != writeResults.Status) || (5 != writeResults.Count) || (FileSync != writeResults.Committed) || (5 != writeResults.FileWCC.After.Attributes.Size) {
+		t.Fatalf("WRITE returned %+v", writeResults)
+	}
+	commitResults := nfsCallbacks.NFSProc3Commit(ctx, &NFSProc3CommitArgsStruct{File: fileFHandle})
+	if (OK != commitResults.Status) || (writeResults.Verf != commitResults.Verf) {
+		t.Fatalf("COMMIT returned %+v", commitResults)
+	}
+
+	createArgs.How = CreateHowStruct{Mode: Unchecked, ObjAttributes: SAttr3Struct{SetSize: true, Size: 2}}
+	createResults = nfsCallbacks.NFSProc3Create(ctx, createArgs)
+	if (OK != createResults.Status) || (2 != createResults.ObjAttributes.Attributes.Size) {
+		t.Fatalf("unchecked CREATE of an existing file returned %+v", createResults)
+	}
+
+	mkDirResults := nfsCallbacks.NFSProc3MKDir(ctx, &NFSProc3MKDirArgsStruct{Where: DirOpArgs3Struct{Dir: rootFHandle, Name: "dir"}})
+	if (OK != mkDirResults.Status) || (FTypeDIR != mkDirResults.ObjAttributes.Attributes.Type) {
+		t.Fatalf("MKDIR returned %+v", mkDirResults)
+	}
+	if NFS3ErrEXIST != nfsCallbacks.NFSProc3MKDir(ctx, &NFSProc3MKDirArgsStruct{Where: DirOpArgs3Struct{Dir: rootFHandle, Name: ".."}}).Status {
+		t.Fatalf("MKDIR of .. not answered with NFS3ErrEXIST")
+	}
+
+	if NFS3ErrNOTSUPP != nfsCallbacks.NFSProc3Rename(ctx, &NFSProc3RenameArgsStruct{}).Status {
+		t.Fatalf("RENAME by a file system lacking FileSystemRenamerInterface not answered with NFS3ErrNOTSUPP")
+	}
+
+	if OK != nfsCallbacks.NFSProc3Remove(ctx, &NFSProc3RemoveArgsStruct{Where: DirOpArgs3Struct{Dir: rootFHandle, Name: "file"}}).Status {
+		t.Fatalf("REMOVE of file not answered with OK")
+	}
+	if NFS3ErrSTALE != nfsCallbacks.NFSProc3GetAttr(ctx, &NFSProc3GetAttrArgsStruct{Object: fileFHandle}).Status {
+		t.Fatalf("GETATTR of a removed file not answered with NFS3ErrSTALE")
+	}
+}
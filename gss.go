@@ -0,0 +1,722 @@
+package nfsd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/swiftstack/onc"
+)
+
+// RPCSEC_GSS (RFC 2203) context establishment, sequence window enforcement, and service (none, integrity,
+// and privacy) protection of requests & replies. The GSS-API mechanism itself is supplied by the caller of
+// EnableRPCSecGSS() via GSSMechanismInterface.
+
+const (
+	rpcGSSVers1 = uint32(1) // enum rpc_gss_vers
+
+	rpcGSSProcData         = uint32(0) // enum rpc_gss_proc_t
+	rpcGSSProcInit         = uint32(1) // enum rpc_gss_proc_t
+	rpcGSSProcContinueInit = uint32(2) // enum rpc_gss_proc_t
+	rpcGSSProcDestroy      = uint32(3) // enum rpc_gss_proc_t
+
+	rpcGSSMaxSeq = uint32(0x80000000) // MAXSEQ
+
+	gssSComplete       = uint32(0x00000000) // GSS_S_COMPLETE
+	gssSContinueNeeded = uint32(0x00000001) // GSS_S_CONTINUE_NEEDED
+	gssSFailure        = uint32(0x000D0000) // GSS_S_FAILURE
+
+	gssSeqWindowSize  = uint32(128)  // sequence window advertised to (and enforced for) each client context
+	gssHandleSize     = 16           // size in bytes of the (random) context handles issued to clients
+	gssMaxContextsNum = int(1 << 14) // limit on concurrently established (or establishing) contexts
+
+	gssMaxIncompleteContextsPerClient = 16               // limit on contexts being established by each client address
+	gssIncompleteContextTTL           = 30 * time.Second // time from RPCSEC_GSS_INIT within which establishment must complete
+	gssIdleContextTTL                 = 1 * time.Hour    // time after which an established but unused context expires
+)
+
+// gssStruct holds the RPCSEC_GSS state shared by all servers launched while EnableRPCSecGSS() is in effect
+type gssStruct struct {
+	sync.Mutex
+	mechanism    GSSMechanismInterface
+	mapPrincipal GSSPrincipalMapper
+	contexts     map[string]*gssContextStruct // key is string(handle)
+	incomplete   map[string]int               // number of contexts being established by each client address
+	now          func() time.Time             // time source (replaced by tests)
+}
+
+// gssContextStruct tracks a single client's security context
+type gssContextStruct struct {
+	sync.Mutex
+	handle      []byte
+	clientAddr  string    // address of the client that issued RPCSEC_GSS_INIT (protected by gssStruct.Mutex)
+	created     time.Time // time of RPCSEC_GSS_INIT (protected by gssStruct.Mutex)
+	lastUsed    time.Time // time of the last call referencing handle (protected by gssStruct.Mutex)
+	established bool      // mirrors complete (protected by gssStruct.Mutex)
+	mechContext GSSContextInterface
+	complete    bool   // if false, context establishment is still in progress
+	seqHighest  uint32 // highest seq_num accepted so far (only valid if seqStarted == true)
+	seqStarted  bool
+	seqSeen     [gssSeqWindowSize]bool // indexed by seq_num % gssSeqWindowSize
+}
+
+type gssCredStruct struct { // struct rpc_gss_cred_vers_1_t
+	gssProc uint32
+	seqNum  uint32
+	service uint32
+	handle  []byte
+}
+
+var globalGSS *gssStruct // used by servers launched via StartIPv4{TCP|UDP}{MountV3|NFSv3}Server (if non-nil)
+
+func newGSS(mechanism GSSMechanismInterface, mapPrincipal GSSPrincipalMapper) (gss *gssStruct) {
+	gss = &gssStruct{
+		mechanism:    mechanism,
+		mapPrincipal: mapPrincipal,
+		contexts:     make(map[string]*gssContextStruct),
+		incomplete:   make(map[string]int),
+		now:          time.Now,
+	}
+	return
+}
+
+func decodeGSSCred(credBody []byte) (gssCred *gssCredStruct, err error) {
+	var (
+		unpacker = unpackerStruct{buf: credBody}
+		version  uint32
+	)
+
+	version = unpacker.unpackUint32()
+	if (nil == unpacker.err) && (rpcGSSVers1 != version) {
+		err = fmt.Errorf("rpc_gss_vers (%v) not supported", version)
+		return
+	}
+
+	gssCred = &gssCredStruct{}
+
+	gssCred.gssProc = unpacker.unpackUint32()
+	gssCred.seqNum = unpacker.unpackUint32()
+	gssCred.service = unpacker.unpackUint32()
+	gssCred.handle = unpacker.unpackOpaque()
+
+	err = unpacker.err
+	if (nil == err) && (uint64(len(credBody)) != unpacker.bytesConsumed) {
+		err = fmt.Errorf("rpc_gss_cred_t contained trailing bytes")
+	}
+
+	return
+}
+
+// handleCall authenticates a call bearing an RPCSEC_GSS credential. Context establishment and destruction
+// calls, as well as those that fail authentication, are answered here. For RPCSEC_GSS_DATA calls that pass,
+// ok is returned as true along with the credential to supply to callbacks and the (unprotected) parms. Note
+// that the replier is updated such that the reply to such calls will be protected accordingly.
+func (gss *gssStruct) handleCall(replier *rpcReplierStruct) (credential *CredentialStruct, parms []byte, ok bool) {
+	var (
+		call    = replier.call
+		err     error
+		gssCred *gssCredStruct
+	)
+
+	gssCred, err = decodeGSSCred(call.credBody)
+	if nil != err {
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS credential of %v malformed: %v", call, err))
+		replier.sendAuthError(rpcAuthStatBadCred)
+		return
+	}
+
+	switch gssCred.gssProc {
+	case rpcGSSProcInit:
+		gss.init(replier, gssCred)
+	case rpcGSSProcContinueInit:
+		gss.init(replier, gssCred)
+	case rpcGSSProcData:
+		credential, parms, ok = gss.data(replier, gssCred)
+	case rpcGSSProcDestroy:
+		gss.destroy(replier, gssCred)
+	default:
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS gss_proc (%v) of %v not recognized", gssCred.gssProc, call))
+		replier.sendAuthError(rpcAuthStatBadCred)
+	}
+
+	return
+}
+
+// init handles both RPCSEC_GSS_INIT & RPCSEC_GSS_CONTINUE_INIT
+func (gss *gssStruct) init(replier *rpcReplierStruct, gssCred *gssCredStruct) {
+	var (
+		bytesConsumed uint64
+		call          = replier.call
+		complete      bool
+		err           error
+		gssContext    *gssContextStruct
+		inputToken    []byte
+		mechContext   GSSContextInterface
+		outputToken   []byte
+		unpacker      unpackerStruct
+		verfBody      []byte
+		verfFlavor    uint32
+		windowPacker  packerStruct
+	)
+
+	if ProcNULL != call.proc {
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS context establishment via non-NULL proc of %v", call))
+		replier.sendAuthError(rpcAuthStatBadCred)
+		return
+	}
+
+	unpacker = unpackerStruct{buf: call.parms}
+	inputToken = unpacker.unpackOpaque() // struct rpc_gss_init_arg
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	if (nil == err) && (uint64(len(call.parms)) != bytesConsumed) {
+		err = fmt.Errorf("rpc_gss_init_arg contained trailing bytes")
+	}
+	if nil != err {
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS context establishment args of %v malformed: %v", call, err))
+		err = replier.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			replier.server.errorLog(err)
+		}
+		return
+	}
+
+	if rpcGSSProcInit == gssCred.gssProc {
+		if 0 != len(gssCred.handle) {
+			replier.sendAuthError(rpcAuthStatBadCred)
+			return
+		}
+		gssContext, err = gss.newContext(clientAddr(replier))
+		if nil != err {
+			replier.server.errorLog(fmt.Errorf("RPCSEC_GSS context establishment of %v refused: %v", call, err))
+			replier.sendInitResult([]byte{}, gssSFailure, nil, AuthNone, []byte{})
+			return
+		}
+	} else {
+		gssContext = gss.lookupContext(gssCred.handle)
+		if nil == gssContext {
+			replier.sendAuthError(rpcAuthStatGSSCredProblem)
+			return
+		}
+	}
+
+	gssContext.Lock()
+	if gssContext.complete {
+		gssContext.Unlock()
+		replier.sendAuthError(rpcAuthStatGSSCredProblem)
+		return
+	}
+	mechContext, outputToken, complete, err = gss.mechanism.AcceptSecContext(gssContext.mechContext, inputToken)
+	if nil != err {
+		gssContext.Unlock()
+		gss.deleteContext(gssContext.handle)
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS context establishment of %v failed: %v", call, err))
+		replier.sendInitResult([]byte{}, gssSFailure, outputToken, AuthNone, []byte{})
+		return
+	}
+	gssContext.mechContext = mechContext
+	gssContext.complete = complete
+	gssContext.Unlock()
+
+	if complete {
+		gss.establishedContext(gssContext)
+	}
+
+	if !complete {
+		replier.sendInitResult(gssContext.handle, gssSContinueNeeded, outputToken, AuthNone, []byte{})
+		return
+	}
+
+	// Once established, the reply verifier is the checksum of the sequence window
+
+	windowPacker.packUint32(gssSeqWindowSize)
+	verfBody, err = mechContext.GetMIC(windowPacker.buf)
+	if nil != err {
+		gss.deleteContext(gssContext.handle)
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS GetMIC() for %v failed: %v", call, err))
+		err = replier.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			replier.server.errorLog(err)
+		}
+		return
+	}
+	verfFlavor = RPCSecGSS
+
+	replier.sendInitResult(gssContext.handle, gssSComplete, outputToken, verfFlavor, verfBody)
+}
+
+// verify checks the verifier & seq_num of an RPCSEC_GSS_DATA or RPCSEC_GSS_DESTROY call. If ok is returned
+// as false, the call has already been either answered or (e.g. if a replay) silently discarded.
+func (gss *gssStruct) verify(replier *rpcReplierStruct, gssCred *gssCredStruct) (gssContext *gssContextStruct, ok bool) {
+	var (
+		call = replier.call
+		err  error
+	)
+
+	gssContext = gss.lookupContext(gssCred.handle)
+	if (nil == gssContext) || !gssContext.isComplete() {
+		replier.sendAuthError(rpcAuthStatGSSCredProblem)
+		return
+	}
+
+	if RPCSecGSS != call.verfFlavor {
+		replier.sendAuthError(rpcAuthStatGSSCredProblem)
+		return
+	}
+	err = gssContext.mechContext.VerifyMIC(call.header, call.verfBody)
+	if nil != err {
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS verifier of %v rejected: %v", call, err))
+		replier.sendAuthError(rpcAuthStatGSSCredProblem)
+		return
+	}
+
+	if rpcGSSMaxSeq <= gssCred.seqNum {
+		gss.deleteContext(gssContext.handle)
+		replier.sendAuthError(rpcAuthStatGSSCtxProblem)
+		return
+	}
+
+	if !gssContext.acceptSeqNum(gssCred.seqNum) {
+		return // replayed or outside the sequence window... so silently discarded
+	}
+
+	replier.gssContext = gssContext
+	replier.gssSeqNum = gssCred.seqNum
+
+	ok = true
+	return
+}
+
+func (gss *gssStruct) data(replier *rpcReplierStruct, gssCred *gssCredStruct) (credential *CredentialStruct, parms []byte, ok bool) {
+	var (
+		call       = replier.call
+		err        error
+		gssContext *gssContextStruct
+		mapped     bool
+	)
+
+	switch gssCred.service {
+	case RPCGSSSvcNone:
+	case RPCGSSSvcIntegrity:
+	case RPCGSSSvcPrivacy:
+	default:
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS service (%v) of %v not recognized", gssCred.service, call))
+		replier.sendAuthError(rpcAuthStatBadCred)
+		return
+	}
+
+	gssContext, ok = gss.verify(replier, gssCred)
+	if !ok {
+		return
+	}
+
+	replier.gssService = gssCred.service
+
+	parms, err = gssContext.unprotect(gssCred.service, gssCred.seqNum, call.parms)
+	if nil != err {
+		replier.server.errorLog(fmt.Errorf("RPCSEC_GSS protected args of %v rejected: %v", call, err))
+		err = replier.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			replier.server.errorLog(err)
+		}
+		ok = false
+		return
+	}
+
+	credential = &CredentialStruct{
+		Flavor:    RPCSecGSS,
+		Principal: gssContext.mechContext.Principal(),
+		Service:   gssCred.service,
+	}
+
+	if nil != gss.mapPrincipal {
+		credential.UID, credential.GID, credential.GIDs, mapped = gss.mapPrincipal(credential.Principal)
+	}
+	if !mapped {
+		credential.UID = replier.server.anonUID
+		credential.GID = replier.server.anonGID
+		credential.GIDs = []uint32{}
+	}
+
+	return
+}
+
+func (gss *gssStruct) destroy(replier *rpcReplierStruct, gssCred *gssCredStruct) {
+	var (
+		err error
+		ok  bool
+	)
+
+	if ProcNULL != replier.call.proc {
+		replier.sendAuthError(rpcAuthStatBadCred)
+		return
+	}
+
+	_, ok = gss.verify(replier, gssCred)
+	if !ok {
+		return
+	}
+
+	err = replier.sendAcceptedSuccess([]byte{}) // note that replier.gssService was not set so the (void) results are not protected
+	if nil != err {
+		replier.server.errorLog(err)
+	}
+
+	gss.deleteContext(gssCred.handle)
+}
+
+// newContext registers a context for a client (at clientAddr) beginning establishment. Each client address may
+// only be establishing a limited number of contexts at once. Should the limit on all contexts be reached, expired
+// contexts are discarded followed, if necessary, by the least recently begun context still being established.
+func (gss *gssStruct) newContext(clientAddr string) (gssContext *gssContextStruct, err error) {
+	var (
+		now time.Time
+	)
+
+	gssContext = &gssContextStruct{handle: make([]byte, gssHandleSize), clientAddr: clientAddr}
+
+	_, err = rand.Read(gssContext.handle)
+	if nil != err {
+		gssContext = nil
+		return
+	}
+
+	gss.Lock()
+	defer gss.Unlock()
+
+	now = gss.now()
+
+	if gssMaxIncompleteContextsPerClient <= gss.incomplete[clientAddr] {
+		gss.expireContexts(now)
+		if gssMaxIncompleteContextsPerClient <= gss.incomplete[clientAddr] {
+			gssContext = nil
+			err = fmt.Errorf("too many RPCSEC_GSS contexts being established by %q (%v)", clientAddr, gssMaxIncompleteContextsPerClient)
+			return
+		}
+	}
+
+	if gssMaxContextsNum <= len(gss.contexts) {
+		gss.expireContexts(now)
+		if (gssMaxContextsNum <= len(gss.contexts)) && !gss.evictIncompleteContext() {
+			gssContext = nil
+			err = fmt.Errorf("too many RPCSEC_GSS contexts (%v)", gssMaxContextsNum)
+			return
+		}
+	}
+
+	gssContext.created = now
+	gssContext.lastUsed = now
+
+	gss.contexts[string(gssContext.handle)] = gssContext
+	gss.incomplete[clientAddr]++
+
+	return
+}
+
+// expired returns whether a context has either not completed establishment or not been used in time. Note that
+// gss.Mutex must be held.
+func (gssContext *gssContextStruct) expired(now time.Time) (expired bool) {
+	if gssContext.established {
+		expired = now.Sub(gssContext.lastUsed) >= gssIdleContextTTL
+	} else {
+		expired = now.Sub(gssContext.created) >= gssIncompleteContextTTL
+	}
+	return
+}
+
+// expireContexts discards all expired contexts. Note that gss.Mutex must be held.
+func (gss *gssStruct) expireContexts(now time.Time) {
+	var (
+		gssContext *gssContextStruct
+	)
+
+	for _, gssContext = range gss.contexts {
+		if gssContext.expired(now) {
+			gss.removeContext(gssContext)
+		}
+	}
+}
+
+// evictIncompleteContext discards the least recently begun context still being established (returning false if
+// there is none). Note that gss.Mutex must be held.
+func (gss *gssStruct) evictIncompleteContext() (evicted bool) {
+	var (
+		gssContext *gssContextStruct
+		oldest     *gssContextStruct
+	)
+
+	for _, gssContext = range gss.contexts {
+		if !gssContext.established && ((nil == oldest) || gssContext.created.Before(oldest.created)) {
+			oldest = gssContext
+		}
+	}
+
+	if nil != oldest {
+		gss.removeContext(oldest)
+		evicted = true
+	}
+
+	return
+}
+
+// removeContext discards a context. Note that gss.Mutex must be held.
+func (gss *gssStruct) removeContext(gssContext *gssContextStruct) {
+	delete(gss.contexts, string(gssContext.handle))
+
+	if !gssContext.established {
+		gss.incomplete[gssContext.clientAddr]--
+		if 0 == gss.incomplete[gssContext.clientAddr] {
+			delete(gss.incomplete, gssContext.clientAddr)
+		}
+	}
+}
+
+// establishedContext records that establishment of a context has completed
+func (gss *gssStruct) establishedContext(gssContext *gssContextStruct) {
+	gss.Lock()
+	if (gss.contexts[string(gssContext.handle)] == gssContext) && !gssContext.established {
+		gss.incomplete[gssContext.clientAddr]--
+		if 0 == gss.incomplete[gssContext.clientAddr] {
+			delete(gss.incomplete, gssContext.clientAddr)
+		}
+		gssContext.established = true
+	}
+	gss.Unlock()
+}
+
+// lookupContext returns the (unexpired) context identified by handle (or nil if there is none)
+func (gss *gssStruct) lookupContext(handle []byte) (gssContext *gssContextStruct) {
+	var (
+		now time.Time
+	)
+
+	gss.Lock()
+	defer gss.Unlock()
+
+	gssContext = gss.contexts[string(handle)]
+	if nil == gssContext {
+		return
+	}
+
+	now = gss.now()
+
+	if gssContext.expired(now) {
+		gss.removeContext(gssContext)
+		gssContext = nil
+		return
+	}
+
+	gssContext.lastUsed = now
+
+	return
+}
+
+func (gss *gssStruct) deleteContext(handle []byte) {
+	var (
+		gssContext *gssContextStruct
+	)
+
+	gss.Lock()
+	gssContext = gss.contexts[string(handle)]
+	if nil != gssContext {
+		gss.removeContext(gssContext)
+	}
+	gss.Unlock()
+}
+
+func (gssContext *gssContextStruct) isComplete() (complete bool) {
+	gssContext.Lock()
+	complete = gssContext.complete
+	gssContext.Unlock()
+	return
+}
+
+// acceptSeqNum enforces the sequence window, returning false for a seq_num that has either already been seen
+// or has fallen below the window
+func (gssContext *gssContextStruct) acceptSeqNum(seqNum uint32) (accepted bool) {
+	var (
+		advance uint32
+	)
+
+	gssContext.Lock()
+	defer gssContext.Unlock()
+
+	if !gssContext.seqStarted {
+		gssContext.seqStarted = true
+		gssContext.seqHighest = seqNum
+		gssContext.seqSeen[seqNum%gssSeqWindowSize] = true
+		accepted = true
+		return
+	}
+
+	if seqNum > gssContext.seqHighest {
+		advance = seqNum - gssContext.seqHighest
+		if advance >= gssSeqWindowSize {
+			gssContext.seqSeen = [gssSeqWindowSize]bool{}
+		} else {
+			for ; advance > 0; advance-- {
+				gssContext.seqSeen[(gssContext.seqHighest+advance)%gssSeqWindowSize] = false
+			}
+		}
+		gssContext.seqHighest = seqNum
+		gssContext.seqSeen[seqNum%gssSeqWindowSize] = true
+		accepted = true
+		return
+	}
+
+	if (gssContext.seqHighest - seqNum) >= gssSeqWindowSize {
+		accepted = false
+		return
+	}
+
+	if gssContext.seqSeen[seqNum%gssSeqWindowSize] {
+		accepted = false
+		return
+	}
+
+	gssContext.seqSeen[seqNum%gssSeqWindowSize] = true
+	accepted = true
+	return
+}
+
+// unprotect returns the args of an RPCSEC_GSS_DATA call after verifying (and, if necessary, decrypting) them
+func (gssContext *gssContextStruct) unprotect(service uint32, seqNum uint32, protected []byte) (parms []byte, err error) {
+	var (
+		checksum []byte
+		dataBody []byte
+		unpacker = unpackerStruct{buf: protected}
+	)
+
+	switch service {
+	case RPCGSSSvcNone:
+		parms = protected
+		return
+	case RPCGSSSvcIntegrity:
+		dataBody = unpacker.unpackOpaque() // struct rpc_gss_integ_data.databody_integ
+		checksum = unpacker.unpackOpaque() // struct rpc_gss_integ_data.checksum
+		if nil != unpacker.err {
+			err = unpacker.err
+			return
+		}
+		err = gssContext.mechContext.VerifyMIC(dataBody, checksum)
+		if nil != err {
+			return
+		}
+	case RPCGSSSvcPrivacy:
+		dataBody = unpacker.unpackOpaque() // struct rpc_gss_priv_data.databody_priv
+		if nil != unpacker.err {
+			err = unpacker.err
+			return
+		}
+		dataBody, err = gssContext.mechContext.Unwrap(dataBody)
+		if nil != err {
+			return
+		}
+	}
+
+	if uint64(len(protected)) != unpacker.bytesConsumed {
+		err = fmt.Errorf("protected args contained trailing bytes")
+		return
+	}
+
+	// Both databody_integ & databody_priv are the seq_num followed by the args themselves
+
+	unpacker = unpackerStruct{buf: dataBody}
+	if seqNum != unpacker.unpackUint32() {
+		err = fmt.Errorf("seq_num within protected args did not match that of the credential")
+		return
+	}
+	if nil != unpacker.err {
+		err = unpacker.err
+		return
+	}
+
+	parms = dataBody[unpacker.bytesConsumed:]
+
+	return
+}
+
+// protect returns the results of an RPCSEC_GSS_DATA call after protecting them as requested by service
+func (gssContext *gssContextStruct) protect(service uint32, seqNum uint32, results []byte) (protected []byte, err error) {
+	var (
+		checksum []byte
+		dataBody []byte
+		packer   packerStruct
+		wrapped  []byte
+	)
+
+	if (RPCGSSSvcIntegrity != service) && (RPCGSSSvcPrivacy != service) {
+		protected = results
+		return
+	}
+
+	// Both databody_integ & databody_priv are the seq_num followed by the results themselves
+
+	packer.packUint32(seqNum)
+	dataBody = append(packer.buf, results...)
+	packer = packerStruct{}
+
+	if RPCGSSSvcIntegrity == service {
+		checksum, err = gssContext.mechContext.GetMIC(dataBody)
+		if nil != err {
+			return
+		}
+		packer.packOpaque(dataBody)
+		packer.packOpaque(checksum)
+	} else {
+		wrapped, err = gssContext.mechContext.Wrap(dataBody)
+		if nil != err {
+			return
+		}
+		packer.packOpaque(wrapped)
+	}
+
+	protected, err = packer.buf, packer.err
+	return
+}
+
+// replyVerifier returns the body of the RPCSEC_GSS verifier for a reply to the call bearing seqNum
+func (gssContext *gssContextStruct) replyVerifier(seqNum uint32) (verfBody []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(seqNum)
+
+	verfBody, err = gssContext.mechContext.GetMIC(packer.buf)
+	return
+}
+
+// sendInitResult replies to an RPCSEC_GSS_INIT or RPCSEC_GSS_CONTINUE_INIT call with an rpc_gss_init_res
+func (replier *rpcReplierStruct) sendInitResult(handle []byte, gssMajor uint32, outputToken []byte, verfFlavor uint32, verfBody []byte) {
+	var (
+		err    error
+		packer packerStruct
+		reply  []byte
+	)
+
+	if nil == outputToken {
+		outputToken = []byte{}
+	}
+
+	packer.packOpaque(handle)
+	packer.packUint32(gssMajor)
+	packer.packUint32(0) // gss_minor
+	packer.packUint32(gssSeqWindowSize)
+	packer.packOpaque(outputToken)
+	if nil != packer.err {
+		replier.server.errorLog(packer.err)
+		return
+	}
+
+	reply, err = encodeRPCAcceptedReply(replier.call.xid, verfFlavor, verfBody, onc.Success, packer.buf)
+	if nil != err {
+		replier.server.errorLog(err)
+		return
+	}
+
+	err = replier.reply(reply)
+	if nil != err {
+		replier.server.errorLog(err)
+	}
+}
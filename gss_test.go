@@ -0,0 +1,547 @@
+package nfsd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/onc"
+)
+
+// fakeGSSMechanismStruct is an in-process GSS-API mechanism establishing a context in two round trips:
+//
+//	client sends "INIT:<principal>" and receives "CHALLENGE"
+//	client sends "RESPONSE" and receives "OK"
+//
+// Checksums are an HMAC keyed by the principal. Wrapping prepends such a checksum to the bytes of the
+// message inverted (i.e. it is not actual encryption).
+type fakeGSSMechanismStruct struct{}
+
+type fakeGSSContextStruct struct {
+	principal string
+}
+
+func (mechanism *fakeGSSMechanismStruct) AcceptSecContext(gssContext GSSContextInterface, inputToken []byte) (acceptedGSSContext GSSContextInterface, outputToken []byte, complete bool, err error) {
+	if nil == gssContext {
+		if !strings.HasPrefix(string(inputToken), "INIT:") {
+			err = fmt.Errorf("unexpected initial token %q", inputToken)
+			return
+		}
+		acceptedGSSContext = &fakeGSSContextStruct{principal: string(inputToken[len("INIT:"):])}
+		outputToken = []byte("CHALLENGE")
+		complete = false
+		return
+	}
+
+	if "RESPONSE" != string(inputToken) {
+		err = fmt.Errorf("unexpected continuation token %q", inputToken)
+		return
+	}
+
+	acceptedGSSContext = gssContext
+	outputToken = []byte("OK")
+	complete = true
+	return
+}
+
+func (gssContext *fakeGSSContextStruct) Principal() (principal string) {
+	principal = gssContext.principal
+	return
+}
+
+func (gssContext *fakeGSSContextStruct) GetMIC(message []byte) (mic []byte, err error) {
+	mac := hmac.New(sha256.New, []byte(gssContext.principal))
+	_, _ = mac.Write(message)
+	mic = mac.Sum(nil)
+	return
+}
+
+func (gssContext *fakeGSSContextStruct) VerifyMIC(message []byte, mic []byte) (err error) {
+	expectedMIC, _ := gssContext.GetMIC(message)
+	if !hmac.Equal(expectedMIC, mic) {
+		err = fmt.Errorf("MIC mismatch")
+	}
+	return
+}
+
+func (gssContext *fakeGSSContextStruct) Wrap(message []byte) (wrapped []byte, err error) {
+	var (
+		i int
+	)
+
+	wrapped, _ = gssContext.GetMIC(message)
+	for i = range message {
+		wrapped = append(wrapped, ^message[i])
+	}
+	return
+}
+
+func (gssContext *fakeGSSContextStruct) Unwrap(wrapped []byte) (message []byte, err error) {
+	var (
+		i int
+	)
+
+	if sha256.Size > len(wrapped) {
+		err = fmt.Errorf("wrapped message too short")
+		return
+	}
+	message = make([]byte, len(wrapped)-sha256.Size)
+	for i = range message {
+		message[i] = ^wrapped[sha256.Size+i]
+	}
+	err = gssContext.VerifyMIC(message, wrapped[:sha256.Size])
+	return
+}
+
+// echoProgramStruct answers each call with its (unprotected) parms, remembering the credential supplied
+type echoProgramStruct struct {
+	credential *CredentialStruct
+}
+
+func (echoProgram *echoProgramStruct) dispatch(connHandle connHandleInterface, xid uint32, proc uint32, credential *CredentialStruct, parms []byte) {
+	echoProgram.credential = credential
+	_ = connHandle.sendAcceptedSuccess(parms)
+}
+
+type gssTestReplyStruct struct {
+	replyStat  uint32
+	authStat   uint32 // only valid if replyStat == rpcReplyStatDenied
+	verfFlavor uint32
+	verfBody   []byte
+	acceptStat uint32
+	body       []byte
+}
+
+// gssTestClientStruct drives an rpcServerStruct's handleCall() directly (i.e. without a transport)
+type gssTestClientStruct struct {
+	t          *testing.T
+	server     *rpcServerStruct
+	xid        uint32
+	seqNum     uint32
+	handle     []byte
+	gssContext *fakeGSSContextStruct
+}
+
+func (client *gssTestClientStruct) call(credFlavor uint32, credBody []byte, verfFlavor uint32, verfBody []byte, proc uint32, parms []byte) (reply *gssTestReplyStruct) {
+	var (
+		packer   packerStruct
+		replies  [][]byte
+		unpacker unpackerStruct
+	)
+
+	client.xid++
+
+	packer.packUint32(client.xid)
+	packer.packUint32(rpcMsgTypeCall)
+	packer.packUint32(rpcVers)
+	packer.packUint32(client.server.prog)
+	packer.packUint32(client.server.vers)
+	packer.packUint32(proc)
+	packer.packUint32(credFlavor)
+	packer.packOpaque(credBody)
+	if nil == verfBody {
+		verfBody, _ = client.gssContext.GetMIC(packer.buf) // i.e. an RPCSEC_GSS verifier
+	}
+	packer.packUint32(verfFlavor)
+	packer.packOpaque(verfBody)
+
+	client.server.handleCall(context.Background(), append(packer.buf, parms...), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 800}, func(reply []byte) (err error) {
+		replies = append(replies, reply)
+		return
+	}, false)
+
+	switch len(replies) {
+	case 0:
+		return
+	case 1:
+		reply = &gssTestReplyStruct{}
+	default:
+		client.t.Fatalf("handleCall() sent %v replies", len(replies))
+	}
+
+	unpacker = unpackerStruct{buf: replies[0]}
+	if client.xid != unpacker.unpackUint32() {
+		client.t.Fatalf("reply xid mismatch")
+	}
+	if rpcMsgTypeReply != unpacker.unpackUint32() {
+		client.t.Fatalf("reply msg_type mismatch")
+	}
+	reply.replyStat = unpacker.unpackUint32()
+	if rpcReplyStatDenied == reply.replyStat {
+		if rpcRejectStatAuthError != unpacker.unpackUint32() {
+			client.t.Fatalf("reject_stat mismatch")
+		}
+		reply.authStat = unpacker.unpackUint32()
+	} else {
+		reply.verfFlavor = unpacker.unpackUint32()
+		reply.verfBody = unpacker.unpackOpaque()
+		reply.acceptStat = unpacker.unpackUint32()
+		reply.body = replies[0][unpacker.bytesConsumed:]
+	}
+	if nil != unpacker.err {
+		client.t.Fatalf("reply malformed: %v", unpacker.err)
+	}
+
+	return
+}
+
+func (client *gssTestClientStruct) gssCred(gssProc uint32, service uint32) (credBody []byte) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(rpcGSSVers1)
+	packer.packUint32(gssProc)
+	packer.packUint32(client.seqNum)
+	packer.packUint32(service)
+	packer.packOpaque(client.handle)
+
+	credBody = packer.buf
+	return
+}
+
+func (client *gssTestClientStruct) establish(principal string) {
+	var (
+		packer   packerStruct
+		reply    *gssTestReplyStruct
+		unpacker unpackerStruct
+	)
+
+	for _, step := range []struct {
+		gssProc  uint32
+		token    string
+		gssMajor uint32
+		response string
+	}{
+		{rpcGSSProcInit, "INIT:" + principal, gssSContinueNeeded, "CHALLENGE"},
+		{rpcGSSProcContinueInit, "RESPONSE", gssSComplete, "OK"},
+	} {
+		packer = packerStruct{}
+		packer.packOpaque([]byte(step.token))
+
+		reply = client.call(RPCSecGSS, client.gssCred(step.gssProc, RPCGSSSvcNone), AuthNone, []byte{}, ProcNULL, packer.buf)
+		if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+			client.t.Fatalf("context establishment step %q not accepted: %+v", step.token, reply)
+		}
+
+		unpacker = unpackerStruct{buf: reply.body}
+		client.handle = unpacker.unpackOpaque()
+		if step.gssMajor != unpacker.unpackUint32() {
+			client.t.Fatalf("context establishment step %q returned wrong gss_major", step.token)
+		}
+		_ = unpacker.unpackUint32() // gss_minor
+		if gssSeqWindowSize != unpacker.unpackUint32() {
+			client.t.Fatalf("context establishment step %q returned wrong seq_window", step.token)
+		}
+		if step.response != string(unpacker.unpackOpaque()) {
+			client.t.Fatalf("context establishment step %q returned wrong gss_token", step.token)
+		}
+	}
+
+	client.gssContext = &fakeGSSContextStruct{principal: principal}
+
+	packer = packerStruct{}
+	packer.packUint32(gssSeqWindowSize)
+	if (RPCSecGSS != reply.verfFlavor) || (nil != client.gssContext.VerifyMIC(packer.buf, reply.verfBody)) {
+		client.t.Fatalf("context establishment completion verifier incorrect")
+	}
+}
+
+func TestGSSData(t *testing.T) {
+	var (
+		args         = []byte{0x00, 0x00, 0x00, 0x2A}
+		echoProgram  = &echoProgramStruct{}
+		packer       packerStruct
+		protected    []byte
+		reply        *gssTestReplyStruct
+		results      []byte
+		seqNumPacker packerStruct
+		service      uint32
+		unpacker     unpackerStruct
+	)
+
+	client := &gssTestClientStruct{
+		t: t,
+		server: &rpcServerStruct{
+			prog:     NFSProgram,
+			vers:     NFSVersion,
+			program:  echoProgram,
+			errorLog: func(err error) {},
+			anonUID:  DefaultAnonUID,
+			anonGID:  DefaultAnonGID,
+			gss: newGSS(&fakeGSSMechanismStruct{}, func(principal string) (uid uint32, gid uint32, gids []uint32, ok bool) {
+				if "alice@EXAMPLE.COM" == principal {
+					uid, gid, gids, ok = 1000, 1000, []uint32{1000, 10}, true
+				}
+				return
+			}),
+		},
+	}
+
+	client.establish("alice@EXAMPLE.COM")
+
+	for _, service = range []uint32{RPCGSSSvcNone, RPCGSSSvcIntegrity, RPCGSSSvcPrivacy} {
+		client.seqNum++
+
+		seqNumPacker = packerStruct{}
+		seqNumPacker.packUint32(client.seqNum)
+
+		packer = packerStruct{}
+		switch service {
+		case RPCGSSSvcNone:
+			packer.buf = args
+		case RPCGSSSvcIntegrity:
+			mic, _ := client.gssContext.GetMIC(append(seqNumPacker.buf, args...))
+			packer.packOpaque(append(seqNumPacker.buf, args...))
+			packer.packOpaque(mic)
+		case RPCGSSSvcPrivacy:
+			wrapped, _ := client.gssContext.Wrap(append(seqNumPacker.buf, args...))
+			packer.packOpaque(wrapped)
+		}
+
+		reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, service), RPCSecGSS, nil, NFSPROC3GETATTR, packer.buf)
+		if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+			t.Fatalf("service %v call not accepted: %+v", service, reply)
+		}
+		if (RPCSecGSS != reply.verfFlavor) || (nil != client.gssContext.VerifyMIC(seqNumPacker.buf, reply.verfBody)) {
+			t.Fatalf("service %v reply verifier incorrect", service)
+		}
+
+		unpacker = unpackerStruct{buf: reply.body}
+		switch service {
+		case RPCGSSSvcNone:
+			results = reply.body
+		case RPCGSSSvcIntegrity:
+			protected = unpacker.unpackOpaque()
+			if nil != client.gssContext.VerifyMIC(protected, unpacker.unpackOpaque()) {
+				t.Fatalf("service %v reply checksum incorrect", service)
+			}
+			results = protected[4:]
+		case RPCGSSSvcPrivacy:
+			protected, _ = client.gssContext.Unwrap(unpacker.unpackOpaque())
+			results = protected[4:]
+		}
+		if !bytes.Equal(args, results) {
+			t.Fatalf("service %v results were %v... expected %v", service, results, args)
+		}
+
+		if (RPCSecGSS != echoProgram.credential.Flavor) || ("alice@EXAMPLE.COM" != echoProgram.credential.Principal) || (service != echoProgram.credential.Service) || (1000 != echoProgram.credential.UID) {
+			t.Fatalf("service %v credential incorrect: %+v", service, echoProgram.credential)
+		}
+	}
+
+	// A replayed seq_num must be silently discarded
+
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, nil, NFSPROC3GETATTR, args)
+	if nil != reply {
+		t.Fatalf("replayed seq_num was answered: %+v", reply)
+	}
+
+	// A bad verifier must be rejected
+
+	client.seqNum++
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, []byte("forged"), NFSPROC3GETATTR, args)
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatGSSCredProblem != reply.authStat) {
+		t.Fatalf("forged verifier not rejected: %+v", reply)
+	}
+
+	// Once destroyed, the context must no longer be accepted
+
+	client.seqNum++
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcDestroy, RPCGSSSvcNone), RPCSecGSS, nil, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("RPCSEC_GSS_DESTROY not accepted: %+v", reply)
+	}
+
+	client.seqNum++
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, nil, NFSPROC3GETATTR, args)
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatGSSCredProblem != reply.authStat) {
+		t.Fatalf("destroyed context not rejected: %+v", reply)
+	}
+}
+
+func TestGSSUnmappedPrincipal(t *testing.T) {
+	var (
+		echoProgram = &echoProgramStruct{}
+		reply       *gssTestReplyStruct
+	)
+
+	client := &gssTestClientStruct{
+		t:      t,
+		server: &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: echoProgram, errorLog: func(err error) {}, anonUID: 7, anonGID: 8, gss: newGSS(&fakeGSSMechanismStruct{}, nil)},
+	}
+
+	client.establish("bob@EXAMPLE.COM")
+
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, nil, ProcNULL, []byte{})
+	if (nil == reply) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("call not accepted: %+v", reply)
+	}
+	if ("bob@EXAMPLE.COM" != echoProgram.credential.Principal) || (7 != echoProgram.credential.UID) || (8 != echoProgram.credential.GID) {
+		t.Fatalf("credential incorrect: %+v", echoProgram.credential)
+	}
+}
+
+func TestGSSDisabled(t *testing.T) {
+	var (
+		reply *gssTestReplyStruct
+	)
+
+	client := &gssTestClientStruct{
+		t:          t,
+		server:     &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: &echoProgramStruct{}, errorLog: func(err error) {}},
+		gssContext: &fakeGSSContextStruct{},
+	}
+
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcInit, RPCGSSSvcNone), AuthNone, []byte{}, ProcNULL, []byte{0, 0, 0, 0})
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatBadCred != reply.authStat) {
+		t.Fatalf("RPCSEC_GSS not rejected: %+v", reply)
+	}
+
+	reply = client.call(AuthNone, []byte{}, AuthNone, []byte{}, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("AUTH_NONE not accepted: %+v", reply)
+	}
+}
+
+func TestGSSSeqWindow(t *testing.T) {
+	var (
+		gssContext = &gssContextStruct{}
+	)
+
+	for _, step := range []struct {
+		seqNum   uint32
+		accepted bool
+	}{
+		{10, true},
+		{10, false}, // replay
+		{12, true},
+		{11, true}, // out of order but within the window
+		{11, false},
+		{10 + gssSeqWindowSize, true},
+		{10, false},                     // now below the window
+		{11 + gssSeqWindowSize, true},   //
+		{12, false},                     // replay (still within the window)
+		{13, true},                      // not yet seen (and still within the window)
+		{13 + 3*gssSeqWindowSize, true}, // window advances beyond all previously seen seq_nums
+		{13 + 2*gssSeqWindowSize, false},
+	} {
+		if step.accepted != gssContext.acceptSeqNum(step.seqNum) {
+			t.Fatalf("acceptSeqNum(%v) should have returned %v", step.seqNum, step.accepted)
+		}
+	}
+}
+
+// testGSSInit issues an RPCSEC_GSS_INIT leaving the context incomplete, returning the gss_major of the result
+func (client *gssTestClientStruct) testGSSInit() (gssMajor uint32) {
+	var (
+		packer   packerStruct
+		reply    *gssTestReplyStruct
+		unpacker unpackerStruct
+	)
+
+	client.handle = nil
+
+	packer.packOpaque([]byte("INIT:mallory@EXAMPLE.COM"))
+
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcInit, RPCGSSSvcNone), AuthNone, []byte{}, ProcNULL, packer.buf)
+	if (nil == reply) || (rpcReplyStatAccepted != reply.replyStat) || (onc.Success != reply.acceptStat) {
+		client.t.Fatalf("RPCSEC_GSS_INIT not accepted: %+v", reply)
+	}
+
+	unpacker = unpackerStruct{buf: reply.body}
+	_ = unpacker.unpackOpaque() // handle
+	gssMajor = unpacker.unpackUint32()
+
+	return
+}
+
+func TestGSSContextExpiry(t *testing.T) {
+	var (
+		err        error
+		gss        = newGSS(&fakeGSSMechanismStruct{}, nil)
+		gssContext *gssContextStruct
+		i          int
+		now        = time.Unix(1600000000, 0)
+		reply      *gssTestReplyStruct
+	)
+
+	gss.now = func() time.Time { return now }
+
+	client := &gssTestClientStruct{
+		t:      t,
+		server: &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: &echoProgramStruct{}, errorLog: func(err error) {}, gss: gss},
+	}
+
+	// Each client address may only be establishing a limited number of contexts at once
+
+	for i = 0; i < gssMaxIncompleteContextsPerClient; i++ {
+		if gssSContinueNeeded != client.testGSSInit() {
+			t.Fatalf("RPCSEC_GSS_INIT #%v refused", i)
+		}
+	}
+	if gssSFailure != client.testGSSInit() {
+		t.Fatalf("RPCSEC_GSS_INIT beyond gssMaxIncompleteContextsPerClient not refused")
+	}
+
+	// ...but incomplete contexts expire
+
+	now = now.Add(gssIncompleteContextTTL)
+
+	client.establish("alice@EXAMPLE.COM")
+	if (1 != len(gss.contexts)) || (0 != len(gss.incomplete)) {
+		t.Fatalf("incomplete contexts not expired: %v contexts, %v incomplete", len(gss.contexts), gss.incomplete)
+	}
+
+	// Established contexts expire once idle
+
+	now = now.Add(gssIdleContextTTL - time.Second)
+
+	client.seqNum++
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, nil, ProcNULL, []byte{})
+	if (nil == reply) || (onc.Success != reply.acceptStat) {
+		t.Fatalf("call via context used within gssIdleContextTTL not accepted: %+v", reply)
+	}
+
+	now = now.Add(gssIdleContextTTL)
+
+	client.seqNum++
+	reply = client.call(RPCSecGSS, client.gssCred(rpcGSSProcData, RPCGSSSvcNone), RPCSecGSS, nil, ProcNULL, []byte{})
+	if (nil == reply) || (rpcReplyStatDenied != reply.replyStat) || (rpcAuthStatGSSCredProblem != reply.authStat) {
+		t.Fatalf("call via idle context not rejected: %+v", reply)
+	}
+	if 0 != len(gss.contexts) {
+		t.Fatalf("idle context not discarded")
+	}
+
+	// Once all contexts are in use, the least recently begun incomplete context is evicted in favor of a new one
+
+	gssContext, err = gss.newContext("10.0.0.2")
+	if nil != err {
+		t.Fatalf("newContext() failed: %v", err)
+	}
+	gss.establishedContext(gssContext)
+
+	for i = 1; i < gssMaxContextsNum; i++ {
+		now = now.Add(time.Millisecond)
+		_, err = gss.newContext(fmt.Sprintf("10.1.%v.%v", i/256, i%256))
+		if nil != err {
+			t.Fatalf("newContext() #%v failed: %v", i, err)
+		}
+	}
+
+	now = now.Add(time.Millisecond)
+	_, err = gss.newContext("10.2.0.0")
+	if nil != err {
+		t.Fatalf("newContext() beyond gssMaxContextsNum should have evicted an incomplete context: %v", err)
+	}
+	if (gssMaxContextsNum != len(gss.contexts)) || (nil == gss.contexts[string(gssContext.handle)]) || (0 != gss.incomplete["10.1.0.1"]) {
+		t.Fatalf("newContext() beyond gssMaxContextsNum evicted the wrong context")
+	}
+}
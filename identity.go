@@ -0,0 +1,120 @@
+package nfsd
+
+// globalIdentityMapping is used by NFSv3 servers launched via StartIPv4{TCP|UDP}NFSv3Server (if non-nil)
+var globalIdentityMapping *IdentityMappingStruct
+
+// copyIdentityMapping returns a deep copy of identityMapping such that subsequent modifications by the
+// caller of SetIdentityMapping() do not affect servers already launched. An AnonUID or AnonGID of 0 is
+// replaced by DefaultAnonUID or DefaultAnonGID (lest root_squash & all_squash map requests to root) unless
+// explicitly specified via AnonUIDSet or AnonGIDSet.
+func copyIdentityMapping(identityMapping *IdentityMappingStruct) (identityMappingCopy *IdentityMappingStruct) {
+	var (
+		id       uint32
+		mappedID uint32
+	)
+
+	if nil == identityMapping {
+		identityMappingCopy = nil
+		return
+	}
+
+	identityMappingCopy = &IdentityMappingStruct{
+		RootSquash: identityMapping.RootSquash,
+		AllSquash:  identityMapping.AllSquash,
+		AnonUID:    identityMapping.AnonUID,
+		AnonGID:    identityMapping.AnonGID,
+		AnonUIDSet: identityMapping.AnonUIDSet,
+		AnonGIDSet: identityMapping.AnonGIDSet,
+		UIDMap:     make(map[uint32]uint32),
+		GIDMap:     make(map[uint32]uint32),
+	}
+
+	if !identityMappingCopy.AnonUIDSet && (0 == identityMappingCopy.AnonUID) {
+		identityMappingCopy.AnonUID = DefaultAnonUID
+	}
+	if !identityMappingCopy.AnonGIDSet && (0 == identityMappingCopy.AnonGID) {
+		identityMappingCopy.AnonGID = DefaultAnonGID
+	}
+
+	for id, mappedID = range identityMapping.UIDMap {
+		identityMappingCopy.UIDMap[id] = mappedID
+	}
+	for id, mappedID = range identityMapping.GIDMap {
+		identityMappingCopy.GIDMap[id] = mappedID
+	}
+
+	return
+}
+
+// apply returns the credential to be supplied to callbacks in place of credential. The supplied credential
+// is not modified. If identityMapping is nil, credential is returned as is.
+func (identityMapping *IdentityMappingStruct) apply(credential *CredentialStruct) (mappedCredential *CredentialStruct) {
+	var (
+		gid uint32
+	)
+
+	if nil == identityMapping {
+		mappedCredential = credential
+		return
+	}
+
+	mappedCredential = &CredentialStruct{
+		Flavor:      credential.Flavor,
+		MachineName: credential.MachineName,
+		Principal:   credential.Principal,
+		Service:     credential.Service,
+	}
+
+	if identityMapping.AllSquash {
+		mappedCredential.UID = identityMapping.AnonUID
+		mappedCredential.GID = identityMapping.AnonGID
+		mappedCredential.GIDs = []uint32{}
+		return
+	}
+
+	mappedCredential.UID = identityMapping.mapUID(credential.UID)
+	mappedCredential.GID = identityMapping.mapGID(credential.GID)
+	mappedCredential.GIDs = make([]uint32, 0, len(credential.GIDs))
+
+	for _, gid = range credential.GIDs {
+		mappedCredential.GIDs = append(mappedCredential.GIDs, identityMapping.mapGID(gid))
+	}
+
+	return
+}
+
+func (identityMapping *IdentityMappingStruct) mapUID(uid uint32) (mappedUID uint32) {
+	var (
+		ok bool
+	)
+
+	if identityMapping.RootSquash && (0 == uid) {
+		mappedUID = identityMapping.AnonUID
+		return
+	}
+
+	mappedUID, ok = identityMapping.UIDMap[uid]
+	if !ok {
+		mappedUID = uid
+	}
+
+	return
+}
+
+func (identityMapping *IdentityMappingStruct) mapGID(gid uint32) (mappedGID uint32) {
+	var (
+		ok bool
+	)
+
+	if identityMapping.RootSquash && (0 == gid) {
+		mappedGID = identityMapping.AnonGID
+		return
+	}
+
+	mappedGID, ok = identityMapping.GIDMap[gid]
+	if !ok {
+		mappedGID = gid
+	}
+
+	return
+}
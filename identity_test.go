@@ -0,0 +1,85 @@
+package nfsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentityMapping(t *testing.T) {
+	var (
+		mappedCredential *CredentialStruct
+	)
+
+	root := &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 0, GID: 0, GIDs: []uint32{0, 10}}
+	user := &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 500, GID: 500, GIDs: []uint32{500, 10}}
+
+	for _, testCase := range []struct {
+		name            string
+		identityMapping *IdentityMappingStruct
+		credential      *CredentialStruct
+		expected        *CredentialStruct
+	}{
+		{
+			name:       "none",
+			credential: root,
+			expected:   root,
+		},
+		{
+			name:            "root_squash of root",
+			identityMapping: &IdentityMappingStruct{RootSquash: true, AnonUID: 65534, AnonGID: 65533},
+			credential:      root,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 65534, GID: 65533, GIDs: []uint32{65533, 10}},
+		},
+		{
+			name:            "root_squash of user",
+			identityMapping: &IdentityMappingStruct{RootSquash: true, AnonUID: 65534, AnonGID: 65533},
+			credential:      user,
+			expected:        user,
+		},
+		{
+			name:            "root_squash without AnonUID & AnonGID",
+			identityMapping: &IdentityMappingStruct{RootSquash: true},
+			credential:      root,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: DefaultAnonUID, GID: DefaultAnonGID, GIDs: []uint32{DefaultAnonGID, 10}},
+		},
+		{
+			name:            "all_squash without AnonUID & AnonGID",
+			identityMapping: &IdentityMappingStruct{AllSquash: true},
+			credential:      user,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: DefaultAnonUID, GID: DefaultAnonGID, GIDs: []uint32{}},
+		},
+		{
+			name:            "all_squash to root via AnonUIDSet & AnonGIDSet",
+			identityMapping: &IdentityMappingStruct{AllSquash: true, AnonUIDSet: true, AnonGIDSet: true},
+			credential:      user,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 0, GID: 0, GIDs: []uint32{}},
+		},
+		{
+			name:            "all_squash",
+			identityMapping: &IdentityMappingStruct{AllSquash: true, AnonUID: 65534, AnonGID: 65533},
+			credential:      user,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 65534, GID: 65533, GIDs: []uint32{}},
+		},
+		{
+			name:            "map table",
+			identityMapping: &IdentityMappingStruct{RootSquash: true, AnonUID: 65534, AnonGID: 65533, UIDMap: map[uint32]uint32{500: 1500, 0: 7}, GIDMap: map[uint32]uint32{10: 110}},
+			credential:      user,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 1500, GID: 500, GIDs: []uint32{500, 110}},
+		},
+		{
+			name:            "map table with root_squash of root",
+			identityMapping: &IdentityMappingStruct{RootSquash: true, AnonUID: 65534, AnonGID: 65533, UIDMap: map[uint32]uint32{500: 1500, 0: 7}, GIDMap: map[uint32]uint32{10: 110}},
+			credential:      root,
+			expected:        &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 65534, GID: 65533, GIDs: []uint32{65533, 110}},
+		},
+	} {
+		mappedCredential = copyIdentityMapping(testCase.identityMapping).apply(testCase.credential)
+		if !reflect.DeepEqual(testCase.expected, mappedCredential) {
+			t.Fatalf("%s: got %+v... expected %+v", testCase.name, mappedCredential, testCase.expected)
+		}
+	}
+
+	if !reflect.DeepEqual(root, &CredentialStruct{Flavor: AuthSys, MachineName: "alpha", UID: 0, GID: 0, GIDs: []uint32{0, 10}}) {
+		t.Fatalf("apply() modified the supplied credential")
+	}
+}
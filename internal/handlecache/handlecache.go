@@ -0,0 +1,250 @@
+// Package handlecache remembers what a backend of package nfsd knows about each object whose file handle it
+// returned (e.g. the object's path) and bounds the search for objects whose handles are not remembered.
+//
+// As any handle not remembered (e.g. one returned before a restart or forged by a client) leads to a search of
+// the backend's tree, searching is limited. A handle not found is answered with NFS3ErrSTALE for missTTL without
+// searching again. Only one search proceeds at a time and none begins until as long as the last took has passed
+// since it completed (such that searching occupies at most half of the time). Handles arriving while a search may
+// not begin are answered with NFS3ErrJUKEBOX (such that the client retries later).
+package handlecache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+const (
+	DefaultMaxEntries = 1 << 16     // limit on the number of entries remembered unless otherwise specified (see New)
+	maxMisses         = 1 << 10     // limit on the number of keys remembered as not found by a search
+	missTTL           = time.Minute // how long a key not found by a search is answered without searching again
+)
+
+// CacheStruct remembers a V for each K (typically identifying the object of a file handle) up to a limit
+// (forgetting the least recently used beyond it) and limits the searches for those not remembered (see Search)
+type CacheStruct[K comparable, V any] struct {
+	sync.Mutex                          // protects entries, lru, & misses
+	entries         map[K]*list.Element // of the *entryStruct of each key remembered
+	lru             *list.List          // of *entryStruct from least to most recently used
+	maxEntries      int                 // beyond which the least recently used entries are forgotten
+	misses          map[K]time.Time     // until when each key not found by a search is not searched for
+	searching       sync.Mutex          // held while searching (protects searchNotBefore)
+	searchNotBefore time.Time           // no search may begin before (see Search)
+}
+
+type entryStruct[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New returns a CacheStruct remembering at most maxEntries entries (or DefaultMaxEntries if 0)
+func New[K comparable, V any](maxEntries int) (cache *CacheStruct[K, V]) {
+	if 0 == maxEntries {
+		maxEntries = DefaultMaxEntries
+	}
+
+	cache = &CacheStruct[K, V]{
+		entries:    make(map[K]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+		misses:     make(map[K]time.Time),
+	}
+
+	return
+}
+
+// Remember invokes update with the value remembered for key (or the zero value of V should key not be
+// remembered), making key the most recently used and forgetting the least recently used keys beyond the limit
+func (cache *CacheStruct[K, V]) Remember(key K, update func(value *V)) {
+	var (
+		element *list.Element
+		ok      bool
+	)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	element, ok = cache.entries[key]
+	if ok {
+		cache.lru.MoveToBack(element)
+	} else {
+		element = cache.lru.PushBack(&entryStruct[K, V]{key: key})
+		cache.entries[key] = element
+	}
+
+	update(&element.Value.(*entryStruct[K, V]).value)
+
+	for cache.lru.Len() > cache.maxEntries {
+		element = cache.lru.Front()
+		delete(cache.entries, element.Value.(*entryStruct[K, V]).key)
+		cache.lru.Remove(element)
+	}
+}
+
+// Recall returns the value remembered for key (if any), making key the most recently used
+func (cache *CacheStruct[K, V]) Recall(key K) (value V, ok bool) {
+	var (
+		element *list.Element
+	)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	element, ok = cache.entries[key]
+	if ok {
+		value = element.Value.(*entryStruct[K, V]).value
+		cache.lru.MoveToBack(element)
+	}
+
+	return
+}
+
+// Update invokes update with the value remembered for key (if any) without altering its recency
+func (cache *CacheStruct[K, V]) Update(key K, update func(value *V)) (ok bool) {
+	var (
+		element *list.Element
+	)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	element, ok = cache.entries[key]
+	if ok {
+		update(&element.Value.(*entryStruct[K, V]).value)
+	}
+
+	return
+}
+
+// Range invokes visit with each key remembered (from least to most recently used) and its value
+func (cache *CacheStruct[K, V]) Range(visit func(key K, value *V)) {
+	var (
+		element *list.Element
+		entry   *entryStruct[K, V]
+	)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	for element = cache.lru.Front(); nil != element; element = element.Next() {
+		entry = element.Value.(*entryStruct[K, V])
+		visit(entry.key, &entry.value)
+	}
+}
+
+// Forget discards the value (if any) remembered for key
+func (cache *CacheStruct[K, V]) Forget(key K) {
+	var (
+		element *list.Element
+		ok      bool
+	)
+
+	cache.Lock()
+	element, ok = cache.entries[key]
+	if ok {
+		delete(cache.entries, key)
+		cache.lru.Remove(element)
+	}
+	cache.Unlock()
+}
+
+// Len returns the number of keys remembered
+func (cache *CacheStruct[K, V]) Len() (count int) {
+	cache.Lock()
+	count = cache.lru.Len()
+	cache.Unlock()
+	return
+}
+
+// SearchNotBefore returns the time before which no search may begin
+func (cache *CacheStruct[K, V]) SearchNotBefore() (notBefore time.Time) {
+	cache.searching.Lock()
+	notBefore = cache.searchNotBefore
+	cache.searching.Unlock()
+	return
+}
+
+// Search invokes walk (which reports whether the object identified by key was found) should a search for key be
+// permitted (see package doc)
+//
+// Returns:
+//
+//	status is nfsd.OK if walk found the object, nfsd.NFS3ErrSTALE if it did not (or did recently), or
+//	       nfsd.NFS3ErrJUKEBOX if a search may not yet begin
+func (cache *CacheStruct[K, V]) Search(key K, walk func() (found bool)) (status uint32) {
+	var (
+		found     bool
+		missed    bool
+		notBefore time.Time
+		start     time.Time
+	)
+
+	cache.Lock()
+	notBefore, missed = cache.misses[key]
+	cache.Unlock()
+
+	if missed && time.Now().Before(notBefore) {
+		status = nfsd.NFS3ErrSTALE
+		return
+	}
+
+	if !cache.searching.TryLock() {
+		status = nfsd.NFS3ErrJUKEBOX
+		return
+	}
+	defer cache.searching.Unlock()
+
+	start = time.Now()
+	if start.Before(cache.searchNotBefore) {
+		status = nfsd.NFS3ErrJUKEBOX
+		return
+	}
+
+	found = walk()
+
+	cache.searchNotBefore = time.Now().Add(time.Since(start))
+
+	if found {
+		cache.Lock()
+		delete(cache.misses, key)
+		cache.Unlock()
+		status = nfsd.OK
+		return
+	}
+
+	cache.missed(key)
+
+	status = nfsd.NFS3ErrSTALE
+
+	return
+}
+
+// missed records that a search did not find key
+func (cache *CacheStruct[K, V]) missed(key K) {
+	var (
+		missKey   K
+		notBefore time.Time
+		now       = time.Now()
+	)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	if len(cache.misses) >= maxMisses {
+		for missKey, notBefore = range cache.misses {
+			if !now.Before(notBefore) {
+				delete(cache.misses, missKey)
+			}
+		}
+		for missKey = range cache.misses {
+			if len(cache.misses) < maxMisses {
+				break
+			}
+			delete(cache.misses, missKey)
+		}
+	}
+
+	cache.misses[key] = now.Add(missTTL)
+}
@@ -0,0 +1,128 @@
+package handlecache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+func TestCacheEntries(t *testing.T) {
+	cache := New[int, string](2)
+
+	cache.Remember(1, func(value *string) { *value = "one" })
+	cache.Remember(2, func(value *string) { *value = "two" })
+
+	if value, ok := cache.Recall(1); !ok || ("one" != value) {
+		t.Fatalf("Recall(1) returned %q, %v", value, ok)
+	}
+
+	// As 1 was recalled, 2 is the least recently used (and so forgotten) once 3 is remembered
+
+	cache.Remember(3, func(value *string) { *value = "three" })
+
+	if 2 != cache.Len() {
+		t.Fatalf("Len() returned %d... expected 2", cache.Len())
+	}
+	if _, ok := cache.Recall(2); ok {
+		t.Fatalf("Recall(2) should have failed as 2 was least recently used")
+	}
+
+	// Remember is supplied the value already remembered
+
+	cache.Remember(3, func(value *string) {
+		if "three" != *value {
+			t.Fatalf("Remember(3) supplied %q... expected \"three\"", *value)
+		}
+		*value = "THREE"
+	})
+
+	if !cache.Update(1, func(value *string) { *value = "ONE" }) {
+		t.Fatalf("Update(1) should have succeeded")
+	}
+	if cache.Update(2, func(value *string) { t.Fatalf("Update(2) should not have invoked update") }) {
+		t.Fatalf("Update(2) should have failed")
+	}
+
+	visited := make(map[int]string)
+	cache.Range(func(key int, value *string) { visited[key] = *value })
+	if !reflect.DeepEqual(visited, map[int]string{1: "ONE", 3: "THREE"}) {
+		t.Fatalf("Range() visited %v", visited)
+	}
+
+	cache.Forget(1)
+
+	if _, ok := cache.Recall(1); ok || (1 != cache.Len()) {
+		t.Fatalf("Recall(1) following Forget(1) should have failed")
+	}
+
+	if DefaultMaxEntries != New[int, string](0).maxEntries {
+		t.Fatalf("New(0) should have remembered up to DefaultMaxEntries")
+	}
+}
+
+func TestCacheSearch(t *testing.T) {
+	cache := New[int, string](0)
+
+	walks := 0
+	walk := func(found bool) func() bool {
+		return func() bool {
+			walks++
+			return found
+		}
+	}
+
+	if nfsd.OK != cache.Search(1, walk(true)) || (1 != walks) {
+		t.Fatalf("Search(1) of an object found should have returned nfsd.OK")
+	}
+
+	// A key not found is answered without searching again
+
+	cache.searchNotBefore = time.Time{}
+	if nfsd.NFS3ErrSTALE != cache.Search(2, walk(false)) || (2 != walks) {
+		t.Fatalf("Search(2) of an object not found should have returned nfsd.NFS3ErrSTALE")
+	}
+	if _, ok := cache.misses[2]; !ok {
+		t.Fatalf("Search(2) not recorded as missed")
+	}
+	cache.searchNotBefore = time.Time{}
+	if nfsd.NFS3ErrSTALE != cache.Search(2, walk(true)) || (2 != walks) {
+		t.Fatalf("Search(2) recently missed should have returned nfsd.NFS3ErrSTALE without searching")
+	}
+
+	// Whereas another key is deferred until a search may begin
+
+	cache.searchNotBefore = time.Now().Add(time.Hour)
+	if nfsd.NFS3ErrJUKEBOX != cache.Search(3, walk(true)) || (2 != walks) {
+		t.Fatalf("Search(3) too soon after the last should have returned nfsd.NFS3ErrJUKEBOX")
+	}
+	if cache.SearchNotBefore() != cache.searchNotBefore {
+		t.Fatalf("SearchNotBefore() returned %v... expected %v", cache.SearchNotBefore(), cache.searchNotBefore)
+	}
+	cache.searchNotBefore = time.Time{}
+	cache.searching.Lock()
+	if nfsd.NFS3ErrJUKEBOX != cache.Search(3, walk(true)) || (2 != walks) {
+		t.Fatalf("Search(3) during another search should have returned nfsd.NFS3ErrJUKEBOX")
+	}
+	cache.searching.Unlock()
+
+	// A key found is no longer remembered as missed
+
+	cache.misses[2] = time.Now()
+	if nfsd.OK != cache.Search(2, walk(true)) || (3 != walks) {
+		t.Fatalf("Search(2) once no longer missed should have returned nfsd.OK")
+	}
+	if _, ok := cache.misses[2]; ok {
+		t.Fatalf("Search(2) found should no longer be recorded as missed")
+	}
+
+	// The keys remembered as missed are bounded
+
+	for key := 0; key < 2*maxMisses; key++ {
+		cache.missed(key)
+	}
+	if maxMisses < len(cache.misses) {
+		t.Fatalf("%d keys remembered as missed exceeding maxMisses", len(cache.misses))
+	}
+}
@@ -0,0 +1,286 @@
+// Package iofs serves any io/fs.FS (e.g. an embed.FS, a *zip.Reader, or os.DirFS) read-only via package nfsd,
+// such that assets compiled into (or archived alongside) a program may be mounted by NFS clients as is. Procedures
+// that would modify the file system are answered with NFS3ErrROFS.
+//
+// File handles (and FileIDs) are derived from the path of each object such that they persist across restarts of
+// the server (though resolving a handle not seen recently requires a search of the file system, of which one at a
+// time proceeds). Symbolic links are only reported as such should the file system also provide ReadLink & Lstat
+// (as does os.DirFS as of Go 1.25); otherwise they are followed. READ makes use of io.ReaderAt, io.Seeker, or
+// fs.ReadFileFS where available, falling back to reading (and discarding) each file up to the requested offset.
+package iofs
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/swiftstack/nfsd"
+	"github.com/swiftstack/nfsd/internal/handlecache"
+)
+
+const (
+	fHandleVersion = byte(1)
+	fHandleHashLen = 16
+	fHandleSize    = 1 + fHandleHashLen // version & (truncated) SHA-256 of the path
+)
+
+type ConfigStruct struct { // the configuration of an IOFSStruct (see New)
+	FS       fs.FS           // the file system served
+	UID      uint32          // owner reported for every object
+	GID      uint32          // group reported for every object
+	FSID     uint64          // reported in the FSID of every FAttr3Struct
+	ErrorLog func(err error) // if nil, errors are reported via the log package
+}
+
+// IOFSStruct serves the file system specified by ConfigStruct.FS (see New)
+type IOFSStruct struct {
+	fsys     fs.FS                                                  //
+	uid      uint32                                                 //
+	gid      uint32                                                 //
+	fsid     uint64                                                 //
+	errorLog func(err error)                                        //
+	paths    *handlecache.CacheStruct[[fHandleHashLen]byte, string] // the path of each object for which a handle was returned
+}
+
+// readLinkFS is implemented by file systems able to report symbolic links (matching fs.ReadLinkFS of Go 1.25)
+type readLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// New constructs an IOFSStruct serving config.FS
+//
+// Arguments:
+//
+//	config specifies the file system to serve (config is copied)
+//
+// Returns:
+//
+//	ioFS is the IOFSStruct (to be supplied as both the MountV3Interface & NFSv3Interface callbacks)
+//	err  is non-nil on failure (e.g. the root of config.FS is not a directory)
+func New(config *ConfigStruct) (ioFS *IOFSStruct, err error) {
+	var (
+		info fs.FileInfo
+	)
+
+	if nil == config.FS {
+		err = fmt.Errorf("iofs: no FS specified")
+		return
+	}
+
+	info, err = fs.Stat(config.FS, ".")
+	if nil != err {
+		return
+	}
+	if !info.IsDir() {
+		err = fmt.Errorf("iofs: root of FS is not a directory")
+		return
+	}
+
+	ioFS = &IOFSStruct{
+		fsys:     config.FS,
+		uid:      config.UID,
+		gid:      config.GID,
+		fsid:     config.FSID,
+		errorLog: config.ErrorLog,
+		paths:    handlecache.New[[fHandleHashLen]byte, string](0),
+	}
+
+	if nil == ioFS.errorLog {
+		ioFS.errorLog = func(err error) { log.Printf("iofs: %v", err) }
+	}
+
+	return
+}
+
+// RootFHandle returns the file handle of the root directory (as returned by MNT of "/")
+func (ioFS *IOFSStruct) RootFHandle() (fHandle []byte) {
+	fHandle = ioFS.fHandleOf(".")
+	return
+}
+
+// rootHash is the hash of the root directory (whose path is always known)
+var rootHash = hashOf(".")
+
+func hashOf(name string) (hash [fHandleHashLen]byte) {
+	var (
+		sum = sha256.Sum256([]byte(name))
+	)
+
+	copy(hash[:], sum[:])
+
+	return
+}
+
+// fileIDOf returns the FileID of the object at name (never 0)
+func fileIDOf(name string) (fileID uint64) {
+	var (
+		hash = hashOf(name)
+	)
+
+	fileID = binary.BigEndian.Uint64(hash[:8])
+	if 0 == fileID {
+		fileID = 1
+	}
+
+	return
+}
+
+// fHandleOf returns the file handle of the object at name, recording name such that the handle resolves to it
+func (ioFS *IOFSStruct) fHandleOf(name string) (fHandle []byte) {
+	var (
+		hash = hashOf(name)
+	)
+
+	fHandle = make([]byte, fHandleSize)
+	fHandle[0] = fHandleVersion
+	copy(fHandle[1:], hash[:])
+
+	ioFS.remember(hash, name)
+
+	return
+}
+
+// remember records name as the path of the object whose hash is hash
+func (ioFS *IOFSStruct) remember(hash [fHandleHashLen]byte, name string) {
+	ioFS.paths.Remember(hash, func(rememberedName *string) { *rememberedName = name })
+}
+
+// rememberedPath returns the path of the object whose hash is hash (if remembered)
+func (ioFS *IOFSStruct) rememberedPath(hash [fHandleHashLen]byte) (name string, ok bool) {
+	name, ok = ioFS.paths.Recall(hash)
+	if !ok && (rootHash == hash) {
+		name = "."
+		ok = true
+	}
+
+	return
+}
+
+// stat returns information about the object at name (not following a symlink if the file system reports them)
+func (ioFS *IOFSStruct) stat(name string) (info fs.FileInfo, err error) {
+	var (
+		ok     bool
+		linkFS readLinkFS
+	)
+
+	linkFS, ok = ioFS.fsys.(readLinkFS)
+	if ok {
+		info, err = linkFS.Lstat(name)
+	} else {
+		info, err = fs.Stat(ioFS.fsys, name)
+	}
+
+	return
+}
+
+// resolve returns the path of (and current information about) the object identified by fHandle. Should the
+// handle's path not be remembered (e.g. it was not returned since New), the file system is searched for the path
+// from which it derives (see handlecache.CacheStruct.Search).
+func (ioFS *IOFSStruct) resolve(fHandle []byte) (name string, info fs.FileInfo, status uint32) {
+	var (
+		err  error
+		hash [fHandleHashLen]byte
+		ok   bool
+	)
+
+	if (fHandleSize != len(fHandle)) || (fHandleVersion != fHandle[0]) {
+		status = nfsd.NFS3ErrBADHANDLE
+		return
+	}
+
+	copy(hash[:], fHandle[1:])
+
+	name, ok = ioFS.rememberedPath(hash)
+	if !ok {
+		status = ioFS.paths.Search(hash, func() (found bool) {
+			name, found = ioFS.walk(hash)
+			return
+		})
+		if nfsd.OK != status {
+			return
+		}
+		ioFS.remember(hash, name)
+	}
+
+	info, err = ioFS.stat(name)
+	if nil != err {
+		ioFS.paths.Forget(hash)
+		status = nfsd.NFS3ErrSTALE
+		return
+	}
+
+	status = nfsd.OK
+
+	return
+}
+
+// walk walks the file system for the path whose hash is hash
+func (ioFS *IOFSStruct) walk(hash [fHandleHashLen]byte) (name string, ok bool) {
+	_ = fs.WalkDir(ioFS.fsys, ".", func(walkPath string, dirEntry fs.DirEntry, walkErr error) (err error) {
+		if nil != walkErr {
+			if (nil != dirEntry) && dirEntry.IsDir() {
+				err = fs.SkipDir
+			}
+			return
+		}
+
+		if hash == hashOf(walkPath) {
+			name = walkPath
+			ok = true
+			err = fs.SkipAll
+		}
+
+		return
+	})
+
+	return
+}
+
+// childPath returns the path of name within the directory at dirPath (".." never ascending above the root)
+func childPath(dirPath string, name string) (childPath string, status uint32) {
+	if ("" == name) || strings.ContainsAny(name, "/\x00") {
+		status = nfsd.NFS3ErrINVAL
+		return
+	}
+	if nfsd.DefaultNameMax < uint32(len(name)) {
+		status = nfsd.NFS3ErrNAMETOOLONG
+		return
+	}
+
+	switch name {
+	case ".":
+		childPath = dirPath
+	case "..":
+		childPath = path.Dir(dirPath)
+	default:
+		childPath = path.Join(dirPath, name)
+	}
+
+	status = nfsd.OK
+
+	return
+}
+
+// logStatus maps err to an nfsstat3 (reporting those errors not expected to be the consequence of a request)
+func (ioFS *IOFSStruct) logStatus(err error) (status uint32) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = nfsd.NFS3ErrNOENT
+	case errors.Is(err, fs.ErrPermission):
+		status = nfsd.NFS3ErrACCES
+	case errors.Is(err, fs.ErrInvalid):
+		status = nfsd.NFS3ErrINVAL
+	default:
+		ioFS.errorLog(err)
+		status = nfsd.NFS3ErrIO
+	}
+
+	return
+}
@@ -0,0 +1,290 @@
+package iofs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+	"github.com/swiftstack/nfsd/internal/handlecache"
+)
+
+var testCredential = &nfsd.CredentialStruct{Flavor: nfsd.AuthSys, UID: 1000, GID: 1000, GIDs: []uint32{}}
+
+func testNew(t *testing.T, fsys fs.FS) (ioFS *IOFSStruct) {
+	ioFS, err := New(&ConfigStruct{FS: fsys, UID: 7, GID: 8, ErrorLog: func(err error) { t.Logf("ErrorLog(%v)", err) }})
+	if nil != err {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return
+}
+
+func testLookup(t *testing.T, ioFS *IOFSStruct, dir []byte, name string) (fHandle []byte) {
+	lookupResults := ioFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: dir, Name: name}})
+	if nfsd.OK != lookupResults.Status {
+		t.Fatalf("LOOKUP of %s returned %+v", name, lookupResults)
+	}
+
+	fHandle = lookupResults.Object
+
+	return
+}
+
+func testRead(t *testing.T, ioFS *IOFSStruct, file []byte, offset uint64, count uint32) (data string, eof bool) {
+	readResults := ioFS.NFSProc3Read(testCredential, &nfsd.NFSProc3ReadArgsStruct{File: file, Offset: offset, Count: count})
+	if (nfsd.OK != readResults.Status) || (uint32(len(readResults.Data)) != readResults.Count) {
+		t.Fatalf("READ returned %+v", readResults)
+	}
+
+	data = string(readResults.Data)
+	eof = readResults.EOF
+
+	return
+}
+
+func TestIOFS(t *testing.T) {
+	modTime := time.Unix(1500000000, 0)
+	mapFS := fstest.MapFS{
+		"hello.txt":         {Data: []byte("hello world"), Mode: 0644, ModTime: modTime},
+		"bin/tool":          {Data: []byte("#!"), Mode: 0755, ModTime: modTime},
+		"bin/lib/README.md": {Data: []byte("docs"), Mode: 0444, ModTime: modTime},
+	}
+
+	ioFS := testNew(t, mapFS)
+	root := ioFS.RootFHandle()
+
+	var (
+		_ nfsd.MountV3Interface = ioFS
+		_ nfsd.NFSv3Interface   = ioFS
+	)
+
+	_, err := New(&ConfigStruct{FS: fstest.MapFS{".": {Mode: 0644}}})
+	if nil == err {
+		t.Fatalf("New() of a FS whose root is not a directory should have failed")
+	}
+
+	mntResults := ioFS.MountProc3Mnt(testCredential, &nfsd.MountProc3MntArgsStruct{DirPath: "/"})
+	if (nfsd.OK != mntResults.Status) || !bytes.Equal(root, mntResults.FHandle) {
+		t.Fatalf("MNT of / returned %+v", mntResults)
+	}
+	if nfsd.MNT3ErrNOTDIR != ioFS.MountProc3Mnt(testCredential, &nfsd.MountProc3MntArgsStruct{DirPath: "/hello.txt"}).Status {
+		t.Fatalf("MNT of a file should have failed")
+	}
+
+	hello := testLookup(t, ioFS, root, "hello.txt")
+
+	getAttrResults := ioFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: hello})
+	if (nfsd.OK != getAttrResults.Status) || (nfsd.FTypeREG != getAttrResults.Attributes.Type) || (0644 != getAttrResults.Attributes.Mode) ||
+		(11 != getAttrResults.Attributes.Size) || (7 != getAttrResults.Attributes.UID) || (8 != getAttrResults.Attributes.GID) ||
+		(uint32(modTime.Unix()) != getAttrResults.Attributes.MTime.Seconds) || (fileIDOf("hello.txt") != getAttrResults.Attributes.FileID) {
+		t.Fatalf("GETATTR of hello.txt returned %+v", getAttrResults)
+	}
+
+	data, eof := testRead(t, ioFS, hello, 6, 100)
+	if ("world" != data) || !eof {
+		t.Fatalf("READ at offset 6 returned %q (eof: %v)", data, eof)
+	}
+	data, eof = testRead(t, ioFS, hello, 0, 5)
+	if ("hello" != data) || eof {
+		t.Fatalf("READ at offset 0 returned %q (eof: %v)", data, eof)
+	}
+
+	accessResults := ioFS.NFSProc3Access(testCredential, &nfsd.NFSProc3AccessArgsStruct{Object: hello, Access: 0x3F})
+	if (nfsd.OK != accessResults.Status) || (nfsd.Access3Read != accessResults.Access) {
+		t.Fatalf("ACCESS of hello.txt returned %+v", accessResults)
+	}
+
+	bin := testLookup(t, ioFS, root, "bin")
+	lib := testLookup(t, ioFS, bin, "lib")
+	if !bytes.Equal(root, testLookup(t, ioFS, bin, "..")) || !bytes.Equal(root, testLookup(t, ioFS, root, "..")) {
+		t.Fatalf("LOOKUP of .. should have returned the parent directory (never ascending above the root)")
+	}
+	if nfsd.NFS3ErrNOENT != ioFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: bin, Name: "missing"}}).Status {
+		t.Fatalf("LOOKUP of a missing name should have returned NFS3ErrNOENT")
+	}
+
+	readDirResults := ioFS.NFSProc3ReadDir(testCredential, &nfsd.NFSProc3ReadDirArgsStruct{Dir: bin, Count: 4096})
+	names := make(map[string]bool)
+	for _, entry := range readDirResults.Entries {
+		names[entry.Name] = true
+	}
+	if (nfsd.OK != readDirResults.Status) || !readDirResults.EOF || (4 != len(names)) || !names["tool"] || !names["lib"] {
+		t.Fatalf("READDIR of bin returned %+v", readDirResults)
+	}
+
+	readDirPlusResults := ioFS.NFSProc3ReadDirPlus(testCredential, &nfsd.NFSProc3ReadDirPlusArgsStruct{Dir: lib, DirCount: 4096, MaxCount: 8192})
+	if (nfsd.OK != readDirPlusResults.Status) || (3 != len(readDirPlusResults.Entries)) {
+		t.Fatalf("READDIRPLUS of bin/lib returned %+v", readDirPlusResults)
+	}
+	for _, entry := range readDirPlusResults.Entries {
+		if "README.md" == entry.Name {
+			if 4 != entry.NameAttributes.Attributes.Size {
+				t.Fatalf("READDIRPLUS returned %+v for README.md", entry)
+			}
+			if data, _ = testRead(t, ioFS, entry.NameHandle.Handle, 0, 100); "docs" != data {
+				t.Fatalf("READ via a handle returned by READDIRPLUS returned %q", data)
+			}
+		}
+	}
+
+	if nfsd.OK != ioFS.NFSProc3FSStat(testCredential, &nfsd.NFSProc3FSStatArgsStruct{FSRoot: root}).Status {
+		t.Fatalf("FSSTAT failed")
+	}
+	if nfsd.OK != ioFS.NFSProc3FSInfo(testCredential, &nfsd.NFSProc3FSInfoArgsStruct{FSRoot: root}).Status {
+		t.Fatalf("FSINFO failed")
+	}
+	if nfsd.OK != ioFS.NFSProc3PathConf(testCredential, &nfsd.NFSProc3PathConfArgsStruct{Object: root}).Status {
+		t.Fatalf("PATHCONF failed")
+	}
+
+	for proc, status := range map[string]uint32{
+		"SETATTR": ioFS.NFSProc3SetAttr(testCredential, &nfsd.NFSProc3SetAttrArgsStruct{Object: hello}).Status,
+		"WRITE":   ioFS.NFSProc3Write(testCredential, &nfsd.NFSProc3WriteArgsStruct{File: hello, Count: 1, Data: []byte{0}}).Status,
+		"CREATE":  ioFS.NFSProc3Create(testCredential, &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "new"}}).Status,
+		"MKDIR":   ioFS.NFSProc3MKDir(testCredential, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "new"}}).Status,
+		"SYMLINK": ioFS.NFSProc3SymLink(testCredential, &nfsd.NFSProc3SymLinkArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "new"}}).Status,
+		"REMOVE":  ioFS.NFSProc3Remove(testCredential, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "hello.txt"}}).Status,
+		"RMDIR":   ioFS.NFSProc3RMDir(testCredential, &nfsd.NFSProc3RMDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "bin"}}).Status,
+		"RENAME":  ioFS.NFSProc3Rename(testCredential, &nfsd.NFSProc3RenameArgsStruct{From: nfsd.DirOpArgs3Struct{Dir: root, Name: "hello.txt"}, To: nfsd.DirOpArgs3Struct{Dir: root, Name: "new"}}).Status,
+		"LINK":    ioFS.NFSProc3Link(testCredential, &nfsd.NFSProc3LinkArgsStruct{File: hello, Link: nfsd.DirOpArgs3Struct{Dir: root, Name: "new"}}).Status,
+		"COMMIT":  ioFS.NFSProc3Commit(testCredential, &nfsd.NFSProc3CommitArgsStruct{File: hello}).Status,
+	} {
+		if nfsd.NFS3ErrROFS != status {
+			t.Fatalf("%s returned %v... expected NFS3ErrROFS", proc, status)
+		}
+	}
+
+	// A handle returned before a restart is found by searching the file system
+	restarted := testNew(t, mapFS)
+	if data, _ = testRead(t, restarted, testLookup(t, ioFS, lib, "README.md"), 0, 100); "docs" != data {
+		t.Fatalf("READ via a handle returned prior to a restart returned %q", data)
+	}
+
+	delete(mapFS, "hello.txt")
+	if nfsd.NFS3ErrSTALE != ioFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: hello}).Status {
+		t.Fatalf("GETATTR of a removed file should have returned NFS3ErrSTALE")
+	}
+	if nfsd.NFS3ErrBADHANDLE != ioFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: []byte{1, 2}}).Status {
+		t.Fatalf("GETATTR of a malformed handle should have returned NFS3ErrBADHANDLE")
+	}
+}
+
+func TestIOFSSearchLimits(t *testing.T) {
+	mapFS := fstest.MapFS{
+		"a": {Data: []byte("a"), Mode: 0644},
+		"b": {Data: []byte("b"), Mode: 0644},
+		"c": {Data: []byte("c"), Mode: 0644},
+		"d": {Data: []byte("d"), Mode: 0644},
+	}
+
+	ioFS := testNew(t, mapFS)
+	root := ioFS.RootFHandle()
+
+	forged := append([]byte{}, root...)
+	forged[1] ^= 0xFF
+
+	// A forged handle is answered with NFS3ErrSTALE (the second time without searching... see package handlecache)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if nfsd.NFS3ErrSTALE != ioFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: forged}).Status {
+			t.Fatalf("GETATTR of a forged handle should have returned NFS3ErrSTALE")
+		}
+	}
+
+	// The paths remembered are bounded yet handles of those forgotten (or of the root) still resolve
+
+	ioFS.paths = handlecache.New[[fHandleHashLen]byte, string](2)
+	files := make([][]byte, 0, len(mapFS))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		files = append(files, testLookup(t, ioFS, root, name))
+	}
+	if 2 != ioFS.paths.Len() {
+		t.Fatalf("%d paths remembered exceeding the limit", ioFS.paths.Len())
+	}
+	for _, file := range append(files, root) {
+		time.Sleep(time.Until(ioFS.paths.SearchNotBefore()))
+		if nfsd.OK != ioFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+			t.Fatalf("GETATTR of a handle whose path was forgotten failed")
+		}
+	}
+	if 2 != ioFS.paths.Len() {
+		t.Fatalf("%d paths remembered exceeding the limit", ioFS.paths.Len())
+	}
+}
+
+func TestIOFSZip(t *testing.T) {
+	var buf bytes.Buffer
+
+	contents := bytes.Repeat([]byte("0123456789"), 1000)
+
+	zipWriter := zip.NewWriter(&buf)
+	fileWriter, err := zipWriter.Create("dir/digits")
+	if nil != err {
+		t.Fatalf("zipWriter.Create() failed: %v", err)
+	}
+	_, _ = fileWriter.Write(contents)
+	err = zipWriter.Close()
+	if nil != err {
+		t.Fatalf("zipWriter.Close() failed: %v", err)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if nil != err {
+		t.Fatalf("zip.NewReader() failed: %v", err)
+	}
+
+	ioFS := testNew(t, zipReader)
+
+	digits := testLookup(t, ioFS, testLookup(t, ioFS, ioFS.RootFHandle(), "dir"), "digits")
+
+	for _, offset := range []uint64{0, 4321, 9995} {
+		data, eof := testRead(t, ioFS, digits, offset, 10)
+		expected := contents[offset:]
+		if 10 < len(expected) {
+			expected = expected[:10]
+		}
+		if (string(expected) != data) || (eof != (9995 == offset)) {
+			t.Fatalf("READ at offset %d returned %q (eof: %v)", offset, data, eof)
+		}
+	}
+}
+
+func TestIOFSSymLink(t *testing.T) {
+	rootPath := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(rootPath, "target"), []byte("data"), 0644)
+	if nil != err {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	err = os.Symlink("target", filepath.Join(rootPath, "symlink"))
+	if nil != err {
+		t.Fatalf("os.Symlink() failed: %v", err)
+	}
+
+	dirFS := os.DirFS(rootPath)
+	if _, ok := dirFS.(readLinkFS); !ok {
+		t.Skip("os.DirFS does not provide ReadLink & Lstat")
+	}
+
+	ioFS := testNew(t, dirFS)
+	root := ioFS.RootFHandle()
+
+	lookupResults := ioFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: "symlink"}})
+	if (nfsd.OK != lookupResults.Status) || (nfsd.FTypeLNK != lookupResults.ObjAttributes.Attributes.Type) {
+		t.Fatalf("LOOKUP of symlink returned %+v", lookupResults)
+	}
+
+	readLinkResults := ioFS.NFSProc3ReadLink(testCredential, &nfsd.NFSProc3ReadLinkArgsStruct{SymLink: lookupResults.Object})
+	if (nfsd.OK != readLinkResults.Status) || ("target" != string(readLinkResults.Path)) {
+		t.Fatalf("READLINK returned %+v", readLinkResults)
+	}
+
+	if nfsd.NFS3ErrINVAL != ioFS.NFSProc3ReadLink(testCredential, &nfsd.NFSProc3ReadLinkArgsStruct{SymLink: testLookup(t, ioFS, root, "target")}).Status {
+		t.Fatalf("READLINK of a regular file should have returned NFS3ErrINVAL")
+	}
+}
@@ -0,0 +1,59 @@
+package iofs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/swiftstack/nfsd"
+)
+
+func (ioFS *IOFSStruct) MountProc3Null(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Mnt returns the file handle of the directory at DirPath (interpreted relative to the root of the FS)
+func (ioFS *IOFSStruct) MountProc3Mnt(credential *nfsd.CredentialStruct, mountProc3MntArgs *nfsd.MountProc3MntArgsStruct) (mountProc3MntResults *nfsd.MountProc3MntResultsStruct) {
+	var (
+		err  error
+		info fs.FileInfo
+		name = strings.TrimPrefix(path.Clean("/"+mountProc3MntArgs.DirPath), "/")
+	)
+
+	mountProc3MntResults = &nfsd.MountProc3MntResultsStruct{}
+
+	if "" == name {
+		name = "."
+	}
+
+	info, err = ioFS.stat(name)
+	if nil != err {
+		mountProc3MntResults.Status = nfsd.MNT3ErrNOENT
+		return
+	}
+	if !info.IsDir() {
+		mountProc3MntResults.Status = nfsd.MNT3ErrNOTDIR
+		return
+	}
+
+	mountProc3MntResults.Status = nfsd.OK
+	mountProc3MntResults.FHandle = ioFS.fHandleOf(name)
+	mountProc3MntResults.AuthFlavors = []uint32{nfsd.AuthSys, nfsd.AuthNone}
+
+	return
+}
+
+// MountProc3Dump returns an empty list as mounts are not tracked by IOFSStruct (see nfsd.EnableMountTable)
+func (ioFS *IOFSStruct) MountProc3Dump(credential *nfsd.CredentialStruct) (mountProc3DumpResults *nfsd.MountProc3DumpResultsStruct) {
+	mountProc3DumpResults = &nfsd.MountProc3DumpResultsStruct{MountList: []nfsd.MountBodyStruct{}}
+	return
+}
+
+func (ioFS *IOFSStruct) MountProc3Umnt(credential *nfsd.CredentialStruct, mountProc3UmntArgs *nfsd.MountProc3UmntArgsStruct) {
+}
+
+func (ioFS *IOFSStruct) MountProc3UmntAll(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Export returns "/" (i.e. the root of the FS) as exported to all clients
+func (ioFS *IOFSStruct) MountProc3Export(credential *nfsd.CredentialStruct) (mountProc3ExportResults *nfsd.MountProc3ExportResultsStruct) {
+	mountProc3ExportResults = &nfsd.MountProc3ExportResultsStruct{Exports: []nfsd.ExportNodeStruct{{Dir: "/", Groups: []string{}}}}
+	return
+}
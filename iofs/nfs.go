@@ -0,0 +1,554 @@
+package iofs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/swiftstack/nfsd"
+)
+
+// objectStruct describes the object identified by a file handle
+type objectStruct struct {
+	name string
+	info fs.FileInfo
+}
+
+func (ioFS *IOFSStruct) object(fHandle []byte) (object *objectStruct, status uint32) {
+	object = &objectStruct{}
+	object.name, object.info, status = ioFS.resolve(fHandle)
+	if nfsd.OK != status {
+		object = nil
+	}
+	return
+}
+
+func nfsTime(info fs.FileInfo) (nfsTime3 nfsd.NFSTime3Struct) {
+	var (
+		modTime = info.ModTime()
+	)
+
+	if !modTime.IsZero() {
+		nfsTime3 = nfsd.NFSTime(modTime)
+	}
+
+	return
+}
+
+func (ioFS *IOFSStruct) fAttr(name string, info fs.FileInfo) (fAttr3 nfsd.FAttr3Struct) {
+	var (
+		mode = info.Mode()
+	)
+
+	switch {
+	case mode.IsDir():
+		fAttr3.Type = nfsd.FTypeDIR
+		fAttr3.NLink = 2
+	case 0 != (mode & fs.ModeSymlink):
+		fAttr3.Type = nfsd.FTypeLNK
+		fAttr3.NLink = 1
+	default:
+		fAttr3.Type = nfsd.FTypeREG
+		fAttr3.NLink = 1
+	}
+
+	fAttr3.Mode = uint32(mode.Perm())
+	if 0 != (mode & fs.ModeSetuid) {
+		fAttr3.Mode |= 04000
+	}
+	if 0 != (mode & fs.ModeSetgid) {
+		fAttr3.Mode |= 02000
+	}
+	if 0 != (mode & fs.ModeSticky) {
+		fAttr3.Mode |= 01000
+	}
+
+	fAttr3.UID = ioFS.uid
+	fAttr3.GID = ioFS.gid
+	if 0 < info.Size() {
+		fAttr3.Size = uint64(info.Size())
+		fAttr3.Used = fAttr3.Size
+	}
+	fAttr3.FSID = ioFS.fsid
+	fAttr3.FileID = fileIDOf(name)
+	fAttr3.ATime = nfsTime(info)
+	fAttr3.MTime = fAttr3.ATime
+	fAttr3.CTime = fAttr3.ATime
+
+	return
+}
+
+func (ioFS *IOFSStruct) ErrorLog(err error) {
+	ioFS.errorLog(err)
+}
+
+func (ioFS *IOFSStruct) NFSProc3Null(credential *nfsd.CredentialStruct) {}
+
+func (ioFS *IOFSStruct) NFSProc3GetAttr(credential *nfsd.CredentialStruct, nfsProc3GetAttrArgs *nfsd.NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *nfsd.NFSProc3GetAttrResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3GetAttrResults = &nfsd.NFSProc3GetAttrResultsStruct{}
+
+	object, nfsProc3GetAttrResults.Status = ioFS.object(nfsProc3GetAttrArgs.Object)
+	if nfsd.OK == nfsProc3GetAttrResults.Status {
+		nfsProc3GetAttrResults.Attributes = ioFS.fAttr(object.name, object.info)
+	}
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3SetAttr(credential *nfsd.CredentialStruct, nfsProc3SetAttrArgs *nfsd.NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *nfsd.NFSProc3SetAttrResultsStruct) {
+	nfsProc3SetAttrResults = &nfsd.NFSProc3SetAttrResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Lookup(credential *nfsd.CredentialStruct, nfsProc3LookupArgs *nfsd.NFSProc3LookupArgsStruct) (nfsProc3LookupResults *nfsd.NFSProc3LookupResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info fs.FileInfo
+		name string
+	)
+
+	nfsProc3LookupResults = &nfsd.NFSProc3LookupResultsStruct{}
+
+	dir, nfsProc3LookupResults.Status = ioFS.object(nfsProc3LookupArgs.What.Dir)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.DirAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(dir.name, dir.info)}
+
+	if !dir.info.IsDir() {
+		nfsProc3LookupResults.Status = nfsd.NFS3ErrNOTDIR
+		return
+	}
+
+	name, nfsProc3LookupResults.Status = childPath(dir.name, nfsProc3LookupArgs.What.Name)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	info, err = ioFS.stat(name)
+	if nil != err {
+		nfsProc3LookupResults.Status = ioFS.logStatus(err)
+		return
+	}
+
+	nfsProc3LookupResults.Object = ioFS.fHandleOf(name)
+	nfsProc3LookupResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(name, info)}
+
+	return
+}
+
+// NFSProc3Access grants reading (and, as applicable, lookup or execution) but never modification
+func (ioFS *IOFSStruct) NFSProc3Access(credential *nfsd.CredentialStruct, nfsProc3AccessArgs *nfsd.NFSProc3AccessArgsStruct) (nfsProc3AccessResults *nfsd.NFSProc3AccessResultsStruct) {
+	var (
+		granted = nfsd.Access3Read
+		object  *objectStruct
+	)
+
+	nfsProc3AccessResults = &nfsd.NFSProc3AccessResultsStruct{}
+
+	object, nfsProc3AccessResults.Status = ioFS.object(nfsProc3AccessArgs.Object)
+	if nfsd.OK != nfsProc3AccessResults.Status {
+		return
+	}
+
+	nfsProc3AccessResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+
+	if object.info.IsDir() {
+		granted |= nfsd.Access3Lookup
+	} else if 0 != (object.info.Mode().Perm() & 0111) {
+		granted |= nfsd.Access3Execute
+	}
+
+	nfsProc3AccessResults.Access = nfsProc3AccessArgs.Access & granted
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3ReadLink(credential *nfsd.CredentialStruct, nfsProc3ReadLinkArgs *nfsd.NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *nfsd.NFSProc3ReadLinkResultsStruct) {
+	var (
+		err    error
+		object *objectStruct
+		ok     bool
+		linkFS readLinkFS
+		target string
+	)
+
+	nfsProc3ReadLinkResults = &nfsd.NFSProc3ReadLinkResultsStruct{}
+
+	object, nfsProc3ReadLinkResults.Status = ioFS.object(nfsProc3ReadLinkArgs.SymLink)
+	if nfsd.OK != nfsProc3ReadLinkResults.Status {
+		return
+	}
+
+	nfsProc3ReadLinkResults.SymLinkAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+
+	linkFS, ok = ioFS.fsys.(readLinkFS)
+	if !ok || (0 == (object.info.Mode() & fs.ModeSymlink)) {
+		nfsProc3ReadLinkResults.Status = nfsd.NFS3ErrINVAL
+		return
+	}
+
+	target, err = linkFS.ReadLink(object.name)
+	if nil != err {
+		nfsProc3ReadLinkResults.Status = ioFS.logStatus(err)
+		return
+	}
+
+	nfsProc3ReadLinkResults.Path = []byte(target)
+
+	return
+}
+
+// readAt reads up to len(buf) bytes at offset of the file at name using the most efficient means available
+func (ioFS *IOFSStruct) readAt(name string, buf []byte, offset int64) (n int, err error) {
+	var (
+		contents []byte
+		file     fs.File
+		ok       bool
+		readerAt io.ReaderAt
+		seeker   io.Seeker
+	)
+
+	file, err = ioFS.fsys.Open(name)
+	if nil != err {
+		return
+	}
+	defer file.Close()
+
+	readerAt, ok = file.(io.ReaderAt)
+	if ok {
+		n, err = readerAt.ReadAt(buf, offset)
+		return
+	}
+
+	seeker, ok = file.(io.Seeker)
+	if ok {
+		_, err = seeker.Seek(offset, io.SeekStart)
+		if nil == err {
+			n, err = io.ReadFull(file, buf)
+		}
+		return
+	}
+
+	_, ok = ioFS.fsys.(fs.ReadFileFS)
+	if ok {
+		contents, err = fs.ReadFile(ioFS.fsys, name)
+		if nil == err {
+			if offset < int64(len(contents)) {
+				n = copy(buf, contents[offset:])
+			}
+			if n < len(buf) {
+				err = io.EOF
+			}
+		}
+		return
+	}
+
+	_, err = io.CopyN(io.Discard, file, offset)
+	if nil == err {
+		n, err = io.ReadFull(file, buf)
+	}
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Read(credential *nfsd.CredentialStruct, nfsProc3ReadArgs *nfsd.NFSProc3ReadArgsStruct) (nfsProc3ReadResults *nfsd.NFSProc3ReadResultsStruct) {
+	var (
+		count  = nfsProc3ReadArgs.Count
+		err    error
+		n      int
+		object *objectStruct
+		size   uint64
+	)
+
+	nfsProc3ReadResults = &nfsd.NFSProc3ReadResultsStruct{}
+
+	object, nfsProc3ReadResults.Status = ioFS.object(nfsProc3ReadArgs.File)
+	if nfsd.OK != nfsProc3ReadResults.Status {
+		return
+	}
+
+	nfsProc3ReadResults.FileAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+
+	if object.info.IsDir() {
+		nfsProc3ReadResults.Status = nfsd.NFS3ErrISDIR
+		return
+	}
+	if !object.info.Mode().IsRegular() {
+		nfsProc3ReadResults.Status = nfsd.NFS3ErrINVAL
+		return
+	}
+
+	size = nfsProc3ReadResults.FileAttributes.Attributes.Size
+
+	if nfsProc3ReadArgs.Offset >= size {
+		nfsProc3ReadResults.Data = []byte{}
+		nfsProc3ReadResults.EOF = true
+		return
+	}
+	if count > nfsd.DefaultTransferMax {
+		count = nfsd.DefaultTransferMax
+	}
+	if uint64(count) > (size - nfsProc3ReadArgs.Offset) {
+		count = uint32(size - nfsProc3ReadArgs.Offset)
+	}
+
+	nfsProc3ReadResults.Data = make([]byte, count)
+
+	n, err = ioFS.readAt(object.name, nfsProc3ReadResults.Data, int64(nfsProc3ReadArgs.Offset))
+	if (nil != err) && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		nfsProc3ReadResults.Data = nil
+		nfsProc3ReadResults.Status = ioFS.logStatus(err)
+		return
+	}
+
+	nfsProc3ReadResults.Data = nfsProc3ReadResults.Data[:n]
+	nfsProc3ReadResults.Count = uint32(n)
+	nfsProc3ReadResults.EOF = (nil != err) || ((nfsProc3ReadArgs.Offset + uint64(n)) >= size)
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Write(credential *nfsd.CredentialStruct, nfsProc3WriteArgs *nfsd.NFSProc3WriteArgsStruct) (nfsProc3WriteResults *nfsd.NFSProc3WriteResultsStruct) {
+	nfsProc3WriteResults = &nfsd.NFSProc3WriteResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Create(credential *nfsd.CredentialStruct, nfsProc3CreateArgs *nfsd.NFSProc3CreateArgsStruct) (nfsProc3CreateResults *nfsd.NFSProc3CreateResultsStruct) {
+	nfsProc3CreateResults = &nfsd.NFSProc3CreateResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3MKDir(credential *nfsd.CredentialStruct, nfsProc3MKDirArgs *nfsd.NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *nfsd.NFSProc3MKDirResultsStruct) {
+	nfsProc3MKDirResults = &nfsd.NFSProc3MKDirResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3SymLink(credential *nfsd.CredentialStruct, nfsProc3SymLinkArgs *nfsd.NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *nfsd.NFSProc3SymLinkResultsStruct) {
+	nfsProc3SymLinkResults = &nfsd.NFSProc3SymLinkResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Remove(credential *nfsd.CredentialStruct, nfsProc3RemoveArgs *nfsd.NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *nfsd.NFSProc3RemoveResultsStruct) {
+	nfsProc3RemoveResults = &nfsd.NFSProc3RemoveResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3RMDir(credential *nfsd.CredentialStruct, nfsProc3RMDirArgs *nfsd.NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *nfsd.NFSProc3RMDirResultsStruct) {
+	nfsProc3RMDirResults = &nfsd.NFSProc3RMDirResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Rename(credential *nfsd.CredentialStruct, nfsProc3RenameArgs *nfsd.NFSProc3RenameArgsStruct) (nfsProc3RenameResults *nfsd.NFSProc3RenameResultsStruct) {
+	nfsProc3RenameResults = &nfsd.NFSProc3RenameResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Link(credential *nfsd.CredentialStruct, nfsProc3LinkArgs *nfsd.NFSProc3LinkArgsStruct) (nfsProc3LinkResults *nfsd.NFSProc3LinkResultsStruct) {
+	nfsProc3LinkResults = &nfsd.NFSProc3LinkResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
+
+// dirEntries returns the entries of the directory at dirPath (including "." & "..") following cookie in cookie
+// order (see nfsd.NameCookieDirEntries)
+func (ioFS *IOFSStruct) dirEntries(dirPath string, cookie uint64) (dirEntries []nfsd.DirEntryStruct, status uint32) {
+	var (
+		err        error
+		fsDirEntry fs.DirEntry
+		fsEntries  []fs.DirEntry
+		names      []string
+	)
+
+	fsEntries, err = fs.ReadDir(ioFS.fsys, dirPath)
+	if nil != err {
+		status = ioFS.logStatus(err)
+		return
+	}
+
+	names = make([]string, 0, len(fsEntries))
+	for _, fsDirEntry = range fsEntries {
+		names = append(names, fsDirEntry.Name())
+	}
+
+	dirEntries = nfsd.NameCookieDirEntries(names, cookie)
+
+	status = nfsd.OK
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3ReadDir(credential *nfsd.CredentialStruct, nfsProc3ReadDirArgs *nfsd.NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *nfsd.NFSProc3ReadDirResultsStruct) {
+	var (
+		dir        *objectStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirResults = &nfsd.NFSProc3ReadDirResultsStruct{}
+
+	dir, nfsProc3ReadDirResults.Status = ioFS.object(nfsProc3ReadDirArgs.Dir)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.DirAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(dir.name, dir.info)}
+
+	if !dir.info.IsDir() {
+		nfsProc3ReadDirResults.Status = nfsd.NFS3ErrNOTDIR
+		return
+	}
+
+	dirEntries, nfsProc3ReadDirResults.Status = ioFS.dirEntries(dir.name, nfsProc3ReadDirArgs.Cookie)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.AppendEntries(nfsProc3ReadDirArgs.Count, dirEntries, func(dirEntry nfsd.DirEntryStruct) (fileID uint64, ok bool) {
+		var (
+			entryPath string
+		)
+
+		entryPath, _ = childPath(dir.name, dirEntry.Name)
+		fileID = fileIDOf(entryPath)
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3ReadDirPlus(credential *nfsd.CredentialStruct, nfsProc3ReadDirPlusArgs *nfsd.NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *nfsd.NFSProc3ReadDirPlusResultsStruct) {
+	var (
+		dir        *objectStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirPlusResults = &nfsd.NFSProc3ReadDirPlusResultsStruct{}
+
+	dir, nfsProc3ReadDirPlusResults.Status = ioFS.object(nfsProc3ReadDirPlusArgs.Dir)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.DirAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(dir.name, dir.info)}
+
+	if !dir.info.IsDir() {
+		nfsProc3ReadDirPlusResults.Status = nfsd.NFS3ErrNOTDIR
+		return
+	}
+
+	dirEntries, nfsProc3ReadDirPlusResults.Status = ioFS.dirEntries(dir.name, nfsProc3ReadDirPlusArgs.Cookie)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.AppendEntries(nfsProc3ReadDirPlusArgs.DirCount, nfsProc3ReadDirPlusArgs.MaxCount, dirEntries, func(dirEntry nfsd.DirEntryStruct) (dirListEntryPlus nfsd.DirListEntryPlusStruct, ok bool) {
+		var (
+			entryPath string
+			err       error
+			info      fs.FileInfo
+		)
+
+		entryPath, _ = childPath(dir.name, dirEntry.Name)
+		info, err = ioFS.stat(entryPath)
+		if nil != err {
+			return // removed since the directory was read
+		}
+
+		dirListEntryPlus = nfsd.DirListEntryPlusStruct{
+			FileID:         fileIDOf(entryPath),
+			NameAttributes: nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(entryPath, info)},
+			NameHandle:     nfsd.PostOpFh3Struct{HandleFollows: true, Handle: ioFS.fHandleOf(entryPath)},
+		}
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+// NFSProc3FSStat reports a full file system (as nothing may be written to it)
+func (ioFS *IOFSStruct) NFSProc3FSStat(credential *nfsd.CredentialStruct, nfsProc3FSStatArgs *nfsd.NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *nfsd.NFSProc3FSStatResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3FSStatResults = &nfsd.NFSProc3FSStatResultsStruct{}
+
+	object, nfsProc3FSStatResults.Status = ioFS.object(nfsProc3FSStatArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSStatResults.Status {
+		return
+	}
+
+	nfsProc3FSStatResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3FSInfo(credential *nfsd.CredentialStruct, nfsProc3FSInfoArgs *nfsd.NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *nfsd.NFSProc3FSInfoResultsStruct) {
+	var (
+		object *objectStruct
+		ok     bool
+	)
+
+	nfsProc3FSInfoResults = &nfsd.NFSProc3FSInfoResultsStruct{}
+
+	object, nfsProc3FSInfoResults.Status = ioFS.object(nfsProc3FSInfoArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSInfoResults.Status {
+		return
+	}
+
+	nfsProc3FSInfoResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+	nfsProc3FSInfoResults.RTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.WTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.DTPref = nfsd.DefaultDirPref
+	nfsProc3FSInfoResults.MaxFileSize = uint64(1<<63 - 1)
+	nfsProc3FSInfoResults.TimeDelta = nfsd.NFSTime3Struct{Seconds: 1, NSeconds: 0}
+	nfsProc3FSInfoResults.Properties = nfsd.FSF3Homogeneous
+
+	_, ok = ioFS.fsys.(readLinkFS)
+	if ok {
+		nfsProc3FSInfoResults.Properties |= nfsd.FSF3SymLink
+	}
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3PathConf(credential *nfsd.CredentialStruct, nfsProc3PathConfArgs *nfsd.NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *nfsd.NFSProc3PathConfResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3PathConfResults = &nfsd.NFSProc3PathConfResultsStruct{}
+
+	object, nfsProc3PathConfResults.Status = ioFS.object(nfsProc3PathConfArgs.Object)
+	if nfsd.OK != nfsProc3PathConfResults.Status {
+		return
+	}
+
+	nfsProc3PathConfResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: ioFS.fAttr(object.name, object.info)}
+	nfsProc3PathConfResults.LinkMax = 1
+	nfsProc3PathConfResults.NameMax = nfsd.DefaultNameMax
+	nfsProc3PathConfResults.NoTrunc = true
+	nfsProc3PathConfResults.ChOwnRestricted = true
+	nfsProc3PathConfResults.CaseInsensitive = false
+	nfsProc3PathConfResults.CasePreserving = true
+
+	return
+}
+
+func (ioFS *IOFSStruct) NFSProc3Commit(credential *nfsd.CredentialStruct, nfsProc3CommitArgs *nfsd.NFSProc3CommitArgsStruct) (nfsProc3CommitResults *nfsd.NFSProc3CommitResultsStruct) {
+	nfsProc3CommitResults = &nfsd.NFSProc3CommitResultsStruct{Status: nfsd.NFS3ErrROFS}
+	return
+}
@@ -0,0 +1,103 @@
+package localfs
+
+import (
+	"os"
+
+	"github.com/swiftstack/nfsd"
+)
+
+// Permission bits of each of the owner, group, & other portions of a mode
+const (
+	permRead  = uint32(04)
+	permWrite = uint32(02)
+	permExec  = uint32(01)
+)
+
+const (
+	modeSetGID = uint32(02000)
+	modeSticky = uint32(01000)
+)
+
+// inGroup returns whether gid is either the primary or one of the supplementary groups of credential
+func inGroup(credential *nfsd.CredentialStruct, gid uint32) (member bool) {
+	var (
+		credentialGID uint32
+	)
+
+	if credential.GID == gid {
+		member = true
+		return
+	}
+
+	for _, credentialGID = range credential.GIDs {
+		if credentialGID == gid {
+			member = true
+			return
+		}
+	}
+
+	member = false
+	return
+}
+
+// permitted returns whether credential is granted all of want (a combination of permRead, permWrite, & permExec)
+// to an object. As for the host, root is granted everything but execution of a non-directory lacking any
+// execute permission bit.
+func permitted(credential *nfsd.CredentialStruct, info os.FileInfo, st *statStruct, want uint32) (ok bool) {
+	var (
+		granted uint32
+	)
+
+	if 0 == credential.UID {
+		ok = (0 == (want & permExec)) || info.IsDir() || (0 != (st.mode & 0111))
+		return
+	}
+
+	switch {
+	case credential.UID == st.uid:
+		granted = (st.mode >> 6) & 07
+	case inGroup(credential, st.gid):
+		granted = (st.mode >> 3) & 07
+	default:
+		granted = st.mode & 07
+	}
+
+	ok = (want == (granted & want))
+
+	return
+}
+
+// mayUnlink returns whether credential (already granted write & execute permission to the directory) may remove
+// or rename the object described by st from within it. In a sticky directory, only the owner of either the
+// object or the directory (or root) may do so.
+func mayUnlink(credential *nfsd.CredentialStruct, dirSt *statStruct, st *statStruct) (ok bool) {
+	ok = (0 == (dirSt.mode & modeSticky)) || (0 == credential.UID) || (credential.UID == dirSt.uid) || (credential.UID == st.uid)
+	return
+}
+
+// accessOf returns the subset of access (a combination of Access3* bits) granted to credential for an object
+func accessOf(credential *nfsd.CredentialStruct, info os.FileInfo, st *statStruct, access uint32) (granted uint32) {
+	if permitted(credential, info, st, permRead) {
+		granted |= nfsd.Access3Read
+	}
+
+	if info.IsDir() {
+		if permitted(credential, info, st, permExec) {
+			granted |= nfsd.Access3Lookup
+		}
+		if permitted(credential, info, st, permWrite|permExec) {
+			granted |= nfsd.Access3Modify | nfsd.Access3Extend | nfsd.Access3Delete
+		}
+	} else {
+		if permitted(credential, info, st, permWrite) {
+			granted |= nfsd.Access3Modify | nfsd.Access3Extend
+		}
+		if permitted(credential, info, st, permExec) {
+			granted |= nfsd.Access3Execute
+		}
+	}
+
+	granted &= access
+
+	return
+}
@@ -0,0 +1,432 @@
+package localfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Every object is accessed relative to a descriptor of the exported directory (see LocalFSStruct.rootFD) rather
+// than via a path of the host. Paths (relative to the exported directory) are resolved by openat2() with
+// RESOLVE_BENEATH & RESOLVE_NO_SYMLINKS such that a directory swapped for a symlink (e.g. by a client racing a
+// RENAME & SYMLINK against a CREATE) fails with ELOOP rather than being followed out of the exported directory.
+// Operations upon the last component of a path are performed relative to a descriptor of its parent directory.
+
+const (
+	sysOpenat2 = uintptr(437) // openat2 (the same on every architecture)
+
+	oPath             = 0x200000 // O_PATH
+	atRemoveDir       = 0x200    // AT_REMOVEDIR
+	resolveNoSymlinks = 0x04     // RESOLVE_NO_SYMLINKS
+	resolveBeneath    = 0x08     // RESOLVE_BENEATH
+)
+
+// openHowStruct is struct open_how (see openat2(2))
+type openHowStruct struct {
+	flags   uint64
+	mode    uint64
+	resolve uint64
+}
+
+// openBeneath opens path (relative to the directory open as rootFD) without following any symlink (including
+// the last component of path) or resolving to anything outside of the directory
+func openBeneath(rootFD int, path string, flags int, mode uint32) (fd int, err error) {
+	var (
+		errno   syscall.Errno
+		how     openHowStruct
+		pathPtr *byte
+		r0      uintptr
+	)
+
+	pathPtr, err = syscall.BytePtrFromString(path)
+	if nil != err {
+		return
+	}
+
+	how = openHowStruct{flags: uint64(flags | syscall.O_CLOEXEC | syscall.O_NOFOLLOW), resolve: resolveBeneath | resolveNoSymlinks}
+	if 0 != (flags & syscall.O_CREAT) {
+		how.mode = uint64(mode)
+	}
+
+	for {
+		r0, _, errno = syscall.Syscall6(sysOpenat2, uintptr(rootFD), uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&how)), unsafe.Sizeof(how), 0, 0)
+		if (syscall.EINTR != errno) && (syscall.EAGAIN != errno) { // EAGAIN indicates a rename raced the resolution
+			break
+		}
+	}
+	if 0 != errno {
+		fd = -1
+		err = &os.PathError{Op: "openat2", Path: path, Err: errno}
+		return
+	}
+
+	fd = int(r0)
+
+	return
+}
+
+// openRoot opens the directory at rootPath (via which every object is subsequently accessed) failing should the
+// kernel not support openat2()
+func openRoot(rootPath string) (rootFD int, err error) {
+	var (
+		fd int
+	)
+
+	rootFD, err = syscall.Open(rootPath, oPath|syscall.O_DIRECTORY|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+	if nil != err {
+		err = &os.PathError{Op: "open", Path: rootPath, Err: err}
+		return
+	}
+
+	fd, err = openBeneath(rootFD, ".", oPath|syscall.O_DIRECTORY, 0)
+	if nil != err {
+		_ = syscall.Close(rootFD)
+		err = fmt.Errorf("localfs: openat2 (Linux 5.6 or later) required: %v", err)
+		return
+	}
+	_ = syscall.Close(fd)
+
+	return
+}
+
+// openFileBeneath is like openBeneath but returns an *os.File named path
+func openFileBeneath(rootFD int, path string, flags int, mode uint32) (file *os.File, err error) {
+	var (
+		fd int
+	)
+
+	fd, err = openBeneath(rootFD, path, flags, mode)
+	if nil == err {
+		file = os.NewFile(uintptr(fd), path)
+	}
+
+	return
+}
+
+// openParent opens the directory containing path returning it along with the last component of path
+func openParent(rootFD int, path string) (dirFD int, name string, err error) {
+	dirFD, err = openBeneath(rootFD, filepath.Dir(path), oPath|syscall.O_DIRECTORY, 0)
+	name = filepath.Base(path)
+	return
+}
+
+// lstatBeneath returns information about the object at path (not following a symlink)
+func lstatBeneath(rootFD int, path string) (info os.FileInfo, err error) {
+	var (
+		file *os.File
+	)
+
+	file, err = openFileBeneath(rootFD, path, oPath, 0)
+	if nil != err {
+		return
+	}
+	info, err = file.Stat()
+	_ = file.Close()
+
+	return
+}
+
+func readlinkBeneath(rootFD int, path string) (target string, err error) {
+	var (
+		buf     = make([]byte, 256)
+		dirFD   int
+		errno   syscall.Errno
+		name    string
+		namePtr *byte
+		r0      uintptr
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	namePtr, err = syscall.BytePtrFromString(name)
+	if nil != err {
+		return
+	}
+
+	for {
+		r0, _, errno = syscall.Syscall6(syscall.SYS_READLINKAT, uintptr(dirFD), uintptr(unsafe.Pointer(namePtr)), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0, 0)
+		if 0 != errno {
+			err = &os.PathError{Op: "readlinkat", Path: path, Err: errno}
+			return
+		}
+		if int(r0) < len(buf) {
+			target = string(buf[:r0])
+			return
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func mkdirBeneath(rootFD int, path string, mode uint32) (err error) {
+	var (
+		dirFD int
+		name  string
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	err = syscall.Mkdirat(dirFD, name, mode)
+
+	return
+}
+
+func symlinkBeneath(rootFD int, target string, path string) (err error) {
+	var (
+		dirFD     int
+		errno     syscall.Errno
+		name      string
+		namePtr   *byte
+		targetPtr *byte
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	targetPtr, err = syscall.BytePtrFromString(target)
+	if nil != err {
+		return
+	}
+	namePtr, err = syscall.BytePtrFromString(name)
+	if nil != err {
+		return
+	}
+
+	_, _, errno = syscall.Syscall(syscall.SYS_SYMLINKAT, uintptr(unsafe.Pointer(targetPtr)), uintptr(dirFD), uintptr(unsafe.Pointer(namePtr)))
+	if 0 != errno {
+		err = &os.PathError{Op: "symlinkat", Path: path, Err: errno}
+	}
+
+	return
+}
+
+// unlinkBeneath removes the object at path (which must be an empty directory if isDir is set)
+func unlinkBeneath(rootFD int, path string, isDir bool) (err error) {
+	var (
+		dirFD   int
+		errno   syscall.Errno
+		flags   int
+		name    string
+		namePtr *byte
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	namePtr, err = syscall.BytePtrFromString(name)
+	if nil != err {
+		return
+	}
+
+	if isDir {
+		flags = atRemoveDir
+	}
+
+	_, _, errno = syscall.Syscall(syscall.SYS_UNLINKAT, uintptr(dirFD), uintptr(unsafe.Pointer(namePtr)), uintptr(flags))
+	if 0 != errno {
+		err = &os.PathError{Op: "unlinkat", Path: path, Err: errno}
+	}
+
+	return
+}
+
+func renameBeneath(rootFD int, fromPath string, toPath string) (err error) {
+	var (
+		fromDirFD int
+		fromName  string
+		toDirFD   int
+		toName    string
+	)
+
+	fromDirFD, fromName, err = openParent(rootFD, fromPath)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fromDirFD)
+
+	toDirFD, toName, err = openParent(rootFD, toPath)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(toDirFD)
+
+	err = syscall.Renameat(fromDirFD, fromName, toDirFD, toName)
+
+	return
+}
+
+// linkBeneath creates toPath as a hard link to the object at fromPath (not following a symlink)
+func linkBeneath(rootFD int, fromPath string, toPath string) (err error) {
+	var (
+		errno       syscall.Errno
+		fromDirFD   int
+		fromName    string
+		fromNamePtr *byte
+		toDirFD     int
+		toName      string
+		toNamePtr   *byte
+	)
+
+	fromDirFD, fromName, err = openParent(rootFD, fromPath)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fromDirFD)
+
+	toDirFD, toName, err = openParent(rootFD, toPath)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(toDirFD)
+
+	fromNamePtr, err = syscall.BytePtrFromString(fromName)
+	if nil != err {
+		return
+	}
+	toNamePtr, err = syscall.BytePtrFromString(toName)
+	if nil != err {
+		return
+	}
+
+	_, _, errno = syscall.Syscall6(syscall.SYS_LINKAT, uintptr(fromDirFD), uintptr(unsafe.Pointer(fromNamePtr)), uintptr(toDirFD), uintptr(unsafe.Pointer(toNamePtr)), 0, 0)
+	if 0 != errno {
+		err = &os.LinkError{Op: "linkat", Old: fromPath, New: toPath, Err: errno}
+	}
+
+	return
+}
+
+// chownBeneath sets the owner & group of the object at path (not following a symlink)
+func chownBeneath(rootFD int, path string, uid int, gid int) (err error) {
+	var (
+		dirFD int
+		name  string
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	err = syscall.Fchownat(dirFD, name, uid, gid, atSymlinkNoFollow)
+
+	return
+}
+
+// chmodBeneath sets the mode of the object at path. As Linux lacks a means to set the mode of a descriptor
+// opened with O_PATH (lest a FIFO or device be opened), that of the descriptor's /proc/self/fd entry is set
+// (as does glibc's fchmodat). A symlink (whose mode is immaterial) is left unchanged.
+func chmodBeneath(rootFD int, path string, mode uint32) (err error) {
+	var (
+		fd int
+		st syscall.Stat_t
+	)
+
+	fd, err = openBeneath(rootFD, path, oPath, 0)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fd)
+
+	err = syscall.Fstat(fd, &st)
+	if (nil != err) || (syscall.S_IFLNK == (st.Mode & syscall.S_IFMT)) {
+		return
+	}
+
+	err = syscall.Chmod(fmt.Sprintf("/proc/self/fd/%d", fd), mode)
+
+	return
+}
+
+// truncateBeneath sets the size of the regular file at path
+func truncateBeneath(rootFD int, path string, size int64) (err error) {
+	var (
+		fd int
+	)
+
+	fd, err = openBeneath(rootFD, path, syscall.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fd)
+
+	err = syscall.Ftruncate(fd, size)
+
+	return
+}
+
+// setTimesBeneath sets the access & modification times of the object at path (not following a symlink)
+func setTimesBeneath(rootFD int, path string, atime time.Time, mtime time.Time) (err error) {
+	var (
+		dirFD    int
+		errno    syscall.Errno
+		name     string
+		namePtr  *byte
+		timespec [2]syscall.Timespec
+	)
+
+	dirFD, name, err = openParent(rootFD, path)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(dirFD)
+
+	namePtr, err = syscall.BytePtrFromString(name)
+	if nil != err {
+		return
+	}
+
+	timespec[0] = syscall.NsecToTimespec(atime.UnixNano())
+	timespec[1] = syscall.NsecToTimespec(mtime.UnixNano())
+
+	_, _, errno = syscall.Syscall6(syscall.SYS_UTIMENSAT, uintptr(dirFD), uintptr(unsafe.Pointer(namePtr)), uintptr(unsafe.Pointer(&timespec[0])), uintptr(atSymlinkNoFollow), 0, 0)
+	if 0 != errno {
+		err = &os.PathError{Op: "utimensat", Path: path, Err: errno}
+	}
+
+	return
+}
+
+// fsStatBeneath reports the capacity of the file system holding the object at path
+func fsStatBeneath(rootFD int, path string) (tBytes uint64, fBytes uint64, aBytes uint64, tFiles uint64, fFiles uint64, err error) {
+	var (
+		fd     int
+		statfs syscall.Statfs_t
+	)
+
+	fd, err = openBeneath(rootFD, path, oPath, 0)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fd)
+
+	err = syscall.Fstatfs(fd, &statfs)
+	if nil != err {
+		return
+	}
+
+	tBytes = uint64(statfs.Blocks) * uint64(statfs.Bsize)
+	fBytes = uint64(statfs.Bfree) * uint64(statfs.Bsize)
+	aBytes = uint64(statfs.Bavail) * uint64(statfs.Bsize)
+	tFiles = uint64(statfs.Files)
+	fFiles = uint64(statfs.Ffree)
+
+	return
+}
@@ -0,0 +1,80 @@
+//go:build !linux
+
+package localfs
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+func openRoot(rootPath string) (rootFD int, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func openFileBeneath(rootFD int, path string, flags int, mode uint32) (file *os.File, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func lstatBeneath(rootFD int, path string) (info os.FileInfo, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func readlinkBeneath(rootFD int, path string) (target string, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func mkdirBeneath(rootFD int, path string, mode uint32) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func symlinkBeneath(rootFD int, target string, path string) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func unlinkBeneath(rootFD int, path string, isDir bool) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func renameBeneath(rootFD int, fromPath string, toPath string) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func linkBeneath(rootFD int, fromPath string, toPath string) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func chownBeneath(rootFD int, path string, uid int, gid int) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func chmodBeneath(rootFD int, path string, mode uint32) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func truncateBeneath(rootFD int, path string, size int64) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func setTimesBeneath(rootFD int, path string, atime time.Time, mtime time.Time) (err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func fsStatBeneath(rootFD int, path string) (tBytes uint64, fBytes uint64, aBytes uint64, tFiles uint64, fFiles uint64, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
@@ -0,0 +1,426 @@
+// Package localfs exports a directory of the host via package nfsd.
+//
+// Every operation is resolved beneath the exported directory via openat2(RESOLVE_BENEATH) such that neither
+// symbolic links nor renames (via clients or the host) escape it. File handles encode the device, inode number,
+// and generation of each object such that they persist across restarts of the server (though resolving a handle
+// not seen recently requires a search of the exported tree, of which one at a time proceeds). Permissions are
+// enforced against the mode, owner, & group of each object on behalf of the AUTH_SYS identity of each request,
+// and objects are created on behalf of that identity when run as root. Special files (FIFOs, sockets, & devices)
+// already present in the exported directory are reported as such but never opened.
+//
+// Creating special files (whether via mknod(2) or an emulation thereof) is not supported as package nfsd does not
+// implement the MKNOD procedure (which clients are thus unable to invoke).
+package localfs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+	"github.com/swiftstack/nfsd/internal/handlecache"
+)
+
+const (
+	fHandleVersion = byte(1)
+	fHandleSize    = 1 + 8 + 8 + 4 // version, device, inode number, & generation
+)
+
+type ConfigStruct struct { // the configuration of a LocalFSStruct (see New)
+	RootPath          string          // the host directory exported
+	ErrorLog          func(err error) // if nil, errors are reported via the log package
+	AllowNoGeneration bool            // if set, RootPath may reside on a filesystem not reporting inode generations (see New)
+}
+
+// LocalFSStruct serves the directory specified by ConfigStruct.RootPath (see New)
+type LocalFSStruct struct {
+	rootPath   string                                             // absolute & cleaned
+	rootFD     int                                                // rootPath (opened O_PATH) relative to which every path is resolved
+	rootFileID fileIDStruct                                       //
+	errorLog   func(err error)                                    //
+	writeVerf  [nfsd.NFS3WriteVerfSize]byte                       // changes with each New such that clients resend uncommitted WRITEs
+	paths      *handlecache.CacheStruct[fileIDStruct, pathStruct] // of each object whose handle was returned
+	euidIsRoot bool                                               // if set, created objects are chowned to the requester's identity
+	fsid       uint64                                             // reported in the FSID of every FAttr3Struct
+}
+
+// pathStruct records the last known path (relative to rootPath) of an object whose handle was returned
+type pathStruct struct {
+	path       string
+	generation uint32    // of the object as of ctime
+	ctime      time.Time // if zero, generation is not yet known
+}
+
+type fileIDStruct struct {
+	dev uint64
+	ino uint64
+}
+
+// New constructs a LocalFSStruct exporting config.RootPath. As file handles identify objects by device, inode
+// number, & generation (see FS_IOC_GETVERSION), a filesystem not reporting generations would see a handle of a
+// removed object resolve to whichever object next reuses its inode number (rather than fail with NFS3ErrSTALE).
+// Such a config.RootPath is refused unless config.AllowNoGeneration is set. Note that other filesystems mounted
+// beneath config.RootPath are not checked (objects on those not reporting generations report 0).
+//
+// Arguments:
+//
+//	config specifies the directory to export (config is copied)
+//
+// Returns:
+//
+//	localFS is the LocalFSStruct (to be supplied as both the MountV3Interface & NFSv3Interface callbacks)
+//	err     is non-nil on failure (e.g. config.RootPath is not a directory, the kernel lacks openat2, or the
+//	        filesystem does not report inode generations)
+func New(config *ConfigStruct) (localFS *LocalFSStruct, err error) {
+	var (
+		info     os.FileInfo
+		rootFD   int
+		rootPath string
+		st       *statStruct
+	)
+
+	rootPath, err = filepath.Abs(config.RootPath)
+	if nil != err {
+		return
+	}
+
+	info, err = os.Lstat(rootPath)
+	if nil != err {
+		return
+	}
+	if !info.IsDir() {
+		err = fmt.Errorf("localfs: %s is not a directory", rootPath)
+		return
+	}
+
+	st, err = statOf(info)
+	if nil != err {
+		return
+	}
+
+	rootFD, err = openRoot(rootPath)
+	if nil != err {
+		return
+	}
+
+	_, err = generationOf(rootFD, ".", info)
+	if nil != err {
+		if !config.AllowNoGeneration {
+			_ = syscall.Close(rootFD)
+			err = fmt.Errorf("localfs: %s does not report inode generations (%v) such that handles of removed objects could resolve to others (see ConfigStruct.AllowNoGeneration)", rootPath, err)
+			return
+		}
+		err = nil
+	}
+
+	localFS = &LocalFSStruct{
+		rootPath:   rootPath,
+		rootFD:     rootFD,
+		rootFileID: fileIDStruct{dev: st.dev, ino: st.ino},
+		errorLog:   config.ErrorLog,
+		paths:      handlecache.New[fileIDStruct, pathStruct](0),
+		euidIsRoot: (0 == os.Geteuid()),
+		fsid:       st.dev,
+	}
+
+	if nil == localFS.errorLog {
+		localFS.errorLog = func(err error) { log.Printf("localfs: %v", err) }
+	}
+
+	_, err = rand.Read(localFS.writeVerf[:])
+	if nil != err {
+		_ = syscall.Close(rootFD)
+		localFS = nil
+		return
+	}
+
+	return
+}
+
+// RootFHandle returns the file handle of the exported directory (as returned by MNT of "/")
+func (localFS *LocalFSStruct) RootFHandle() (fHandle []byte) {
+	fHandle, _, _, _ = localFS.fHandleOf(".")
+	return
+}
+
+// fHandleOf returns the file handle of (and current information about) the object at path (relative to the
+// exported directory as are all paths herein), recording path such that the handle resolves to it
+func (localFS *LocalFSStruct) fHandleOf(path string) (fHandle []byte, info os.FileInfo, st *statStruct, err error) {
+	var (
+		fileID fileIDStruct
+	)
+
+	info, st, err = localFS.lstat(path)
+	if nil != err {
+		return
+	}
+
+	fileID = fileIDStruct{dev: st.dev, ino: st.ino}
+
+	fHandle = make([]byte, fHandleSize)
+	fHandle[0] = fHandleVersion
+	binary.BigEndian.PutUint64(fHandle[1:9], fileID.dev)
+	binary.BigEndian.PutUint64(fHandle[9:17], fileID.ino)
+	localFS.remember(fileID, path)
+
+	binary.BigEndian.PutUint32(fHandle[17:21], localFS.generation(fileID, path, info, st))
+
+	return
+}
+
+// remember records path as the last known path of the object identified by fileID
+func (localFS *LocalFSStruct) remember(fileID fileIDStruct, path string) {
+	localFS.paths.Remember(fileID, func(pathP *pathStruct) { pathP.path = path })
+}
+
+// lastKnownPath returns the last known path of the object identified by fileID (if any)
+func (localFS *LocalFSStruct) lastKnownPath(fileID fileIDStruct) (path string, ok bool) {
+	var (
+		pathS pathStruct
+	)
+
+	pathS, ok = localFS.paths.Recall(fileID)
+	if ok {
+		path = pathS.path
+	} else if localFS.rootFileID == fileID {
+		path = "."
+		ok = true
+	}
+
+	return
+}
+
+// generation returns the generation of the object identified by fileID (found at path). As obtaining it requires
+// opening the object, the generation is remembered alongside the object's path until the object's ctime changes.
+func (localFS *LocalFSStruct) generation(fileID fileIDStruct, path string, info os.FileInfo, st *statStruct) (generation uint32) {
+	var (
+		known bool
+	)
+
+	localFS.paths.Update(fileID, func(pathP *pathStruct) {
+		if !pathP.ctime.IsZero() && pathP.ctime.Equal(st.ctime) {
+			generation = pathP.generation
+			known = true
+		}
+	})
+	if known {
+		return
+	}
+
+	generation, _ = generationOf(localFS.rootFD, path, info) // 0 if not reported (see New)
+
+	localFS.paths.Update(fileID, func(pathP *pathStruct) {
+		pathP.generation = generation
+		pathP.ctime = st.ctime
+	})
+
+	return
+}
+
+// lstat returns information about the object at path (not following a symlink)
+func (localFS *LocalFSStruct) lstat(path string) (info os.FileInfo, st *statStruct, err error) {
+	info, err = lstatBeneath(localFS.rootFD, path)
+	if nil != err {
+		return
+	}
+	st, err = statOf(info)
+	if nil != err {
+		info = nil
+	}
+	return
+}
+
+// resolve returns the path of (and current information about) the object identified by fHandle. Should the
+// object no longer be found at its last known path (e.g. it was renamed via the host or the server restarted),
+// the exported tree is searched for it (see handlecache.CacheStruct.Search).
+func (localFS *LocalFSStruct) resolve(fHandle []byte) (path string, info os.FileInfo, st *statStruct, status uint32) {
+	var (
+		fileID     fileIDStruct
+		generation uint32
+		ok         bool
+	)
+
+	if (fHandleSize != len(fHandle)) || (fHandleVersion != fHandle[0]) {
+		status = nfsd.NFS3ErrBADHANDLE
+		return
+	}
+
+	fileID = fileIDStruct{dev: binary.BigEndian.Uint64(fHandle[1:9]), ino: binary.BigEndian.Uint64(fHandle[9:17])}
+	generation = binary.BigEndian.Uint32(fHandle[17:21])
+
+	path, ok = localFS.lastKnownPath(fileID)
+	if ok {
+		info, st, ok = localFS.matches(path, fileID)
+	}
+	if !ok {
+		localFS.paths.Forget(fileID)
+		status = localFS.paths.Search(fileID, func() (found bool) {
+			path, info, st, found = localFS.walk(fileID)
+			return
+		})
+		if nfsd.OK != status {
+			return
+		}
+		localFS.remember(fileID, path)
+	}
+
+	if generation != localFS.generation(fileID, path, info, st) {
+		localFS.paths.Forget(fileID)
+		status = nfsd.NFS3ErrSTALE
+		return
+	}
+
+	status = nfsd.OK
+
+	return
+}
+
+// matches returns whether the object at path is (still) that identified by fileID
+func (localFS *LocalFSStruct) matches(path string, fileID fileIDStruct) (info os.FileInfo, st *statStruct, ok bool) {
+	var (
+		err error
+	)
+
+	info, st, err = localFS.lstat(path)
+	ok = (nil == err) && (fileID.dev == st.dev) && (fileID.ino == st.ino)
+
+	return
+}
+
+// walk walks the exported tree for the object identified by fileID
+func (localFS *LocalFSStruct) walk(fileID fileIDStruct) (path string, info os.FileInfo, st *statStruct, ok bool) {
+	_ = filepath.WalkDir(localFS.rootPath, func(walkPath string, dirEntry fs.DirEntry, walkErr error) (err error) {
+		var (
+			walkInfo os.FileInfo
+			walkSt   *statStruct
+		)
+
+		if nil != walkErr {
+			if (nil != dirEntry) && dirEntry.IsDir() {
+				err = fs.SkipDir
+			}
+			return
+		}
+
+		walkInfo, err = dirEntry.Info()
+		if nil != err {
+			err = nil
+			return
+		}
+		walkSt, err = statOf(walkInfo)
+		if nil != err {
+			err = nil
+			return
+		}
+
+		if (fileID.dev == walkSt.dev) && (fileID.ino == walkSt.ino) {
+			path, err = filepath.Rel(localFS.rootPath, walkPath)
+			if nil != err {
+				return
+			}
+			info = walkInfo
+			st = walkSt
+			ok = true
+			err = fs.SkipAll
+		}
+
+		return
+	})
+
+	return
+}
+
+// childPath returns the path of name within the directory at dirPath. Note that "." & ".." are only valid for
+// LOOKUP (the latter never ascending above the exported directory).
+func (localFS *LocalFSStruct) childPath(dirPath string, name string) (path string, status uint32) {
+	if ("" == name) || strings.ContainsAny(name, "/\x00") {
+		status = nfsd.NFS3ErrINVAL
+		return
+	}
+	if nfsd.DefaultNameMax < uint32(len(name)) {
+		status = nfsd.NFS3ErrNAMETOOLONG
+		return
+	}
+
+	switch name {
+	case ".":
+		path = dirPath
+	case "..":
+		if "." == dirPath {
+			path = dirPath
+		} else {
+			path = filepath.Dir(dirPath)
+		}
+	default:
+		path = filepath.Join(dirPath, name)
+	}
+
+	status = nfsd.OK
+
+	return
+}
+
+// logStatus maps err to an nfsstat3 (reporting those errors not expected to be the consequence of a request)
+func (localFS *LocalFSStruct) logStatus(err error) (status uint32) {
+	var (
+		errno syscall.Errno
+	)
+
+	if !errors.As(err, &errno) {
+		localFS.errorLog(err)
+		status = nfsd.NFS3ErrIO
+		return
+	}
+
+	switch errno {
+	case syscall.EPERM:
+		status = nfsd.NFS3ErrPERM
+	case syscall.ENOENT:
+		status = nfsd.NFS3ErrNOENT
+	case syscall.ENXIO:
+		status = nfsd.NFS3ErrNXIO
+	case syscall.EACCES:
+		status = nfsd.NFS3ErrACCES
+	case syscall.EEXIST:
+		status = nfsd.NFS3ErrEXIST
+	case syscall.EXDEV:
+		status = nfsd.NFS3ErrXDEV
+	case syscall.ENODEV:
+		status = nfsd.NFS3ErrNODEV
+	case syscall.ENOTDIR:
+		status = nfsd.NFS3ErrNOTDIR
+	case syscall.EISDIR:
+		status = nfsd.NFS3ErrISDIR
+	case syscall.EINVAL:
+		status = nfsd.NFS3ErrINVAL
+	case syscall.EFBIG:
+		status = nfsd.NFS3ErrFBIG
+	case syscall.ENOSPC:
+		status = nfsd.NFS3ErrNOSPC
+	case syscall.EROFS:
+		status = nfsd.NFS3ErrROFS
+	case syscall.EMLINK:
+		status = nfsd.NFS3ErrMLINK
+	case syscall.ENAMETOOLONG:
+		status = nfsd.NFS3ErrNAMETOOLONG
+	case syscall.ENOTEMPTY:
+		status = nfsd.NFS3ErrNOTEMPTY
+	case syscall.EDQUOT:
+		status = nfsd.NFS3ErrDQUOT
+	case syscall.ESTALE:
+		status = nfsd.NFS3ErrSTALE
+	default:
+		localFS.errorLog(err)
+		status = nfsd.NFS3ErrIO
+	}
+
+	return
+}
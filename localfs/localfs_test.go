@@ -0,0 +1,451 @@
+package localfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+	"github.com/swiftstack/nfsd/internal/handlecache"
+)
+
+var (
+	testRoot  = &nfsd.CredentialStruct{Flavor: nfsd.AuthSys, UID: 0, GID: 0, GIDs: []uint32{}}
+	testOwner = &nfsd.CredentialStruct{Flavor: nfsd.AuthSys, UID: 1000, GID: 1000, GIDs: []uint32{}}
+	testOther = &nfsd.CredentialStruct{Flavor: nfsd.AuthSys, UID: 2000, GID: 2000, GIDs: []uint32{}}
+)
+
+func testNew(t *testing.T) (localFS *LocalFSStruct, rootPath string) {
+	var (
+		err error
+	)
+
+	rootPath = t.TempDir()
+
+	err = os.Chmod(rootPath, 0777)
+	if nil != err {
+		t.Fatalf("os.Chmod() failed: %v", err)
+	}
+
+	localFS, err = New(&ConfigStruct{RootPath: rootPath, ErrorLog: func(err error) { t.Logf("ErrorLog(%v)", err) }, AllowNoGeneration: true})
+	if nil != err {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	return
+}
+
+func testCreate(t *testing.T, localFS *LocalFSStruct, credential *nfsd.CredentialStruct, dir []byte, name string, mode uint32) (fHandle []byte) {
+	createResults := localFS.NFSProc3Create(credential, &nfsd.NFSProc3CreateArgsStruct{
+		Where: nfsd.DirOpArgs3Struct{Dir: dir, Name: name},
+		How:   nfsd.CreateHowStruct{Mode: nfsd.Guarded, ObjAttributes: nfsd.SAttr3Struct{SetMode: true, Mode: mode}},
+	})
+	if (nfsd.OK != createResults.Status) || !createResults.Obj.HandleFollows {
+		t.Fatalf("CREATE of %s returned %+v", name, createResults)
+	}
+
+	fHandle = createResults.Obj.Handle
+
+	return
+}
+
+func testNames(t *testing.T, localFS *LocalFSStruct, dir []byte) (names map[string]bool) {
+	readDirResults := localFS.NFSProc3ReadDir(testRoot, &nfsd.NFSProc3ReadDirArgsStruct{Dir: dir, Count: 4096})
+	if (nfsd.OK != readDirResults.Status) || !readDirResults.EOF {
+		t.Fatalf("READDIR returned %+v", readDirResults)
+	}
+
+	names = make(map[string]bool)
+	for _, entry := range readDirResults.Entries {
+		names[entry.Name] = true
+	}
+
+	return
+}
+
+func TestLocalFS(t *testing.T) {
+	localFS, rootPath := testNew(t)
+	root := localFS.RootFHandle()
+
+	var (
+		_ nfsd.MountV3Interface = localFS
+		_ nfsd.NFSv3Interface   = localFS
+	)
+
+	if nfsd.OK != localFS.MountProc3Mnt(testOwner, &nfsd.MountProc3MntArgsStruct{DirPath: "/"}).Status {
+		t.Fatalf("MNT of / failed")
+	}
+
+	file := testCreate(t, localFS, testOwner, root, "file", 0640)
+
+	createResults := localFS.NFSProc3Create(testOwner, &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}, How: nfsd.CreateHowStruct{Mode: nfsd.Guarded}})
+	if nfsd.NFS3ErrEXIST != createResults.Status {
+		t.Fatalf("Guarded CREATE of an existing file returned %v... expected NFS3ErrEXIST", createResults.Status)
+	}
+
+	getAttrResults := localFS.NFSProc3GetAttr(testOwner, &nfsd.NFSProc3GetAttrArgsStruct{Object: file})
+	if (nfsd.OK != getAttrResults.Status) || (nfsd.FTypeREG != getAttrResults.Attributes.Type) || (0640 != getAttrResults.Attributes.Mode) || (1000 != getAttrResults.Attributes.UID) {
+		t.Fatalf("GETATTR returned %+v", getAttrResults)
+	}
+
+	writeResults := localFS.NFSProc3Write(testOwner, &nfsd.NFSProc3WriteArgsStruct{File: file, Count: 5, Stable: nfsd.FileSync, Data: []byte("hello")})
+	if (nfsd.OK != writeResults.Status) || (5 != writeResults.Count) || (nfsd.FileSync != writeResults.Committed) || (5 != writeResults.FileWCC.After.Attributes.Size) {
+		t.Fatalf("WRITE returned %+v", writeResults)
+	}
+
+	readResults := localFS.NFSProc3Read(testOwner, &nfsd.NFSProc3ReadArgsStruct{File: file, Offset: 1, Count: 100})
+	if (nfsd.OK != readResults.Status) || !bytes.Equal([]byte("ello"), readResults.Data) || !readResults.EOF {
+		t.Fatalf("READ returned %+v", readResults)
+	}
+
+	commitResults := localFS.NFSProc3Commit(testOwner, &nfsd.NFSProc3CommitArgsStruct{File: file})
+	if (nfsd.OK != commitResults.Status) || (writeResults.Verf != commitResults.Verf) {
+		t.Fatalf("COMMIT returned %+v", commitResults)
+	}
+
+	lookupResults := localFS.NFSProc3Lookup(testOwner, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}})
+	if (nfsd.OK != lookupResults.Status) || !bytes.Equal(file, lookupResults.Object) {
+		t.Fatalf("LOOKUP returned %+v", lookupResults)
+	}
+	lookupResults = localFS.NFSProc3Lookup(testOwner, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: ".."}})
+	if (nfsd.OK != lookupResults.Status) || !bytes.Equal(root, lookupResults.Object) {
+		t.Fatalf("LOOKUP of .. of the exported directory should have returned the exported directory")
+	}
+
+	mkDirResults := localFS.NFSProc3MKDir(testOwner, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}})
+	if (nfsd.OK != mkDirResults.Status) || (nfsd.FTypeDIR != mkDirResults.ObjAttributes.Attributes.Type) || (0755 != mkDirResults.ObjAttributes.Attributes.Mode) {
+		t.Fatalf("MKDIR returned %+v", mkDirResults)
+	}
+	dir := mkDirResults.Obj.Handle
+
+	linkResults := localFS.NFSProc3Link(testOwner, &nfsd.NFSProc3LinkArgsStruct{File: file, Link: nfsd.DirOpArgs3Struct{Dir: dir, Name: "link"}})
+	if (nfsd.OK != linkResults.Status) || (2 != linkResults.FileAttributes.Attributes.NLink) {
+		t.Fatalf("LINK returned %+v", linkResults)
+	}
+
+	symLinkResults := localFS.NFSProc3SymLink(testOwner, &nfsd.NFSProc3SymLinkArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "symlink"}, SymLinkData: []byte("file")})
+	if (nfsd.OK != symLinkResults.Status) || (nfsd.FTypeLNK != symLinkResults.ObjAttributes.Attributes.Type) {
+		t.Fatalf("SYMLINK returned %+v", symLinkResults)
+	}
+	readLinkResults := localFS.NFSProc3ReadLink(testOwner, &nfsd.NFSProc3ReadLinkArgsStruct{SymLink: symLinkResults.Obj.Handle})
+	if (nfsd.OK != readLinkResults.Status) || ("file" != string(readLinkResults.Path)) {
+		t.Fatalf("READLINK returned %+v", readLinkResults)
+	}
+
+	renameResults := localFS.NFSProc3Rename(testOwner, &nfsd.NFSProc3RenameArgsStruct{From: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}, To: nfsd.DirOpArgs3Struct{Dir: dir, Name: "renamed"}})
+	if nfsd.OK != renameResults.Status {
+		t.Fatalf("RENAME returned %+v", renameResults)
+	}
+	if nfsd.OK != localFS.NFSProc3GetAttr(testOwner, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR of a renamed file failed")
+	}
+
+	names := testNames(t, localFS, root)
+	if (4 != len(names)) || !names["."] || !names[".."] || !names["dir"] || !names["symlink"] {
+		t.Fatalf("READDIR returned %v", names)
+	}
+
+	readDirPlusResults := localFS.NFSProc3ReadDirPlus(testOwner, &nfsd.NFSProc3ReadDirPlusArgsStruct{Dir: dir, DirCount: 4096, MaxCount: 8192})
+	if (nfsd.OK != readDirPlusResults.Status) || (4 != len(readDirPlusResults.Entries)) || !readDirPlusResults.EOF {
+		t.Fatalf("READDIRPLUS returned %+v", readDirPlusResults)
+	}
+	for _, entry := range readDirPlusResults.Entries {
+		if ("renamed" == entry.Name) && !bytes.Equal(file, entry.NameHandle.Handle) {
+			t.Fatalf("READDIRPLUS returned handle %v for renamed... expected %v", entry.NameHandle.Handle, file)
+		}
+	}
+
+	readDirResults := localFS.NFSProc3ReadDir(testOwner, &nfsd.NFSProc3ReadDirArgsStruct{Dir: dir, Count: 4096})
+	resumed := localFS.NFSProc3ReadDir(testOwner, &nfsd.NFSProc3ReadDirArgsStruct{Dir: dir, Cookie: readDirResults.Entries[1].Cookie, Count: 4096})
+	if (nfsd.OK != resumed.Status) || (2 != len(resumed.Entries)) || (readDirResults.Entries[2] != resumed.Entries[0]) {
+		t.Fatalf("READDIR resumed from a cookie returned %+v", resumed)
+	}
+
+	if nfsd.NFS3ErrNOTEMPTY != localFS.NFSProc3RMDir(testOwner, &nfsd.NFSProc3RMDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("RMDIR of a non-empty directory should have failed with NFS3ErrNOTEMPTY")
+	}
+	if nfsd.NFS3ErrISDIR != localFS.NFSProc3Remove(testOwner, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("REMOVE of a directory should have failed with NFS3ErrISDIR")
+	}
+	for _, name := range []string{"renamed", "link"} {
+		if nfsd.OK != localFS.NFSProc3Remove(testOwner, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: dir, Name: name}}).Status {
+			t.Fatalf("REMOVE of %s failed", name)
+		}
+	}
+	if nfsd.OK != localFS.NFSProc3RMDir(testOwner, &nfsd.NFSProc3RMDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("RMDIR of an empty directory failed")
+	}
+	if nfsd.NFS3ErrSTALE != localFS.NFSProc3GetAttr(testOwner, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR of a removed file should have failed with NFS3ErrSTALE")
+	}
+
+	_, err := os.Lstat(filepath.Join(rootPath, "symlink"))
+	if nil != err {
+		t.Fatalf("symlink not found in the exported directory: %v", err)
+	}
+}
+
+func TestLocalFSPersistentHandles(t *testing.T) {
+	localFS, rootPath := testNew(t)
+
+	mkDirResults := localFS.NFSProc3MKDir(testRoot, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: localFS.RootFHandle(), Name: "dir"}})
+	if nfsd.OK != mkDirResults.Status {
+		t.Fatalf("MKDIR returned %+v", mkDirResults)
+	}
+	file := testCreate(t, localFS, testRoot, mkDirResults.Obj.Handle, "file", 0644)
+
+	restarted, err := New(&ConfigStruct{RootPath: rootPath, AllowNoGeneration: true})
+	if nil != err {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if !bytes.Equal(localFS.RootFHandle(), restarted.RootFHandle()) {
+		t.Fatalf("handle of the exported directory changed across New()")
+	}
+
+	getAttrResults := restarted.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file})
+	if (nfsd.OK != getAttrResults.Status) || (nfsd.FTypeREG != getAttrResults.Attributes.Type) {
+		t.Fatalf("GETATTR of a handle returned prior to New() returned %+v", getAttrResults)
+	}
+
+	err = os.Rename(filepath.Join(rootPath, "dir", "file"), filepath.Join(rootPath, "moved"))
+	if nil != err {
+		t.Fatalf("os.Rename() failed: %v", err)
+	}
+	time.Sleep(time.Until(restarted.paths.SearchNotBefore())) // otherwise, a search promptly following the last is refused
+	if nfsd.OK != restarted.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR of a file renamed via the host failed")
+	}
+
+	if nfsd.NFS3ErrBADHANDLE != restarted.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: []byte{1, 2, 3}}).Status {
+		t.Fatalf("GETATTR of a malformed handle should have failed with NFS3ErrBADHANDLE")
+	}
+}
+
+func TestLocalFSSearchLimits(t *testing.T) {
+	localFS, _ := testNew(t)
+
+	forged := append([]byte{}, localFS.RootFHandle()...)
+	binary.BigEndian.PutUint64(forged[9:17], ^uint64(0))
+
+	// A handle not found by a search is answered with NFS3ErrSTALE (as it then is without searching again... see
+	// package handlecache for the limits on searching)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if nfsd.NFS3ErrSTALE != localFS.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: forged}).Status {
+			t.Fatalf("GETATTR of a forged handle should have failed with NFS3ErrSTALE")
+		}
+	}
+
+	// The last known paths remembered are bounded yet handles of those forgotten still resolve
+
+	localFS.paths = handlecache.New[fileIDStruct, pathStruct](2)
+	files := make([][]byte, 0, 4)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		files = append(files, testCreate(t, localFS, testRoot, localFS.RootFHandle(), name, 0644))
+	}
+	if 2 != localFS.paths.Len() {
+		t.Fatalf("%d paths remembered exceeding the limit", localFS.paths.Len())
+	}
+	for _, file := range files {
+		time.Sleep(time.Until(localFS.paths.SearchNotBefore()))
+		if nfsd.OK != localFS.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+			t.Fatalf("GETATTR of a handle whose path was forgotten failed")
+		}
+	}
+	if 2 != localFS.paths.Len() {
+		t.Fatalf("%d paths remembered exceeding the limit", localFS.paths.Len())
+	}
+}
+
+func TestLocalFSGenerations(t *testing.T) {
+	localFS, rootPath := testNew(t)
+
+	file := testCreate(t, localFS, testRoot, localFS.RootFHandle(), "file", 0644)
+	fileID := fileIDStruct{dev: binary.BigEndian.Uint64(file[1:9]), ino: binary.BigEndian.Uint64(file[9:17])}
+
+	// The generation is remembered (rather than obtained anew) until the object's ctime changes
+
+	localFS.paths.Update(fileID, func(pathP *pathStruct) { pathP.generation ^= 1 })
+	if nfsd.NFS3ErrSTALE != localFS.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR should have been answered via the remembered generation")
+	}
+	localFS.remember(fileID, "file") // as STALE forgot it
+	if nfsd.OK != localFS.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR of a file whose generation is not remembered failed")
+	}
+	localFS.paths.Update(fileID, func(pathP *pathStruct) { pathP.generation ^= 1 })
+	err := os.Chmod(filepath.Join(rootPath, "file"), 0600)
+	if nil != err {
+		t.Fatalf("os.Chmod() failed: %v", err)
+	}
+	if nfsd.OK != localFS.NFSProc3GetAttr(testRoot, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Status {
+		t.Fatalf("GETATTR of a file whose ctime changed should have obtained its generation anew")
+	}
+
+	// An exported directory on a filesystem not reporting generations is refused unless so allowed
+
+	info, err := os.Lstat(rootPath)
+	if nil != err {
+		t.Fatalf("os.Lstat() failed: %v", err)
+	}
+	_, err = generationOf(localFS.rootFD, ".", info)
+	if nil == err {
+		rootPath, err = os.MkdirTemp("/dev/shm", "localfs")
+		if nil != err {
+			t.Skipf("no filesystem not reporting generations available: %v", err)
+		}
+		defer os.RemoveAll(rootPath)
+	}
+	_, err = New(&ConfigStruct{RootPath: rootPath})
+	if nil == err {
+		t.Skipf("%s reports generations", rootPath)
+	}
+	_, err = New(&ConfigStruct{RootPath: rootPath, AllowNoGeneration: true})
+	if nil != err {
+		t.Fatalf("New() with AllowNoGeneration failed: %v", err)
+	}
+}
+
+func TestLocalFSPermissions(t *testing.T) {
+	localFS, rootPath := testNew(t)
+	root := localFS.RootFHandle()
+
+	mkDirResults := localFS.NFSProc3MKDir(testOwner, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "private"}})
+	if nfsd.OK != mkDirResults.Status {
+		t.Fatalf("MKDIR returned %+v", mkDirResults)
+	}
+	if nfsd.NFS3ErrACCES != localFS.NFSProc3Create(testOther, &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: mkDirResults.Obj.Handle, Name: "file"}}).Status {
+		t.Fatalf("CREATE within a directory owned by another user should have failed with NFS3ErrACCES")
+	}
+
+	file := testCreate(t, localFS, testOwner, root, "file", 0640)
+
+	if nfsd.NFS3ErrACCES != localFS.NFSProc3Read(testOther, &nfsd.NFSProc3ReadArgsStruct{File: file, Count: 1}).Status {
+		t.Fatalf("READ by other of a mode 0640 file should have failed with NFS3ErrACCES")
+	}
+	if nfsd.Access3Read != localFS.NFSProc3Access(&nfsd.CredentialStruct{UID: 2000, GID: 2000, GIDs: []uint32{1000}}, &nfsd.NFSProc3AccessArgsStruct{Object: file, Access: nfsd.Access3Read | nfsd.Access3Modify}).Access {
+		t.Fatalf("ACCESS by a member of the group of a mode 0640 file should have granted (only) Access3Read")
+	}
+	if 0 != localFS.NFSProc3Access(testOther, &nfsd.NFSProc3AccessArgsStruct{Object: file, Access: nfsd.Access3Read | nfsd.Access3Modify}).Access {
+		t.Fatalf("ACCESS by other of a mode 0640 file should have granted nothing")
+	}
+	if nfsd.NFS3ErrPERM != localFS.NFSProc3SetAttr(testOther, &nfsd.NFSProc3SetAttrArgsStruct{Object: file, NewAttributes: nfsd.SAttr3Struct{SetMode: true, Mode: 0666}}).Status {
+		t.Fatalf("SETATTR of the mode by other should have failed with NFS3ErrPERM")
+	}
+
+	setAttrResults := localFS.NFSProc3SetAttr(testOwner, &nfsd.NFSProc3SetAttrArgsStruct{Object: file, NewAttributes: nfsd.SAttr3Struct{SetMode: true, Mode: 0400, SetSize: true, Size: 10}})
+	if (nfsd.OK != setAttrResults.Status) || (0400 != setAttrResults.WCC.After.Attributes.Mode) || (10 != setAttrResults.WCC.After.Attributes.Size) {
+		t.Fatalf("SETATTR by the owner returned %+v", setAttrResults)
+	}
+	if nfsd.OK != localFS.NFSProc3Write(testOwner, &nfsd.NFSProc3WriteArgsStruct{File: file, Count: 1, Data: []byte{1}}).Status {
+		t.Fatalf("WRITE by the owner of a mode 0400 file failed")
+	}
+
+	err := syscall.Chmod(rootPath, 01777)
+	if nil != err {
+		t.Fatalf("syscall.Chmod() failed: %v", err)
+	}
+	if nfsd.NFS3ErrPERM != localFS.NFSProc3Remove(testOther, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}}).Status {
+		t.Fatalf("REMOVE by other of a file within a sticky directory should have failed with NFS3ErrPERM")
+	}
+	if nfsd.OK != localFS.NFSProc3Remove(testOwner, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}}).Status {
+		t.Fatalf("REMOVE by the owner of a file within a sticky directory failed")
+	}
+}
+
+func TestLocalFSSpecialFiles(t *testing.T) {
+	localFS, rootPath := testNew(t)
+
+	err := syscall.Mkfifo(filepath.Join(rootPath, "fifo"), 0666)
+	if nil != err {
+		t.Skipf("syscall.Mkfifo() failed: %v", err)
+	}
+
+	lookupResults := localFS.NFSProc3Lookup(testRoot, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: localFS.RootFHandle(), Name: "fifo"}})
+	if (nfsd.OK != lookupResults.Status) || (nfsd.FTypeFIFO != lookupResults.ObjAttributes.Attributes.Type) {
+		t.Fatalf("LOOKUP of a FIFO returned %+v", lookupResults)
+	}
+
+	if nfsd.NFS3ErrINVAL != localFS.NFSProc3Read(testRoot, &nfsd.NFSProc3ReadArgsStruct{File: lookupResults.Object, Count: 1}).Status {
+		t.Fatalf("READ of a FIFO should have failed with NFS3ErrINVAL")
+	}
+}
+
+func TestLocalFSSymlinkedParent(t *testing.T) {
+	localFS, rootPath := testNew(t)
+	outsidePath := t.TempDir()
+
+	mkDirResults := localFS.NFSProc3MKDir(testRoot, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: localFS.RootFHandle(), Name: "dir"}})
+	if nfsd.OK != mkDirResults.Status {
+		t.Fatalf("MKDIR of dir returned %+v", mkDirResults)
+	}
+	mkDirResults = localFS.NFSProc3MKDir(testRoot, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: mkDirResults.Obj.Handle, Name: "sub"}})
+	if nfsd.OK != mkDirResults.Status {
+		t.Fatalf("MKDIR of dir/sub returned %+v", mkDirResults)
+	}
+	sub := mkDirResults.Obj.Handle
+
+	// Swap dir for a symlink to a directory outside of the export (as might a client racing RENAME & SYMLINK)
+
+	err := os.Mkdir(filepath.Join(outsidePath, "sub"), 0777)
+	if nil == err {
+		err = os.Chmod(filepath.Join(outsidePath, "sub"), 0777)
+	}
+	if nil != err {
+		t.Fatalf("os.Mkdir() failed: %v", err)
+	}
+	err = os.Rename(filepath.Join(rootPath, "dir"), filepath.Join(rootPath, "moved"))
+	if nil != err {
+		t.Fatalf("os.Rename() failed: %v", err)
+	}
+	err = os.Symlink(outsidePath, filepath.Join(rootPath, "dir"))
+	if nil != err {
+		t.Fatalf("os.Symlink() failed: %v", err)
+	}
+
+	// An operation upon the last known path of dir/sub must not follow the symlink
+
+	_, err = openFileBeneath(localFS.rootFD, "dir/sub/file", syscall.O_WRONLY|syscall.O_CREAT, 0600)
+	if nil == err {
+		t.Fatalf("openFileBeneath() via a symlinked parent should have failed")
+	}
+	_, err = lstatBeneath(localFS.rootFD, "dir/sub")
+	if nil == err {
+		t.Fatalf("lstatBeneath() via a symlinked parent should have failed")
+	}
+	for _, err = range []error{
+		mkdirBeneath(localFS.rootFD, "dir/sub/dir", 0700),
+		symlinkBeneath(localFS.rootFD, "target", "dir/sub/symlink"),
+		renameBeneath(localFS.rootFD, "moved/sub", "dir/sub/renamed"),
+		chmodBeneath(localFS.rootFD, "dir/sub", 0700),
+		setTimesBeneath(localFS.rootFD, "dir/sub", time.Now(), time.Now()),
+	} {
+		if nil == err {
+			t.Fatalf("operation via a symlinked parent should have failed")
+		}
+	}
+
+	// Whereas CREATE via the handle of dir/sub finds it where it was moved
+
+	file := testCreate(t, localFS, testRoot, sub, "file", 0644)
+	if nil == file {
+		t.Fatalf("CREATE returned no handle")
+	}
+	_, err = os.Lstat(filepath.Join(rootPath, "moved", "sub", "file"))
+	if nil != err {
+		t.Fatalf("CREATE via the handle of a moved directory failed to create moved/sub/file: %v", err)
+	}
+
+	outsideEntries, err := os.ReadDir(filepath.Join(outsidePath, "sub"))
+	if (nil != err) || (0 != len(outsideEntries)) {
+		t.Fatalf("directory outside of the export modified: %v %v", outsideEntries, err)
+	}
+	outsideInfo, err := os.Stat(filepath.Join(outsidePath, "sub"))
+	if (nil != err) || (0777 != (outsideInfo.Mode() & os.ModePerm)) {
+		t.Fatalf("mode of directory outside of the export modified: %v %v", outsideInfo, err)
+	}
+}
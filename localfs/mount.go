@@ -0,0 +1,68 @@
+package localfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/swiftstack/nfsd"
+)
+
+func (localFS *LocalFSStruct) MountProc3Null(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Mnt returns the file handle of DirPath (interpreted relative to the exported directory). No component
+// of DirPath may be a symlink.
+func (localFS *LocalFSStruct) MountProc3Mnt(credential *nfsd.CredentialStruct, mountProc3MntArgs *nfsd.MountProc3MntArgsStruct) (mountProc3MntResults *nfsd.MountProc3MntResultsStruct) {
+	var (
+		component string
+		err       error
+		info      os.FileInfo
+		path      = "."
+	)
+
+	mountProc3MntResults = &nfsd.MountProc3MntResultsStruct{}
+
+	for _, component = range strings.Split(filepath.Clean("/"+mountProc3MntArgs.DirPath), "/") {
+		if "" == component {
+			continue
+		}
+		path = filepath.Join(path, component)
+		info, _, err = localFS.lstat(path)
+		if nil != err {
+			mountProc3MntResults.Status = nfsd.MNT3ErrNOENT
+			return
+		}
+		if !info.IsDir() {
+			mountProc3MntResults.Status = nfsd.MNT3ErrNOTDIR
+			return
+		}
+	}
+
+	mountProc3MntResults.FHandle, _, _, err = localFS.fHandleOf(path)
+	if nil != err {
+		mountProc3MntResults.Status = nfsd.MNT3ErrIO
+		return
+	}
+
+	mountProc3MntResults.Status = nfsd.OK
+	mountProc3MntResults.AuthFlavors = []uint32{nfsd.AuthSys}
+
+	return
+}
+
+// MountProc3Dump returns an empty list as mounts are not tracked by LocalFSStruct (see nfsd.EnableMountTable)
+func (localFS *LocalFSStruct) MountProc3Dump(credential *nfsd.CredentialStruct) (mountProc3DumpResults *nfsd.MountProc3DumpResultsStruct) {
+	mountProc3DumpResults = &nfsd.MountProc3DumpResultsStruct{MountList: []nfsd.MountBodyStruct{}}
+	return
+}
+
+func (localFS *LocalFSStruct) MountProc3Umnt(credential *nfsd.CredentialStruct, mountProc3UmntArgs *nfsd.MountProc3UmntArgsStruct) {
+}
+
+func (localFS *LocalFSStruct) MountProc3UmntAll(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Export returns "/" (i.e. the exported directory) as exported to all clients
+func (localFS *LocalFSStruct) MountProc3Export(credential *nfsd.CredentialStruct) (mountProc3ExportResults *nfsd.MountProc3ExportResultsStruct) {
+	mountProc3ExportResults = &nfsd.MountProc3ExportResultsStruct{Exports: []nfsd.ExportNodeStruct{{Dir: "/", Groups: []string{}}}}
+	return
+}
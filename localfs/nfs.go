@@ -0,0 +1,1225 @@
+package localfs
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+// linkMax is the LinkMax with which PATHCONF is answered (as ext4)
+const linkMax = uint32(65000)
+
+// Modes of objects created without SAttr3Struct.SetMode
+const (
+	defaultFileMode = uint32(0644)
+	defaultDirMode  = uint32(0755)
+)
+
+// objectStruct describes the object identified by a file handle
+type objectStruct struct {
+	path string
+	info os.FileInfo
+	st   *statStruct
+}
+
+func (localFS *LocalFSStruct) object(fHandle []byte) (object *objectStruct, status uint32) {
+	object = &objectStruct{}
+	object.path, object.info, object.st, status = localFS.resolve(fHandle)
+	if nfsd.OK != status {
+		object = nil
+	}
+	return
+}
+
+// dir is like object but additionally requires that the object be a directory to which credential is granted want
+func (localFS *LocalFSStruct) dir(credential *nfsd.CredentialStruct, fHandle []byte, want uint32) (dir *objectStruct, status uint32) {
+	dir, status = localFS.object(fHandle)
+	if nfsd.OK != status {
+		return
+	}
+
+	if !dir.info.IsDir() {
+		status = nfsd.NFS3ErrNOTDIR
+	} else if !permitted(credential, dir.info, dir.st, want) {
+		status = nfsd.NFS3ErrACCES
+	}
+
+	return
+}
+
+// newName is like childPath but also rejects "." & ".." (with dotStatus) being names of entries that can be
+// neither created nor removed
+func (localFS *LocalFSStruct) newName(dirPath string, name string, dotStatus uint32) (path string, status uint32) {
+	if ("." == name) || (".." == name) {
+		status = dotStatus
+		return
+	}
+	path, status = localFS.childPath(dirPath, name)
+	return
+}
+
+func timeOf(nfsTime3 nfsd.NFSTime3Struct) (t time.Time) {
+	t = time.Unix(int64(nfsTime3.Seconds), int64(nfsTime3.NSeconds))
+	return
+}
+
+func (localFS *LocalFSStruct) fAttr(info os.FileInfo, st *statStruct) (fAttr3 nfsd.FAttr3Struct) {
+	var (
+		mode = info.Mode()
+	)
+
+	switch {
+	case mode.IsDir():
+		fAttr3.Type = nfsd.FTypeDIR
+	case 0 != (mode & os.ModeSymlink):
+		fAttr3.Type = nfsd.FTypeLNK
+	case 0 != (mode & os.ModeNamedPipe):
+		fAttr3.Type = nfsd.FTypeFIFO
+	case 0 != (mode & os.ModeSocket):
+		fAttr3.Type = nfsd.FTypeSOCK
+	case 0 != (mode & os.ModeCharDevice):
+		fAttr3.Type = nfsd.FTypeCHR
+	case 0 != (mode & os.ModeDevice):
+		fAttr3.Type = nfsd.FTypeBLK
+	default:
+		fAttr3.Type = nfsd.FTypeREG
+	}
+
+	fAttr3.Mode = st.mode
+	fAttr3.NLink = uint32(st.nlink)
+	fAttr3.UID = st.uid
+	fAttr3.GID = st.gid
+	fAttr3.Size = uint64(info.Size())
+	fAttr3.Used = uint64(st.blocks) * 512
+	if (nfsd.FTypeCHR == fAttr3.Type) || (nfsd.FTypeBLK == fAttr3.Type) {
+		fAttr3.RDev = nfsd.SpecData3Struct{SpecData1: st.rdevMajor, SpecData2: st.rdevMinor}
+	}
+	fAttr3.FSID = localFS.fsid
+	fAttr3.FileID = st.ino
+	fAttr3.ATime = nfsd.NFSTime(st.atime)
+	fAttr3.MTime = nfsd.NFSTime(info.ModTime())
+	fAttr3.CTime = nfsd.NFSTime(st.ctime)
+
+	return
+}
+
+// postOpAttr returns the current attributes of the object at path (if available)
+func (localFS *LocalFSStruct) postOpAttr(path string) (postOpAttr nfsd.PostOpAttrStruct) {
+	var (
+		err  error
+		info os.FileInfo
+		st   *statStruct
+	)
+
+	info, st, err = localFS.lstat(path)
+	if nil == err {
+		postOpAttr = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+	}
+
+	return
+}
+
+func preOpAttr(object *objectStruct) (preOpAttr nfsd.PreOpAttrStruct) {
+	if nil != object {
+		preOpAttr = nfsd.PreOpAttrStruct{
+			AttributesFollow: true,
+			Attributes: nfsd.WCCAttrStruct{
+				Size:  uint64(object.info.Size()),
+				MTime: nfsd.NFSTime(object.info.ModTime()),
+				CTime: nfsd.NFSTime(object.st.ctime),
+			},
+		}
+	}
+	return
+}
+
+// wcc returns the wcc_data of an object given its attributes before an operation (should object be non-nil)
+func (localFS *LocalFSStruct) wcc(object *objectStruct) (wccData nfsd.WCCDataStruct) {
+	if nil != object {
+		wccData = nfsd.WCCDataStruct{Before: preOpAttr(object), After: localFS.postOpAttr(object.path)}
+	}
+	return
+}
+
+// initialize establishes the owner, group, & mode (unless defaultMode == 0) of an object just created on behalf
+// of credential within dir. The group is that of dir should dir have its setgid bit set (in which case new
+// directories inherit the bit).
+func (localFS *LocalFSStruct) initialize(credential *nfsd.CredentialStruct, dir *objectStruct, path string, sAttr3 *nfsd.SAttr3Struct, defaultMode uint32, isDir bool) (err error) {
+	var (
+		gid  = credential.GID
+		mode = defaultMode
+		uid  = credential.UID
+	)
+
+	if 0 != (dir.st.mode & modeSetGID) {
+		gid = dir.st.gid
+		if isDir {
+			mode |= modeSetGID
+		}
+	}
+	if (0 == credential.UID) && sAttr3.SetUID {
+		uid = sAttr3.UID
+	}
+	if sAttr3.SetGID && ((0 == credential.UID) || inGroup(credential, sAttr3.GID)) {
+		gid = sAttr3.GID
+	}
+	if sAttr3.SetMode {
+		mode = (mode & modeSetGID) | (sAttr3.Mode & 07777)
+	}
+
+	if localFS.euidIsRoot {
+		err = chownBeneath(localFS.rootFD, path, int(uid), int(gid))
+		if nil != err {
+			return
+		}
+	}
+
+	if 0 != defaultMode {
+		err = chmodBeneath(localFS.rootFD, path, mode)
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) ErrorLog(err error) {
+	localFS.errorLog(err)
+}
+
+func (localFS *LocalFSStruct) NFSProc3Null(credential *nfsd.CredentialStruct) {}
+
+func (localFS *LocalFSStruct) NFSProc3GetAttr(credential *nfsd.CredentialStruct, nfsProc3GetAttrArgs *nfsd.NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *nfsd.NFSProc3GetAttrResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3GetAttrResults = &nfsd.NFSProc3GetAttrResultsStruct{}
+
+	object, nfsProc3GetAttrResults.Status = localFS.object(nfsProc3GetAttrArgs.Object)
+	if nfsd.OK == nfsProc3GetAttrResults.Status {
+		nfsProc3GetAttrResults.Attributes = localFS.fAttr(object.info, object.st)
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3SetAttr(credential *nfsd.CredentialStruct, nfsProc3SetAttrArgs *nfsd.NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *nfsd.NFSProc3SetAttrResultsStruct) {
+	var (
+		atime   time.Time
+		err     error
+		gid     = -1
+		isOwner bool
+		mtime   time.Time
+		object  *objectStruct
+		sAttr3  = &nfsProc3SetAttrArgs.NewAttributes
+		uid     = -1
+	)
+
+	nfsProc3SetAttrResults = &nfsd.NFSProc3SetAttrResultsStruct{}
+
+	object, nfsProc3SetAttrResults.Status = localFS.object(nfsProc3SetAttrArgs.Object)
+	if nfsd.OK != nfsProc3SetAttrResults.Status {
+		return
+	}
+
+	defer func() {
+		nfsProc3SetAttrResults.WCC = localFS.wcc(object)
+	}()
+
+	if nfsProc3SetAttrArgs.Guard.CheckCTime && (nfsProc3SetAttrArgs.Guard.CTime != nfsd.NFSTime(object.st.ctime)) {
+		nfsProc3SetAttrResults.Status = nfsd.NFS3ErrNOTSYNC
+		return
+	}
+
+	isOwner = (0 == credential.UID) || (credential.UID == object.st.uid)
+
+	if (sAttr3.SetMode && !isOwner) ||
+		(sAttr3.SetUID && (sAttr3.UID != object.st.uid) && (0 != credential.UID)) ||
+		(sAttr3.SetGID && (sAttr3.GID != object.st.gid) && !((0 == credential.UID) || (isOwner && inGroup(credential, sAttr3.GID)))) ||
+		((nfsd.SetToClientTime == sAttr3.SetATime) && !isOwner) ||
+		((nfsd.SetToClientTime == sAttr3.SetMTime) && !isOwner) {
+		nfsProc3SetAttrResults.Status = nfsd.NFS3ErrPERM
+		return
+	}
+	if ((nfsd.SetToServerTime == sAttr3.SetATime) || (nfsd.SetToServerTime == sAttr3.SetMTime)) && !isOwner && !permitted(credential, object.info, object.st, permWrite) {
+		nfsProc3SetAttrResults.Status = nfsd.NFS3ErrACCES
+		return
+	}
+
+	if sAttr3.SetSize {
+		if object.info.IsDir() {
+			nfsProc3SetAttrResults.Status = nfsd.NFS3ErrISDIR
+			return
+		}
+		if !object.info.Mode().IsRegular() {
+			nfsProc3SetAttrResults.Status = nfsd.NFS3ErrINVAL
+			return
+		}
+		if !permitted(credential, object.info, object.st, permWrite) {
+			nfsProc3SetAttrResults.Status = nfsd.NFS3ErrACCES
+			return
+		}
+		err = truncateBeneath(localFS.rootFD, object.path, int64(sAttr3.Size))
+		if nil != err {
+			nfsProc3SetAttrResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	if sAttr3.SetUID {
+		uid = int(sAttr3.UID)
+	}
+	if sAttr3.SetGID {
+		gid = int(sAttr3.GID)
+	}
+	if (-1 != uid) || (-1 != gid) {
+		err = chownBeneath(localFS.rootFD, object.path, uid, gid)
+		if nil != err {
+			nfsProc3SetAttrResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	if sAttr3.SetMode && (0 == (object.info.Mode() & os.ModeSymlink)) {
+		err = chmodBeneath(localFS.rootFD, object.path, sAttr3.Mode&07777)
+		if nil != err {
+			nfsProc3SetAttrResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	if (nfsd.DontChange != sAttr3.SetATime) || (nfsd.DontChange != sAttr3.SetMTime) {
+		atime = object.st.atime
+		mtime = object.info.ModTime()
+		switch sAttr3.SetATime {
+		case nfsd.SetToServerTime:
+			atime = time.Now()
+		case nfsd.SetToClientTime:
+			atime = timeOf(sAttr3.ATime)
+		}
+		switch sAttr3.SetMTime {
+		case nfsd.SetToServerTime:
+			mtime = time.Now()
+		case nfsd.SetToClientTime:
+			mtime = timeOf(sAttr3.MTime)
+		}
+		err = setTimesBeneath(localFS.rootFD, object.path, atime, mtime)
+		if nil != err {
+			nfsProc3SetAttrResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Lookup(credential *nfsd.CredentialStruct, nfsProc3LookupArgs *nfsd.NFSProc3LookupArgsStruct) (nfsProc3LookupResults *nfsd.NFSProc3LookupResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info os.FileInfo
+		path string
+		st   *statStruct
+	)
+
+	nfsProc3LookupResults = &nfsd.NFSProc3LookupResultsStruct{}
+
+	dir, nfsProc3LookupResults.Status = localFS.dir(credential, nfsProc3LookupArgs.What.Dir, permExec)
+	if nil == dir {
+		return
+	}
+
+	nfsProc3LookupResults.DirAttributes = localFS.postOpAttr(dir.path)
+
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	path, nfsProc3LookupResults.Status = localFS.childPath(dir.path, nfsProc3LookupArgs.What.Name)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.Object, info, st, err = localFS.fHandleOf(path)
+	if nil != err {
+		nfsProc3LookupResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3LookupResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Access(credential *nfsd.CredentialStruct, nfsProc3AccessArgs *nfsd.NFSProc3AccessArgsStruct) (nfsProc3AccessResults *nfsd.NFSProc3AccessResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3AccessResults = &nfsd.NFSProc3AccessResultsStruct{}
+
+	object, nfsProc3AccessResults.Status = localFS.object(nfsProc3AccessArgs.Object)
+	if nfsd.OK != nfsProc3AccessResults.Status {
+		return
+	}
+
+	nfsProc3AccessResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(object.info, object.st)}
+	nfsProc3AccessResults.Access = accessOf(credential, object.info, object.st, nfsProc3AccessArgs.Access)
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3ReadLink(credential *nfsd.CredentialStruct, nfsProc3ReadLinkArgs *nfsd.NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *nfsd.NFSProc3ReadLinkResultsStruct) {
+	var (
+		err    error
+		object *objectStruct
+		target string
+	)
+
+	nfsProc3ReadLinkResults = &nfsd.NFSProc3ReadLinkResultsStruct{}
+
+	object, nfsProc3ReadLinkResults.Status = localFS.object(nfsProc3ReadLinkArgs.SymLink)
+	if nfsd.OK != nfsProc3ReadLinkResults.Status {
+		return
+	}
+
+	nfsProc3ReadLinkResults.SymLinkAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(object.info, object.st)}
+
+	if 0 == (object.info.Mode() & os.ModeSymlink) {
+		nfsProc3ReadLinkResults.Status = nfsd.NFS3ErrINVAL
+		return
+	}
+
+	target, err = readlinkBeneath(localFS.rootFD, object.path)
+	if nil != err {
+		nfsProc3ReadLinkResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3ReadLinkResults.Path = []byte(target)
+
+	return
+}
+
+// regularFile is like object but additionally requires that the object be a regular file (lest a FIFO or device
+// be opened) to which credential is granted want. As a client checks permissions upon open (rather than upon
+// each READ & WRITE), the owner is always granted access (e.g. to write a file just created with mode 0444).
+func (localFS *LocalFSStruct) regularFile(credential *nfsd.CredentialStruct, fHandle []byte, want uint32) (object *objectStruct, status uint32) {
+	object, status = localFS.object(fHandle)
+	if nfsd.OK != status {
+		return
+	}
+
+	if object.info.IsDir() {
+		status = nfsd.NFS3ErrISDIR
+	} else if !object.info.Mode().IsRegular() {
+		status = nfsd.NFS3ErrINVAL
+	} else if (credential.UID != object.st.uid) && !permitted(credential, object.info, object.st, want) {
+		status = nfsd.NFS3ErrACCES
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Read(credential *nfsd.CredentialStruct, nfsProc3ReadArgs *nfsd.NFSProc3ReadArgsStruct) (nfsProc3ReadResults *nfsd.NFSProc3ReadResultsStruct) {
+	var (
+		count  = nfsProc3ReadArgs.Count
+		err    error
+		file   *os.File
+		n      int
+		object *objectStruct
+	)
+
+	nfsProc3ReadResults = &nfsd.NFSProc3ReadResultsStruct{}
+
+	object, nfsProc3ReadResults.Status = localFS.regularFile(credential, nfsProc3ReadArgs.File, permRead)
+	if nil == object {
+		return
+	}
+
+	defer func() {
+		nfsProc3ReadResults.FileAttributes = localFS.postOpAttr(object.path)
+	}()
+
+	if nfsd.OK != nfsProc3ReadResults.Status {
+		return
+	}
+
+	file, err = openFileBeneath(localFS.rootFD, object.path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if nil != err {
+		nfsProc3ReadResults.Status = localFS.logStatus(err)
+		return
+	}
+	defer file.Close()
+
+	if nfsd.DefaultTransferMax < count {
+		count = nfsd.DefaultTransferMax
+	}
+
+	nfsProc3ReadResults.Data = make([]byte, count)
+
+	n, err = file.ReadAt(nfsProc3ReadResults.Data, int64(nfsProc3ReadArgs.Offset))
+	if (nil != err) && (io.EOF != err) {
+		nfsProc3ReadResults.Status = localFS.logStatus(err)
+		nfsProc3ReadResults.Data = nil
+		return
+	}
+
+	nfsProc3ReadResults.Data = nfsProc3ReadResults.Data[:n]
+	nfsProc3ReadResults.Count = uint32(n)
+	nfsProc3ReadResults.EOF = (io.EOF == err) || ((nfsProc3ReadArgs.Offset + uint64(n)) >= uint64(object.info.Size()))
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Write(credential *nfsd.CredentialStruct, nfsProc3WriteArgs *nfsd.NFSProc3WriteArgsStruct) (nfsProc3WriteResults *nfsd.NFSProc3WriteResultsStruct) {
+	var (
+		data   = nfsProc3WriteArgs.Data
+		err    error
+		file   *os.File
+		n      int
+		object *objectStruct
+	)
+
+	nfsProc3WriteResults = &nfsd.NFSProc3WriteResultsStruct{}
+
+	object, nfsProc3WriteResults.Status = localFS.regularFile(credential, nfsProc3WriteArgs.File, permWrite)
+	if nil == object {
+		return
+	}
+
+	defer func() {
+		nfsProc3WriteResults.FileWCC = localFS.wcc(object)
+	}()
+
+	if nfsd.OK != nfsProc3WriteResults.Status {
+		return
+	}
+
+	if uint32(len(data)) > nfsProc3WriteArgs.Count {
+		data = data[:nfsProc3WriteArgs.Count]
+	}
+
+	file, err = openFileBeneath(localFS.rootFD, object.path, syscall.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if nil != err {
+		nfsProc3WriteResults.Status = localFS.logStatus(err)
+		return
+	}
+	defer file.Close()
+
+	n, err = file.WriteAt(data, int64(nfsProc3WriteArgs.Offset))
+	if nil != err {
+		nfsProc3WriteResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	switch nfsProc3WriteArgs.Stable {
+	case nfsd.DataSync:
+		err = dataSync(file)
+	case nfsd.FileSync:
+		err = file.Sync()
+	}
+	if nil != err {
+		nfsProc3WriteResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3WriteResults.Count = uint32(n)
+	nfsProc3WriteResults.Committed = nfsProc3WriteArgs.Stable
+	nfsProc3WriteResults.Verf = localFS.writeVerf
+
+	return
+}
+
+// exclusiveTimes returns the access & modification times in which the verifier of an exclusive CREATE is recorded
+func exclusiveTimes(verf [nfsd.NFS3CreateVerfSize]byte) (atime time.Time, mtime time.Time) {
+	atime = time.Unix(int64(binary.BigEndian.Uint32(verf[0:4])), 0)
+	mtime = time.Unix(int64(binary.BigEndian.Uint32(verf[4:8])), 0)
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Create(credential *nfsd.CredentialStruct, nfsProc3CreateArgs *nfsd.NFSProc3CreateArgsStruct) (nfsProc3CreateResults *nfsd.NFSProc3CreateResultsStruct) {
+	var (
+		atime   time.Time
+		created bool
+		dir     *objectStruct
+		err     error
+		file    *os.File
+		flags   = syscall.O_WRONLY | syscall.O_CREAT | syscall.O_NONBLOCK
+		how     = &nfsProc3CreateArgs.How
+		info    os.FileInfo
+		mtime   time.Time
+		path    string
+		st      *statStruct
+	)
+
+	nfsProc3CreateResults = &nfsd.NFSProc3CreateResultsStruct{}
+
+	dir, nfsProc3CreateResults.Status = localFS.dir(credential, nfsProc3CreateArgs.Where.Dir, permWrite|permExec)
+	if nil == dir {
+		return
+	}
+
+	defer func() {
+		nfsProc3CreateResults.DirWCC = localFS.wcc(dir)
+	}()
+
+	if nfsd.OK != nfsProc3CreateResults.Status {
+		return
+	}
+
+	path, nfsProc3CreateResults.Status = localFS.newName(dir.path, nfsProc3CreateArgs.Where.Name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != nfsProc3CreateResults.Status {
+		return
+	}
+
+	if nfsd.Unchecked != how.Mode {
+		flags |= syscall.O_EXCL
+	}
+
+	info, st, err = localFS.lstat(path)
+	created = os.IsNotExist(err)
+
+	if (nil == err) && (nfsd.Unchecked == how.Mode) {
+		if !info.Mode().IsRegular() {
+			nfsProc3CreateResults.Status = nfsd.NFS3ErrEXIST
+			return
+		}
+		if how.ObjAttributes.SetSize && !permitted(credential, info, st, permWrite) {
+			nfsProc3CreateResults.Status = nfsd.NFS3ErrACCES
+			return
+		}
+	}
+
+	file, err = openFileBeneath(localFS.rootFD, path, flags, 0600)
+	if nil != err {
+		if (nfsd.Exclusive == how.Mode) && os.IsExist(err) {
+			// A retransmission of an exclusive CREATE that succeeded finds the verifier recorded in its times
+			info, st, err = localFS.lstat(path)
+			atime, mtime = exclusiveTimes(how.Verf)
+			if (nil == err) && info.Mode().IsRegular() && st.atime.Equal(atime) && info.ModTime().Equal(mtime) {
+				nfsProc3CreateResults.Obj.Handle, info, st, err = localFS.fHandleOf(path)
+				if nil == err {
+					nfsProc3CreateResults.Obj.HandleFollows = true
+					nfsProc3CreateResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+					return
+				}
+			}
+			err = os.ErrExist
+		}
+		nfsProc3CreateResults.Status = localFS.logStatus(err)
+		return
+	}
+	_ = file.Close()
+
+	if created {
+		if nfsd.Exclusive == how.Mode {
+			err = localFS.initialize(credential, dir, path, &nfsd.SAttr3Struct{}, defaultFileMode, false)
+			if nil == err {
+				atime, mtime = exclusiveTimes(how.Verf)
+				err = setTimesBeneath(localFS.rootFD, path, atime, mtime)
+			}
+		} else {
+			err = localFS.initialize(credential, dir, path, &how.ObjAttributes, defaultFileMode, false)
+		}
+		if nil != err {
+			_ = unlinkBeneath(localFS.rootFD, path, false)
+			nfsProc3CreateResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	if (nfsd.Exclusive != how.Mode) && how.ObjAttributes.SetSize {
+		err = truncateBeneath(localFS.rootFD, path, int64(how.ObjAttributes.Size))
+		if nil != err {
+			nfsProc3CreateResults.Status = localFS.logStatus(err)
+			return
+		}
+	}
+
+	nfsProc3CreateResults.Obj.Handle, info, st, err = localFS.fHandleOf(path)
+	if nil != err {
+		nfsProc3CreateResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3CreateResults.Obj.HandleFollows = true
+	nfsProc3CreateResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3MKDir(credential *nfsd.CredentialStruct, nfsProc3MKDirArgs *nfsd.NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *nfsd.NFSProc3MKDirResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info os.FileInfo
+		path string
+		st   *statStruct
+	)
+
+	nfsProc3MKDirResults = &nfsd.NFSProc3MKDirResultsStruct{}
+
+	dir, nfsProc3MKDirResults.Status = localFS.dir(credential, nfsProc3MKDirArgs.Where.Dir, permWrite|permExec)
+	if nil == dir {
+		return
+	}
+
+	defer func() {
+		nfsProc3MKDirResults.DirWCC = localFS.wcc(dir)
+	}()
+
+	if nfsd.OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	path, nfsProc3MKDirResults.Status = localFS.newName(dir.path, nfsProc3MKDirArgs.Where.Name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	err = mkdirBeneath(localFS.rootFD, path, 0700)
+	if nil != err {
+		nfsProc3MKDirResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	err = localFS.initialize(credential, dir, path, &nfsProc3MKDirArgs.Attributes, defaultDirMode, true)
+	if nil != err {
+		_ = unlinkBeneath(localFS.rootFD, path, true)
+		nfsProc3MKDirResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3MKDirResults.Obj.Handle, info, st, err = localFS.fHandleOf(path)
+	if nil != err {
+		nfsProc3MKDirResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3MKDirResults.Obj.HandleFollows = true
+	nfsProc3MKDirResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3SymLink(credential *nfsd.CredentialStruct, nfsProc3SymLinkArgs *nfsd.NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *nfsd.NFSProc3SymLinkResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info os.FileInfo
+		path string
+		st   *statStruct
+	)
+
+	nfsProc3SymLinkResults = &nfsd.NFSProc3SymLinkResultsStruct{}
+
+	dir, nfsProc3SymLinkResults.Status = localFS.dir(credential, nfsProc3SymLinkArgs.Where.Dir, permWrite|permExec)
+	if nil == dir {
+		return
+	}
+
+	defer func() {
+		nfsProc3SymLinkResults.DirWCC = localFS.wcc(dir)
+	}()
+
+	if nfsd.OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	path, nfsProc3SymLinkResults.Status = localFS.newName(dir.path, nfsProc3SymLinkArgs.Where.Name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	err = symlinkBeneath(localFS.rootFD, string(nfsProc3SymLinkArgs.SymLinkData), path)
+	if nil != err {
+		nfsProc3SymLinkResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	err = localFS.initialize(credential, dir, path, &nfsProc3SymLinkArgs.SymLinkAttributes, 0, false) // the mode of a symlink is immaterial
+	if nil != err {
+		_ = unlinkBeneath(localFS.rootFD, path, false)
+		nfsProc3SymLinkResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3SymLinkResults.Obj.Handle, info, st, err = localFS.fHandleOf(path)
+	if nil != err {
+		nfsProc3SymLinkResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3SymLinkResults.Obj.HandleFollows = true
+	nfsProc3SymLinkResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)}
+
+	return
+}
+
+// unlinkable returns the path of the entry name of dir (to which credential is granted write & execute
+// permission) should credential be permitted to remove it
+func (localFS *LocalFSStruct) unlinkable(credential *nfsd.CredentialStruct, dir *objectStruct, name string) (path string, info os.FileInfo, status uint32) {
+	var (
+		err error
+		st  *statStruct
+	)
+
+	path, status = localFS.newName(dir.path, name, nfsd.NFS3ErrINVAL)
+	if nfsd.OK != status {
+		return
+	}
+
+	info, st, err = localFS.lstat(path)
+	if nil != err {
+		status = localFS.logStatus(err)
+		return
+	}
+
+	if !mayUnlink(credential, dir.st, st) {
+		status = nfsd.NFS3ErrPERM
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Remove(credential *nfsd.CredentialStruct, nfsProc3RemoveArgs *nfsd.NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *nfsd.NFSProc3RemoveResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info os.FileInfo
+		path string
+	)
+
+	nfsProc3RemoveResults = &nfsd.NFSProc3RemoveResultsStruct{}
+
+	dir, nfsProc3RemoveResults.Status = localFS.dir(credential, nfsProc3RemoveArgs.Where.Dir, permWrite|permExec)
+	if nil == dir {
+		return
+	}
+
+	defer func() {
+		nfsProc3RemoveResults.DirWCC = localFS.wcc(dir)
+	}()
+
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	path, info, nfsProc3RemoveResults.Status = localFS.unlinkable(credential, dir, nfsProc3RemoveArgs.Where.Name)
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+	if info.IsDir() {
+		nfsProc3RemoveResults.Status = nfsd.NFS3ErrISDIR
+		return
+	}
+
+	err = unlinkBeneath(localFS.rootFD, path, false)
+	if nil != err {
+		nfsProc3RemoveResults.Status = localFS.logStatus(err)
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3RMDir(credential *nfsd.CredentialStruct, nfsProc3RMDirArgs *nfsd.NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *nfsd.NFSProc3RMDirResultsStruct) {
+	var (
+		dir  *objectStruct
+		err  error
+		info os.FileInfo
+		path string
+	)
+
+	nfsProc3RMDirResults = &nfsd.NFSProc3RMDirResultsStruct{}
+
+	dir, nfsProc3RMDirResults.Status = localFS.dir(credential, nfsProc3RMDirArgs.Where.Dir, permWrite|permExec)
+	if nil == dir {
+		return
+	}
+
+	defer func() {
+		nfsProc3RMDirResults.DirWCC = localFS.wcc(dir)
+	}()
+
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	path, info, nfsProc3RMDirResults.Status = localFS.unlinkable(credential, dir, nfsProc3RMDirArgs.Where.Name)
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+	if !info.IsDir() {
+		nfsProc3RMDirResults.Status = nfsd.NFS3ErrNOTDIR
+		return
+	}
+
+	err = unlinkBeneath(localFS.rootFD, path, true)
+	if nil != err {
+		nfsProc3RMDirResults.Status = localFS.logStatus(err)
+	}
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Rename(credential *nfsd.CredentialStruct, nfsProc3RenameArgs *nfsd.NFSProc3RenameArgsStruct) (nfsProc3RenameResults *nfsd.NFSProc3RenameResultsStruct) {
+	var (
+		err      error
+		fromDir  *objectStruct
+		fromInfo os.FileInfo
+		fromPath string
+		toDir    *objectStruct
+		toPath   string
+	)
+
+	nfsProc3RenameResults = &nfsd.NFSProc3RenameResultsStruct{}
+
+	fromDir, nfsProc3RenameResults.Status = localFS.dir(credential, nfsProc3RenameArgs.From.Dir, permWrite|permExec)
+	if nil == fromDir {
+		return
+	}
+
+	defer func() {
+		nfsProc3RenameResults.FromDirWCC = localFS.wcc(fromDir)
+		nfsProc3RenameResults.ToDirWCC = localFS.wcc(toDir)
+	}()
+
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	toDir, nfsProc3RenameResults.Status = localFS.dir(credential, nfsProc3RenameArgs.To.Dir, permWrite|permExec)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	fromPath, fromInfo, nfsProc3RenameResults.Status = localFS.unlinkable(credential, fromDir, nfsProc3RenameArgs.From.Name)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	toPath, _, nfsProc3RenameResults.Status = localFS.unlinkable(credential, toDir, nfsProc3RenameArgs.To.Name)
+	if nfsd.NFS3ErrNOENT == nfsProc3RenameResults.Status {
+		nfsProc3RenameResults.Status = nfsd.OK
+	}
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	err = renameBeneath(localFS.rootFD, fromPath, toPath)
+	if nil != err {
+		nfsProc3RenameResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	localFS.renamed(fromPath, toPath, fromInfo.IsDir())
+
+	return
+}
+
+// renamed updates the last known paths of the object renamed from fromPath to toPath (and, should it be a
+// directory, those of its descendants)
+func (localFS *LocalFSStruct) renamed(fromPath string, toPath string, isDir bool) {
+	var (
+		prefix = fromPath + string(filepath.Separator)
+	)
+
+	localFS.paths.Range(func(_ fileIDStruct, pathP *pathStruct) {
+		if pathP.path == fromPath {
+			pathP.path = toPath
+		} else if isDir && strings.HasPrefix(pathP.path, prefix) {
+			pathP.path = toPath + pathP.path[len(fromPath):]
+		}
+	})
+}
+
+func (localFS *LocalFSStruct) NFSProc3Link(credential *nfsd.CredentialStruct, nfsProc3LinkArgs *nfsd.NFSProc3LinkArgsStruct) (nfsProc3LinkResults *nfsd.NFSProc3LinkResultsStruct) {
+	var (
+		dir    *objectStruct
+		err    error
+		object *objectStruct
+		path   string
+	)
+
+	nfsProc3LinkResults = &nfsd.NFSProc3LinkResultsStruct{}
+
+	object, nfsProc3LinkResults.Status = localFS.object(nfsProc3LinkArgs.File)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	defer func() {
+		nfsProc3LinkResults.FileAttributes = localFS.postOpAttr(object.path)
+		nfsProc3LinkResults.LinkDirWCC = localFS.wcc(dir)
+	}()
+
+	if object.info.IsDir() {
+		nfsProc3LinkResults.Status = nfsd.NFS3ErrISDIR
+		return
+	}
+
+	dir, nfsProc3LinkResults.Status = localFS.dir(credential, nfsProc3LinkArgs.Link.Dir, permWrite|permExec)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	path, nfsProc3LinkResults.Status = localFS.newName(dir.path, nfsProc3LinkArgs.Link.Name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	err = linkBeneath(localFS.rootFD, object.path, path)
+	if nil != err {
+		nfsProc3LinkResults.Status = localFS.logStatus(err)
+	}
+
+	return
+}
+
+// dirEntries returns the entries of dir (including "." & "..") following cookie in cookie order (see
+// nfsd.NameCookieDirEntries)
+func (localFS *LocalFSStruct) dirEntries(dir *objectStruct, cookie uint64) (dirEntries []nfsd.DirEntryStruct, status uint32) {
+	var (
+		err   error
+		file  *os.File
+		names []string
+	)
+
+	file, err = openFileBeneath(localFS.rootFD, dir.path, syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+	if nil != err {
+		status = localFS.logStatus(err)
+		return
+	}
+	names, err = file.Readdirnames(-1)
+	_ = file.Close()
+	if nil != err {
+		status = localFS.logStatus(err)
+		return
+	}
+
+	dirEntries = nfsd.NameCookieDirEntries(names, cookie)
+
+	status = nfsd.OK
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3ReadDir(credential *nfsd.CredentialStruct, nfsProc3ReadDirArgs *nfsd.NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *nfsd.NFSProc3ReadDirResultsStruct) {
+	var (
+		dir        *objectStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirResults = &nfsd.NFSProc3ReadDirResultsStruct{}
+
+	dir, nfsProc3ReadDirResults.Status = localFS.dir(credential, nfsProc3ReadDirArgs.Dir, permRead)
+	if nil == dir {
+		return
+	}
+
+	nfsProc3ReadDirResults.DirAttributes = localFS.postOpAttr(dir.path)
+
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	dirEntries, nfsProc3ReadDirResults.Status = localFS.dirEntries(dir, nfsProc3ReadDirArgs.Cookie)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.AppendEntries(nfsProc3ReadDirArgs.Count, dirEntries, func(dirEntry nfsd.DirEntryStruct) (fileID uint64, ok bool) {
+		var (
+			err  error
+			path string
+			st   *statStruct
+		)
+
+		path, _ = localFS.childPath(dir.path, dirEntry.Name)
+		_, st, err = localFS.lstat(path)
+		if nil != err {
+			return // removed since the directory was read
+		}
+
+		fileID = st.ino
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3ReadDirPlus(credential *nfsd.CredentialStruct, nfsProc3ReadDirPlusArgs *nfsd.NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *nfsd.NFSProc3ReadDirPlusResultsStruct) {
+	var (
+		dir        *objectStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirPlusResults = &nfsd.NFSProc3ReadDirPlusResultsStruct{}
+
+	dir, nfsProc3ReadDirPlusResults.Status = localFS.dir(credential, nfsProc3ReadDirPlusArgs.Dir, permRead)
+	if nil == dir {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.DirAttributes = localFS.postOpAttr(dir.path)
+
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	dirEntries, nfsProc3ReadDirPlusResults.Status = localFS.dirEntries(dir, nfsProc3ReadDirPlusArgs.Cookie)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.AppendEntries(nfsProc3ReadDirPlusArgs.DirCount, nfsProc3ReadDirPlusArgs.MaxCount, dirEntries, func(dirEntry nfsd.DirEntryStruct) (dirListEntryPlus nfsd.DirListEntryPlusStruct, ok bool) {
+		var (
+			err     error
+			fHandle []byte
+			info    os.FileInfo
+			path    string
+			st      *statStruct
+		)
+
+		path, _ = localFS.childPath(dir.path, dirEntry.Name)
+		fHandle, info, st, err = localFS.fHandleOf(path)
+		if nil != err {
+			return // removed since the directory was read
+		}
+
+		dirListEntryPlus = nfsd.DirListEntryPlusStruct{
+			FileID:         st.ino,
+			NameAttributes: nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(info, st)},
+			NameHandle:     nfsd.PostOpFh3Struct{HandleFollows: true, Handle: fHandle},
+		}
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3FSStat(credential *nfsd.CredentialStruct, nfsProc3FSStatArgs *nfsd.NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *nfsd.NFSProc3FSStatResultsStruct) {
+	var (
+		err    error
+		object *objectStruct
+	)
+
+	nfsProc3FSStatResults = &nfsd.NFSProc3FSStatResultsStruct{}
+
+	object, nfsProc3FSStatResults.Status = localFS.object(nfsProc3FSStatArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSStatResults.Status {
+		return
+	}
+
+	nfsProc3FSStatResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(object.info, object.st)}
+
+	nfsProc3FSStatResults.TBytes, nfsProc3FSStatResults.FBytes, nfsProc3FSStatResults.ABytes, nfsProc3FSStatResults.TFiles, nfsProc3FSStatResults.FFiles, err = fsStatBeneath(localFS.rootFD, object.path)
+	if nil != err {
+		nfsProc3FSStatResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3FSStatResults.AFiles = nfsProc3FSStatResults.FFiles
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3FSInfo(credential *nfsd.CredentialStruct, nfsProc3FSInfoArgs *nfsd.NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *nfsd.NFSProc3FSInfoResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3FSInfoResults = &nfsd.NFSProc3FSInfoResultsStruct{}
+
+	object, nfsProc3FSInfoResults.Status = localFS.object(nfsProc3FSInfoArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSInfoResults.Status {
+		return
+	}
+
+	nfsProc3FSInfoResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(object.info, object.st)}
+	nfsProc3FSInfoResults.RTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.WTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.DTPref = nfsd.DefaultDirPref
+	nfsProc3FSInfoResults.MaxFileSize = uint64(1<<63 - 1)
+	nfsProc3FSInfoResults.TimeDelta = nfsd.NFSTime3Struct{Seconds: 0, NSeconds: 1}
+	nfsProc3FSInfoResults.Properties = nfsd.FSF3Link | nfsd.FSF3SymLink | nfsd.FSF3Homogeneous | nfsd.FSF3CanSetTime
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3PathConf(credential *nfsd.CredentialStruct, nfsProc3PathConfArgs *nfsd.NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *nfsd.NFSProc3PathConfResultsStruct) {
+	var (
+		object *objectStruct
+	)
+
+	nfsProc3PathConfResults = &nfsd.NFSProc3PathConfResultsStruct{}
+
+	object, nfsProc3PathConfResults.Status = localFS.object(nfsProc3PathConfArgs.Object)
+	if nfsd.OK != nfsProc3PathConfResults.Status {
+		return
+	}
+
+	nfsProc3PathConfResults.ObjAttributes = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: localFS.fAttr(object.info, object.st)}
+	nfsProc3PathConfResults.LinkMax = linkMax
+	nfsProc3PathConfResults.NameMax = nfsd.DefaultNameMax
+	nfsProc3PathConfResults.NoTrunc = true
+	nfsProc3PathConfResults.ChOwnRestricted = true
+	nfsProc3PathConfResults.CaseInsensitive = false
+	nfsProc3PathConfResults.CasePreserving = true
+
+	return
+}
+
+func (localFS *LocalFSStruct) NFSProc3Commit(credential *nfsd.CredentialStruct, nfsProc3CommitArgs *nfsd.NFSProc3CommitArgsStruct) (nfsProc3CommitResults *nfsd.NFSProc3CommitResultsStruct) {
+	var (
+		err    error
+		file   *os.File
+		object *objectStruct
+	)
+
+	nfsProc3CommitResults = &nfsd.NFSProc3CommitResultsStruct{}
+
+	object, nfsProc3CommitResults.Status = localFS.regularFile(credential, nfsProc3CommitArgs.File, 0)
+	if nil == object {
+		return
+	}
+
+	defer func() {
+		nfsProc3CommitResults.FileWCC = localFS.wcc(object)
+	}()
+
+	if nfsd.OK != nfsProc3CommitResults.Status {
+		return
+	}
+
+	file, err = openFileBeneath(localFS.rootFD, object.path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if nil != err {
+		nfsProc3CommitResults.Status = localFS.logStatus(err)
+		return
+	}
+	defer file.Close()
+
+	err = file.Sync()
+	if nil != err {
+		nfsProc3CommitResults.Status = localFS.logStatus(err)
+		return
+	}
+
+	nfsProc3CommitResults.Verf = localFS.writeVerf
+
+	return
+}
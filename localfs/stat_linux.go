@@ -0,0 +1,98 @@
+package localfs
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	atSymlinkNoFollow = 0x100 // AT_SYMLINK_NOFOLLOW
+
+	// fsIOCGetVersion is FS_IOC_GETVERSION (i.e. _IOR('v', 1, long)) reporting the generation of an inode
+	fsIOCGetVersion = uintptr(2<<30) | (unsafe.Sizeof(uintptr(0)) << 16) | uintptr('v'<<8) | 1
+)
+
+// statStruct holds the platform-specific portion of an os.FileInfo
+type statStruct struct {
+	dev       uint64
+	ino       uint64
+	nlink     uint64
+	mode      uint32 // permission bits (including setuid, setgid, & sticky)
+	uid       uint32
+	gid       uint32
+	rdevMajor uint32
+	rdevMinor uint32
+	blocks    int64 // in 512 byte units
+	atime     time.Time
+	ctime     time.Time
+}
+
+func statOf(info os.FileInfo) (st *statStruct, err error) {
+	var (
+		ok bool
+		sy *syscall.Stat_t
+	)
+
+	sy, ok = info.Sys().(*syscall.Stat_t)
+	if !ok {
+		err = fmt.Errorf("localfs: no syscall.Stat_t for %s", info.Name())
+		return
+	}
+
+	st = &statStruct{
+		dev:       uint64(sy.Dev),
+		ino:       uint64(sy.Ino),
+		nlink:     uint64(sy.Nlink),
+		mode:      uint32(sy.Mode) & 07777,
+		uid:       sy.Uid,
+		gid:       sy.Gid,
+		rdevMajor: uint32(((uint64(sy.Rdev) >> 8) & 0xfff) | ((uint64(sy.Rdev) >> 32) & ^uint64(0xfff))),
+		rdevMinor: uint32((uint64(sy.Rdev) & 0xff) | ((uint64(sy.Rdev) >> 12) & ^uint64(0xff))),
+		blocks:    int64(sy.Blocks),
+		atime:     time.Unix(int64(sy.Atim.Sec), int64(sy.Atim.Nsec)),
+		ctime:     time.Unix(int64(sy.Ctim.Sec), int64(sy.Ctim.Nsec)),
+	}
+
+	return
+}
+
+// generationOf returns the generation of the regular file or directory at path. Note that a generation of 0 is
+// valid (some filesystems, e.g. ext4, report 0 for objects never reused) whereas err is non-nil should the
+// filesystem not report generations (e.g. tmpfs). Other types of objects are not opened (lest doing so block or
+// have side effects) and report 0.
+func generationOf(rootFD int, path string, info os.FileInfo) (generation uint32, err error) {
+	var (
+		errno syscall.Errno
+		fd    int
+		value [2]uint32 // the kernel writes an int (despite the long in the ioctl number)
+	)
+
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		return
+	}
+
+	fd, err = openBeneath(rootFD, path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if nil != err {
+		return
+	}
+	defer syscall.Close(fd)
+
+	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), fsIOCGetVersion, uintptr(unsafe.Pointer(&value[0])))
+	if 0 != errno {
+		err = errno
+		return
+	}
+
+	generation = value[0]
+
+	return
+}
+
+// dataSync flushes the data (but not necessarily the metadata) of file to stable storage
+func dataSync(file *os.File) (err error) {
+	err = syscall.Fdatasync(int(file.Fd()))
+	return
+}
@@ -0,0 +1,39 @@
+//go:build !linux
+
+package localfs
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+// statStruct holds the platform-specific portion of an os.FileInfo (not available on this platform)
+type statStruct struct {
+	dev       uint64
+	ino       uint64
+	nlink     uint64
+	mode      uint32
+	uid       uint32
+	gid       uint32
+	rdevMajor uint32
+	rdevMinor uint32
+	blocks    int64
+	atime     time.Time
+	ctime     time.Time
+}
+
+func statOf(info os.FileInfo) (st *statStruct, err error) {
+	err = fmt.Errorf("localfs: not supported on %s", runtime.GOOS)
+	return
+}
+
+func generationOf(rootFD int, path string, info os.FileInfo) (generation uint32, err error) {
+	return
+}
+
+func dataSync(file *os.File) (err error) {
+	err = file.Sync()
+	return
+}
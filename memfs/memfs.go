@@ -0,0 +1,303 @@
+// Package memfs is a file system held entirely in memory served via package nfsd, intended for exercising NFS
+// clients (and this package) in tests without root privileges, kernel NFS support, or any state left behind.
+//
+// FileIDs are assigned sequentially (the root directory being FileID 1) and never reused, file handles are simply
+// the big-endian FileID, and the cookie verifier of each directory counts the modifications of its entries such
+// that, given the same sequence of requests (and ConfigStruct.Now), a MemFSStruct returns the same results.
+// Permissions are recorded but not enforced. Errors may be injected on chosen procedures via InjectError.
+package memfs
+
+import (
+	"encoding/binary"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+// Capacity of a MemFSStruct unless otherwise specified
+const (
+	DefaultMaxBytes = uint64(1 << 30)
+	DefaultMaxFiles = uint64(1 << 20)
+)
+
+const (
+	rootFileID  = uint64(1)
+	fHandleSize = 8
+	fsid        = uint64(0x6d656d6673) // "memfs"
+)
+
+type ConfigStruct struct { // the configuration of a MemFSStruct (see New)
+	MaxBytes uint64           // capacity for file data & symlink targets (if 0, DefaultMaxBytes)
+	MaxFiles uint64           // capacity for objects, including the root directory (if 0, DefaultMaxFiles)
+	Now      func() time.Time // if nil, time.Now; supplies the times recorded in attributes
+	ErrorLog func(err error)  // if nil, errors are reported via the log package
+}
+
+// MemFSStruct is an in-memory file system (see New)
+type MemFSStruct struct {
+	sync.Mutex                                // protects all of the following
+	config         ConfigStruct               // as supplied to New (with defaults applied)
+	inodes         map[uint64]*inodeStruct    // indexed by FileID
+	nextFileID     uint64                     //
+	bytesUsed      uint64                     // sum of len(inodeStruct.data)
+	injectedErrors map[uint32]*injectedStruct // indexed by NFSv3 procedure (e.g. nfsd.NFSPROC3WRITE)
+}
+
+type inodeStruct struct {
+	fileID    uint64
+	fType     uint32 // enum ftype3
+	mode      uint32
+	uid       uint32
+	gid       uint32
+	nLink     uint32
+	data      []byte            // contents of an FTypeREG or target of an FTypeLNK
+	entries   map[string]uint64 // only used/valid if fType == FTypeDIR; excludes "." & ".."
+	parent    uint64            // only used/valid if fType == FTypeDIR
+	version   uint64            // only used/valid if fType == FTypeDIR; incremented as entries is modified
+	exclusive bool              // set if created via an exclusive CREATE not yet followed by SETATTR
+	verf      [nfsd.NFS3CreateVerfSize]byte
+	atime     time.Time
+	mtime     time.Time
+	ctime     time.Time
+}
+
+type injectedStruct struct {
+	status    uint32
+	remaining int // if < 0, unlimited
+}
+
+// New constructs an empty MemFSStruct (i.e. holding only its root directory)
+//
+// Arguments:
+//
+//	config specifies the capacity & clock of the file system (config is copied; may be nil)
+//
+// Returns:
+//
+//	memFS is the MemFSStruct (to be supplied as both the MountV3Interface & NFSv3Interface callbacks)
+func New(config *ConfigStruct) (memFS *MemFSStruct) {
+	memFS = &MemFSStruct{
+		inodes:         make(map[uint64]*inodeStruct),
+		nextFileID:     rootFileID,
+		injectedErrors: make(map[uint32]*injectedStruct),
+	}
+
+	if nil != config {
+		memFS.config = *config
+	}
+	if 0 == memFS.config.MaxBytes {
+		memFS.config.MaxBytes = DefaultMaxBytes
+	}
+	if 0 == memFS.config.MaxFiles {
+		memFS.config.MaxFiles = DefaultMaxFiles
+	}
+	if nil == memFS.config.Now {
+		memFS.config.Now = time.Now
+	}
+	if nil == memFS.config.ErrorLog {
+		memFS.config.ErrorLog = func(err error) { log.Printf("memfs: %v", err) }
+	}
+
+	root := memFS.newInode(nfsd.FTypeDIR, 0777, 0, 0)
+	root.parent = root.fileID
+
+	return
+}
+
+// RootFHandle returns the file handle of the root directory (as returned by MNT of "/")
+func (memFS *MemFSStruct) RootFHandle() (fHandle []byte) {
+	fHandle = fHandleOf(rootFileID)
+	return
+}
+
+// InjectError causes invocations of an NFSv3 procedure to fail (without effect) with status. Typical statuses
+// are nfsd.NFS3ErrIO, nfsd.NFS3ErrJUKEBOX, & nfsd.NFS3ErrNOSPC.
+//
+// Arguments:
+//
+//	proc   specifies the procedure (e.g. nfsd.NFSPROC3WRITE)
+//	status specifies the nfsstat3 returned
+//	count  specifies the number of invocations to fail (if 0, all invocations until ClearErrors)
+func (memFS *MemFSStruct) InjectError(proc uint32, status uint32, count int) {
+	memFS.Lock()
+	if 0 == count {
+		count = -1
+	}
+	memFS.injectedErrors[proc] = &injectedStruct{status: status, remaining: count}
+	memFS.Unlock()
+}
+
+// ClearErrors reverts all procedures to succeeding (where possible)
+func (memFS *MemFSStruct) ClearErrors() {
+	memFS.Lock()
+	memFS.injectedErrors = make(map[uint32]*injectedStruct)
+	memFS.Unlock()
+}
+
+// injectedError returns the status with which an invocation of proc is to fail (or nfsd.OK). Must be called with
+// memFS locked.
+func (memFS *MemFSStruct) injectedError(proc uint32) (status uint32) {
+	var (
+		injected *injectedStruct
+		ok       bool
+	)
+
+	injected, ok = memFS.injectedErrors[proc]
+	if !ok {
+		status = nfsd.OK
+		return
+	}
+
+	status = injected.status
+
+	if 0 < injected.remaining {
+		injected.remaining--
+		if 0 == injected.remaining {
+			delete(memFS.injectedErrors, proc)
+		}
+	}
+
+	return
+}
+
+func fHandleOf(fileID uint64) (fHandle []byte) {
+	fHandle = make([]byte, fHandleSize)
+	binary.BigEndian.PutUint64(fHandle, fileID)
+	return
+}
+
+// inode returns the inode identified by fHandle. Must be called with memFS locked.
+func (memFS *MemFSStruct) inode(fHandle []byte) (inode *inodeStruct, status uint32) {
+	var (
+		ok bool
+	)
+
+	if fHandleSize != len(fHandle) {
+		status = nfsd.NFS3ErrBADHANDLE
+		return
+	}
+
+	inode, ok = memFS.inodes[binary.BigEndian.Uint64(fHandle)]
+	if !ok {
+		status = nfsd.NFS3ErrSTALE
+		return
+	}
+
+	status = nfsd.OK
+
+	return
+}
+
+// dir is like inode but additionally requires that the inode be a directory. Must be called with memFS locked.
+func (memFS *MemFSStruct) dir(fHandle []byte) (dir *inodeStruct, status uint32) {
+	dir, status = memFS.inode(fHandle)
+	if (nfsd.OK == status) && (nfsd.FTypeDIR != dir.fType) {
+		dir = nil
+		status = nfsd.NFS3ErrNOTDIR
+	}
+	return
+}
+
+// newInode allocates an inode (the caller having ensured there is capacity for it). Must be called with memFS locked.
+func (memFS *MemFSStruct) newInode(fType uint32, mode uint32, uid uint32, gid uint32) (inode *inodeStruct) {
+	var (
+		now = memFS.config.Now()
+	)
+
+	inode = &inodeStruct{
+		fileID: memFS.nextFileID,
+		fType:  fType,
+		mode:   mode & 07777,
+		uid:    uid,
+		gid:    gid,
+		nLink:  1,
+		atime:  now,
+		mtime:  now,
+		ctime:  now,
+	}
+
+	if nfsd.FTypeDIR == fType {
+		inode.nLink = 2
+		inode.entries = make(map[string]uint64)
+	}
+
+	memFS.inodes[inode.fileID] = inode
+	memFS.nextFileID++
+
+	return
+}
+
+// release discards inode once it is no longer linked. Must be called with memFS locked.
+func (memFS *MemFSStruct) release(inode *inodeStruct) {
+	if 0 == inode.nLink {
+		memFS.bytesUsed -= uint64(len(inode.data))
+		delete(memFS.inodes, inode.fileID)
+	}
+}
+
+// resize sets the length of inode.data should there be capacity to do so. Must be called with memFS locked.
+func (memFS *MemFSStruct) resize(inode *inodeStruct, size uint64) (status uint32) {
+	var (
+		oldSize = uint64(len(inode.data))
+	)
+
+	if (size > oldSize) && ((size - oldSize) > (memFS.config.MaxBytes - memFS.bytesUsed)) {
+		status = nfsd.NFS3ErrNOSPC
+		return
+	}
+
+	if size > oldSize {
+		inode.data = append(inode.data, make([]byte, size-oldSize)...)
+	} else {
+		inode.data = inode.data[:size]
+	}
+
+	memFS.bytesUsed = memFS.bytesUsed - oldSize + size
+
+	status = nfsd.OK
+
+	return
+}
+
+func (inode *inodeStruct) fAttr() (fAttr3 nfsd.FAttr3Struct) {
+	fAttr3 = nfsd.FAttr3Struct{
+		Type:   inode.fType,
+		Mode:   inode.mode,
+		NLink:  inode.nLink,
+		UID:    inode.uid,
+		GID:    inode.gid,
+		Size:   uint64(len(inode.data)),
+		Used:   uint64(len(inode.data)),
+		FSID:   fsid,
+		FileID: inode.fileID,
+		ATime:  nfsd.NFSTime(inode.atime),
+		MTime:  nfsd.NFSTime(inode.mtime),
+		CTime:  nfsd.NFSTime(inode.ctime),
+	}
+
+	if nfsd.FTypeDIR == inode.fType {
+		fAttr3.Size = 4096
+		fAttr3.Used = 4096
+	}
+
+	return
+}
+
+func (inode *inodeStruct) postOpAttr() (postOpAttr nfsd.PostOpAttrStruct) {
+	if nil != inode {
+		postOpAttr = nfsd.PostOpAttrStruct{AttributesFollow: true, Attributes: inode.fAttr()}
+	}
+	return
+}
+
+func (inode *inodeStruct) preOpAttr() (preOpAttr nfsd.PreOpAttrStruct) {
+	if nil != inode {
+		preOpAttr = nfsd.PreOpAttrStruct{
+			AttributesFollow: true,
+			Attributes:       nfsd.WCCAttrStruct{Size: inode.fAttr().Size, MTime: nfsd.NFSTime(inode.mtime), CTime: nfsd.NFSTime(inode.ctime)},
+		}
+	}
+	return
+}
@@ -0,0 +1,442 @@
+package memfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+var testCredential = &nfsd.CredentialStruct{Flavor: nfsd.AuthSys, UID: 1000, GID: 1000, GIDs: []uint32{}}
+
+func testNew(t *testing.T, config *ConfigStruct) (memFS *MemFSStruct) {
+	if nil == config {
+		config = &ConfigStruct{}
+	}
+	config.ErrorLog = func(err error) { t.Logf("ErrorLog(%v)", err) }
+	config.Now = func() time.Time { return time.Unix(1500000000, 0) }
+
+	memFS = New(config)
+
+	return
+}
+
+func testCreate(t *testing.T, memFS *MemFSStruct, dir []byte, name string) (fHandle []byte) {
+	createResults := memFS.NFSProc3Create(testCredential, &nfsd.NFSProc3CreateArgsStruct{
+		Where: nfsd.DirOpArgs3Struct{Dir: dir, Name: name},
+		How:   nfsd.CreateHowStruct{Mode: nfsd.Guarded},
+	})
+	if (nfsd.OK != createResults.Status) || !createResults.Obj.HandleFollows {
+		t.Fatalf("CREATE of %s returned %+v", name, createResults)
+	}
+
+	fHandle = createResults.Obj.Handle
+
+	return
+}
+
+func testReadDir(memFS *MemFSStruct, dir []byte, cookie uint64, cookieVerf [nfsd.NFS3CookieVerfSize]byte, count uint32) (readDirResults *nfsd.NFSProc3ReadDirResultsStruct) {
+	readDirResults = memFS.NFSProc3ReadDir(testCredential, &nfsd.NFSProc3ReadDirArgsStruct{Dir: dir, Cookie: cookie, CookieVerf: cookieVerf, Count: count})
+	return
+}
+
+func TestMemFS(t *testing.T) {
+	memFS := testNew(t, nil)
+	root := memFS.RootFHandle()
+
+	var (
+		_ nfsd.MountV3Interface = memFS
+		_ nfsd.NFSv3Interface   = memFS
+	)
+
+	mntResults := memFS.MountProc3Mnt(testCredential, &nfsd.MountProc3MntArgsStruct{DirPath: "/"})
+	if (nfsd.OK != mntResults.Status) || !bytes.Equal(root, mntResults.FHandle) {
+		t.Fatalf("MNT of / returned %+v", mntResults)
+	}
+
+	getAttrResults := memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: root})
+	if (nfsd.OK != getAttrResults.Status) || (nfsd.FTypeDIR != getAttrResults.Attributes.Type) || (1 != getAttrResults.Attributes.FileID) {
+		t.Fatalf("GETATTR of the root directory returned %+v", getAttrResults)
+	}
+
+	file := testCreate(t, memFS, root, "file")
+
+	writeResults := memFS.NFSProc3Write(testCredential, &nfsd.NFSProc3WriteArgsStruct{File: file, Offset: 2, Count: 5, Data: []byte("hello")})
+	if (nfsd.OK != writeResults.Status) || (5 != writeResults.Count) || (nfsd.FileSync != writeResults.Committed) || (7 != writeResults.FileWCC.After.Attributes.Size) {
+		t.Fatalf("WRITE returned %+v", writeResults)
+	}
+
+	readResults := memFS.NFSProc3Read(testCredential, &nfsd.NFSProc3ReadArgsStruct{File: file, Offset: 0, Count: 100})
+	if (nfsd.OK != readResults.Status) || !readResults.EOF || !bytes.Equal([]byte("\x00\x00hello"), readResults.Data) {
+		t.Fatalf("READ returned %+v", readResults)
+	}
+
+	lookupResults := memFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}})
+	if (nfsd.OK != lookupResults.Status) || !bytes.Equal(file, lookupResults.Object) || (2 != lookupResults.ObjAttributes.Attributes.FileID) || (testCredential.UID != lookupResults.ObjAttributes.Attributes.UID) {
+		t.Fatalf("LOOKUP of file returned %+v", lookupResults)
+	}
+
+	createResults := memFS.NFSProc3Create(testCredential, &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}, How: nfsd.CreateHowStruct{Mode: nfsd.Guarded}})
+	if nfsd.NFS3ErrEXIST != createResults.Status {
+		t.Fatalf("guarded CREATE of an existing file returned %v... expected NFS3ErrEXIST", createResults.Status)
+	}
+
+	exclusiveArgs := &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "exclusive"}, How: nfsd.CreateHowStruct{Mode: nfsd.Exclusive, Verf: [nfsd.NFS3CreateVerfSize]byte{1, 2, 3}}}
+	createResults = memFS.NFSProc3Create(testCredential, exclusiveArgs)
+	if nfsd.OK != createResults.Status {
+		t.Fatalf("exclusive CREATE returned %+v", createResults)
+	}
+	exclusive := createResults.Obj.Handle
+	createResults = memFS.NFSProc3Create(testCredential, exclusiveArgs)
+	if (nfsd.OK != createResults.Status) || !bytes.Equal(exclusive, createResults.Obj.Handle) {
+		t.Fatalf("retransmitted exclusive CREATE returned %+v", createResults)
+	}
+	exclusiveArgs.How.Verf[0]++
+	if nfsd.NFS3ErrEXIST != memFS.NFSProc3Create(testCredential, exclusiveArgs).Status {
+		t.Fatalf("exclusive CREATE bearing a different verifier should have failed")
+	}
+
+	mkDirResults := memFS.NFSProc3MKDir(testCredential, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}})
+	if (nfsd.OK != mkDirResults.Status) || (3 != mkDirResults.DirWCC.After.Attributes.NLink) {
+		t.Fatalf("MKDIR returned %+v", mkDirResults)
+	}
+	dir := mkDirResults.Obj.Handle
+
+	mntResults = memFS.MountProc3Mnt(testCredential, &nfsd.MountProc3MntArgsStruct{DirPath: "/dir"})
+	if (nfsd.OK != mntResults.Status) || !bytes.Equal(dir, mntResults.FHandle) {
+		t.Fatalf("MNT of /dir returned %+v", mntResults)
+	}
+	if nfsd.MNT3ErrNOTDIR != memFS.MountProc3Mnt(testCredential, &nfsd.MountProc3MntArgsStruct{DirPath: "/file"}).Status {
+		t.Fatalf("MNT of a file should have failed")
+	}
+
+	symLinkResults := memFS.NFSProc3SymLink(testCredential, &nfsd.NFSProc3SymLinkArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: dir, Name: "symlink"}, SymLinkData: []byte("../file")})
+	if nfsd.OK != symLinkResults.Status {
+		t.Fatalf("SYMLINK returned %+v", symLinkResults)
+	}
+	readLinkResults := memFS.NFSProc3ReadLink(testCredential, &nfsd.NFSProc3ReadLinkArgsStruct{SymLink: symLinkResults.Obj.Handle})
+	if (nfsd.OK != readLinkResults.Status) || ("../file" != string(readLinkResults.Path)) {
+		t.Fatalf("READLINK returned %+v", readLinkResults)
+	}
+
+	linkResults := memFS.NFSProc3Link(testCredential, &nfsd.NFSProc3LinkArgsStruct{File: file, Link: nfsd.DirOpArgs3Struct{Dir: dir, Name: "link"}})
+	if (nfsd.OK != linkResults.Status) || (2 != linkResults.FileAttributes.Attributes.NLink) {
+		t.Fatalf("LINK returned %+v", linkResults)
+	}
+
+	if nfsd.NFS3ErrINVAL != memFS.NFSProc3Rename(testCredential, &nfsd.NFSProc3RenameArgsStruct{From: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}, To: nfsd.DirOpArgs3Struct{Dir: dir, Name: "sub"}}).Status {
+		t.Fatalf("RENAME of a directory beneath itself should have failed")
+	}
+	if nfsd.NFS3ErrISDIR != memFS.NFSProc3Rename(testCredential, &nfsd.NFSProc3RenameArgsStruct{From: nfsd.DirOpArgs3Struct{Dir: root, Name: "exclusive"}, To: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("RENAME of a file over a directory should have failed")
+	}
+	renameResults := memFS.NFSProc3Rename(testCredential, &nfsd.NFSProc3RenameArgsStruct{From: nfsd.DirOpArgs3Struct{Dir: root, Name: "exclusive"}, To: nfsd.DirOpArgs3Struct{Dir: dir, Name: "link"}})
+	if nfsd.OK != renameResults.Status {
+		t.Fatalf("RENAME over a link returned %+v", renameResults)
+	}
+	if 1 != memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: file}).Attributes.NLink {
+		t.Fatalf("RENAME over a link should have decremented the link count of its target")
+	}
+
+	if nfsd.NFS3ErrNOTEMPTY != memFS.NFSProc3RMDir(testCredential, &nfsd.NFSProc3RMDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("RMDIR of a non-empty directory should have failed")
+	}
+	if nfsd.NFS3ErrISDIR != memFS.NFSProc3Remove(testCredential, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("REMOVE of a directory should have failed")
+	}
+	for _, name := range []string{"symlink", "link"} {
+		if nfsd.OK != memFS.NFSProc3Remove(testCredential, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: dir, Name: name}}).Status {
+			t.Fatalf("REMOVE of %s failed", name)
+		}
+	}
+	rmDirResults := memFS.NFSProc3RMDir(testCredential, &nfsd.NFSProc3RMDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}})
+	if (nfsd.OK != rmDirResults.Status) || (2 != rmDirResults.DirWCC.After.Attributes.NLink) {
+		t.Fatalf("RMDIR returned %+v", rmDirResults)
+	}
+
+	if nfsd.NFS3ErrSTALE != memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: dir}).Status {
+		t.Fatalf("GETATTR of a removed directory should have returned NFS3ErrSTALE")
+	}
+	if nfsd.NFS3ErrSTALE != memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: exclusive}).Status {
+		t.Fatalf("GETATTR of a file renamed over should have returned NFS3ErrSTALE")
+	}
+	if nfsd.NFS3ErrBADHANDLE != memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: []byte{1}}).Status {
+		t.Fatalf("GETATTR of a malformed handle should have returned NFS3ErrBADHANDLE")
+	}
+
+	if nfsd.NFS3ErrNOENT != memFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: "fresh"}}).Status {
+		t.Fatalf("LOOKUP of a missing name should have returned NFS3ErrNOENT")
+	}
+	if 6 != binary.BigEndian.Uint64(testCreate(t, memFS, root, "fresh")) {
+		t.Fatalf("FileIDs should be assigned sequentially (and never reused)")
+	}
+}
+
+func TestMemFSCapacity(t *testing.T) {
+	memFS := testNew(t, &ConfigStruct{MaxBytes: 10, MaxFiles: 3})
+	root := memFS.RootFHandle()
+
+	file := testCreate(t, memFS, root, "file")
+
+	if nfsd.OK != memFS.NFSProc3Write(testCredential, &nfsd.NFSProc3WriteArgsStruct{File: file, Count: 8, Data: make([]byte, 8)}).Status {
+		t.Fatalf("WRITE within capacity failed")
+	}
+	if nfsd.NFS3ErrNOSPC != memFS.NFSProc3Write(testCredential, &nfsd.NFSProc3WriteArgsStruct{File: file, Offset: 8, Count: 4, Data: make([]byte, 4)}).Status {
+		t.Fatalf("WRITE beyond MaxBytes should have returned NFS3ErrNOSPC")
+	}
+	if nfsd.NFS3ErrNOSPC != memFS.NFSProc3SymLink(testCredential, &nfsd.NFSProc3SymLinkArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "symlink"}, SymLinkData: []byte("target")}).Status {
+		t.Fatalf("SYMLINK beyond MaxBytes should have returned NFS3ErrNOSPC")
+	}
+
+	fsStatResults := memFS.NFSProc3FSStat(testCredential, &nfsd.NFSProc3FSStatArgsStruct{FSRoot: root})
+	if (nfsd.OK != fsStatResults.Status) ||
+		(10 != fsStatResults.TBytes) || (2 != fsStatResults.FBytes) || (2 != fsStatResults.ABytes) ||
+		(3 != fsStatResults.TFiles) || (1 != fsStatResults.FFiles) || (1 != fsStatResults.AFiles) {
+		t.Fatalf("FSSTAT returned %+v", fsStatResults)
+	}
+
+	_ = testCreate(t, memFS, root, "other")
+
+	if nfsd.NFS3ErrNOSPC != memFS.NFSProc3MKDir(testCredential, &nfsd.NFSProc3MKDirArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "dir"}}).Status {
+		t.Fatalf("MKDIR beyond MaxFiles should have returned NFS3ErrNOSPC")
+	}
+
+	if nfsd.OK != memFS.NFSProc3Remove(testCredential, &nfsd.NFSProc3RemoveArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}}).Status {
+		t.Fatalf("REMOVE failed")
+	}
+
+	fsStatResults = memFS.NFSProc3FSStat(testCredential, &nfsd.NFSProc3FSStatArgsStruct{FSRoot: root})
+	if (10 != fsStatResults.FBytes) || (1 != fsStatResults.FFiles) {
+		t.Fatalf("FSSTAT following REMOVE returned %+v", fsStatResults)
+	}
+}
+
+func TestMemFSInjectError(t *testing.T) {
+	memFS := testNew(t, nil)
+	root := memFS.RootFHandle()
+
+	memFS.InjectError(nfsd.NFSPROC3GETATTR, nfsd.NFS3ErrJUKEBOX, 2)
+	memFS.InjectError(nfsd.NFSPROC3CREATE, nfsd.NFS3ErrIO, 0)
+
+	for _, expected := range []uint32{nfsd.NFS3ErrJUKEBOX, nfsd.NFS3ErrJUKEBOX, nfsd.OK} {
+		if expected != memFS.NFSProc3GetAttr(testCredential, &nfsd.NFSProc3GetAttrArgsStruct{Object: root}).Status {
+			t.Fatalf("GETATTR should have returned %v", expected)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		createResults := memFS.NFSProc3Create(testCredential, &nfsd.NFSProc3CreateArgsStruct{Where: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}})
+		if (nfsd.NFS3ErrIO != createResults.Status) || createResults.DirWCC.After.AttributesFollow {
+			t.Fatalf("CREATE should have returned NFS3ErrIO (without attributes)... got %+v", createResults)
+		}
+	}
+	if nfsd.NFS3ErrNOENT != memFS.NFSProc3Lookup(testCredential, &nfsd.NFSProc3LookupArgsStruct{What: nfsd.DirOpArgs3Struct{Dir: root, Name: "file"}}).Status {
+		t.Fatalf("failed CREATE should not have created file")
+	}
+
+	memFS.ClearErrors()
+
+	_ = testCreate(t, memFS, root, "file")
+}
+
+func TestMemFSCookieVerf(t *testing.T) {
+	memFS := testNew(t, nil)
+	root := memFS.RootFHandle()
+
+	for _, name := range []string{"c", "a", "b"} {
+		_ = testCreate(t, memFS, root, name)
+	}
+
+	var names []string
+
+	// A count of 180 holds the READDIR3resok header & two entries such that the listing spans several replies
+
+	readDirResults := testReadDir(memFS, root, 0, [nfsd.NFS3CookieVerfSize]byte{}, 180)
+	for {
+		if nfsd.OK != readDirResults.Status {
+			t.Fatalf("READDIR returned %+v", readDirResults)
+		}
+		for _, entry := range readDirResults.Entries {
+			names = append(names, entry.Name)
+		}
+		if readDirResults.EOF {
+			break
+		}
+		readDirResults = testReadDir(memFS, root, readDirResults.Entries[len(readDirResults.Entries)-1].Cookie, readDirResults.CookieVerf, 180)
+	}
+
+	if "[. .. a b c]" != fmt.Sprint(names) {
+		t.Fatalf("READDIR returned %v", names)
+	}
+
+	readDirResults = testReadDir(memFS, root, 0, [nfsd.NFS3CookieVerfSize]byte{}, 180)
+	cookie := readDirResults.Entries[len(readDirResults.Entries)-1].Cookie
+	cookieVerf := readDirResults.CookieVerf
+
+	if again := testReadDir(memFS, root, 0, [nfsd.NFS3CookieVerfSize]byte{}, 180); again.CookieVerf != cookieVerf {
+		t.Fatalf("cookie verifier of an unmodified directory should not change")
+	}
+
+	_ = testCreate(t, memFS, root, "d")
+
+	if nfsd.NFS3ErrBADCOOKIE != testReadDir(memFS, root, cookie, cookieVerf, 180).Status {
+		t.Fatalf("READDIR of a modified directory bearing the prior cookie verifier should have returned NFS3ErrBADCOOKIE")
+	}
+	if nfsd.NFS3ErrTOOSMALL != testReadDir(memFS, root, 0, [nfsd.NFS3CookieVerfSize]byte{}, 8).Status {
+		t.Fatalf("READDIR unable to return a single entry should have returned NFS3ErrTOOSMALL")
+	}
+}
+
+// testXDROpaque appends the XDR encoding of variable-length opaque data (or a string) to buf
+func testXDROpaque(buf []byte, data []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	return append(buf, make([]byte, (4-(len(data)%4))%4)...)
+}
+
+// testCall sends an NFSv3 call (via AUTH_SYS as testCredential) over conn returning the results of its reply
+func testCall(t *testing.T, conn net.Conn, xid uint32, proc uint32, args []byte) (results []byte) {
+	var (
+		credBody []byte
+		msg      []byte
+		offset   uint32
+		reply    []byte
+	)
+
+	credBody = binary.BigEndian.AppendUint32(credBody, 0)
+	credBody = testXDROpaque(credBody, []byte("memfs"))
+	credBody = binary.BigEndian.AppendUint32(credBody, testCredential.UID)
+	credBody = binary.BigEndian.AppendUint32(credBody, testCredential.GID)
+	credBody = binary.BigEndian.AppendUint32(credBody, 0)
+
+	for _, u32 := range []uint32{0, xid, 0, 2, 100003, 3, proc, nfsd.AuthSys} { // leading 0 is the record mark
+		msg = binary.BigEndian.AppendUint32(msg, u32)
+	}
+	msg = testXDROpaque(msg, credBody)
+	msg = binary.BigEndian.AppendUint32(msg, nfsd.AuthNone)
+	msg = testXDROpaque(msg, nil)
+	msg = append(msg, args...)
+	binary.BigEndian.PutUint32(msg, 0x80000000|uint32(len(msg)-4))
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err := conn.Write(msg)
+	if nil != err {
+		t.Fatalf("conn.Write() failed: %v", err)
+	}
+
+	recordMark := make([]byte, 4)
+	_, err = io.ReadFull(conn, recordMark)
+	if nil != err {
+		t.Fatalf("reading record mark failed: %v", err)
+	}
+	reply = make([]byte, binary.BigEndian.Uint32(recordMark)&0x7FFFFFFF)
+	_, err = io.ReadFull(conn, reply)
+	if nil != err {
+		t.Fatalf("reading reply failed: %v", err)
+	}
+
+	// xid, REPLY, MSG_ACCEPTED, & verifier (flavor & body) precede accept_stat
+	if (20 > len(reply)) || (xid != binary.BigEndian.Uint32(reply[0:4])) || (1 != binary.BigEndian.Uint32(reply[4:8])) || (0 != binary.BigEndian.Uint32(reply[8:12])) {
+		t.Fatalf("malformed reply to proc %d: %v", proc, reply)
+	}
+	offset = 16 + ((binary.BigEndian.Uint32(reply[12:16]) + 3) & ^uint32(3))
+	if 0 != binary.BigEndian.Uint32(reply[offset+4:offset+8]) {
+		t.Fatalf("reply to proc %d not successful: %v", proc, reply)
+	}
+
+	results = reply[offset+8:]
+
+	return
+}
+
+func TestMemFSServer(t *testing.T) {
+	memFS := testNew(t, nil)
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	nfsPort := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	server, err := nfsd.NewServer(&nfsd.ServerConfigStruct{
+		Listeners:      []nfsd.ListenerConfigStruct{{Network: "tcp4", BindAddr: "127.0.0.1", NFSPort: uint16(nfsPort)}},
+		MountCallbacks: memFS,
+		NFSCallbacks:   memFS,
+	})
+	if nil != err {
+		t.Fatalf("nfsd.NewServer() failed: %v", err)
+	}
+
+	go func() {
+		_ = server.Serve(context.Background())
+	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+	}()
+
+	var conn net.Conn
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		conn, err = net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(nfsPort)))
+		if nil == err {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("net.Dial() failed: %v", err)
+		}
+	}
+	defer conn.Close()
+
+	root := testXDROpaque(nil, memFS.RootFHandle())
+
+	// GETATTR3resok is status followed by fattr3 (whose fileid follows type, mode, nlink, uid, gid, size, used, rdev, & fsid)
+	results := testCall(t, conn, 1, nfsd.NFSPROC3GETATTR, root)
+	if (88 != len(results)) || (nfsd.OK != binary.BigEndian.Uint32(results)) || (nfsd.FTypeDIR != binary.BigEndian.Uint32(results[4:])) || (1 != binary.BigEndian.Uint64(results[56:])) {
+		t.Fatalf("GETATTR of the root directory returned %v", results)
+	}
+
+	// CREATE (UNCHECKED with an empty sattr3) returns status & post_op_fh3 ahead of the attributes
+	args := testXDROpaque(append([]byte{}, root...), []byte("file"))
+	args = append(args, make([]byte, 4+6*4)...)
+	results = testCall(t, conn, 2, nfsd.NFSPROC3CREATE, args)
+	if (16 > len(results)) || (nfsd.OK != binary.BigEndian.Uint32(results)) || (1 != binary.BigEndian.Uint32(results[4:])) || (fHandleSize != binary.BigEndian.Uint32(results[8:])) {
+		t.Fatalf("CREATE returned %v", results)
+	}
+	file := testXDROpaque(nil, results[12:12+fHandleSize])
+
+	args = binary.BigEndian.AppendUint64(append([]byte{}, file...), 0)
+	args = binary.BigEndian.AppendUint32(args, 5)
+	args = binary.BigEndian.AppendUint32(args, nfsd.FileSync)
+	args = testXDROpaque(args, []byte("hello"))
+	results = testCall(t, conn, 3, nfsd.NFSPROC3WRITE, args)
+	if nfsd.OK != binary.BigEndian.Uint32(results) {
+		t.Fatalf("WRITE returned %v", results)
+	}
+
+	// READ3resok is status, post_op_attr, count, eof, & data
+	args = binary.BigEndian.AppendUint64(append([]byte{}, file...), 0)
+	args = binary.BigEndian.AppendUint32(args, 100)
+	results = testCall(t, conn, 4, nfsd.NFSPROC3READ, args)
+	if (104 > len(results)) || (nfsd.OK != binary.BigEndian.Uint32(results)) || (5 != binary.BigEndian.Uint32(results[92:])) || (1 != binary.BigEndian.Uint32(results[96:])) || ("hello" != string(results[104:109])) {
+		t.Fatalf("READ returned %v", results)
+	}
+
+	memFS.InjectError(nfsd.NFSPROC3GETATTR, nfsd.NFS3ErrJUKEBOX, 1)
+
+	results = testCall(t, conn, 5, nfsd.NFSPROC3GETATTR, root)
+	if (4 != len(results)) || (nfsd.NFS3ErrJUKEBOX != binary.BigEndian.Uint32(results)) {
+		t.Fatalf("GETATTR bearing an injected error returned %v", results)
+	}
+	results = testCall(t, conn, 6, nfsd.NFSPROC3GETATTR, root)
+	if nfsd.OK != binary.BigEndian.Uint32(results) {
+		t.Fatalf("GETATTR following an injected error returned %v", results)
+	}
+}
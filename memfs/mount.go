@@ -0,0 +1,63 @@
+package memfs
+
+import (
+	"strings"
+
+	"github.com/swiftstack/nfsd"
+)
+
+func (memFS *MemFSStruct) MountProc3Null(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Mnt returns the file handle of the directory at DirPath (interpreted relative to the root directory)
+func (memFS *MemFSStruct) MountProc3Mnt(credential *nfsd.CredentialStruct, mountProc3MntArgs *nfsd.MountProc3MntArgsStruct) (mountProc3MntResults *nfsd.MountProc3MntResultsStruct) {
+	var (
+		component string
+		dir       *inodeStruct
+		status    uint32
+	)
+
+	mountProc3MntResults = &nfsd.MountProc3MntResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	dir = memFS.inodes[rootFileID]
+
+	for _, component = range strings.Split(mountProc3MntArgs.DirPath, "/") {
+		if "" == component {
+			continue
+		}
+		dir, status = memFS.lookup(dir, component)
+		if nfsd.OK != status {
+			mountProc3MntResults.Status = nfsd.MNT3ErrNOENT
+			return
+		}
+		if nfsd.FTypeDIR != dir.fType {
+			mountProc3MntResults.Status = nfsd.MNT3ErrNOTDIR
+			return
+		}
+	}
+
+	mountProc3MntResults.Status = nfsd.OK
+	mountProc3MntResults.FHandle = fHandleOf(dir.fileID)
+	mountProc3MntResults.AuthFlavors = []uint32{nfsd.AuthSys, nfsd.AuthNone}
+
+	return
+}
+
+// MountProc3Dump returns an empty list as mounts are not tracked by MemFSStruct (see nfsd.EnableMountTable)
+func (memFS *MemFSStruct) MountProc3Dump(credential *nfsd.CredentialStruct) (mountProc3DumpResults *nfsd.MountProc3DumpResultsStruct) {
+	mountProc3DumpResults = &nfsd.MountProc3DumpResultsStruct{MountList: []nfsd.MountBodyStruct{}}
+	return
+}
+
+func (memFS *MemFSStruct) MountProc3Umnt(credential *nfsd.CredentialStruct, mountProc3UmntArgs *nfsd.MountProc3UmntArgsStruct) {
+}
+
+func (memFS *MemFSStruct) MountProc3UmntAll(credential *nfsd.CredentialStruct) {}
+
+// MountProc3Export returns "/" (i.e. the root directory) as exported to all clients
+func (memFS *MemFSStruct) MountProc3Export(credential *nfsd.CredentialStruct) (mountProc3ExportResults *nfsd.MountProc3ExportResultsStruct) {
+	mountProc3ExportResults = &nfsd.MountProc3ExportResultsStruct{Exports: []nfsd.ExportNodeStruct{{Dir: "/", Groups: []string{}}}}
+	return
+}
@@ -0,0 +1,1178 @@
+package memfs
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swiftstack/nfsd"
+)
+
+// linkMax is the LinkMax with which PATHCONF is answered (beyond which LINK fails with NFS3ErrMLINK)
+const linkMax = uint32(65000)
+
+// Cookies of the "." & ".." entries of each directory (those of other entries follow in name order)
+const (
+	cookieDot    = uint64(1)
+	cookieDotDot = uint64(2)
+)
+
+// Modes of objects created without SAttr3Struct.SetMode
+const (
+	defaultFileMode    = uint32(0644)
+	defaultDirMode     = uint32(0755)
+	defaultSymLinkMode = uint32(0777)
+)
+
+// writeVerf is returned by every WRITE & COMMIT. As all WRITEs are FileSync (and the contents of a MemFSStruct
+// do not outlive it), it need never change.
+var writeVerf = [nfsd.NFS3WriteVerfSize]byte{'m', 'e', 'm', 'f', 's'}
+
+// checkName validates the name of an entry to be created or removed (rejecting "." & ".." with dotStatus)
+func checkName(name string, dotStatus uint32) (status uint32) {
+	switch {
+	case ("" == name) || strings.ContainsAny(name, "/\x00"):
+		status = nfsd.NFS3ErrINVAL
+	case nfsd.DefaultNameMax < uint32(len(name)):
+		status = nfsd.NFS3ErrNAMETOOLONG
+	case ("." == name) || (".." == name):
+		status = dotStatus
+	default:
+		status = nfsd.OK
+	}
+	return
+}
+
+// lookup returns the inode named within dir (including "." & ".."). Must be called with memFS locked.
+func (memFS *MemFSStruct) lookup(dir *inodeStruct, name string) (inode *inodeStruct, status uint32) {
+	var (
+		fileID uint64
+		ok     bool
+	)
+
+	switch name {
+	case ".":
+		fileID = dir.fileID
+	case "..":
+		fileID = dir.parent
+	default:
+		status = checkName(name, nfsd.NFS3ErrINVAL)
+		if nfsd.OK != status {
+			return
+		}
+		fileID, ok = dir.entries[name]
+		if !ok {
+			status = nfsd.NFS3ErrNOENT
+			return
+		}
+	}
+
+	inode = memFS.inodes[fileID]
+	status = nfsd.OK
+
+	return
+}
+
+// modified records a change to the entries of dir (invalidating the cookies previously returned for it)
+func (memFS *MemFSStruct) modified(dir *inodeStruct) {
+	var (
+		now = memFS.config.Now()
+	)
+
+	dir.version++
+	dir.mtime = now
+	dir.ctime = now
+}
+
+// link adds an entry named name for inode to dir. Must be called with memFS locked.
+func (memFS *MemFSStruct) link(dir *inodeStruct, name string, inode *inodeStruct) {
+	dir.entries[name] = inode.fileID
+	if nfsd.FTypeDIR == inode.fType {
+		dir.nLink++
+		inode.parent = dir.fileID
+	}
+	memFS.modified(dir)
+}
+
+// unlink removes the entry named name (for inode) from dir. The caller adjusts the link count of inode. Must be
+// called with memFS locked.
+func (memFS *MemFSStruct) unlink(dir *inodeStruct, name string, inode *inodeStruct) {
+	delete(dir.entries, name)
+	if nfsd.FTypeDIR == inode.fType {
+		dir.nLink--
+	}
+	inode.ctime = memFS.config.Now()
+	memFS.modified(dir)
+}
+
+// create adds a new object named name to dir owned by credential. Must be called with memFS locked.
+func (memFS *MemFSStruct) create(credential *nfsd.CredentialStruct, dir *inodeStruct, name string, fType uint32, sAttr3 *nfsd.SAttr3Struct, defaultMode uint32) (inode *inodeStruct, status uint32) {
+	var (
+		ok bool
+	)
+
+	status = checkName(name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != status {
+		return
+	}
+
+	_, ok = dir.entries[name]
+	if ok {
+		status = nfsd.NFS3ErrEXIST
+		return
+	}
+
+	if uint64(len(memFS.inodes)) >= memFS.config.MaxFiles {
+		status = nfsd.NFS3ErrNOSPC
+		return
+	}
+
+	inode = memFS.newInode(fType, defaultMode, credential.UID, credential.GID)
+
+	status = memFS.setAttr(inode, sAttr3)
+	if nfsd.OK != status {
+		delete(memFS.inodes, inode.fileID)
+		inode = nil
+		return
+	}
+
+	memFS.link(dir, name, inode)
+
+	return
+}
+
+// setAttr applies sAttr3 to inode. Must be called with memFS locked.
+func (memFS *MemFSStruct) setAttr(inode *inodeStruct, sAttr3 *nfsd.SAttr3Struct) (status uint32) {
+	var (
+		now = memFS.config.Now()
+	)
+
+	if sAttr3.SetSize {
+		switch inode.fType {
+		case nfsd.FTypeREG:
+			status = memFS.resize(inode, sAttr3.Size)
+			if nfsd.OK != status {
+				return
+			}
+			inode.mtime = now
+		case nfsd.FTypeDIR:
+			status = nfsd.NFS3ErrISDIR
+			return
+		default:
+			status = nfsd.NFS3ErrINVAL
+			return
+		}
+	}
+
+	if sAttr3.SetMode {
+		inode.mode = sAttr3.Mode & 07777
+	}
+	if sAttr3.SetUID {
+		inode.uid = sAttr3.UID
+	}
+	if sAttr3.SetGID {
+		inode.gid = sAttr3.GID
+	}
+
+	switch sAttr3.SetATime {
+	case nfsd.SetToServerTime:
+		inode.atime = now
+	case nfsd.SetToClientTime:
+		inode.atime = timeOf(sAttr3.ATime)
+	}
+	switch sAttr3.SetMTime {
+	case nfsd.SetToServerTime:
+		inode.mtime = now
+	case nfsd.SetToClientTime:
+		inode.mtime = timeOf(sAttr3.MTime)
+	}
+
+	inode.ctime = now
+	inode.exclusive = false
+
+	status = nfsd.OK
+
+	return
+}
+
+func timeOf(nfsTime3 nfsd.NFSTime3Struct) (t time.Time) {
+	t = time.Unix(int64(nfsTime3.Seconds), int64(nfsTime3.NSeconds))
+	return
+}
+
+func wcc(before nfsd.PreOpAttrStruct, inode *inodeStruct) (wccData nfsd.WCCDataStruct) {
+	wccData = nfsd.WCCDataStruct{Before: before, After: inode.postOpAttr()}
+	return
+}
+
+func (memFS *MemFSStruct) ErrorLog(err error) {
+	memFS.config.ErrorLog(err)
+}
+
+func (memFS *MemFSStruct) NFSProc3Null(credential *nfsd.CredentialStruct) {}
+
+func (memFS *MemFSStruct) NFSProc3GetAttr(credential *nfsd.CredentialStruct, nfsProc3GetAttrArgs *nfsd.NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *nfsd.NFSProc3GetAttrResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3GetAttrResults = &nfsd.NFSProc3GetAttrResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3GetAttrResults.Status = memFS.injectedError(nfsd.NFSPROC3GETATTR)
+	if nfsd.OK != nfsProc3GetAttrResults.Status {
+		return
+	}
+
+	inode, nfsProc3GetAttrResults.Status = memFS.inode(nfsProc3GetAttrArgs.Object)
+	if nfsd.OK == nfsProc3GetAttrResults.Status {
+		nfsProc3GetAttrResults.Attributes = inode.fAttr()
+	}
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3SetAttr(credential *nfsd.CredentialStruct, nfsProc3SetAttrArgs *nfsd.NFSProc3SetAttrArgsStruct) (nfsProc3SetAttrResults *nfsd.NFSProc3SetAttrResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3SetAttrResults = &nfsd.NFSProc3SetAttrResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3SetAttrResults.Status = memFS.injectedError(nfsd.NFSPROC3SETATTR)
+	if nfsd.OK != nfsProc3SetAttrResults.Status {
+		return
+	}
+
+	inode, nfsProc3SetAttrResults.Status = memFS.inode(nfsProc3SetAttrArgs.Object)
+	if nfsd.OK != nfsProc3SetAttrResults.Status {
+		return
+	}
+
+	before = inode.preOpAttr()
+
+	defer func() {
+		nfsProc3SetAttrResults.WCC = wcc(before, inode)
+	}()
+
+	if nfsProc3SetAttrArgs.Guard.CheckCTime && (nfsProc3SetAttrArgs.Guard.CTime != nfsd.NFSTime(inode.ctime)) {
+		nfsProc3SetAttrResults.Status = nfsd.NFS3ErrNOTSYNC
+		return
+	}
+
+	nfsProc3SetAttrResults.Status = memFS.setAttr(inode, &nfsProc3SetAttrArgs.NewAttributes)
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Lookup(credential *nfsd.CredentialStruct, nfsProc3LookupArgs *nfsd.NFSProc3LookupArgsStruct) (nfsProc3LookupResults *nfsd.NFSProc3LookupResultsStruct) {
+	var (
+		dir   *inodeStruct
+		inode *inodeStruct
+	)
+
+	nfsProc3LookupResults = &nfsd.NFSProc3LookupResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3LookupResults.Status = memFS.injectedError(nfsd.NFSPROC3LOOKUP)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	dir, nfsProc3LookupResults.Status = memFS.dir(nfsProc3LookupArgs.What.Dir)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.DirAttributes = dir.postOpAttr()
+
+	inode, nfsProc3LookupResults.Status = memFS.lookup(dir, nfsProc3LookupArgs.What.Name)
+	if nfsd.OK != nfsProc3LookupResults.Status {
+		return
+	}
+
+	nfsProc3LookupResults.Object = fHandleOf(inode.fileID)
+	nfsProc3LookupResults.ObjAttributes = inode.postOpAttr()
+
+	return
+}
+
+// NFSProc3Access grants all access applicable to the type of the object (permissions not being enforced)
+func (memFS *MemFSStruct) NFSProc3Access(credential *nfsd.CredentialStruct, nfsProc3AccessArgs *nfsd.NFSProc3AccessArgsStruct) (nfsProc3AccessResults *nfsd.NFSProc3AccessResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3AccessResults = &nfsd.NFSProc3AccessResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3AccessResults.Status = memFS.injectedError(nfsd.NFSPROC3ACCESS)
+	if nfsd.OK != nfsProc3AccessResults.Status {
+		return
+	}
+
+	inode, nfsProc3AccessResults.Status = memFS.inode(nfsProc3AccessArgs.Object)
+	if nfsd.OK != nfsProc3AccessResults.Status {
+		return
+	}
+
+	nfsProc3AccessResults.ObjAttributes = inode.postOpAttr()
+
+	if nfsd.FTypeDIR == inode.fType {
+		nfsProc3AccessResults.Access = nfsProc3AccessArgs.Access & (nfsd.Access3Read | nfsd.Access3Lookup | nfsd.Access3Modify | nfsd.Access3Extend | nfsd.Access3Delete)
+	} else {
+		nfsProc3AccessResults.Access = nfsProc3AccessArgs.Access & (nfsd.Access3Read | nfsd.Access3Modify | nfsd.Access3Extend | nfsd.Access3Execute)
+	}
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3ReadLink(credential *nfsd.CredentialStruct, nfsProc3ReadLinkArgs *nfsd.NFSProc3ReadLinkArgsStruct) (nfsProc3ReadLinkResults *nfsd.NFSProc3ReadLinkResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3ReadLinkResults = &nfsd.NFSProc3ReadLinkResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3ReadLinkResults.Status = memFS.injectedError(nfsd.NFSPROC3READLINK)
+	if nfsd.OK != nfsProc3ReadLinkResults.Status {
+		return
+	}
+
+	inode, nfsProc3ReadLinkResults.Status = memFS.inode(nfsProc3ReadLinkArgs.SymLink)
+	if nfsd.OK != nfsProc3ReadLinkResults.Status {
+		return
+	}
+
+	nfsProc3ReadLinkResults.SymLinkAttributes = inode.postOpAttr()
+
+	if nfsd.FTypeLNK != inode.fType {
+		nfsProc3ReadLinkResults.Status = nfsd.NFS3ErrINVAL
+		return
+	}
+
+	nfsProc3ReadLinkResults.Path = append([]byte{}, inode.data...)
+
+	return
+}
+
+// regularFile is like inode but additionally requires that the inode be a regular file. Must be called with
+// memFS locked.
+func (memFS *MemFSStruct) regularFile(fHandle []byte) (inode *inodeStruct, status uint32) {
+	inode, status = memFS.inode(fHandle)
+	if nfsd.OK != status {
+		return
+	}
+
+	switch inode.fType {
+	case nfsd.FTypeREG:
+	case nfsd.FTypeDIR:
+		status = nfsd.NFS3ErrISDIR
+	default:
+		status = nfsd.NFS3ErrINVAL
+	}
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Read(credential *nfsd.CredentialStruct, nfsProc3ReadArgs *nfsd.NFSProc3ReadArgsStruct) (nfsProc3ReadResults *nfsd.NFSProc3ReadResultsStruct) {
+	var (
+		end   uint64
+		inode *inodeStruct
+		size  uint64
+	)
+
+	nfsProc3ReadResults = &nfsd.NFSProc3ReadResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3ReadResults.Status = memFS.injectedError(nfsd.NFSPROC3READ)
+	if nfsd.OK != nfsProc3ReadResults.Status {
+		return
+	}
+
+	inode, nfsProc3ReadResults.Status = memFS.regularFile(nfsProc3ReadArgs.File)
+	if nil == inode {
+		return
+	}
+
+	nfsProc3ReadResults.FileAttributes = inode.postOpAttr()
+
+	if nfsd.OK != nfsProc3ReadResults.Status {
+		return
+	}
+
+	size = uint64(len(inode.data))
+
+	if nfsProc3ReadArgs.Offset >= size {
+		nfsProc3ReadResults.Data = []byte{}
+		nfsProc3ReadResults.EOF = true
+		return
+	}
+
+	end = nfsProc3ReadArgs.Offset + uint64(nfsProc3ReadArgs.Count)
+	if end >= size {
+		end = size
+		nfsProc3ReadResults.EOF = true
+	}
+
+	nfsProc3ReadResults.Data = append([]byte{}, inode.data[nfsProc3ReadArgs.Offset:end]...)
+	nfsProc3ReadResults.Count = uint32(len(nfsProc3ReadResults.Data))
+
+	return
+}
+
+// NFSProc3Write applies each WRITE immediately (i.e. reporting it as FileSync)
+func (memFS *MemFSStruct) NFSProc3Write(credential *nfsd.CredentialStruct, nfsProc3WriteArgs *nfsd.NFSProc3WriteArgsStruct) (nfsProc3WriteResults *nfsd.NFSProc3WriteResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		data   = nfsProc3WriteArgs.Data
+		end    uint64
+		inode  *inodeStruct
+		now    time.Time
+	)
+
+	nfsProc3WriteResults = &nfsd.NFSProc3WriteResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3WriteResults.Status = memFS.injectedError(nfsd.NFSPROC3WRITE)
+	if nfsd.OK != nfsProc3WriteResults.Status {
+		return
+	}
+
+	inode, nfsProc3WriteResults.Status = memFS.regularFile(nfsProc3WriteArgs.File)
+	if nil == inode {
+		return
+	}
+
+	before = inode.preOpAttr()
+
+	defer func() {
+		nfsProc3WriteResults.FileWCC = wcc(before, inode)
+	}()
+
+	if nfsd.OK != nfsProc3WriteResults.Status {
+		return
+	}
+
+	if uint32(len(data)) > nfsProc3WriteArgs.Count {
+		data = data[:nfsProc3WriteArgs.Count]
+	}
+
+	end = nfsProc3WriteArgs.Offset + uint64(len(data))
+	if end < nfsProc3WriteArgs.Offset {
+		nfsProc3WriteResults.Status = nfsd.NFS3ErrFBIG
+		return
+	}
+
+	if end > uint64(len(inode.data)) {
+		nfsProc3WriteResults.Status = memFS.resize(inode, end)
+		if nfsd.OK != nfsProc3WriteResults.Status {
+			return
+		}
+	}
+
+	copy(inode.data[nfsProc3WriteArgs.Offset:], data)
+
+	now = memFS.config.Now()
+	inode.mtime = now
+	inode.ctime = now
+
+	nfsProc3WriteResults.Count = uint32(len(data))
+	nfsProc3WriteResults.Committed = nfsd.FileSync
+	nfsProc3WriteResults.Verf = writeVerf
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Create(credential *nfsd.CredentialStruct, nfsProc3CreateArgs *nfsd.NFSProc3CreateArgsStruct) (nfsProc3CreateResults *nfsd.NFSProc3CreateResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		how    = &nfsProc3CreateArgs.How
+		inode  *inodeStruct
+	)
+
+	nfsProc3CreateResults = &nfsd.NFSProc3CreateResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3CreateResults.Status = memFS.injectedError(nfsd.NFSPROC3CREATE)
+	if nfsd.OK != nfsProc3CreateResults.Status {
+		return
+	}
+
+	dir, nfsProc3CreateResults.Status = memFS.dir(nfsProc3CreateArgs.Where.Dir)
+	if nfsd.OK != nfsProc3CreateResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3CreateResults.DirWCC = wcc(before, dir)
+		if nfsd.OK == nfsProc3CreateResults.Status {
+			nfsProc3CreateResults.Obj = nfsd.PostOpFh3Struct{HandleFollows: true, Handle: fHandleOf(inode.fileID)}
+			nfsProc3CreateResults.ObjAttributes = inode.postOpAttr()
+		}
+	}()
+
+	inode, nfsProc3CreateResults.Status = memFS.lookup(dir, nfsProc3CreateArgs.Where.Name)
+	if nfsd.OK == nfsProc3CreateResults.Status {
+		switch {
+		case (nfsd.Unchecked == how.Mode) && (nfsd.FTypeREG == inode.fType):
+			if how.ObjAttributes.SetSize {
+				nfsProc3CreateResults.Status = memFS.setAttr(inode, &nfsd.SAttr3Struct{SetSize: true, Size: how.ObjAttributes.Size})
+			}
+		case (nfsd.Exclusive == how.Mode) && inode.exclusive && (how.Verf == inode.verf):
+			// A retransmission of an exclusive CREATE that succeeded
+		default:
+			nfsProc3CreateResults.Status = nfsd.NFS3ErrEXIST
+		}
+		return
+	}
+
+	if nfsd.Exclusive == how.Mode {
+		inode, nfsProc3CreateResults.Status = memFS.create(credential, dir, nfsProc3CreateArgs.Where.Name, nfsd.FTypeREG, &nfsd.SAttr3Struct{}, defaultFileMode)
+		if nfsd.OK == nfsProc3CreateResults.Status {
+			inode.exclusive = true
+			inode.verf = how.Verf
+		}
+	} else {
+		inode, nfsProc3CreateResults.Status = memFS.create(credential, dir, nfsProc3CreateArgs.Where.Name, nfsd.FTypeREG, &how.ObjAttributes, defaultFileMode)
+	}
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3MKDir(credential *nfsd.CredentialStruct, nfsProc3MKDirArgs *nfsd.NFSProc3MKDirArgsStruct) (nfsProc3MKDirResults *nfsd.NFSProc3MKDirResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3MKDirResults = &nfsd.NFSProc3MKDirResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3MKDirResults.Status = memFS.injectedError(nfsd.NFSPROC3MKDIR)
+	if nfsd.OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	dir, nfsProc3MKDirResults.Status = memFS.dir(nfsProc3MKDirArgs.Where.Dir)
+	if nfsd.OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3MKDirResults.DirWCC = wcc(before, dir)
+	}()
+
+	inode, nfsProc3MKDirResults.Status = memFS.create(credential, dir, nfsProc3MKDirArgs.Where.Name, nfsd.FTypeDIR, &nfsProc3MKDirArgs.Attributes, defaultDirMode)
+	if nfsd.OK != nfsProc3MKDirResults.Status {
+		return
+	}
+
+	nfsProc3MKDirResults.Obj = nfsd.PostOpFh3Struct{HandleFollows: true, Handle: fHandleOf(inode.fileID)}
+	nfsProc3MKDirResults.ObjAttributes = inode.postOpAttr()
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3SymLink(credential *nfsd.CredentialStruct, nfsProc3SymLinkArgs *nfsd.NFSProc3SymLinkArgsStruct) (nfsProc3SymLinkResults *nfsd.NFSProc3SymLinkResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3SymLinkResults = &nfsd.NFSProc3SymLinkResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3SymLinkResults.Status = memFS.injectedError(nfsd.NFSPROC3SYMLINK)
+	if nfsd.OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	dir, nfsProc3SymLinkResults.Status = memFS.dir(nfsProc3SymLinkArgs.Where.Dir)
+	if nfsd.OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3SymLinkResults.DirWCC = wcc(before, dir)
+	}()
+
+	if uint64(len(nfsProc3SymLinkArgs.SymLinkData)) > (memFS.config.MaxBytes - memFS.bytesUsed) {
+		nfsProc3SymLinkResults.Status = nfsd.NFS3ErrNOSPC
+		return
+	}
+
+	inode, nfsProc3SymLinkResults.Status = memFS.create(credential, dir, nfsProc3SymLinkArgs.Where.Name, nfsd.FTypeLNK, &nfsProc3SymLinkArgs.SymLinkAttributes, defaultSymLinkMode)
+	if nfsd.OK != nfsProc3SymLinkResults.Status {
+		return
+	}
+
+	inode.data = append([]byte{}, nfsProc3SymLinkArgs.SymLinkData...)
+	memFS.bytesUsed += uint64(len(inode.data))
+
+	nfsProc3SymLinkResults.Obj = nfsd.PostOpFh3Struct{HandleFollows: true, Handle: fHandleOf(inode.fileID)}
+	nfsProc3SymLinkResults.ObjAttributes = inode.postOpAttr()
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Remove(credential *nfsd.CredentialStruct, nfsProc3RemoveArgs *nfsd.NFSProc3RemoveArgsStruct) (nfsProc3RemoveResults *nfsd.NFSProc3RemoveResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3RemoveResults = &nfsd.NFSProc3RemoveResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3RemoveResults.Status = memFS.injectedError(nfsd.NFSPROC3REMOVE)
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	dir, nfsProc3RemoveResults.Status = memFS.dir(nfsProc3RemoveArgs.Where.Dir)
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3RemoveResults.DirWCC = wcc(before, dir)
+	}()
+
+	nfsProc3RemoveResults.Status = checkName(nfsProc3RemoveArgs.Where.Name, nfsd.NFS3ErrINVAL)
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	inode, nfsProc3RemoveResults.Status = memFS.lookup(dir, nfsProc3RemoveArgs.Where.Name)
+	if nfsd.OK != nfsProc3RemoveResults.Status {
+		return
+	}
+
+	if nfsd.FTypeDIR == inode.fType {
+		nfsProc3RemoveResults.Status = nfsd.NFS3ErrISDIR
+		return
+	}
+
+	memFS.unlink(dir, nfsProc3RemoveArgs.Where.Name, inode)
+	inode.nLink--
+	memFS.release(inode)
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3RMDir(credential *nfsd.CredentialStruct, nfsProc3RMDirArgs *nfsd.NFSProc3RMDirArgsStruct) (nfsProc3RMDirResults *nfsd.NFSProc3RMDirResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3RMDirResults = &nfsd.NFSProc3RMDirResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3RMDirResults.Status = memFS.injectedError(nfsd.NFSPROC3RMDIR)
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	dir, nfsProc3RMDirResults.Status = memFS.dir(nfsProc3RMDirArgs.Where.Dir)
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3RMDirResults.DirWCC = wcc(before, dir)
+	}()
+
+	nfsProc3RMDirResults.Status = checkName(nfsProc3RMDirArgs.Where.Name, nfsd.NFS3ErrINVAL)
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	inode, nfsProc3RMDirResults.Status = memFS.lookup(dir, nfsProc3RMDirArgs.Where.Name)
+	if nfsd.OK != nfsProc3RMDirResults.Status {
+		return
+	}
+
+	if nfsd.FTypeDIR != inode.fType {
+		nfsProc3RMDirResults.Status = nfsd.NFS3ErrNOTDIR
+		return
+	}
+	if 0 != len(inode.entries) {
+		nfsProc3RMDirResults.Status = nfsd.NFS3ErrNOTEMPTY
+		return
+	}
+
+	memFS.unlink(dir, nfsProc3RMDirArgs.Where.Name, inode)
+	inode.nLink = 0
+	memFS.release(inode)
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Rename(credential *nfsd.CredentialStruct, nfsProc3RenameArgs *nfsd.NFSProc3RenameArgsStruct) (nfsProc3RenameResults *nfsd.NFSProc3RenameResultsStruct) {
+	var (
+		ancestor   *inodeStruct
+		fromBefore nfsd.PreOpAttrStruct
+		fromDir    *inodeStruct
+		inode      *inodeStruct
+		ok         bool
+		target     *inodeStruct
+		targetID   uint64
+		toBefore   nfsd.PreOpAttrStruct
+		toDir      *inodeStruct
+	)
+
+	nfsProc3RenameResults = &nfsd.NFSProc3RenameResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3RenameResults.Status = memFS.injectedError(nfsd.NFSPROC3RENAME)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	fromDir, nfsProc3RenameResults.Status = memFS.dir(nfsProc3RenameArgs.From.Dir)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+	toDir, nfsProc3RenameResults.Status = memFS.dir(nfsProc3RenameArgs.To.Dir)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	fromBefore = fromDir.preOpAttr()
+	toBefore = toDir.preOpAttr()
+
+	defer func() {
+		nfsProc3RenameResults.FromDirWCC = wcc(fromBefore, fromDir)
+		nfsProc3RenameResults.ToDirWCC = wcc(toBefore, toDir)
+	}()
+
+	nfsProc3RenameResults.Status = checkName(nfsProc3RenameArgs.From.Name, nfsd.NFS3ErrINVAL)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+	nfsProc3RenameResults.Status = checkName(nfsProc3RenameArgs.To.Name, nfsd.NFS3ErrINVAL)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	inode, nfsProc3RenameResults.Status = memFS.lookup(fromDir, nfsProc3RenameArgs.From.Name)
+	if nfsd.OK != nfsProc3RenameResults.Status {
+		return
+	}
+
+	if nfsd.FTypeDIR == inode.fType {
+		// A directory may not be moved beneath itself
+		for ancestor = toDir; ; ancestor = memFS.inodes[ancestor.parent] {
+			if ancestor == inode {
+				nfsProc3RenameResults.Status = nfsd.NFS3ErrINVAL
+				return
+			}
+			if rootFileID == ancestor.fileID {
+				break
+			}
+		}
+	}
+
+	targetID, ok = toDir.entries[nfsProc3RenameArgs.To.Name]
+	if ok {
+		target = memFS.inodes[targetID]
+		if target == inode {
+			return
+		}
+		switch {
+		case (nfsd.FTypeDIR == inode.fType) && (nfsd.FTypeDIR != target.fType):
+			nfsProc3RenameResults.Status = nfsd.NFS3ErrNOTDIR
+			return
+		case (nfsd.FTypeDIR != inode.fType) && (nfsd.FTypeDIR == target.fType):
+			nfsProc3RenameResults.Status = nfsd.NFS3ErrISDIR
+			return
+		case (nfsd.FTypeDIR == target.fType) && (0 != len(target.entries)):
+			nfsProc3RenameResults.Status = nfsd.NFS3ErrNOTEMPTY
+			return
+		}
+		memFS.unlink(toDir, nfsProc3RenameArgs.To.Name, target)
+		if nfsd.FTypeDIR == target.fType {
+			target.nLink = 0
+		} else {
+			target.nLink--
+		}
+		memFS.release(target)
+	}
+
+	memFS.unlink(fromDir, nfsProc3RenameArgs.From.Name, inode)
+	memFS.link(toDir, nfsProc3RenameArgs.To.Name, inode)
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Link(credential *nfsd.CredentialStruct, nfsProc3LinkArgs *nfsd.NFSProc3LinkArgsStruct) (nfsProc3LinkResults *nfsd.NFSProc3LinkResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		dir    *inodeStruct
+		inode  *inodeStruct
+		ok     bool
+	)
+
+	nfsProc3LinkResults = &nfsd.NFSProc3LinkResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3LinkResults.Status = memFS.injectedError(nfsd.NFSPROC3LINK)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	inode, nfsProc3LinkResults.Status = memFS.inode(nfsProc3LinkArgs.File)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	defer func() {
+		nfsProc3LinkResults.FileAttributes = inode.postOpAttr()
+	}()
+
+	dir, nfsProc3LinkResults.Status = memFS.dir(nfsProc3LinkArgs.Link.Dir)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	before = dir.preOpAttr()
+
+	defer func() {
+		nfsProc3LinkResults.LinkDirWCC = wcc(before, dir)
+	}()
+
+	if nfsd.FTypeDIR == inode.fType {
+		nfsProc3LinkResults.Status = nfsd.NFS3ErrISDIR
+		return
+	}
+	if inode.nLink >= linkMax {
+		nfsProc3LinkResults.Status = nfsd.NFS3ErrMLINK
+		return
+	}
+
+	nfsProc3LinkResults.Status = checkName(nfsProc3LinkArgs.Link.Name, nfsd.NFS3ErrEXIST)
+	if nfsd.OK != nfsProc3LinkResults.Status {
+		return
+	}
+
+	_, ok = dir.entries[nfsProc3LinkArgs.Link.Name]
+	if ok {
+		nfsProc3LinkResults.Status = nfsd.NFS3ErrEXIST
+		return
+	}
+
+	memFS.link(dir, nfsProc3LinkArgs.Link.Name, inode)
+	inode.nLink++
+	inode.ctime = memFS.config.Now()
+
+	return
+}
+
+// dirEntries returns the entries of dir (including "." & "..") following cookie in cookie order. As cookies
+// beyond that of ".." are positions in name order, they are only honored alongside the cookie verifier
+// returned with them (i.e. until the entries of dir are next modified). Must be called with memFS locked.
+func (memFS *MemFSStruct) dirEntries(dir *inodeStruct, cookie uint64, cookieVerf [nfsd.NFS3CookieVerfSize]byte) (dirEntries []nfsd.DirEntryStruct, verf [nfsd.NFS3CookieVerfSize]byte, status uint32) {
+	var (
+		name  string
+		names = make([]string, 0, len(dir.entries))
+	)
+
+	binary.BigEndian.PutUint64(verf[:], dir.version)
+
+	if (0 != cookie) && (cookieVerf != verf) {
+		status = nfsd.NFS3ErrBADCOOKIE
+		return
+	}
+
+	for name = range dir.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if cookie > (cookieDotDot + uint64(len(names))) {
+		status = nfsd.NFS3ErrBADCOOKIE
+		return
+	}
+
+	dirEntries = make([]nfsd.DirEntryStruct, 0, 2+len(names))
+	dirEntries = append(dirEntries, nfsd.DirEntryStruct{Name: ".", Cookie: cookieDot})
+	dirEntries = append(dirEntries, nfsd.DirEntryStruct{Name: "..", Cookie: cookieDotDot})
+
+	for _, name = range names {
+		dirEntries = append(dirEntries, nfsd.DirEntryStruct{Name: name, Cookie: cookieDotDot + uint64(len(dirEntries)-1)})
+	}
+
+	dirEntries = dirEntries[cookie:]
+
+	status = nfsd.OK
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3ReadDir(credential *nfsd.CredentialStruct, nfsProc3ReadDirArgs *nfsd.NFSProc3ReadDirArgsStruct) (nfsProc3ReadDirResults *nfsd.NFSProc3ReadDirResultsStruct) {
+	var (
+		dir        *inodeStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirResults = &nfsd.NFSProc3ReadDirResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3ReadDirResults.Status = memFS.injectedError(nfsd.NFSPROC3READDIR)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	dir, nfsProc3ReadDirResults.Status = memFS.dir(nfsProc3ReadDirArgs.Dir)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.DirAttributes = dir.postOpAttr()
+
+	dirEntries, nfsProc3ReadDirResults.CookieVerf, nfsProc3ReadDirResults.Status = memFS.dirEntries(dir, nfsProc3ReadDirArgs.Cookie, nfsProc3ReadDirArgs.CookieVerf)
+	if nfsd.OK != nfsProc3ReadDirResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirResults.AppendEntries(nfsProc3ReadDirArgs.Count, dirEntries, func(dirEntry nfsd.DirEntryStruct) (fileID uint64, ok bool) {
+		var (
+			inode *inodeStruct
+		)
+
+		inode, _ = memFS.lookup(dir, dirEntry.Name)
+		fileID = inode.fileID
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3ReadDirPlus(credential *nfsd.CredentialStruct, nfsProc3ReadDirPlusArgs *nfsd.NFSProc3ReadDirPlusArgsStruct) (nfsProc3ReadDirPlusResults *nfsd.NFSProc3ReadDirPlusResultsStruct) {
+	var (
+		dir        *inodeStruct
+		dirEntries []nfsd.DirEntryStruct
+	)
+
+	nfsProc3ReadDirPlusResults = &nfsd.NFSProc3ReadDirPlusResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3ReadDirPlusResults.Status = memFS.injectedError(nfsd.NFSPROC3READDIRPLUS)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	dir, nfsProc3ReadDirPlusResults.Status = memFS.dir(nfsProc3ReadDirPlusArgs.Dir)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.DirAttributes = dir.postOpAttr()
+
+	dirEntries, nfsProc3ReadDirPlusResults.CookieVerf, nfsProc3ReadDirPlusResults.Status = memFS.dirEntries(dir, nfsProc3ReadDirPlusArgs.Cookie, nfsProc3ReadDirPlusArgs.CookieVerf)
+	if nfsd.OK != nfsProc3ReadDirPlusResults.Status {
+		return
+	}
+
+	nfsProc3ReadDirPlusResults.AppendEntries(nfsProc3ReadDirPlusArgs.DirCount, nfsProc3ReadDirPlusArgs.MaxCount, dirEntries, func(dirEntry nfsd.DirEntryStruct) (dirListEntryPlus nfsd.DirListEntryPlusStruct, ok bool) {
+		var (
+			inode *inodeStruct
+		)
+
+		inode, _ = memFS.lookup(dir, dirEntry.Name)
+		dirListEntryPlus = nfsd.DirListEntryPlusStruct{
+			FileID:         inode.fileID,
+			NameAttributes: inode.postOpAttr(),
+			NameHandle:     nfsd.PostOpFh3Struct{HandleFollows: true, Handle: fHandleOf(inode.fileID)},
+		}
+		ok = true
+
+		return
+	})
+
+	return
+}
+
+// NFSProc3FSStat reports the capacity of the file system as limited by ConfigStruct.MaxBytes & MaxFiles
+func (memFS *MemFSStruct) NFSProc3FSStat(credential *nfsd.CredentialStruct, nfsProc3FSStatArgs *nfsd.NFSProc3FSStatArgsStruct) (nfsProc3FSStatResults *nfsd.NFSProc3FSStatResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3FSStatResults = &nfsd.NFSProc3FSStatResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3FSStatResults.Status = memFS.injectedError(nfsd.NFSPROC3FSSTAT)
+	if nfsd.OK != nfsProc3FSStatResults.Status {
+		return
+	}
+
+	inode, nfsProc3FSStatResults.Status = memFS.inode(nfsProc3FSStatArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSStatResults.Status {
+		return
+	}
+
+	nfsProc3FSStatResults.ObjAttributes = inode.postOpAttr()
+	nfsProc3FSStatResults.TBytes = memFS.config.MaxBytes
+	nfsProc3FSStatResults.FBytes = memFS.config.MaxBytes - memFS.bytesUsed
+	nfsProc3FSStatResults.ABytes = nfsProc3FSStatResults.FBytes
+	nfsProc3FSStatResults.TFiles = memFS.config.MaxFiles
+	nfsProc3FSStatResults.FFiles = memFS.config.MaxFiles - uint64(len(memFS.inodes))
+	nfsProc3FSStatResults.AFiles = nfsProc3FSStatResults.FFiles
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3FSInfo(credential *nfsd.CredentialStruct, nfsProc3FSInfoArgs *nfsd.NFSProc3FSInfoArgsStruct) (nfsProc3FSInfoResults *nfsd.NFSProc3FSInfoResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3FSInfoResults = &nfsd.NFSProc3FSInfoResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3FSInfoResults.Status = memFS.injectedError(nfsd.NFSPROC3FSINFO)
+	if nfsd.OK != nfsProc3FSInfoResults.Status {
+		return
+	}
+
+	inode, nfsProc3FSInfoResults.Status = memFS.inode(nfsProc3FSInfoArgs.FSRoot)
+	if nfsd.OK != nfsProc3FSInfoResults.Status {
+		return
+	}
+
+	nfsProc3FSInfoResults.ObjAttributes = inode.postOpAttr()
+	nfsProc3FSInfoResults.RTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.RTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.WTMax = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTPref = nfsd.DefaultTransferMax
+	nfsProc3FSInfoResults.WTMult = nfsd.DefaultTransferMult
+	nfsProc3FSInfoResults.DTPref = nfsd.DefaultDirPref
+	nfsProc3FSInfoResults.MaxFileSize = memFS.config.MaxBytes
+	nfsProc3FSInfoResults.TimeDelta = nfsd.NFSTime3Struct{Seconds: 0, NSeconds: 1}
+	nfsProc3FSInfoResults.Properties = nfsd.FSF3Link | nfsd.FSF3SymLink | nfsd.FSF3Homogeneous | nfsd.FSF3CanSetTime
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3PathConf(credential *nfsd.CredentialStruct, nfsProc3PathConfArgs *nfsd.NFSProc3PathConfArgsStruct) (nfsProc3PathConfResults *nfsd.NFSProc3PathConfResultsStruct) {
+	var (
+		inode *inodeStruct
+	)
+
+	nfsProc3PathConfResults = &nfsd.NFSProc3PathConfResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3PathConfResults.Status = memFS.injectedError(nfsd.NFSPROC3PATHCONF)
+	if nfsd.OK != nfsProc3PathConfResults.Status {
+		return
+	}
+
+	inode, nfsProc3PathConfResults.Status = memFS.inode(nfsProc3PathConfArgs.Object)
+	if nfsd.OK != nfsProc3PathConfResults.Status {
+		return
+	}
+
+	nfsProc3PathConfResults.ObjAttributes = inode.postOpAttr()
+	nfsProc3PathConfResults.LinkMax = linkMax
+	nfsProc3PathConfResults.NameMax = nfsd.DefaultNameMax
+	nfsProc3PathConfResults.NoTrunc = true
+	nfsProc3PathConfResults.ChOwnRestricted = false
+	nfsProc3PathConfResults.CaseInsensitive = false
+	nfsProc3PathConfResults.CasePreserving = true
+
+	return
+}
+
+func (memFS *MemFSStruct) NFSProc3Commit(credential *nfsd.CredentialStruct, nfsProc3CommitArgs *nfsd.NFSProc3CommitArgsStruct) (nfsProc3CommitResults *nfsd.NFSProc3CommitResultsStruct) {
+	var (
+		before nfsd.PreOpAttrStruct
+		inode  *inodeStruct
+	)
+
+	nfsProc3CommitResults = &nfsd.NFSProc3CommitResultsStruct{}
+
+	memFS.Lock()
+	defer memFS.Unlock()
+
+	nfsProc3CommitResults.Status = memFS.injectedError(nfsd.NFSPROC3COMMIT)
+	if nfsd.OK != nfsProc3CommitResults.Status {
+		return
+	}
+
+	inode, nfsProc3CommitResults.Status = memFS.regularFile(nfsProc3CommitArgs.File)
+	if nil == inode {
+		return
+	}
+
+	before = inode.preOpAttr()
+	nfsProc3CommitResults.FileWCC = wcc(before, inode)
+
+	if nfsd.OK == nfsProc3CommitResults.Status {
+		nfsProc3CommitResults.Verf = writeVerf
+	}
+
+	return
+}
@@ -0,0 +1,312 @@
+package nfsd
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type mountTableKeyStruct struct {
+	clientAddr string // IP address of the client (if reported by the connection of the request)
+	hostName   string // AuthSysBodyStruct.MachineName of the client (or clientAddr if that was not supplied)
+	dirPath    string
+}
+
+type mountTableStruct struct {
+	sync.Mutex
+	rmtabPath string // if == "", the mount table is not persisted
+	errorLog  func(err error)
+	entries   map[mountTableKeyStruct]uint32
+}
+
+var globalMountTable *mountTableStruct // used by Mount V3 servers launched via StartIPv4{TCP|UDP}MountV3Server
+
+// newMountTable returns a mount table persisted in rmtabPath (if != ""). Malformed lines of an existing rmtab
+// file are reported via errorLog (or the log package if nil) and otherwise ignored.
+func newMountTable(rmtabPath string, errorLog func(err error)) (mountTable *mountTableStruct, err error) {
+	mountTable = &mountTableStruct{
+		rmtabPath: rmtabPath,
+		errorLog:  errorLog,
+		entries:   make(map[mountTableKeyStruct]uint32),
+	}
+
+	if nil == mountTable.errorLog {
+		mountTable.errorLog = func(err error) { log.Printf("nfsd: %v", err) }
+	}
+
+	if "" != rmtabPath {
+		err = mountTable.load()
+		if nil != err {
+			mountTable = nil
+		}
+	}
+
+	return
+}
+
+// rmtabEscape returns field with '%', ':', and control characters replaced by "%XX" such that neither a client's
+// MachineName nor DirPath can break (or add) a line of the rmtab file
+func rmtabEscape(field string) (escaped string) {
+	var (
+		b       byte
+		builder strings.Builder
+		i       int
+	)
+
+	for i = 0; i < len(field); i++ {
+		b = field[i]
+		if ('%' == b) || (':' == b) || (0x20 > b) || (0x7F == b) {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		} else {
+			builder.WriteByte(b)
+		}
+	}
+
+	escaped = builder.String()
+
+	return
+}
+
+// rmtabUnescape reverses rmtabEscape (leaving fields written by other rmtab writers, which lack "%XX"
+// sequences, as is)
+func rmtabUnescape(escaped string) (field string, err error) {
+	var (
+		b       uint64
+		builder strings.Builder
+		i       int
+	)
+
+	for i = 0; i < len(escaped); i++ {
+		if '%' != escaped[i] {
+			builder.WriteByte(escaped[i])
+			continue
+		}
+		if len(escaped) < i+3 {
+			err = fmt.Errorf("truncated escape sequence")
+			return
+		}
+		b, err = strconv.ParseUint(escaped[i+1:i+3], 16, 8)
+		if nil != err {
+			return
+		}
+		builder.WriteByte(byte(b))
+		i += 2
+	}
+
+	field = builder.String()
+
+	return
+}
+
+// load reads a Linux rmtab-formatted file where each line is of the form "hostname:dirpath:0x<count>" (with
+// hostname & dirpath escaped via rmtabEscape). Malformed lines are reported & skipped such that a single bad
+// line cannot prevent the server from starting.
+func (mountTable *mountTableStruct) load() (err error) {
+	var (
+		count    uint64
+		dirPath  string
+		file     *os.File
+		hostName string
+		line     string
+		lineErr  error
+		scanner  *bufio.Scanner
+		splitOne int
+		splitTwo int
+	)
+
+	file, err = os.Open(mountTable.rmtabPath)
+	if nil != err {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	defer file.Close()
+
+	scanner = bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line = strings.TrimSpace(scanner.Text())
+		if "" == line {
+			continue
+		}
+		splitOne = strings.Index(line, ":")
+		splitTwo = strings.LastIndex(line, ":")
+		if (0 > splitOne) || (splitOne == splitTwo) {
+			mountTable.errorLog(fmt.Errorf("rmtab (%s) line %q malformed... skipped", mountTable.rmtabPath, line))
+			continue
+		}
+		count, lineErr = strconv.ParseUint(line[splitTwo+1:], 0, 32)
+		if nil == lineErr {
+			hostName, lineErr = rmtabUnescape(line[:splitOne])
+		}
+		if nil == lineErr {
+			dirPath, lineErr = rmtabUnescape(line[splitOne+1 : splitTwo])
+		}
+		if nil != lineErr {
+			mountTable.errorLog(fmt.Errorf("rmtab (%s) line %q malformed: %v... skipped", mountTable.rmtabPath, line, lineErr))
+			continue
+		}
+		mountTable.entries[mountTableKeyStruct{hostName: hostName, dirPath: dirPath}] += uint32(count)
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+// save atomically replaces the rmtab file with the current contents of the mount table (if persisted)
+func (mountTable *mountTableStruct) save() (err error) {
+	var (
+		count     map[string]uint32
+		key       mountTableKeyStruct
+		line      string
+		lines     []string
+		rmtabData []byte
+		tempPath  string
+	)
+
+	if "" == mountTable.rmtabPath {
+		return
+	}
+
+	count = make(map[string]uint32)
+
+	for key = range mountTable.entries {
+		count[rmtabEscape(key.hostName)+":"+rmtabEscape(key.dirPath)] += mountTable.entries[key]
+	}
+
+	lines = make([]string, 0, len(count))
+
+	for line = range count {
+		lines = append(lines, fmt.Sprintf("%s:0x%08x\n", line, count[line]))
+	}
+
+	sort.Strings(lines)
+
+	rmtabData = []byte(strings.Join(lines, ""))
+
+	tempPath = filepath.Join(filepath.Dir(mountTable.rmtabPath), "."+filepath.Base(mountTable.rmtabPath)+".tmp")
+
+	err = ioutil.WriteFile(tempPath, rmtabData, 0644)
+	if nil != err {
+		return
+	}
+
+	err = os.Rename(tempPath, mountTable.rmtabPath)
+
+	return
+}
+
+func (mountTable *mountTableStruct) mnt(clientAddr string, hostName string, dirPath string) (err error) {
+	if "" == hostName {
+		hostName = clientAddr
+	}
+	if "" == hostName {
+		return // the client can be identified by neither address nor name
+	}
+
+	mountTable.Lock()
+	mountTable.entries[mountTableKeyStruct{clientAddr: clientAddr, hostName: hostName, dirPath: dirPath}]++
+	err = mountTable.save()
+	mountTable.Unlock()
+
+	return
+}
+
+func (mountTable *mountTableStruct) umnt(clientAddr string, hostName string, dirPath string) (err error) {
+	var (
+		key mountTableKeyStruct
+	)
+
+	if "" == hostName {
+		hostName = clientAddr
+	}
+	if "" == hostName {
+		return // the client can be identified by neither address nor name
+	}
+
+	mountTable.Lock()
+	for key = range mountTable.entries {
+		if key.matchesClient(clientAddr, hostName) && (key.dirPath == dirPath) {
+			delete(mountTable.entries, key)
+		}
+	}
+	err = mountTable.save()
+	mountTable.Unlock()
+
+	return
+}
+
+func (mountTable *mountTableStruct) umntAll(clientAddr string, hostName string) (err error) {
+	var (
+		key mountTableKeyStruct
+	)
+
+	if "" == hostName {
+		hostName = clientAddr
+	}
+	if "" == hostName {
+		return // the client can be identified by neither address nor name
+	}
+
+	mountTable.Lock()
+	for key = range mountTable.entries {
+		if key.matchesClient(clientAddr, hostName) {
+			delete(mountTable.entries, key)
+		}
+	}
+	err = mountTable.save()
+	mountTable.Unlock()
+
+	return
+}
+
+func (mountTable *mountTableStruct) dump() (mountProc3DumpResults *MountProc3DumpResultsStruct) {
+	var (
+		key       mountTableKeyStruct
+		mountBody MountBodyStruct
+		seen      map[MountBodyStruct]bool
+	)
+
+	mountProc3DumpResults = &MountProc3DumpResultsStruct{MountList: make([]MountBodyStruct, 0)}
+
+	seen = make(map[MountBodyStruct]bool)
+
+	mountTable.Lock()
+	for key = range mountTable.entries {
+		seen[MountBodyStruct{HostName: key.hostName, Directory: key.dirPath}] = true
+	}
+	mountTable.Unlock()
+
+	for mountBody = range seen {
+		mountProc3DumpResults.MountList = append(mountProc3DumpResults.MountList, mountBody)
+	}
+
+	sort.Slice(mountProc3DumpResults.MountList, func(i int, j int) bool {
+		if mountProc3DumpResults.MountList[i].HostName == mountProc3DumpResults.MountList[j].HostName {
+			return mountProc3DumpResults.MountList[i].Directory < mountProc3DumpResults.MountList[j].Directory
+		}
+		return mountProc3DumpResults.MountList[i].HostName < mountProc3DumpResults.MountList[j].HostName
+	})
+
+	return
+}
+
+// matchesClient reports whether a mount table entry was made by the specified client. Entries loaded from
+// an rmtab file have no clientAddr and so are matched by hostName alone.
+func (key *mountTableKeyStruct) matchesClient(clientAddr string, hostName string) (matches bool) {
+	if ("" != key.clientAddr) && ("" != clientAddr) {
+		matches = (key.clientAddr == clientAddr)
+	} else {
+		matches = (key.hostName == hostName)
+	}
+	return
+}
@@ -0,0 +1,208 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/swiftstack/xdr"
+)
+
+func TestMountTable(t *testing.T) {
+	var (
+		err        error
+		mountTable *mountTableStruct
+		rmtabData  []byte
+		rmtabPath  string
+		testDir    string
+	)
+
+	testDir, err = ioutil.TempDir("", "nfsd_mount_table_test")
+	if nil != err {
+		t.Fatalf("ioutil.TempDir() failed: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	rmtabPath = filepath.Join(testDir, "rmtab")
+
+	mountTable, err = newMountTable(rmtabPath, nil)
+	if nil != err {
+		t.Fatalf("newMountTable() failed: %v", err)
+	}
+
+	_ = mountTable.mnt("10.0.0.1", "alpha", "/export/a")
+	_ = mountTable.mnt("10.0.0.1", "alpha", "/export/b")
+	_ = mountTable.mnt("10.0.0.2", "", "/export/a")
+
+	if !reflect.DeepEqual(mountTable.dump().MountList, []MountBodyStruct{
+		{HostName: "10.0.0.2", Directory: "/export/a"},
+		{HostName: "alpha", Directory: "/export/a"},
+		{HostName: "alpha", Directory: "/export/b"},
+	}) {
+		t.Fatalf("dump() returned unexpected %+v", mountTable.dump().MountList)
+	}
+
+	rmtabData, err = ioutil.ReadFile(rmtabPath)
+	if nil != err {
+		t.Fatalf("ioutil.ReadFile() failed: %v", err)
+	}
+	if "10.0.0.2:/export/a:0x00000001\nalpha:/export/a:0x00000001\nalpha:/export/b:0x00000001\n" != string(rmtabData) {
+		t.Fatalf("rmtab contained unexpected %q", rmtabData)
+	}
+
+	_ = mountTable.umnt("10.0.0.1", "alpha", "/export/a")
+	_ = mountTable.umntAll("10.0.0.2", "")
+
+	if !reflect.DeepEqual(mountTable.dump().MountList, []MountBodyStruct{{HostName: "alpha", Directory: "/export/b"}}) {
+		t.Fatalf("dump() after umnt() & umntAll() returned unexpected %+v", mountTable.dump().MountList)
+	}
+
+	// Reload from rmtab... entries no longer know their clientAddr so must match by hostName
+
+	mountTable, err = newMountTable(rmtabPath, nil)
+	if nil != err {
+		t.Fatalf("newMountTable() reload failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(mountTable.dump().MountList, []MountBodyStruct{{HostName: "alpha", Directory: "/export/b"}}) {
+		t.Fatalf("dump() after reload returned unexpected %+v", mountTable.dump().MountList)
+	}
+
+	_ = mountTable.umntAll("10.0.0.1", "alpha")
+
+	if 0 != len(mountTable.dump().MountList) {
+		t.Fatalf("dump() after final umntAll() returned unexpected %+v", mountTable.dump().MountList)
+	}
+}
+
+// TestMountTableEscaping verifies that a MachineName (or DirPath) containing ':', '%', or control characters
+// neither breaks nor adds rmtab lines and survives a reload, and that malformed rmtab lines are skipped
+func TestMountTableEscaping(t *testing.T) {
+	var (
+		err        error
+		errors     []error
+		hostName   = "evil:host\nbogus:/export/x:0x00000001\x00%41"
+		mountTable *mountTableStruct
+		rmtabData  []byte
+		rmtabPath  string
+		testDir    string
+	)
+
+	testDir, err = ioutil.TempDir("", "nfsd_mount_table_test")
+	if nil != err {
+		t.Fatalf("ioutil.TempDir() failed: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	rmtabPath = filepath.Join(testDir, "rmtab")
+
+	mountTable, err = newMountTable(rmtabPath, nil)
+	if nil != err {
+		t.Fatalf("newMountTable() failed: %v", err)
+	}
+
+	err = mountTable.mnt("10.0.0.1", hostName, "/export/a\nb")
+	if nil != err {
+		t.Fatalf("mnt() failed: %v", err)
+	}
+
+	rmtabData, err = ioutil.ReadFile(rmtabPath)
+	if nil != err {
+		t.Fatalf("ioutil.ReadFile() failed: %v", err)
+	}
+	if "evil%3Ahost%0Abogus%3A/export/x%3A0x00000001%00%2541:/export/a%0Ab:0x00000001\n" != string(rmtabData) {
+		t.Fatalf("rmtab contained unexpected %q", rmtabData)
+	}
+
+	// Append malformed lines that must be skipped (and reported) upon reload
+
+	err = ioutil.WriteFile(rmtabPath, append(rmtabData, []byte("nocolons\nbad%zz:/export/c:0x1\nbeta:/export/d:notacount\ngamma:/export/e:0x2\n")...), 0644)
+	if nil != err {
+		t.Fatalf("ioutil.WriteFile() failed: %v", err)
+	}
+
+	mountTable, err = newMountTable(rmtabPath, func(err error) { errors = append(errors, err) })
+	if nil != err {
+		t.Fatalf("newMountTable() reload failed: %v", err)
+	}
+
+	if 3 != len(errors) {
+		t.Fatalf("newMountTable() reload reported %v... expected 3 malformed lines", errors)
+	}
+	if !reflect.DeepEqual(mountTable.dump().MountList, []MountBodyStruct{
+		{HostName: hostName, Directory: "/export/a\nb"},
+		{HostName: "gamma", Directory: "/export/e"},
+	}) {
+		t.Fatalf("dump() after reload returned unexpected %+v", mountTable.dump().MountList)
+	}
+}
+
+// testMountV3Struct permits MNT of any directory
+type testMountV3Struct struct {
+	MountV3Interface
+	t *testing.T
+}
+
+func (testMountV3 *testMountV3Struct) ErrorLog(err error) {
+	testMountV3.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testMountV3 *testMountV3Struct) MountProc3Mnt(credential *CredentialStruct, mountProc3MntArgs *MountProc3MntArgsStruct) (mountProc3MntResults *MountProc3MntResultsStruct) {
+	mountProc3MntResults = &MountProc3MntResultsStruct{Status: OK, FHandle: []byte{1}, AuthFlavors: []uint32{AuthSys}}
+	return
+}
+
+// TestMountTableWithoutClientAddr verifies MNT, UMNT, & UMNTALL via a connection not reporting the address of the
+// client
+func TestMountTableWithoutClientAddr(t *testing.T) {
+	var (
+		err                 error
+		mountRequestHandler *mountRequestHandlerStruct
+		mountTable          *mountTableStruct
+		parms               []byte
+		testReplier         *testReplierStruct
+	)
+
+	mountTable, err = newMountTable("", nil)
+	if nil != err {
+		t.Fatalf("newMountTable() failed: %v", err)
+	}
+
+	mountRequestHandler = &mountRequestHandlerStruct{callbacks: &testMountV3Struct{t: t}, mountTable: mountTable}
+
+	parms, err = xdr.Pack(&MountProc3MntArgsStruct{DirPath: "/export"})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	testReplier = &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 1, MOUNTPROC3MNT, &CredentialStruct{Flavor: AuthSys, MachineName: "alpha"}, parms)
+	if (4 > len(testReplier.results)) || (OK != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("MNT returned %v", testReplier.results)
+	}
+
+	testReplier = &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 2, MOUNTPROC3MNT, &CredentialStruct{Flavor: AuthNone}, parms)
+	if (4 > len(testReplier.results)) || (OK != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("MNT via AUTH_NONE returned %v", testReplier.results)
+	}
+
+	if !reflect.DeepEqual(mountTable.dump().MountList, []MountBodyStruct{{HostName: "alpha", Directory: "/export"}}) {
+		t.Fatalf("dump() after MNTs lacking client address returned unexpected %+v", mountTable.dump().MountList)
+	}
+
+	testReplier = &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 3, MOUNTPROC3UMNTALL, &CredentialStruct{Flavor: AuthNone}, nil)
+	if 1 != len(mountTable.dump().MountList) {
+		t.Fatalf("UMNTALL via AUTH_NONE removed entries of other clients: %+v", mountTable.dump().MountList)
+	}
+
+	testReplier = &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 4, MOUNTPROC3UMNT, &CredentialStruct{Flavor: AuthSys, MachineName: "alpha"}, parms)
+	if 0 != len(mountTable.dump().MountList) {
+		t.Fatalf("dump() after UMNT returned unexpected %+v", mountTable.dump().MountList)
+	}
+}
@@ -0,0 +1,723 @@
+package nfsd
+
+import (
+	"fmt"
+
+	"github.com/swiftstack/xdr"
+)
+
+// packerStruct accumulates the XDR encoding of a sequence of items, remembering only the first error encountered
+type packerStruct struct {
+	buf []byte
+	err error
+}
+
+func (packer *packerStruct) pack(obj interface{}) {
+	var (
+		packed []byte
+	)
+
+	if nil != packer.err {
+		return
+	}
+
+	packed, packer.err = xdr.Pack(obj)
+	if nil != packer.err {
+		return
+	}
+
+	packer.buf = append(packer.buf, packed...)
+}
+
+func (packer *packerStruct) packStatus(status uint32) {
+	packer.pack(&StatusOnlyStruct{Status: status})
+}
+
+func (packer *packerStruct) packBool(b bool) {
+	packer.pack(&BooleanOnlyStruct{Bool: b})
+}
+
+func (packer *packerStruct) packUint32(u32 uint32) {
+	packer.pack(&UnsignedIntegerOnlyStruct{UnsignedInteger: u32})
+}
+
+func (packer *packerStruct) packUint64(u64 uint64) {
+	packer.pack(&UnsignedHyperIntegerOnlyStruct{UnsignedHyperInteger: u64})
+}
+
+func (packer *packerStruct) packOpaque(b []byte) {
+	packer.pack(&VariableLengthOpaqueDataOnlyStruct{VariableLengthOpaqueData: b})
+}
+
+func (packer *packerStruct) packFHandle(fHandle []byte) {
+	if nil != packer.err {
+		return
+	}
+
+	if FHSize3 < uint32(len(fHandle)) {
+		packer.err = fmt.Errorf("file handle length (%v) exceeds FHSize3 (%v)", len(fHandle), FHSize3)
+		return
+	}
+
+	packer.packOpaque(fHandle)
+}
+
+func (packer *packerStruct) packName(name string) {
+	if nil != packer.err {
+		return
+	}
+
+	if MntNameLen < uint32(len(name)) {
+		packer.err = fmt.Errorf("name length (%v) exceeds MntNameLen (%v)", len(name), MntNameLen)
+		return
+	}
+
+	packer.packOpaque([]byte(name)) // string<> shares the opaque<> encoding
+}
+
+func (packer *packerStruct) packDirPath(dirPath string) {
+	if nil != packer.err {
+		return
+	}
+
+	if MntPathLen < uint32(len(dirPath)) {
+		packer.err = fmt.Errorf("dirpath length (%v) exceeds MntPathLen (%v)", len(dirPath), MntPathLen)
+		return
+	}
+
+	packer.packOpaque([]byte(dirPath)) // string<> shares the opaque<> encoding
+}
+
+func (packer *packerStruct) packPreOpAttr(preOpAttr *PreOpAttrStruct) {
+	packer.packBool(preOpAttr.AttributesFollow)
+	if preOpAttr.AttributesFollow {
+		packer.pack(&preOpAttr.Attributes)
+	}
+}
+
+func (packer *packerStruct) packPostOpAttr(postOpAttr *PostOpAttrStruct) {
+	packer.packBool(postOpAttr.AttributesFollow)
+	if postOpAttr.AttributesFollow {
+		packer.pack(&postOpAttr.Attributes)
+	}
+}
+
+func (packer *packerStruct) packPostOpFh3(postOpFh3 *PostOpFh3Struct) {
+	packer.packBool(postOpFh3.HandleFollows)
+	if postOpFh3.HandleFollows {
+		packer.packFHandle(postOpFh3.Handle)
+	}
+}
+
+func (packer *packerStruct) packWCCData(wccData *WCCDataStruct) {
+	packer.packPreOpAttr(&wccData.Before)
+	packer.packPostOpAttr(&wccData.After)
+}
+
+func (packer *packerStruct) packTimeHow(timeHow uint32, time *NFSTime3Struct) {
+	if nil != packer.err {
+		return
+	}
+
+	switch timeHow {
+	case DontChange:
+		packer.packUint32(timeHow)
+	case SetToServerTime:
+		packer.packUint32(timeHow)
+	case SetToClientTime:
+		packer.packUint32(timeHow)
+		packer.pack(time)
+	default:
+		packer.err = fmt.Errorf("time_how (%v) not recognized", timeHow)
+	}
+}
+
+func (packer *packerStruct) packSAttr3(sAttr3 *SAttr3Struct) {
+	packer.packBool(sAttr3.SetMode)
+	if sAttr3.SetMode {
+		packer.packUint32(sAttr3.Mode)
+	}
+
+	packer.packBool(sAttr3.SetUID)
+	if sAttr3.SetUID {
+		packer.packUint32(sAttr3.UID)
+	}
+
+	packer.packBool(sAttr3.SetGID)
+	if sAttr3.SetGID {
+		packer.packUint32(sAttr3.GID)
+	}
+
+	packer.packBool(sAttr3.SetSize)
+	if sAttr3.SetSize {
+		packer.packUint64(sAttr3.Size)
+	}
+
+	packer.packTimeHow(sAttr3.SetATime, &sAttr3.ATime)
+	packer.packTimeHow(sAttr3.SetMTime, &sAttr3.MTime)
+}
+
+func (packer *packerStruct) packSAttrGuard3(sAttrGuard3 *SAttrGuard3Struct) {
+	packer.packBool(sAttrGuard3.CheckCTime)
+	if sAttrGuard3.CheckCTime {
+		packer.pack(&sAttrGuard3.CTime)
+	}
+}
+
+func (packer *packerStruct) packCreateHow(createHow *CreateHowStruct) {
+	if nil != packer.err {
+		return
+	}
+
+	switch createHow.Mode {
+	case Unchecked:
+		packer.packUint32(createHow.Mode)
+		packer.packSAttr3(&createHow.ObjAttributes)
+	case Guarded:
+		packer.packUint32(createHow.Mode)
+		packer.packSAttr3(&createHow.ObjAttributes)
+	case Exclusive:
+		packer.packUint32(createHow.Mode)
+		packer.pack(&CreateVerfOnlyStruct{Verf: createHow.Verf})
+	default:
+		packer.err = fmt.Errorf("createmode3 (%v) not recognized", createHow.Mode)
+	}
+}
+
+// The following pack() methods encode the results of those Mount V3 procedures containing XDR optional-data linked lists
+
+func (mountProc3DumpResults *MountProc3DumpResultsStruct) pack() (results []byte, err error) {
+	var (
+		mountBodyIndex int
+		packer         packerStruct
+	)
+
+	for mountBodyIndex = range mountProc3DumpResults.MountList {
+		packer.packBool(true)
+		packer.packName(mountProc3DumpResults.MountList[mountBodyIndex].HostName)
+		packer.packDirPath(mountProc3DumpResults.MountList[mountBodyIndex].Directory)
+	}
+	packer.packBool(false)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (mountProc3ExportResults *MountProc3ExportResultsStruct) pack() (results []byte, err error) {
+	var (
+		exportNodeIndex int
+		groupIndex      int
+		packer          packerStruct
+	)
+
+	for exportNodeIndex = range mountProc3ExportResults.Exports {
+		packer.packBool(true)
+		packer.packDirPath(mountProc3ExportResults.Exports[exportNodeIndex].Dir)
+		for groupIndex = range mountProc3ExportResults.Exports[exportNodeIndex].Groups {
+			packer.packBool(true)
+			packer.packName(mountProc3ExportResults.Exports[exportNodeIndex].Groups[groupIndex])
+		}
+		packer.packBool(false)
+	}
+	packer.packBool(false)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+// The following packResOK() methods encode the "resok" arm of each NFSv3 procedure's results union (including the leading status)
+
+func (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3SetAttrResults.Status)
+	packer.packWCCData(&nfsProc3SetAttrResults.WCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3LookupResults *NFSProc3LookupResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3LookupResults.Status)
+	packer.packFHandle(nfsProc3LookupResults.Object)
+	packer.packPostOpAttr(&nfsProc3LookupResults.ObjAttributes)
+	packer.packPostOpAttr(&nfsProc3LookupResults.DirAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3AccessResults *NFSProc3AccessResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3AccessResults.Status)
+	packer.packPostOpAttr(&nfsProc3AccessResults.ObjAttributes)
+	packer.packUint32(nfsProc3AccessResults.Access)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadLinkResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadLinkResults.SymLinkAttributes)
+	packer.packOpaque(nfsProc3ReadLinkResults.Path) // nfspath3 is a string<> which shares the opaque<> encoding
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadResults *NFSProc3ReadResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadResults.FileAttributes)
+	packer.packUint32(nfsProc3ReadResults.Count)
+	packer.packBool(nfsProc3ReadResults.EOF)
+	packer.packOpaque(nfsProc3ReadResults.Data)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3WriteResults *NFSProc3WriteResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3WriteResults.Status)
+	packer.packWCCData(&nfsProc3WriteResults.FileWCC)
+	packer.packUint32(nfsProc3WriteResults.Count)
+	packer.packUint32(nfsProc3WriteResults.Committed) // enum stable_how
+	packer.pack(&WriteVerfOnlyStruct{Verf: nfsProc3WriteResults.Verf})
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3CreateResults *NFSProc3CreateResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3CreateResults.Status)
+	packer.packPostOpFh3(&nfsProc3CreateResults.Obj)
+	packer.packPostOpAttr(&nfsProc3CreateResults.ObjAttributes)
+	packer.packWCCData(&nfsProc3CreateResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3MKDirResults.Status)
+	packer.packPostOpFh3(&nfsProc3MKDirResults.Obj)
+	packer.packPostOpAttr(&nfsProc3MKDirResults.ObjAttributes)
+	packer.packWCCData(&nfsProc3MKDirResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3SymLinkResults.Status)
+	packer.packPostOpFh3(&nfsProc3SymLinkResults.Obj)
+	packer.packPostOpAttr(&nfsProc3SymLinkResults.ObjAttributes)
+	packer.packWCCData(&nfsProc3SymLinkResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3RemoveResults.Status)
+	packer.packWCCData(&nfsProc3RemoveResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3RMDirResults.Status)
+	packer.packWCCData(&nfsProc3RMDirResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3RenameResults *NFSProc3RenameResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3RenameResults.Status)
+	packer.packWCCData(&nfsProc3RenameResults.FromDirWCC)
+	packer.packWCCData(&nfsProc3RenameResults.ToDirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3LinkResults *NFSProc3LinkResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3LinkResults.Status)
+	packer.packPostOpAttr(&nfsProc3LinkResults.FileAttributes)
+	packer.packWCCData(&nfsProc3LinkResults.LinkDirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		entryIndex int
+		packer     packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadDirResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadDirResults.DirAttributes)
+	packer.pack(&CookieVerfOnlyStruct{Verf: nfsProc3ReadDirResults.CookieVerf})
+
+	// entry3 *entries is an XDR optional-data linked list: each entry is preceded by TRUE and the list is terminated by FALSE
+
+	for entryIndex = range nfsProc3ReadDirResults.Entries {
+		packer.packBool(true)
+		packer.packUint64(nfsProc3ReadDirResults.Entries[entryIndex].FileID)
+		packer.packName(nfsProc3ReadDirResults.Entries[entryIndex].Name)
+		packer.packUint64(nfsProc3ReadDirResults.Entries[entryIndex].Cookie)
+	}
+	packer.packBool(false)
+
+	packer.packBool(nfsProc3ReadDirResults.EOF)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		entryIndex int
+		packer     packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadDirPlusResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadDirPlusResults.DirAttributes)
+	packer.pack(&CookieVerfOnlyStruct{Verf: nfsProc3ReadDirPlusResults.CookieVerf})
+
+	// entryplus3 *entries is an XDR optional-data linked list: each entry is preceded by TRUE and the list is terminated by FALSE
+
+	for entryIndex = range nfsProc3ReadDirPlusResults.Entries {
+		packer.packBool(true)
+		packer.packUint64(nfsProc3ReadDirPlusResults.Entries[entryIndex].FileID)
+		packer.packName(nfsProc3ReadDirPlusResults.Entries[entryIndex].Name)
+		packer.packUint64(nfsProc3ReadDirPlusResults.Entries[entryIndex].Cookie)
+		packer.packPostOpAttr(&nfsProc3ReadDirPlusResults.Entries[entryIndex].NameAttributes)
+		packer.packPostOpFh3(&nfsProc3ReadDirPlusResults.Entries[entryIndex].NameHandle)
+	}
+	packer.packBool(false)
+
+	packer.packBool(nfsProc3ReadDirPlusResults.EOF)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3FSStatResults.Status)
+	packer.packPostOpAttr(&nfsProc3FSStatResults.ObjAttributes)
+	packer.packUint64(nfsProc3FSStatResults.TBytes)
+	packer.packUint64(nfsProc3FSStatResults.FBytes)
+	packer.packUint64(nfsProc3FSStatResults.ABytes)
+	packer.packUint64(nfsProc3FSStatResults.TFiles)
+	packer.packUint64(nfsProc3FSStatResults.FFiles)
+	packer.packUint64(nfsProc3FSStatResults.AFiles)
+	packer.packUint32(nfsProc3FSStatResults.InvarSec)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3FSInfoResults.Status)
+	packer.packPostOpAttr(&nfsProc3FSInfoResults.ObjAttributes)
+	packer.packUint32(nfsProc3FSInfoResults.RTMax)
+	packer.packUint32(nfsProc3FSInfoResults.RTPref)
+	packer.packUint32(nfsProc3FSInfoResults.RTMult)
+	packer.packUint32(nfsProc3FSInfoResults.WTMax)
+	packer.packUint32(nfsProc3FSInfoResults.WTPref)
+	packer.packUint32(nfsProc3FSInfoResults.WTMult)
+	packer.packUint32(nfsProc3FSInfoResults.DTPref)
+	packer.packUint64(nfsProc3FSInfoResults.MaxFileSize)
+	packer.pack(&nfsProc3FSInfoResults.TimeDelta)
+	packer.packUint32(nfsProc3FSInfoResults.Properties)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3PathConfResults.Status)
+	packer.packPostOpAttr(&nfsProc3PathConfResults.ObjAttributes)
+	packer.packUint32(nfsProc3PathConfResults.LinkMax)
+	packer.packUint32(nfsProc3PathConfResults.NameMax)
+	packer.packBool(nfsProc3PathConfResults.NoTrunc)
+	packer.packBool(nfsProc3PathConfResults.ChOwnRestricted)
+	packer.packBool(nfsProc3PathConfResults.CaseInsensitive)
+	packer.packBool(nfsProc3PathConfResults.CasePreserving)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3CommitResults *NFSProc3CommitResultsStruct) packResOK() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3CommitResults.Status)
+	packer.packWCCData(&nfsProc3CommitResults.FileWCC)
+	packer.pack(&WriteVerfOnlyStruct{Verf: nfsProc3CommitResults.Verf})
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+// The following packResFail() methods encode the "resfail" arm of each NFSv3 procedure's results union (including the leading status)
+
+func (nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct) packResFail() (results []byte, err error) {
+	results, err = nfsProc3SetAttrResults.packResOK() // SETATTR3resok & SETATTR3resfail are identical
+	return
+}
+
+func (nfsProc3LookupResults *NFSProc3LookupResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3LookupResults.Status)
+	packer.packPostOpAttr(&nfsProc3LookupResults.DirAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3AccessResults *NFSProc3AccessResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3AccessResults.Status)
+	packer.packPostOpAttr(&nfsProc3AccessResults.ObjAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadLinkResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadLinkResults.SymLinkAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadResults *NFSProc3ReadResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadResults.FileAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3WriteResults *NFSProc3WriteResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3WriteResults.Status)
+	packer.packWCCData(&nfsProc3WriteResults.FileWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3CreateResults *NFSProc3CreateResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3CreateResults.Status)
+	packer.packWCCData(&nfsProc3CreateResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3MKDirResults *NFSProc3MKDirResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3MKDirResults.Status)
+	packer.packWCCData(&nfsProc3MKDirResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3SymLinkResults.Status)
+	packer.packWCCData(&nfsProc3SymLinkResults.DirWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3RemoveResults *NFSProc3RemoveResultsStruct) packResFail() (results []byte, err error) {
+	results, err = nfsProc3RemoveResults.packResOK() // REMOVE3resok & REMOVE3resfail are identical
+	return
+}
+
+func (nfsProc3RMDirResults *NFSProc3RMDirResultsStruct) packResFail() (results []byte, err error) {
+	results, err = nfsProc3RMDirResults.packResOK() // RMDIR3resok & RMDIR3resfail are identical
+	return
+}
+
+func (nfsProc3RenameResults *NFSProc3RenameResultsStruct) packResFail() (results []byte, err error) {
+	results, err = nfsProc3RenameResults.packResOK() // RENAME3resok & RENAME3resfail are identical
+	return
+}
+
+func (nfsProc3LinkResults *NFSProc3LinkResultsStruct) packResFail() (results []byte, err error) {
+	results, err = nfsProc3LinkResults.packResOK() // LINK3resok & LINK3resfail are identical
+	return
+}
+
+func (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadDirResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadDirResults.DirAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3ReadDirPlusResults.Status)
+	packer.packPostOpAttr(&nfsProc3ReadDirPlusResults.DirAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3FSStatResults *NFSProc3FSStatResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3FSStatResults.Status)
+	packer.packPostOpAttr(&nfsProc3FSStatResults.ObjAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3FSInfoResults.Status)
+	packer.packPostOpAttr(&nfsProc3FSInfoResults.ObjAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3PathConfResults *NFSProc3PathConfResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3PathConfResults.Status)
+	packer.packPostOpAttr(&nfsProc3PathConfResults.ObjAttributes)
+
+	results, err = packer.buf, packer.err
+	return
+}
+
+func (nfsProc3CommitResults *NFSProc3CommitResultsStruct) packResFail() (results []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packStatus(nfsProc3CommitResults.Status)
+	packer.packWCCData(&nfsProc3CommitResults.FileWCC)
+
+	results, err = packer.buf, packer.err
+	return
+}
@@ -0,0 +1,335 @@
+package nfsd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMountResultsPack(t *testing.T) {
+	var (
+		buf                     []byte
+		err                     error
+		mountProc3DumpResults   *MountProc3DumpResultsStruct
+		mountProc3ExportResults *MountProc3ExportResultsStruct
+	)
+
+	mountProc3DumpResults = &MountProc3DumpResultsStruct{}
+
+	buf, err = mountProc3DumpResults.pack()
+	if nil != err {
+		t.Fatalf("mountProc3DumpResults.pack() failed: %v", err)
+	}
+	if !bytes.Equal(wireBytes(t, "00000000"), buf) {
+		t.Fatalf("mountProc3DumpResults.pack() of empty mountlist returned unexpected %x", buf)
+	}
+
+	mountProc3DumpResults.MountList = []MountBodyStruct{{HostName: "client", Directory: "/export"}}
+
+	buf, err = mountProc3DumpResults.pack()
+	if nil != err {
+		t.Fatalf("mountProc3DumpResults.pack() failed: %v", err)
+	}
+	if !bytes.Equal(wireBytes(t, "00000001 00000006 636c6965 6e740000 00000007 2f657870 6f727400 00000000"), buf) {
+		t.Fatalf("mountProc3DumpResults.pack() returned unexpected %x", buf)
+	}
+
+	mountProc3ExportResults = &MountProc3ExportResultsStruct{
+		Exports: []ExportNodeStruct{
+			{Dir: "/a", Groups: []string{"*"}},
+			{Dir: "/b"},
+		},
+	}
+
+	buf, err = mountProc3ExportResults.pack()
+	if nil != err {
+		t.Fatalf("mountProc3ExportResults.pack() failed: %v", err)
+	}
+	if !bytes.Equal(wireBytes(t, "00000001 00000002 2f610000 00000001 00000001 2a000000 00000000"+ // "/a" exported to "*"
+		" 00000001 00000002 2f620000 00000000"+ //                                                    "/b" with no groups
+		" 00000000"), buf) {
+		t.Fatalf("mountProc3ExportResults.pack() returned unexpected %x", buf)
+	}
+}
+
+type packVectorStruct struct {
+	name string
+	pack func() ([]byte, error)
+	wire string
+}
+
+func testPackVectors(t *testing.T, packVectors []packVectorStruct) {
+	for _, packVector := range packVectors {
+		buf, err := packVector.pack()
+		if nil != err {
+			t.Fatalf("%s: pack failed: %v", packVector.name, err)
+		}
+		if !bytes.Equal(wireBytes(t, packVector.wire), buf) {
+			t.Fatalf("%s: pack returned %x, expected %s", packVector.name, buf, packVector.wire)
+		}
+	}
+}
+
+func TestNFSResOKPack(t *testing.T) {
+	var (
+		fHandle    = []byte{0x01, 0x02, 0x03, 0x04, 0x05}
+		objAttr    = PostOpAttrStruct{AttributesFollow: true, Attributes: fattr3RegularFile}
+		verf       = [NFS3WriteVerfSize]byte{0, 1, 2, 3, 4, 5, 6, 7}
+		wccData    = WCCDataStruct{Before: PreOpAttrStruct{AttributesFollow: true, Attributes: wccAttr}, After: objAttr}
+		wccDataHex = "00000001 " + wccAttrHex + " 00000001 " + fattr3RegularFileHex
+	)
+
+	testPackVectors(t, []packVectorStruct{
+		{
+			name: "SETATTR3resok",
+			pack: (&NFSProc3SetAttrResultsStruct{WCC: wccData}).packResOK,
+			wire: "00000000 " + wccDataHex,
+		},
+		{
+			name: "LOOKUP3resok",
+			pack: (&NFSProc3LookupResultsStruct{Object: fHandle, ObjAttributes: objAttr}).packResOK,
+			wire: "00000000 00000005 01020304 05000000 00000001 " + fattr3RegularFileHex + " 00000000",
+		},
+		{
+			name: "ACCESS3resok",
+			pack: (&NFSProc3AccessResultsStruct{Access: Access3Read | Access3Lookup}).packResOK,
+			wire: "00000000 00000000 00000003",
+		},
+		{
+			name: "READLINK3resok",
+			pack: (&NFSProc3ReadLinkResultsStruct{Path: []byte("../target")}).packResOK,
+			wire: "00000000 00000000 00000009 2e2e2f74 61726765 74000000",
+		},
+		{
+			name: "READ3resok",
+			pack: (&NFSProc3ReadResultsStruct{FileAttributes: objAttr, Count: 3, EOF: true, Data: []byte("abc")}).packResOK,
+			wire: "00000000 00000001 " + fattr3RegularFileHex + " 00000003 00000001 00000003 61626300",
+		},
+		{
+			name: "WRITE3resok",
+			pack: (&NFSProc3WriteResultsStruct{FileWCC: wccData, Count: 4096, Committed: FileSync, Verf: verf}).packResOK,
+			wire: "00000000 " + wccDataHex + " 00001000 00000002 00010203 04050607",
+		},
+		{
+			name: "CREATE3resok",
+			pack: (&NFSProc3CreateResultsStruct{Obj: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}}).packResOK,
+			wire: "00000000 00000001 00000005 01020304 05000000 00000000 00000000 00000000",
+		},
+		{
+			name: "MKDIR3resok",
+			pack: (&NFSProc3MKDirResultsStruct{DirWCC: wccData}).packResOK,
+			wire: "00000000 00000000 00000000 " + wccDataHex,
+		},
+		{
+			name: "SYMLINK3resok",
+			pack: (&NFSProc3SymLinkResultsStruct{Obj: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}, ObjAttributes: objAttr}).packResOK,
+			wire: "00000000 00000001 00000005 01020304 05000000 00000001 " + fattr3RegularFileHex + " 00000000 00000000",
+		},
+		{
+			name: "REMOVE3resok",
+			pack: (&NFSProc3RemoveResultsStruct{DirWCC: wccData}).packResOK,
+			wire: "00000000 " + wccDataHex,
+		},
+		{
+			name: "RMDIR3resok",
+			pack: (&NFSProc3RMDirResultsStruct{}).packResOK,
+			wire: "00000000 00000000 00000000",
+		},
+		{
+			name: "RENAME3resok",
+			pack: (&NFSProc3RenameResultsStruct{FromDirWCC: wccData}).packResOK,
+			wire: "00000000 " + wccDataHex + " 00000000 00000000",
+		},
+		{
+			name: "LINK3resok",
+			pack: (&NFSProc3LinkResultsStruct{FileAttributes: objAttr}).packResOK,
+			wire: "00000000 00000001 " + fattr3RegularFileHex + " 00000000 00000000",
+		},
+		{
+			name: "READDIR3resok (empty)",
+			pack: (&NFSProc3ReadDirResultsStruct{CookieVerf: [NFS3CookieVerfSize]byte{1}, EOF: true}).packResOK,
+			wire: "00000000 00000000 01000000 00000000 00000000 00000001",
+		},
+		{
+			name: "READDIR3resok",
+			pack: (&NFSProc3ReadDirResultsStruct{Entries: []DirListEntryStruct{{FileID: 42, Name: "a", Cookie: 3}, {FileID: 43, Name: "bcde", Cookie: 4}}}).packResOK,
+			wire: "00000000 00000000 00000000 00000000" +
+				" 00000001 00000000 0000002a 00000001 61000000 00000000 00000003" +
+				" 00000001 00000000 0000002b 00000004 62636465 00000000 00000004" +
+				" 00000000 00000000",
+		},
+		{
+			name: "READDIRPLUS3resok",
+			pack: (&NFSProc3ReadDirPlusResultsStruct{
+				Entries: []DirListEntryPlusStruct{
+					{FileID: 42, Name: "a", Cookie: 3, NameAttributes: objAttr, NameHandle: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}},
+					{FileID: 43, Name: "b", Cookie: 4},
+				},
+				EOF: true,
+			}).packResOK,
+			wire: "00000000 00000000 00000000 00000000" +
+				" 00000001 00000000 0000002a 00000001 61000000 00000000 00000003 00000001 " + fattr3RegularFileHex + " 00000001 00000005 01020304 05000000" +
+				" 00000001 00000000 0000002b 00000001 62000000 00000000 00000004 00000000 00000000" +
+				" 00000000 00000001",
+		},
+		{
+			name: "FSSTAT3resok",
+			pack: (&NFSProc3FSStatResultsStruct{TBytes: 1, FBytes: 2, ABytes: 3, TFiles: 4, FFiles: 5, AFiles: 6, InvarSec: 7}).packResOK,
+			wire: "00000000 00000000" +
+				" 00000000 00000001 00000000 00000002 00000000 00000003 00000000 00000004 00000000 00000005 00000000 00000006" +
+				" 00000007",
+		},
+		{
+			name: "FSINFO3resok",
+			pack: (&NFSProc3FSInfoResultsStruct{
+				RTMax:       1 << 20,
+				RTPref:      1 << 16,
+				RTMult:      4096,
+				WTMax:       1 << 20,
+				WTPref:      1 << 16,
+				WTMult:      4096,
+				DTPref:      8192,
+				MaxFileSize: 1<<63 - 1,
+				TimeDelta:   NFSTime3Struct{Seconds: 0, NSeconds: 1},
+				Properties:  FSF3Link | FSF3SymLink | FSF3Homogeneous | FSF3CanSetTime,
+			}).packResOK,
+			wire: "00000000 00000000 00100000 00010000 00001000 00100000 00010000 00001000 00002000" +
+				" 7fffffff ffffffff 00000000 00000001 0000001b",
+		},
+		{
+			name: "PATHCONF3resok",
+			pack: (&NFSProc3PathConfResultsStruct{LinkMax: 32000, NameMax: 255, NoTrunc: true, ChOwnRestricted: true, CasePreserving: true}).packResOK,
+			wire: "00000000 00000000 00007d00 000000ff 00000001 00000001 00000000 00000001",
+		},
+		{
+			name: "COMMIT3resok",
+			pack: (&NFSProc3CommitResultsStruct{Verf: verf}).packResOK,
+			wire: "00000000 00000000 00000000 00010203 04050607",
+		},
+	})
+}
+
+func TestNFSResOKPackRejectsOversizeFHandle(t *testing.T) {
+	var (
+		err error
+	)
+
+	_, err = (&NFSProc3LookupResultsStruct{Object: make([]byte, FHSize3+1)}).packResOK()
+	if nil == err {
+		t.Fatalf("LOOKUP3resok with a %v byte file handle should have failed to pack", FHSize3+1)
+	}
+
+	_, err = (&NFSProc3CreateResultsStruct{Obj: PostOpFh3Struct{HandleFollows: true, Handle: make([]byte, FHSize3+1)}}).packResOK()
+	if nil == err {
+		t.Fatalf("CREATE3resok with a %v byte file handle should have failed to pack", FHSize3+1)
+	}
+}
+
+// TestNFSResFailPack populates resok-only fields to verify that each resfail arm omits them
+func TestNFSResFailPack(t *testing.T) {
+	var (
+		fHandle    = []byte{0x01, 0x02, 0x03, 0x04, 0x05}
+		objAttr    = PostOpAttrStruct{AttributesFollow: true, Attributes: fattr3RegularFile}
+		objAttrHex = "00000001 " + fattr3RegularFileHex
+		verf       = [NFS3WriteVerfSize]byte{0, 1, 2, 3, 4, 5, 6, 7}
+		wccData    = WCCDataStruct{Before: PreOpAttrStruct{AttributesFollow: true, Attributes: wccAttr}, After: objAttr}
+		wccDataHex = "00000001 " + wccAttrHex + " " + objAttrHex
+	)
+
+	testPackVectors(t, []packVectorStruct{
+		{
+			name: "SETATTR3resfail",
+			pack: (&NFSProc3SetAttrResultsStruct{Status: NFS3ErrACCES, WCC: wccData}).packResFail,
+			wire: "0000000d " + wccDataHex,
+		},
+		{
+			name: "LOOKUP3resfail",
+			pack: (&NFSProc3LookupResultsStruct{Status: NFS3ErrNOENT, Object: fHandle, ObjAttributes: objAttr, DirAttributes: objAttr}).packResFail,
+			wire: "00000002 " + objAttrHex,
+		},
+		{
+			name: "ACCESS3resfail",
+			pack: (&NFSProc3AccessResultsStruct{Status: NFS3ErrSTALE, ObjAttributes: objAttr, Access: Access3Read}).packResFail,
+			wire: "00000046 " + objAttrHex,
+		},
+		{
+			name: "READLINK3resfail",
+			pack: (&NFSProc3ReadLinkResultsStruct{Status: NFS3ErrIO, SymLinkAttributes: objAttr, Path: []byte("x")}).packResFail,
+			wire: "00000005 " + objAttrHex,
+		},
+		{
+			name: "READ3resfail",
+			pack: (&NFSProc3ReadResultsStruct{Status: NFS3ErrIO, FileAttributes: objAttr, Count: 3, EOF: true, Data: []byte("abc")}).packResFail,
+			wire: "00000005 " + objAttrHex,
+		},
+		{
+			name: "WRITE3resfail",
+			pack: (&NFSProc3WriteResultsStruct{Status: NFS3ErrNOSPC, FileWCC: wccData, Count: 1, Committed: FileSync, Verf: verf}).packResFail,
+			wire: "0000001c " + wccDataHex,
+		},
+		{
+			name: "CREATE3resfail",
+			pack: (&NFSProc3CreateResultsStruct{Status: NFS3ErrEXIST, Obj: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}, ObjAttributes: objAttr, DirWCC: wccData}).packResFail,
+			wire: "00000011 " + wccDataHex,
+		},
+		{
+			name: "MKDIR3resfail",
+			pack: (&NFSProc3MKDirResultsStruct{Status: NFS3ErrEXIST, Obj: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}, ObjAttributes: objAttr, DirWCC: wccData}).packResFail,
+			wire: "00000011 " + wccDataHex,
+		},
+		{
+			name: "SYMLINK3resfail",
+			pack: (&NFSProc3SymLinkResultsStruct{Status: NFS3ErrEXIST, Obj: PostOpFh3Struct{HandleFollows: true, Handle: fHandle}, ObjAttributes: objAttr}).packResFail,
+			wire: "00000011 00000000 00000000",
+		},
+		{
+			name: "REMOVE3resfail",
+			pack: (&NFSProc3RemoveResultsStruct{Status: NFS3ErrNOENT, DirWCC: wccData}).packResFail,
+			wire: "00000002 " + wccDataHex,
+		},
+		{
+			name: "RMDIR3resfail",
+			pack: (&NFSProc3RMDirResultsStruct{Status: NFS3ErrNOTEMPTY, DirWCC: wccData}).packResFail,
+			wire: "00000042 " + wccDataHex,
+		},
+		{
+			name: "RENAME3resfail",
+			pack: (&NFSProc3RenameResultsStruct{Status: NFS3ErrNOTDIR, ToDirWCC: wccData}).packResFail,
+			wire: "00000014 00000000 00000000 " + wccDataHex,
+		},
+		{
+			name: "LINK3resfail",
+			pack: (&NFSProc3LinkResultsStruct{Status: NFS3ErrEXIST, FileAttributes: objAttr, LinkDirWCC: wccData}).packResFail,
+			wire: "00000011 " + objAttrHex + " " + wccDataHex,
+		},
+		{
+			name: "READDIR3resfail",
+			pack: (&NFSProc3ReadDirResultsStruct{Status: NFS3ErrBADCOOKIE, DirAttributes: objAttr, CookieVerf: [NFS3CookieVerfSize]byte{1}, Entries: []DirListEntryStruct{{FileID: 42, Name: "a", Cookie: 3}}, EOF: true}).packResFail,
+			wire: "00002713 " + objAttrHex,
+		},
+		{
+			name: "READDIRPLUS3resfail",
+			pack: (&NFSProc3ReadDirPlusResultsStruct{Status: NFS3ErrBADCOOKIE, DirAttributes: objAttr, Entries: []DirListEntryPlusStruct{{FileID: 42, Name: "a", Cookie: 3}}, EOF: true}).packResFail,
+			wire: "00002713 " + objAttrHex,
+		},
+		{
+			name: "FSSTAT3resfail",
+			pack: (&NFSProc3FSStatResultsStruct{Status: NFS3ErrIO, ObjAttributes: objAttr, TBytes: 1, InvarSec: 7}).packResFail,
+			wire: "00000005 " + objAttrHex,
+		},
+		{
+			name: "FSINFO3resfail",
+			pack: (&NFSProc3FSInfoResultsStruct{Status: NFS3ErrSTALE, RTMax: 1 << 20}).packResFail,
+			wire: "00000046 00000000",
+		},
+		{
+			name: "PATHCONF3resfail",
+			pack: (&NFSProc3PathConfResultsStruct{Status: NFS3ErrSERVERFAULT, ObjAttributes: objAttr, NameMax: 255}).packResFail,
+			wire: "00002716 " + objAttrHex,
+		},
+		{
+			name: "COMMIT3resfail",
+			pack: (&NFSProc3CommitResultsStruct{Status: NFS3ErrIO, FileWCC: wccData, Verf: verf}).packResFail,
+			wire: "00000005 " + wccDataHex,
+		},
+	})
+}
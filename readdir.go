@@ -0,0 +1,277 @@
+package nfsd
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Sizes (in bytes) of the fixed-size XDR items making up READDIR & READDIRPLUS results
+const (
+	xdrUnitSize           = uint32(4)
+	xdrBoolSize           = xdrUnitSize
+	xdrUint32Size         = xdrUnitSize
+	xdrUint64Size         = 2 * xdrUnitSize
+	xdrFAttr3Size         = uint32(84)
+	xdrCookieVerfSize     = NFS3CookieVerfSize
+	xdrDirListTrailerSize = xdrBoolSize + xdrBoolSize // FALSE terminating the entries list followed by bool eof
+)
+
+func xdrOpaqueSize(length int) (size uint32) {
+	size = xdrUint32Size + ((uint32(length) + xdrUnitSize - 1) & ^(xdrUnitSize - 1))
+	return
+}
+
+func (postOpAttr *PostOpAttrStruct) xdrSize() (size uint32) {
+	size = xdrBoolSize
+	if postOpAttr.AttributesFollow {
+		size += xdrFAttr3Size
+	}
+	return
+}
+
+func (postOpFh3 *PostOpFh3Struct) xdrSize() (size uint32) {
+	size = xdrBoolSize
+	if postOpFh3.HandleFollows {
+		size += xdrOpaqueSize(len(postOpFh3.Handle))
+	}
+	return
+}
+
+// dirInfoSize returns the portion of an entry3's or entryplus3's size that counts against READDIRPLUS's dircount
+func dirInfoSize(name string) (size uint32) {
+	size = xdrUint64Size + xdrOpaqueSize(len(name)) + xdrUint64Size // fileid, name, & cookie
+	return
+}
+
+// trimToCount discards trailing Entries (clearing EOF) such that the encoded results fit within the count
+// specified in NFSProc3ReadDirArgsStruct. Should not even the results lacking any entry (or, if any, a single entry)
+// fit, Status is set to NFS3ErrTOOSMALL.
+func (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) trimToCount(count uint32) {
+	var (
+		entryIndex int
+		entrySize  uint32
+		size       uint32
+	)
+
+	size = xdrUint32Size + nfsProc3ReadDirResults.DirAttributes.xdrSize() + xdrCookieVerfSize + xdrDirListTrailerSize
+	if count < size {
+		nfsProc3ReadDirResults.Status = NFS3ErrTOOSMALL
+		return
+	}
+
+	for entryIndex = range nfsProc3ReadDirResults.Entries {
+		entrySize = xdrBoolSize + dirInfoSize(nfsProc3ReadDirResults.Entries[entryIndex].Name)
+		if count < (size + entrySize) {
+			if 0 == entryIndex {
+				nfsProc3ReadDirResults.Status = NFS3ErrTOOSMALL
+			} else {
+				nfsProc3ReadDirResults.Entries = nfsProc3ReadDirResults.Entries[:entryIndex]
+				nfsProc3ReadDirResults.EOF = false
+			}
+			return
+		}
+		size += entrySize
+	}
+}
+
+// trimToCounts discards trailing Entries (clearing EOF) such that the encoded results fit within both the dirCount
+// and maxCount specified in NFSProc3ReadDirPlusArgsStruct. Should not even the results lacking any entry (or, if
+// any, a single entry) fit, Status is set to NFS3ErrTOOSMALL.
+func (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) trimToCounts(dirCount uint32, maxCount uint32) {
+	var (
+		dirInfo    uint32
+		entryIndex int
+		entrySize  uint32
+		size       uint32
+	)
+
+	size = xdrUint32Size + nfsProc3ReadDirPlusResults.DirAttributes.xdrSize() + xdrCookieVerfSize + xdrDirListTrailerSize
+	if maxCount < size {
+		nfsProc3ReadDirPlusResults.Status = NFS3ErrTOOSMALL
+		return
+	}
+
+	for entryIndex = range nfsProc3ReadDirPlusResults.Entries {
+		entrySize = xdrBoolSize +
+			dirInfoSize(nfsProc3ReadDirPlusResults.Entries[entryIndex].Name) +
+			nfsProc3ReadDirPlusResults.Entries[entryIndex].NameAttributes.xdrSize() +
+			nfsProc3ReadDirPlusResults.Entries[entryIndex].NameHandle.xdrSize()
+		if (maxCount < (size + entrySize)) || (dirCount < (dirInfo + dirInfoSize(nfsProc3ReadDirPlusResults.Entries[entryIndex].Name))) {
+			if 0 == entryIndex {
+				nfsProc3ReadDirPlusResults.Status = NFS3ErrTOOSMALL
+			} else {
+				nfsProc3ReadDirPlusResults.Entries = nfsProc3ReadDirPlusResults.Entries[:entryIndex]
+				nfsProc3ReadDirPlusResults.EOF = false
+			}
+			return
+		}
+		size += entrySize
+		dirInfo += dirInfoSize(nfsProc3ReadDirPlusResults.Entries[entryIndex].Name)
+	}
+}
+
+// DirEntryStruct describes an entry of a directory to be returned by READDIR or READDIRPLUS (see AppendEntries)
+type DirEntryStruct struct {
+	Name   string
+	Cookie uint64
+}
+
+// Cookies of the "." & ".." entries returned by NameCookieDirEntries
+const (
+	nameCookieDot    = uint64(1)
+	nameCookieDotDot = uint64(2)
+)
+
+// nameCookieTieBits is the number of low-order bits of each name's hash cleared (see NameCookieDirEntries) such that
+// the cookies of names whose hashes collide may be told apart
+const nameCookieTieBits = 8
+
+// NameCookieDirEntries returns the entries of a directory (including "." & "..") following cookie in cookie order.
+// Each cookie is derived from the name of its entry such that it remains valid across modifications of the
+// directory (and across restarts of the server) without the need for a cookie verifier. The low-order bits of
+// each cookie are reserved to break ties between names whose hashes collide: such names are ordered by name and
+// each is assigned the cookie following that of the one before it (such that their cookies remain stable unless
+// another colliding name is added or removed).
+//
+// Arguments:
+//
+//	names  specifies the names of the entries of the directory (excluding "." & "..")
+//	cookie specifies the cookie of the entry the returned entries are to follow (or 0 to return all of them)
+//
+// Returns:
+//
+//	dirEntries are the entries following cookie in cookie order
+func NameCookieDirEntries(names []string, cookie uint64) (dirEntries []DirEntryStruct) {
+	var (
+		hash = fnv.New64a()
+	)
+
+	dirEntries = nameCookieDirEntries(names, cookie, func(name string) (nameHash uint64) {
+		hash.Reset()
+		_, _ = hash.Write([]byte(name))
+		nameHash = hash.Sum64()
+		return
+	})
+
+	return
+}
+
+// nameCookieDirEntries implements NameCookieDirEntries using nameHash to hash each name
+func nameCookieDirEntries(names []string, cookie uint64, nameHash func(name string) (hash uint64)) (dirEntries []DirEntryStruct) {
+	var (
+		dirEntry   DirEntryStruct
+		entryIndex int
+		lastCookie uint64
+		name       string
+	)
+
+	dirEntries = make([]DirEntryStruct, 0, 2+len(names))
+
+	for _, name = range names {
+		dirEntry = DirEntryStruct{Name: name, Cookie: (nameHash(name) >> 1) &^ ((1 << nameCookieTieBits) - 1)}
+		if nameCookieDotDot >= dirEntry.Cookie {
+			dirEntry.Cookie = 1 << nameCookieTieBits
+		}
+		dirEntries = append(dirEntries, dirEntry)
+	}
+
+	sort.Slice(dirEntries, func(i int, j int) bool {
+		if dirEntries[i].Cookie == dirEntries[j].Cookie {
+			return dirEntries[i].Name < dirEntries[j].Name
+		}
+		return dirEntries[i].Cookie < dirEntries[j].Cookie
+	})
+
+	lastCookie = nameCookieDotDot
+	for entryIndex = range dirEntries {
+		if lastCookie >= dirEntries[entryIndex].Cookie {
+			dirEntries[entryIndex].Cookie = lastCookie + 1
+		}
+		lastCookie = dirEntries[entryIndex].Cookie
+	}
+
+	dirEntries = append([]DirEntryStruct{{Name: ".", Cookie: nameCookieDot}, {Name: "..", Cookie: nameCookieDotDot}}, dirEntries...)
+
+	dirEntries = dirEntries[sort.Search(len(dirEntries), func(i int) bool { return dirEntries[i].Cookie > cookie }):]
+
+	return
+}
+
+// AppendEntries appends those of dirEntries that fit within the count specified in NFSProc3ReadDirArgsStruct to
+// Entries (setting EOF if all of them do). Should not even a single entry fit, Status is set to NFS3ErrTOOSMALL.
+//
+// Arguments:
+//
+//	count      specifies the count of the READDIR request
+//	dirEntries specifies the entries of the directory following the cookie of the READDIR request
+//	fileIDOf   returns the FileID of dirEntry (or !ok should it no longer exist, in which case it is skipped)
+func (nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct) AppendEntries(count uint32, dirEntries []DirEntryStruct, fileIDOf func(dirEntry DirEntryStruct) (fileID uint64, ok bool)) {
+	var (
+		dirEntry DirEntryStruct
+		fileID   uint64
+		ok       bool
+		size     uint32
+	)
+
+	nfsProc3ReadDirResults.EOF = true
+
+	size = xdrUint32Size + nfsProc3ReadDirResults.DirAttributes.xdrSize() + xdrCookieVerfSize + xdrDirListTrailerSize
+
+	for _, dirEntry = range dirEntries {
+		if size > count {
+			nfsProc3ReadDirResults.EOF = false
+			break
+		}
+		fileID, ok = fileIDOf(dirEntry)
+		if !ok {
+			continue
+		}
+		nfsProc3ReadDirResults.Entries = append(nfsProc3ReadDirResults.Entries, DirListEntryStruct{FileID: fileID, Name: dirEntry.Name, Cookie: dirEntry.Cookie})
+		size += xdrBoolSize + dirInfoSize(dirEntry.Name)
+	}
+
+	nfsProc3ReadDirResults.trimToCount(count)
+}
+
+// AppendEntries appends those of dirEntries that fit within both the dirCount and maxCount specified in
+// NFSProc3ReadDirPlusArgsStruct to Entries (setting EOF if all of them do). Should not even a single entry fit,
+// Status is set to NFS3ErrTOOSMALL.
+//
+// Arguments:
+//
+//	dirCount   specifies the dircount of the READDIRPLUS request
+//	maxCount   specifies the maxcount of the READDIRPLUS request
+//	dirEntries specifies the entries of the directory following the cookie of the READDIRPLUS request
+//	entryOf    returns the FileID, NameAttributes, & NameHandle of dirEntry (Name & Cookie are taken from
+//	           dirEntry) or !ok should it no longer exist (in which case it is skipped)
+func (nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct) AppendEntries(dirCount uint32, maxCount uint32, dirEntries []DirEntryStruct, entryOf func(dirEntry DirEntryStruct) (dirListEntryPlus DirListEntryPlusStruct, ok bool)) {
+	var (
+		dirEntry         DirEntryStruct
+		dirInfo          uint32
+		dirListEntryPlus DirListEntryPlusStruct
+		ok               bool
+		size             uint32
+	)
+
+	nfsProc3ReadDirPlusResults.EOF = true
+
+	size = xdrUint32Size + nfsProc3ReadDirPlusResults.DirAttributes.xdrSize() + xdrCookieVerfSize + xdrDirListTrailerSize
+
+	for _, dirEntry = range dirEntries {
+		if (size > maxCount) || (dirInfo > dirCount) {
+			nfsProc3ReadDirPlusResults.EOF = false
+			break
+		}
+		dirListEntryPlus, ok = entryOf(dirEntry)
+		if !ok {
+			continue
+		}
+		dirListEntryPlus.Name = dirEntry.Name
+		dirListEntryPlus.Cookie = dirEntry.Cookie
+		nfsProc3ReadDirPlusResults.Entries = append(nfsProc3ReadDirPlusResults.Entries, dirListEntryPlus)
+		size += xdrBoolSize + dirInfoSize(dirEntry.Name) + dirListEntryPlus.NameAttributes.xdrSize() + dirListEntryPlus.NameHandle.xdrSize()
+		dirInfo += dirInfoSize(dirEntry.Name)
+	}
+
+	nfsProc3ReadDirPlusResults.trimToCounts(dirCount, maxCount)
+}
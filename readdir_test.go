@@ -0,0 +1,274 @@
+package nfsd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadDirTrimToCount(t *testing.T) {
+	var (
+		count                  uint32
+		entryIndex             int
+		nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct
+		results                []byte
+		err                    error
+	)
+
+	for count = 0; count < 512; count++ {
+		nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{
+			Status:        OK,
+			DirAttributes: PostOpAttrStruct{AttributesFollow: true},
+			Entries:       make([]DirListEntryStruct, 8),
+			EOF:           true,
+		}
+		for entryIndex = range nfsProc3ReadDirResults.Entries {
+			nfsProc3ReadDirResults.Entries[entryIndex] = DirListEntryStruct{FileID: uint64(entryIndex), Name: fmt.Sprintf("file_%v", entryIndex), Cookie: uint64(entryIndex + 1)}
+		}
+
+		nfsProc3ReadDirResults.trimToCount(count)
+
+		if OK != nfsProc3ReadDirResults.Status {
+			if NFS3ErrTOOSMALL != nfsProc3ReadDirResults.Status {
+				t.Fatalf("count == %v: unexpected Status %v", count, nfsProc3ReadDirResults.Status)
+			}
+			continue
+		}
+
+		results, err = nfsProc3ReadDirResults.packResOK()
+		if nil != err {
+			t.Fatalf("count == %v: packResOK() failed: %v", count, err)
+		}
+		if uint32(len(results)) > count {
+			t.Fatalf("count == %v: packResOK() returned %v bytes", count, len(results))
+		}
+		if nfsProc3ReadDirResults.EOF != (8 == len(nfsProc3ReadDirResults.Entries)) {
+			t.Fatalf("count == %v: EOF == %v with %v entries", count, nfsProc3ReadDirResults.EOF, len(nfsProc3ReadDirResults.Entries))
+		}
+		if (8 > len(nfsProc3ReadDirResults.Entries)) && (uint32(len(results))+32 <= count) {
+			t.Fatalf("count == %v: trimmed to %v entries leaving room for another", count, len(nfsProc3ReadDirResults.Entries))
+		}
+	}
+}
+
+func TestReadDirPlusTrimToCounts(t *testing.T) {
+	var (
+		nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct
+		results                    []byte
+		err                        error
+	)
+
+	newResults := func() *NFSProc3ReadDirPlusResultsStruct {
+		return &NFSProc3ReadDirPlusResultsStruct{
+			Status: OK,
+			Entries: []DirListEntryPlusStruct{
+				{FileID: 1, Name: "a", Cookie: 1, NameAttributes: PostOpAttrStruct{AttributesFollow: true}, NameHandle: PostOpFh3Struct{HandleFollows: true, Handle: make([]byte, 32)}},
+				{FileID: 2, Name: "b", Cookie: 2, NameAttributes: PostOpAttrStruct{AttributesFollow: true}, NameHandle: PostOpFh3Struct{HandleFollows: true, Handle: make([]byte, 32)}},
+			},
+			EOF: true,
+		}
+	}
+
+	// Each entry here is 4 + (8 + 8 + 8) + 88 + 40 == 156 bytes of which 24 bytes count against dircount
+
+	nfsProc3ReadDirPlusResults = newResults()
+	nfsProc3ReadDirPlusResults.trimToCounts(4096, 4096)
+	if (OK != nfsProc3ReadDirPlusResults.Status) || (2 != len(nfsProc3ReadDirPlusResults.Entries)) || !nfsProc3ReadDirPlusResults.EOF {
+		t.Fatalf("trimToCounts(4096, 4096) should not have trimmed")
+	}
+
+	nfsProc3ReadDirPlusResults = newResults()
+	nfsProc3ReadDirPlusResults.trimToCounts(24, 4096)
+	if (OK != nfsProc3ReadDirPlusResults.Status) || (1 != len(nfsProc3ReadDirPlusResults.Entries)) || nfsProc3ReadDirPlusResults.EOF {
+		t.Fatalf("trimToCounts(24, 4096) should have trimmed to one entry")
+	}
+
+	nfsProc3ReadDirPlusResults = newResults()
+	nfsProc3ReadDirPlusResults.trimToCounts(4096, 4+4+8+156+8)
+	if (OK != nfsProc3ReadDirPlusResults.Status) || (1 != len(nfsProc3ReadDirPlusResults.Entries)) || nfsProc3ReadDirPlusResults.EOF {
+		t.Fatalf("trimToCounts(4096, 180) should have trimmed to one entry")
+	}
+	results, err = nfsProc3ReadDirPlusResults.packResOK()
+	if nil != err {
+		t.Fatalf("packResOK() failed: %v", err)
+	}
+	if 180 != len(results) {
+		t.Fatalf("packResOK() returned %v bytes... expected 180", len(results))
+	}
+
+	nfsProc3ReadDirPlusResults = newResults()
+	nfsProc3ReadDirPlusResults.trimToCounts(4096, 179)
+	if NFS3ErrTOOSMALL != nfsProc3ReadDirPlusResults.Status {
+		t.Fatalf("trimToCounts(4096, 179) should have returned NFS3ErrTOOSMALL")
+	}
+
+	nfsProc3ReadDirPlusResults = newResults()
+	nfsProc3ReadDirPlusResults.Entries = nil
+	nfsProc3ReadDirPlusResults.trimToCounts(0, 4096)
+	if (OK != nfsProc3ReadDirPlusResults.Status) || !nfsProc3ReadDirPlusResults.EOF {
+		t.Fatalf("trimToCounts() of no entries within maxCount should not have returned NFS3ErrTOOSMALL")
+	}
+}
+
+func TestReadDirTrimToCountEmpty(t *testing.T) {
+	var (
+		count                      uint32
+		err                        error
+		headerSize                 uint32
+		nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct
+		nfsProc3ReadDirResults     *NFSProc3ReadDirResultsStruct
+		results                    []byte
+	)
+
+	results, err = (&NFSProc3ReadDirResultsStruct{DirAttributes: PostOpAttrStruct{AttributesFollow: true}, EOF: true}).packResOK()
+	if nil != err {
+		t.Fatalf("packResOK() failed: %v", err)
+	}
+	headerSize = uint32(len(results))
+
+	for count = 0; count < headerSize+8; count++ {
+		nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: OK, DirAttributes: PostOpAttrStruct{AttributesFollow: true}, EOF: true}
+		nfsProc3ReadDirResults.trimToCount(count)
+		if (count < headerSize) != (NFS3ErrTOOSMALL == nfsProc3ReadDirResults.Status) {
+			t.Fatalf("READDIR of empty directory with count == %v (header is %v bytes) returned Status %v", count, headerSize, nfsProc3ReadDirResults.Status)
+		}
+
+		nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{Status: OK, DirAttributes: PostOpAttrStruct{AttributesFollow: true}, EOF: true}
+		nfsProc3ReadDirPlusResults.trimToCounts(0, count)
+		if (count < headerSize) != (NFS3ErrTOOSMALL == nfsProc3ReadDirPlusResults.Status) {
+			t.Fatalf("READDIRPLUS of empty directory with maxcount == %v (header is %v bytes) returned Status %v", count, headerSize, nfsProc3ReadDirPlusResults.Status)
+		}
+	}
+}
+
+func TestReadDirAppendEntries(t *testing.T) {
+	var (
+		calls                      int
+		count                      uint32
+		dirEntries                 []DirEntryStruct
+		dirEntryIndex              int
+		err                        error
+		names                      = make([]string, 0, 64)
+		nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct
+		nfsProc3ReadDirResults     *NFSProc3ReadDirResultsStruct
+		results                    []byte
+	)
+
+	for dirEntryIndex = 0; dirEntryIndex < cap(names); dirEntryIndex++ {
+		names = append(names, fmt.Sprintf("file_%v", dirEntryIndex))
+	}
+
+	dirEntries = NameCookieDirEntries(names, 0)
+	if (2+len(names) != len(dirEntries)) || ("." != dirEntries[0].Name) || (".." != dirEntries[1].Name) {
+		t.Fatalf("NameCookieDirEntries() returned %+v", dirEntries)
+	}
+	for dirEntryIndex = 1; dirEntryIndex < len(dirEntries); dirEntryIndex++ {
+		if dirEntries[dirEntryIndex-1].Cookie >= dirEntries[dirEntryIndex].Cookie {
+			t.Fatalf("NameCookieDirEntries() returned entries out of cookie order")
+		}
+	}
+	if 2+len(names)-10 != len(NameCookieDirEntries(names, dirEntries[9].Cookie)) {
+		t.Fatalf("NameCookieDirEntries() following the cookie of the 10th entry should have returned the remainder")
+	}
+
+	// All entries fitting within count (save those reported removed) are returned
+
+	nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: OK, DirAttributes: PostOpAttrStruct{AttributesFollow: true}}
+	nfsProc3ReadDirResults.AppendEntries(65536, dirEntries, func(dirEntry DirEntryStruct) (fileID uint64, ok bool) {
+		return dirEntry.Cookie, ("file_7" != dirEntry.Name)
+	})
+	if (OK != nfsProc3ReadDirResults.Status) || !nfsProc3ReadDirResults.EOF || (len(dirEntries)-1 != len(nfsProc3ReadDirResults.Entries)) {
+		t.Fatalf("AppendEntries() returned %v entries (EOF: %v, Status: %v)", len(nfsProc3ReadDirResults.Entries), nfsProc3ReadDirResults.EOF, nfsProc3ReadDirResults.Status)
+	}
+
+	// Otherwise, the results fit within count & entries beyond those returned are not visited
+
+	for count = 256; count < 1024; count += 64 {
+		calls = 0
+		nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: OK, DirAttributes: PostOpAttrStruct{AttributesFollow: true}}
+		nfsProc3ReadDirResults.AppendEntries(count, dirEntries, func(dirEntry DirEntryStruct) (fileID uint64, ok bool) {
+			calls++
+			return dirEntry.Cookie, true
+		})
+		results, err = nfsProc3ReadDirResults.packResOK()
+		if (nil != err) || (OK != nfsProc3ReadDirResults.Status) || nfsProc3ReadDirResults.EOF || (uint32(len(results)) > count) {
+			t.Fatalf("AppendEntries(%v) returned %v bytes (EOF: %v, Status: %v, err: %v)", count, len(results), nfsProc3ReadDirResults.EOF, nfsProc3ReadDirResults.Status, err)
+		}
+		if calls > len(nfsProc3ReadDirResults.Entries)+1 {
+			t.Fatalf("AppendEntries(%v) returned %v entries yet visited %v", count, len(nfsProc3ReadDirResults.Entries), calls)
+		}
+
+		calls = 0
+		nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{Status: OK, DirAttributes: PostOpAttrStruct{AttributesFollow: true}}
+		nfsProc3ReadDirPlusResults.AppendEntries(count/4, count, dirEntries, func(dirEntry DirEntryStruct) (dirListEntryPlus DirListEntryPlusStruct, ok bool) {
+			calls++
+			dirListEntryPlus = DirListEntryPlusStruct{
+				FileID:         dirEntry.Cookie,
+				NameAttributes: PostOpAttrStruct{AttributesFollow: true},
+				NameHandle:     PostOpFh3Struct{HandleFollows: true, Handle: make([]byte, 8)},
+			}
+			return dirListEntryPlus, true
+		})
+		results, err = nfsProc3ReadDirPlusResults.packResOK()
+		if (nil != err) || (OK != nfsProc3ReadDirPlusResults.Status) || nfsProc3ReadDirPlusResults.EOF || (uint32(len(results)) > count) {
+			t.Fatalf("AppendEntries(%v, %v) returned %v bytes (EOF: %v, Status: %v, err: %v)", count/4, count, len(results), nfsProc3ReadDirPlusResults.EOF, nfsProc3ReadDirPlusResults.Status, err)
+		}
+		if (OK != nfsProc3ReadDirPlusResults.Status) || ("." != nfsProc3ReadDirPlusResults.Entries[0].Name) {
+			t.Fatalf("AppendEntries(%v, %v) did not take Name from dirEntries", count/4, count)
+		}
+		if calls > len(nfsProc3ReadDirPlusResults.Entries)+1 {
+			t.Fatalf("AppendEntries(%v, %v) returned %v entries yet visited %v", count/4, count, len(nfsProc3ReadDirPlusResults.Entries), calls)
+		}
+	}
+}
+
+func TestNameCookieDirEntriesCollisions(t *testing.T) {
+	var (
+		dirEntries    []DirEntryStruct
+		dirEntryIndex int
+		names         = make([]string, 0, 300)
+	)
+
+	// All but "next" hash alike while "next" hashes to the cookie following those of the others if they didn't collide
+
+	for dirEntryIndex = 0; dirEntryIndex < cap(names)-1; dirEntryIndex++ {
+		names = append(names, fmt.Sprintf("file_%03v", dirEntryIndex))
+	}
+	names = append(names, "next")
+
+	nameHash := func(name string) (hash uint64) {
+		hash = uint64(1) << 32
+		if "next" == name {
+			hash += 1 << (nameCookieTieBits + 1)
+		}
+		return
+	}
+
+	dirEntries = nameCookieDirEntries(names, 0, nameHash)
+	if 2+len(names) != len(dirEntries) {
+		t.Fatalf("nameCookieDirEntries() returned %v entries... expected %v", len(dirEntries), 2+len(names))
+	}
+	for dirEntryIndex = 1; dirEntryIndex < len(dirEntries); dirEntryIndex++ {
+		if dirEntries[dirEntryIndex-1].Cookie >= dirEntries[dirEntryIndex].Cookie {
+			t.Fatalf("nameCookieDirEntries() returned cookies %v (of %s) & %v (of %s) out of order", dirEntries[dirEntryIndex-1].Cookie, dirEntries[dirEntryIndex-1].Name, dirEntries[dirEntryIndex].Cookie, dirEntries[dirEntryIndex].Name)
+		}
+	}
+	if ("file_000" != dirEntries[2].Name) || ("file_298" != dirEntries[300].Name) || ("next" != dirEntries[301].Name) {
+		t.Fatalf("nameCookieDirEntries() did not order colliding names by name")
+	}
+
+	// Resuming following the cookie of any entry returns exactly those after it
+
+	for dirEntryIndex = range dirEntries {
+		resumed := nameCookieDirEntries(names, dirEntries[dirEntryIndex].Cookie, nameHash)
+		if (len(dirEntries)-dirEntryIndex-1 != len(resumed)) || ((0 < len(resumed)) && (dirEntries[dirEntryIndex+1] != resumed[0])) {
+			t.Fatalf("nameCookieDirEntries() following the cookie of %s returned %v entries", dirEntries[dirEntryIndex].Name, len(resumed))
+		}
+	}
+
+	// A name whose hash collides with no other keeps the cookie derived from its hash alone
+
+	dirEntries = nameCookieDirEntries([]string{"file_000", "next"}, 0, nameHash)
+	if (nameHash("next")>>1 != dirEntries[3].Cookie) || (nameHash("file_000")>>1 != dirEntries[2].Cookie) {
+		t.Fatalf("nameCookieDirEntries() returned %+v", dirEntries)
+	}
+}
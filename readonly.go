@@ -0,0 +1,21 @@
+package nfsd
+
+// Access3Modify, Access3Extend, and Access3Delete are never granted to clients whose access is read-only
+const readOnlyAccessMask = ^(Access3Modify | Access3Extend | Access3Delete)
+
+var globalReadOnly bool // used by NFSv3 servers launched via StartIPv4{TCP|UDP}NFSv3Server
+
+// admitMutation is like admit but is used by procedures that would modify the file system. If the client's
+// access is read-only, status is returned as NFS3ErrROFS such that the callback is never invoked.
+func (nfsRequestHandler *nfsRequestHandlerStruct) admitMutation(connHandle connHandleInterface, credential *CredentialStruct, fHandles ...*[]byte) (export *exportStruct, admittedCredential *CredentialStruct, status uint32) {
+	var (
+		readOnly bool
+	)
+
+	export, admittedCredential, readOnly, status = nfsRequestHandler.admit(connHandle, credential, fHandles...)
+	if (OK == status) && readOnly {
+		status = NFS3ErrROFS
+	}
+
+	return
+}
@@ -0,0 +1,108 @@
+package nfsd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/swiftstack/xdr"
+)
+
+// testReplierStruct captures the reply to a call handed directly to a request handler
+type testReplierStruct struct {
+	addr         net.Addr // reported as that of the client (may be nil)
+	authRejectOK bool     // if false, sendAuthErrorReply() fails (with errAuthErrorReplyUnavailable)
+	results      []byte
+	acceptStat   uint32
+	authStat     uint32
+	replies      int // number of replies sent
+}
+
+func (testReplier *testReplierStruct) RemoteAddr() net.Addr {
+	return testReplier.addr
+}
+
+func (testReplier *testReplierStruct) sendAcceptedSuccess(results []byte) (err error) {
+	testReplier.results = results
+	testReplier.replies++
+	return
+}
+
+func (testReplier *testReplierStruct) sendAcceptedOtherErrorReply(acceptStat uint32) (err error) {
+	testReplier.acceptStat = acceptStat
+	testReplier.replies++
+	return
+}
+
+func (testReplier *testReplierStruct) sendAuthErrorReply(authStat uint32) (err error) {
+	if !testReplier.authRejectOK {
+		err = errAuthErrorReplyUnavailable
+		return
+	}
+	testReplier.authStat = authStat
+	testReplier.replies++
+	return
+}
+
+func (testReplier *testReplierStruct) sentReply() (sent bool) {
+	sent = 0 < testReplier.replies
+	return
+}
+
+// testReadOnlyNFSv3Struct panics (via the nil embedded interface) if any unexpected callback is invoked
+type testReadOnlyNFSv3Struct struct {
+	NFSv3Interface
+	t *testing.T
+}
+
+func (testReadOnlyNFSv3 *testReadOnlyNFSv3Struct) ErrorLog(err error) {
+	testReadOnlyNFSv3.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testReadOnlyNFSv3 *testReadOnlyNFSv3Struct) NFSProc3Access(credential *CredentialStruct, nfsProc3AccessArgs *NFSProc3AccessArgsStruct) (nfsProc3AccessResults *NFSProc3AccessResultsStruct) {
+	nfsProc3AccessResults = &NFSProc3AccessResultsStruct{Status: OK, Access: nfsProc3AccessArgs.Access}
+	return
+}
+
+func TestReadOnly(t *testing.T) {
+	var (
+		err          error
+		parms        []byte
+		testReplier  = &testReplierStruct{}
+		expectedROFS = []byte{0, 0, 0, 30, 0, 0, 0, 0, 0, 0, 0, 0} // NFS3ErrROFS followed by wcc_data lacking both pre_op_attr & post_op_attr
+	)
+
+	nfsRequestHandler := &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(&testReadOnlyNFSv3Struct{t: t}), readOnly: true}
+	credential := &CredentialStruct{Flavor: AuthSys}
+
+	parms, err = xdr.Pack(&NFSProc3RemoveArgsStruct{Where: DirOpArgs3Struct{Dir: []byte{1, 2}, Name: "victim"}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	nfsRequestHandler.dispatch(testReplier, 1, NFSPROC3REMOVE, credential, parms)
+	if !bytes.Equal(expectedROFS, testReplier.results) {
+		t.Fatalf("REMOVE returned %v... expected %v", testReplier.results, expectedROFS)
+	}
+
+	parms, err = xdr.Pack(&NFSProc3AccessArgsStruct{Object: []byte{1, 2}, Access: Access3Read | Access3Modify | Access3Extend | Access3Delete | Access3Execute})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	nfsRequestHandler.dispatch(testReplier, 2, NFSPROC3ACCESS, credential, parms)
+	if (12 != len(testReplier.results)) || (OK != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("ACCESS returned %v", testReplier.results)
+	}
+	if (Access3Read | Access3Execute) != binary.BigEndian.Uint32(testReplier.results[8:]) {
+		t.Fatalf("ACCESS granted %#x... expected only Access3Read|Access3Execute", binary.BigEndian.Uint32(testReplier.results[8:]))
+	}
+
+	nfsRequestHandler.readOnly = false
+
+	nfsRequestHandler.dispatch(testReplier, 3, NFSPROC3ACCESS, credential, parms)
+	if (Access3Read | Access3Modify | Access3Extend | Access3Delete | Access3Execute) != binary.BigEndian.Uint32(testReplier.results[8:]) {
+		t.Fatalf("ACCESS granted %#x when not read-only", binary.BigEndian.Uint32(testReplier.results[8:]))
+	}
+}
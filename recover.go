@@ -0,0 +1,102 @@
+package nfsd
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/swiftstack/onc"
+)
+
+var (
+	mountProcNames = map[uint32]string{
+		ProcNULL:          "MOUNTPROC3_NULL",
+		MOUNTPROC3MNT:     "MOUNTPROC3_MNT",
+		MOUNTPROC3DUMP:    "MOUNTPROC3_DUMP",
+		MOUNTPROC3UMNT:    "MOUNTPROC3_UMNT",
+		MOUNTPROC3UMNTALL: "MOUNTPROC3_UMNTALL",
+		MOUNTPROC3EXPORT:  "MOUNTPROC3_EXPORT",
+	}
+	nfsProcNames = map[uint32]string{
+		ProcNULL:            "NFSPROC3_NULL",
+		NFSPROC3GETATTR:     "NFSPROC3_GETATTR",
+		NFSPROC3SETATTR:     "NFSPROC3_SETATTR",
+		NFSPROC3LOOKUP:      "NFSPROC3_LOOKUP",
+		NFSPROC3ACCESS:      "NFSPROC3_ACCESS",
+		NFSPROC3READLINK:    "NFSPROC3_READLINK",
+		NFSPROC3READ:        "NFSPROC3_READ",
+		NFSPROC3WRITE:       "NFSPROC3_WRITE",
+		NFSPROC3CREATE:      "NFSPROC3_CREATE",
+		NFSPROC3MKDIR:       "NFSPROC3_MKDIR",
+		NFSPROC3SYMLINK:     "NFSPROC3_SYMLINK",
+		NFSPROC3REMOVE:      "NFSPROC3_REMOVE",
+		NFSPROC3RMDIR:       "NFSPROC3_RMDIR",
+		NFSPROC3RENAME:      "NFSPROC3_RENAME",
+		NFSPROC3LINK:        "NFSPROC3_LINK",
+		NFSPROC3READDIR:     "NFSPROC3_READDIR",
+		NFSPROC3READDIRPLUS: "NFSPROC3_READDIRPLUS",
+		NFSPROC3FSSTAT:      "NFSPROC3_FSSTAT",
+		NFSPROC3FSINFO:      "NFSPROC3_FSINFO",
+		NFSPROC3PATHCONF:    "NFSPROC3_PATHCONF",
+		NFSPROC3COMMIT:      "NFSPROC3_COMMIT",
+	}
+)
+
+// invokeCallback invokes callback on behalf of the call identified by procName & xid. Should callback panic or
+// return nil results (as indicated by callback returning false), the failure is logged via errorLog and ok is
+// returned as false (such that the call may be answered with NFS3ErrSERVERFAULT or MNT3ErrSERVERFAULT).
+func invokeCallback(errorLog func(err error), procName string, xid uint32, callback func() (returnedResults bool)) (ok bool) {
+	defer func() {
+		var (
+			panicValue interface{}
+		)
+
+		panicValue = recover()
+		if nil != panicValue {
+			errorLog(fmt.Errorf("%s callback (xid 0x%08X) panicked: %v\n%s", procName, xid, panicValue, debug.Stack()))
+			ok = false
+		}
+	}()
+
+	ok = callback()
+	if !ok {
+		errorLog(fmt.Errorf("%s callback (xid 0x%08X) returned nil results", procName, xid))
+	}
+
+	return
+}
+
+// recoverDispatch is deferred by each dispatch() such that a panic not recovered by invokeCallback (i.e. one
+// outside of any callback) is logged and the call answered with SYSTEM_ERR rather than crashing the process. Should
+// the call already have been answered (i.e. the panic followed the reply), no second reply is sent for its xid.
+func recoverDispatch(errorLog func(err error), procName string, connHandle connHandleInterface, xid uint32) {
+	var (
+		err        error
+		panicValue interface{}
+	)
+
+	panicValue = recover()
+	if nil == panicValue {
+		return
+	}
+
+	errorLog(fmt.Errorf("%s (xid 0x%08X) panicked: %v\n%s", procName, xid, panicValue, debug.Stack()))
+
+	if connHandle.sentReply() {
+		return
+	}
+
+	err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+	if nil != err {
+		errorLog(err)
+	}
+}
+
+func (mountRequestHandler *mountRequestHandlerStruct) invoke(proc uint32, xid uint32, callback func() (returnedResults bool)) (ok bool) {
+	ok = invokeCallback(mountRequestHandler.callbacks.ErrorLog, mountProcNames[proc], xid, callback)
+	return
+}
+
+func (nfsRequestHandler *nfsRequestHandlerStruct) invoke(proc uint32, xid uint32, callback func() (returnedResults bool)) (ok bool) {
+	ok = invokeCallback(nfsRequestHandler.callbacks.ErrorLog, nfsProcNames[proc], xid, callback)
+	return
+}
@@ -0,0 +1,154 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+// testFaultyNFSv3Struct panics upon GETATTR and returns nil results upon LOOKUP
+type testFaultyNFSv3Struct struct {
+	NFSv3Interface
+	errs []error
+}
+
+func (testFaultyNFSv3 *testFaultyNFSv3Struct) ErrorLog(err error) {
+	testFaultyNFSv3.errs = append(testFaultyNFSv3.errs, err)
+}
+
+func (testFaultyNFSv3 *testFaultyNFSv3Struct) NFSProc3GetAttr(credential *CredentialStruct, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	panic("GETATTR failed")
+}
+
+func (testFaultyNFSv3 *testFaultyNFSv3Struct) NFSProc3Lookup(credential *CredentialStruct, nfsProc3LookupArgs *NFSProc3LookupArgsStruct) (nfsProc3LookupResults *NFSProc3LookupResultsStruct) {
+	return nil
+}
+
+// testFaultyMountV3Struct panics upon MNT and returns nil results upon EXPORT
+type testFaultyMountV3Struct struct {
+	MountV3Interface
+	errs []error
+}
+
+func (testFaultyMountV3 *testFaultyMountV3Struct) ErrorLog(err error) {
+	testFaultyMountV3.errs = append(testFaultyMountV3.errs, err)
+}
+
+func (testFaultyMountV3 *testFaultyMountV3Struct) MountProc3Mnt(credential *CredentialStruct, mountProc3MntArgs *MountProc3MntArgsStruct) (mountProc3MntResults *MountProc3MntResultsStruct) {
+	panic("MNT failed")
+}
+
+func (testFaultyMountV3 *testFaultyMountV3Struct) MountProc3Export(credential *CredentialStruct) (mountProc3ExportResults *MountProc3ExportResultsStruct) {
+	return nil
+}
+
+func TestRecoverNFSv3(t *testing.T) {
+	callbacks := &testFaultyNFSv3Struct{}
+	nfsRequestHandler := &nfsRequestHandlerStruct{callbacks: newNFSv3ContextAdapter(callbacks)}
+	credential := &CredentialStruct{Flavor: AuthSys}
+
+	parms, err := xdr.Pack(&NFSProc3GetAttrArgsStruct{Object: []byte{1, 2}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	testReplier := &testReplierStruct{}
+	nfsRequestHandler.dispatch(testReplier, 0x1234, NFSPROC3GETATTR, credential, parms)
+	if (4 > len(testReplier.results)) || (NFS3ErrSERVERFAULT != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("GETATTR whose callback panicked returned %v... expected NFS3ErrSERVERFAULT", testReplier.results)
+	}
+	if (1 != len(callbacks.errs)) || !strings.Contains(callbacks.errs[0].Error(), "NFSPROC3_GETATTR callback (xid 0x00001234) panicked: GETATTR failed") {
+		t.Fatalf("GETATTR whose callback panicked logged %v", callbacks.errs)
+	}
+
+	parms, err = xdr.Pack(&NFSProc3LookupArgsStruct{What: DirOpArgs3Struct{Dir: []byte{1, 2}, Name: "missing"}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	testReplier = &testReplierStruct{}
+	nfsRequestHandler.dispatch(testReplier, 0x5678, NFSPROC3LOOKUP, credential, parms)
+	if (4 > len(testReplier.results)) || (NFS3ErrSERVERFAULT != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("LOOKUP whose callback returned nil returned %v... expected NFS3ErrSERVERFAULT", testReplier.results)
+	}
+	if (2 != len(callbacks.errs)) || !strings.Contains(callbacks.errs[1].Error(), "NFSPROC3_LOOKUP callback (xid 0x00005678) returned nil results") {
+		t.Fatalf("LOOKUP whose callback returned nil logged %v", callbacks.errs)
+	}
+}
+
+func TestRecoverMountV3(t *testing.T) {
+	callbacks := &testFaultyMountV3Struct{}
+	mountRequestHandler := &mountRequestHandlerStruct{callbacks: callbacks}
+	credential := &CredentialStruct{Flavor: AuthSys}
+
+	parms, err := xdr.Pack(&MountProc3MntArgsStruct{DirPath: "/export"})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	testReplier := &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 1, MOUNTPROC3MNT, credential, parms)
+	if (4 != len(testReplier.results)) || (MNT3ErrSERVERFAULT != binary.BigEndian.Uint32(testReplier.results)) {
+		t.Fatalf("MNT whose callback panicked returned %v... expected MNT3ErrSERVERFAULT", testReplier.results)
+	}
+
+	testReplier = &testReplierStruct{}
+	mountRequestHandler.dispatch(testReplier, 2, MOUNTPROC3EXPORT, credential, []byte{})
+	if onc.SystemErr != testReplier.acceptStat {
+		t.Fatalf("EXPORT whose callback returned nil returned accept_stat %v... expected SYSTEM_ERR", testReplier.acceptStat)
+	}
+
+	if 2 != len(callbacks.errs) {
+		t.Fatalf("expected 2 errors to have been logged but got %v", callbacks.errs)
+	}
+}
+
+func TestRecoverDispatchAfterReply(t *testing.T) {
+	var errs []error
+
+	errorLog := func(err error) { errs = append(errs, err) }
+
+	replies := 0
+	replier := &rpcReplierStruct{
+		call: &rpcCallStruct{xid: 0x1234},
+		send: func(reply []byte) (err error) {
+			replies++
+			return
+		},
+	}
+
+	// A panic preceding any reply is answered with SYSTEM_ERR
+
+	func() {
+		defer recoverDispatch(errorLog, "NFSPROC3_GETATTR", replier, 0x1234)
+		panic("before reply")
+	}()
+	if (1 != replies) || !replier.sentReply() {
+		t.Fatalf("panic before any reply sent %d replies... expected 1 (SYSTEM_ERR)", replies)
+	}
+
+	// Whereas one following the reply (e.g. as a DRC entry is completed) sends no second reply for the xid
+
+	replies = 0
+	replier.replied = false
+	drcReplier := &drcReplierStruct{connHandle: replier, xid: 0x1234}
+
+	func() {
+		defer recoverDispatch(errorLog, "NFSPROC3_GETATTR", drcReplier, 0x1234)
+		err := drcReplier.sendAcceptedSuccess([]byte{0, 0, 0, 0})
+		if nil != err {
+			t.Fatalf("sendAcceptedSuccess() failed: %v", err)
+		}
+		panic("after reply")
+	}()
+	if 1 != replies {
+		t.Fatalf("panic after the reply sent %d replies... expected 1", replies)
+	}
+
+	if (2 != len(errs)) || !strings.Contains(errs[1].Error(), "NFSPROC3_GETATTR (xid 0x00001234) panicked: after reply") {
+		t.Fatalf("panics logged %v", errs)
+	}
+}
@@ -2,59 +2,131 @@ package nfsd
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 
 	"github.com/swiftstack/onc"
-	"github.com/swiftstack/onc/oncserver"
 	"github.com/swiftstack/xdr"
 )
 
 type mountRequestHandlerStruct struct {
-	callbacks MountV3Interface
-	prot      uint32 // either onc.IPProtoTCP or onc.IPProtoUDP
-	port      uint16
+	callbacks   MountV3Interface
+	prot        uint32 // either onc.IPProtoTCP or onc.IPProtoUDP
+	port        uint16
+	mountTable  *mountTableStruct  // if nil, mounts are not tracked by this package
+	anonUID     uint32             // UID to which AUTH_NONE requests are mapped
+	anonGID     uint32             // GID to which AUTH_NONE requests are mapped
+	gss         *gssStruct         // if nil, RPCSEC_GSS flavors may not be offered in MNT results
+	exportTable *exportTableStruct // if nil, MNT & EXPORT are answered by callbacks alone
 }
 
 type nfsRequestHandlerStruct struct {
-	callbacks NFSv3Interface
-	prot      uint32 // either onc.IPProtoTCP or onc.IPProtoUDP
-	port      uint16
+	callbacks       NFSv3ContextInterface
+	prot            uint32 // either onc.IPProtoTCP or onc.IPProtoUDP
+	port            uint16
+	anonUID         uint32                 // UID to which AUTH_NONE requests are mapped
+	anonGID         uint32                 // GID to which AUTH_NONE requests are mapped
+	identityMapping *IdentityMappingStruct // if nil, credentials are supplied to callbacks as received
+	exportTable     *exportTableStruct     // if non-nil, file handles are prefixed by export ID & identityMapping is ignored
+	readOnly        bool                   // if true, procedures that would modify the file system return NFS3ErrROFS
+	drc             *drcStruct             // if nil, retransmitted calls are executed anew
 }
 
-func (mountRequestHandler *mountRequestHandlerStruct) ONCRequest(connHandle oncserver.ConnHandle, xid uint32, prog uint32, vers uint32, proc uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+// clientAddr returns the IP address (sans port) of the client issuing a request (or "" if not available)
+func clientAddr(connHandle connHandleInterface) (addr string) {
 	var (
-		err error
+		err        error
+		remoteAddr net.Addr
 	)
 
-	if onc.ProgNumMount != prog {
-		err = fmt.Errorf("prog was %v... expected it to be onc.ProgNumMount (%v)", prog, onc.ProgNumMount)
-		mountRequestHandler.callbacks.ErrorLog(err)
-		panic(err) // i.e. this shouldn't have happened if oncserver "dispatcher" is functioning correctly
+	remoteAddr = connHandle.RemoteAddr()
+	if nil == remoteAddr {
+		addr = ""
+		return
 	}
 
-	if 3 != vers {
-		err = fmt.Errorf("vers was %v... expected it to be 3", vers)
-		mountRequestHandler.callbacks.ErrorLog(err)
-		panic(err) // i.e. this shouldn't have happened if oncserver "dispatcher" is functioning correctly
+	addr, _, err = net.SplitHostPort(remoteAddr.String())
+	if nil != err {
+		addr = remoteAddr.String()
+	}
+
+	return
+}
+
+// callDeferred returns whether a request was received by a draining server and is to be answered with
+// NFS3ErrJUKEBOX (see rpcServerStruct.shutdown())
+func callDeferred(connHandle connHandleInterface) (deferred bool) {
+	var (
+		ok      bool
+		replier *rpcReplierStruct
+	)
+
+	replier, ok = rpcReplier(connHandle)
+	deferred = ok && replier.deferred
+
+	return
+}
+
+// privilegedPort returns whether the client issuing a request did so from a reserved (i.e. < 1024) port. If the
+// address of the client is not available, the port is presumed not to be privileged.
+func privilegedPort(connHandle connHandleInterface) (privileged bool) {
+	var (
+		err        error
+		port       string
+		portNumber uint64
+		remoteAddr net.Addr
+	)
+
+	remoteAddr = connHandle.RemoteAddr()
+	if nil == remoteAddr {
+		privileged = false
+		return
+	}
+
+	_, port, err = net.SplitHostPort(remoteAddr.String())
+	if nil != err {
+		privileged = false
+		return
 	}
 
+	portNumber, err = strconv.ParseUint(port, 10, 16)
+	privileged = (nil == err) && (1024 > portNumber)
+
+	return
+}
+
+// dispatch invokes the handler for proc on behalf of an rpcServerStruct
+func (mountRequestHandler *mountRequestHandlerStruct) dispatch(connHandle connHandleInterface, xid uint32, proc uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		err error
+	)
+
+	defer recoverDispatch(mountRequestHandler.callbacks.ErrorLog, mountProcNames[proc], connHandle, xid)
+
 	switch proc {
 	case ProcNULL:
-		mountRequestHandler.null(connHandle, xid, authSysBody, parms)
+		mountRequestHandler.null(connHandle, xid, credential, parms)
 	case MOUNTPROC3MNT:
-		mountRequestHandler.mnt(connHandle, xid, authSysBody, parms)
+		mountRequestHandler.mnt(connHandle, xid, credential, parms)
+	case MOUNTPROC3DUMP:
+		mountRequestHandler.dump(connHandle, xid, credential, parms)
 	case MOUNTPROC3UMNT:
-		mountRequestHandler.umnt(connHandle, xid, authSysBody, parms)
+		mountRequestHandler.umnt(connHandle, xid, credential, parms)
+	case MOUNTPROC3UMNTALL:
+		mountRequestHandler.umntall(connHandle, xid, credential, parms)
+	case MOUNTPROC3EXPORT:
+		mountRequestHandler.export(connHandle, xid, credential, parms)
 	default:
 		err = fmt.Errorf("proc %v not available", proc)
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.ProcUnavail)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.ProcUnavail)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
 	}
 }
 
-func (mountRequestHandler *mountRequestHandlerStruct) null(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (mountRequestHandler *mountRequestHandlerStruct) null(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		err error
 	)
@@ -62,25 +134,38 @@ func (mountRequestHandler *mountRequestHandlerStruct) null(connHandle oncserver.
 	if 0 != len(parms) {
 		err = fmt.Errorf("ProcNULL(...parms) should have been void")
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	mountRequestHandler.callbacks.MountProc3Null(authSysBody)
+	if !mountRequestHandler.invoke(ProcNULL, xid, func() (returnedResults bool) {
+		mountRequestHandler.callbacks.MountProc3Null(credential)
+		returnedResults = true
+		return
+	}) {
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, nil)
+	err = connHandle.sendAcceptedSuccess(nil)
 	if nil != err {
 		mountRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
+		authFlavor           uint32
 		bytesConsumed        uint64
 		err                  error
+		export               *exportStruct
+		exportOptions        *ExportOptionsStruct
 		mountProc3MntArgs    MountProc3MntArgsStruct
 		mountProc3MntResults *MountProc3MntResultsStruct
 		results              []byte
@@ -90,7 +175,7 @@ func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle oncserver.C
 	bytesConsumed, err = xdr.Unpack(parms, &mountProc3MntArgs)
 	if nil != err {
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -99,29 +184,78 @@ func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle oncserver.C
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	mountProc3MntResults = mountRequestHandler.callbacks.MountProc3Mnt(authSysBody, &mountProc3MntArgs)
+	if nil == mountRequestHandler.exportTable {
+		if !mountRequestHandler.invoke(MOUNTPROC3MNT, xid, func() (returnedResults bool) {
+			mountProc3MntResults = mountRequestHandler.callbacks.MountProc3Mnt(credential, &mountProc3MntArgs)
+			returnedResults = (nil != mountProc3MntResults)
+			return
+		}) {
+			mountProc3MntResults = &MountProc3MntResultsStruct{Status: MNT3ErrSERVERFAULT}
+		}
+	} else {
+		export = mountRequestHandler.exportTable.lookupPath(mountProc3MntArgs.DirPath)
+		if nil == export {
+			mountProc3MntResults = &MountProc3MntResultsStruct{Status: MNT3ErrNOENT}
+		} else {
+			exportOptions = mountRequestHandler.exportTable.matchClient(export, clientAddr(connHandle))
+			if (nil == exportOptions) || !exportOptions.authFlavorPermitted(credential) || (exportOptions.Secure && !privilegedPort(connHandle)) {
+				mountProc3MntResults = &MountProc3MntResultsStruct{Status: MNT3ErrACCES}
+			} else {
+				if !mountRequestHandler.invoke(MOUNTPROC3MNT, xid, func() (returnedResults bool) {
+					mountProc3MntResults = mountRequestHandler.callbacks.MountProc3Mnt(credential, &mountProc3MntArgs)
+					returnedResults = (nil != mountProc3MntResults)
+					return
+				}) {
+					mountProc3MntResults = &MountProc3MntResultsStruct{Status: MNT3ErrSERVERFAULT}
+				}
+				if OK == mountProc3MntResults.Status {
+					mountProc3MntResults.FHandle, err = export.wrapFHandle(mountProc3MntResults.FHandle)
+					if nil != err {
+						mountRequestHandler.callbacks.ErrorLog(err)
+						err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+						if nil != err {
+							mountRequestHandler.callbacks.ErrorLog(err)
+						}
+						return
+					}
+					mountProc3MntResults.AuthFlavors = append([]uint32{}, exportOptions.AuthFlavors...)
+				}
+			}
+		}
+	}
 
 	if OK == mountProc3MntResults.Status {
-		if (1 != len(mountProc3MntResults.AuthFlavors)) || (onc.AuthSys != mountProc3MntResults.AuthFlavors[0]) {
-			err = fmt.Errorf("mountProc3MntResults.AuthFlavors must == []{onc.AuthSys}")
+		if 0 == len(mountProc3MntResults.AuthFlavors) {
+			err = fmt.Errorf("mountProc3MntResults.AuthFlavors must not be empty")
 			mountRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				mountRequestHandler.callbacks.ErrorLog(err)
 			}
 			return
 		}
+		for _, authFlavor = range mountProc3MntResults.AuthFlavors {
+			if !authFlavorSupported(authFlavor, mountRequestHandler.gss) {
+				err = fmt.Errorf("mountProc3MntResults.AuthFlavors contains unsupported auth_flavor (%v)", authFlavor)
+				mountRequestHandler.callbacks.ErrorLog(err)
+				err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+				if nil != err {
+					mountRequestHandler.callbacks.ErrorLog(err)
+				}
+				return
+			}
+		}
 		results, err = xdr.Pack(mountProc3MntResults)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				mountRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -132,7 +266,7 @@ func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle oncserver.C
 		results, err = xdr.Pack(statusOnlyResults)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				mountRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -140,13 +274,69 @@ func (mountRequestHandler *mountRequestHandlerStruct) mnt(connHandle oncserver.C
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	if (OK == mountProc3MntResults.Status) && (nil != mountRequestHandler.mountTable) {
+		err = mountRequestHandler.mountTable.mnt(clientAddr(connHandle), credential.MachineName, mountProc3MntArgs.DirPath)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+	}
+
+	err = connHandle.sendAcceptedSuccess(results)
+	if nil != err {
+		mountRequestHandler.callbacks.ErrorLog(err)
+	}
+}
+
+func (mountRequestHandler *mountRequestHandlerStruct) dump(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		err                   error
+		mountProc3DumpResults *MountProc3DumpResultsStruct
+		results               []byte
+	)
+
+	if 0 != len(parms) {
+		err = fmt.Errorf("MOUNTPROC3DUMP(...parms) should have been void")
+		mountRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	if nil == mountRequestHandler.mountTable {
+		if !mountRequestHandler.invoke(MOUNTPROC3DUMP, xid, func() (returnedResults bool) {
+			mountProc3DumpResults = mountRequestHandler.callbacks.MountProc3Dump(credential)
+			returnedResults = (nil != mountProc3DumpResults)
+			return
+		}) {
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				mountRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	} else {
+		mountProc3DumpResults = mountRequestHandler.mountTable.dump()
+	}
+
+	results, err = mountProc3DumpResults.pack()
+	if nil != err {
+		mountRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		mountRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (mountRequestHandler *mountRequestHandlerStruct) umnt(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (mountRequestHandler *mountRequestHandlerStruct) umnt(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed      uint64
 		err                error
@@ -156,7 +346,7 @@ func (mountRequestHandler *mountRequestHandlerStruct) umnt(connHandle oncserver.
 	bytesConsumed, err = xdr.Unpack(parms, &mountProc3UmntArgs)
 	if nil != err {
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -165,128 +355,271 @@ func (mountRequestHandler *mountRequestHandlerStruct) umnt(connHandle oncserver.
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		mountRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			mountRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	mountRequestHandler.callbacks.MountProc3Umnt(authSysBody, &mountProc3UmntArgs)
+	if nil != mountRequestHandler.mountTable {
+		err = mountRequestHandler.mountTable.umnt(clientAddr(connHandle), credential.MachineName, mountProc3UmntArgs.DirPath)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+	}
+
+	if !mountRequestHandler.invoke(MOUNTPROC3UMNT, xid, func() (returnedResults bool) {
+		mountRequestHandler.callbacks.MountProc3Umnt(credential, &mountProc3UmntArgs)
+		returnedResults = true
+		return
+	}) {
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, nil)
+	err = connHandle.sendAcceptedSuccess(nil)
 	if nil != err {
 		mountRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) ONCRequest(connHandle oncserver.ConnHandle, xid uint32, prog uint32, vers uint32, proc uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (mountRequestHandler *mountRequestHandlerStruct) umntall(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		err error
 	)
 
-	if onc.ProgNumNFS != prog {
-		err = fmt.Errorf("prog was %v... expected it to be onc.ProgNumNFS (%v)", prog, onc.ProgNumNFS)
-		nfsRequestHandler.callbacks.ErrorLog(err)
-		panic(err) // i.e. this shouldn't have happened if oncserver "dispatcher" is functioning correctly
+	if 0 != len(parms) {
+		err = fmt.Errorf("MOUNTPROC3UMNTALL(...parms) should have been void")
+		mountRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	if nil != mountRequestHandler.mountTable {
+		err = mountRequestHandler.mountTable.umntAll(clientAddr(connHandle), credential.MachineName)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+	}
+
+	if !mountRequestHandler.invoke(MOUNTPROC3UMNTALL, xid, func() (returnedResults bool) {
+		mountRequestHandler.callbacks.MountProc3UmntAll(credential)
+		returnedResults = true
+		return
+	}) {
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	err = connHandle.sendAcceptedSuccess(nil)
+	if nil != err {
+		mountRequestHandler.callbacks.ErrorLog(err)
+	}
+}
+
+func (mountRequestHandler *mountRequestHandlerStruct) export(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		err                     error
+		mountProc3ExportResults *MountProc3ExportResultsStruct
+		results                 []byte
+	)
+
+	if 0 != len(parms) {
+		err = fmt.Errorf("MOUNTPROC3EXPORT(...parms) should have been void")
+		mountRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	if nil == mountRequestHandler.exportTable {
+		if !mountRequestHandler.invoke(MOUNTPROC3EXPORT, xid, func() (returnedResults bool) {
+			mountProc3ExportResults = mountRequestHandler.callbacks.MountProc3Export(credential)
+			returnedResults = (nil != mountProc3ExportResults)
+			return
+		}) {
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				mountRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	} else {
+		mountProc3ExportResults = mountRequestHandler.exportTable.exportsResults()
 	}
 
-	if 3 != vers {
-		err = fmt.Errorf("vers was %v... expected it to be 3", vers)
+	results, err = mountProc3ExportResults.pack()
+	if nil != err {
+		mountRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			mountRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	err = connHandle.sendAcceptedSuccess(results)
+	if nil != err {
+		mountRequestHandler.callbacks.ErrorLog(err)
+	}
+}
+
+// dispatch invokes the handler for proc on behalf of an rpcServerStruct
+func (nfsRequestHandler *nfsRequestHandlerStruct) dispatch(connHandle connHandleInterface, xid uint32, proc uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		drcReplier *drcReplierStruct
+		drcResults []byte
+		drcStatus  drcStatusType
+		err        error
+	)
+
+	defer recoverDispatch(nfsRequestHandler.callbacks.ErrorLog, nfsProcNames[proc], connHandle, xid)
+
+	if (ProcNULL != proc) && !nfsRequestHandler.portPermitted(connHandle, parms) {
+		err = fmt.Errorf("proc %v from unprivileged port of %s rejected by secure export", proc, clientAddr(connHandle))
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		panic(err) // i.e. this shouldn't have happened if oncserver "dispatcher" is functioning correctly
+		err = connHandle.sendAuthErrorReply(rpcAuthStatTooWeak)
+		if errAuthErrorReplyUnavailable != err {
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+		// otherwise admit() answers the call with NFS3ErrACCES
+	}
+
+	drcReplier, drcResults, drcStatus = nfsRequestHandler.drc.lookup(connHandle, xid, proc, parms)
+	switch drcStatus {
+	case drcMiss:
+		connHandle = drcReplier
+		defer nfsRequestHandler.drc.complete(drcReplier)
+	case drcReplay:
+		err = connHandle.sendAcceptedSuccess(drcResults)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	case drcInProgress:
+		return // the client will retransmit again should the original call's reply be lost
 	}
 
 	switch proc {
 	case ProcNULL:
-		nfsRequestHandler.null(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.null(connHandle, xid, credential, parms)
 	case NFSPROC3GETATTR:
-		nfsRequestHandler.getattr(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.getattr(connHandle, xid, credential, parms)
 	case NFSPROC3SETATTR:
-		nfsRequestHandler.setattr(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.setattr(connHandle, xid, credential, parms)
 	case NFSPROC3LOOKUP:
-		nfsRequestHandler.lookup(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.lookup(connHandle, xid, credential, parms)
 	case NFSPROC3ACCESS:
-		nfsRequestHandler.access(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.access(connHandle, xid, credential, parms)
 	case NFSPROC3READLINK:
-		nfsRequestHandler.readlink(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.readlink(connHandle, xid, credential, parms)
 	case NFSPROC3READ:
-		nfsRequestHandler.read(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.read(connHandle, xid, credential, parms)
 	case NFSPROC3WRITE:
-		nfsRequestHandler.write(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.write(connHandle, xid, credential, parms)
 	case NFSPROC3CREATE:
-		nfsRequestHandler.create(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.create(connHandle, xid, credential, parms)
 	case NFSPROC3MKDIR:
-		nfsRequestHandler.mkdir(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.mkdir(connHandle, xid, credential, parms)
 	case NFSPROC3SYMLINK:
-		nfsRequestHandler.symlink(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.symlink(connHandle, xid, credential, parms)
 	case NFSPROC3REMOVE:
-		nfsRequestHandler.remove(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.remove(connHandle, xid, credential, parms)
 	case NFSPROC3RMDIR:
-		nfsRequestHandler.rmdir(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.rmdir(connHandle, xid, credential, parms)
 	case NFSPROC3RENAME:
-		nfsRequestHandler.rename(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.rename(connHandle, xid, credential, parms)
 	case NFSPROC3LINK:
-		nfsRequestHandler.link(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.link(connHandle, xid, credential, parms)
 	case NFSPROC3READDIR:
-		nfsRequestHandler.readdir(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.readdir(connHandle, xid, credential, parms)
 	case NFSPROC3READDIRPLUS:
-		nfsRequestHandler.readdirplus(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.readdirplus(connHandle, xid, credential, parms)
 	case NFSPROC3FSSTAT:
-		nfsRequestHandler.fsstat(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.fsstat(connHandle, xid, credential, parms)
 	case NFSPROC3FSINFO:
-		nfsRequestHandler.fsinfo(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.fsinfo(connHandle, xid, credential, parms)
 	case NFSPROC3PATHCONF:
-		nfsRequestHandler.pathconf(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.pathconf(connHandle, xid, credential, parms)
 	case NFSPROC3COMMIT:
-		nfsRequestHandler.commit(connHandle, xid, authSysBody, parms)
+		nfsRequestHandler.commit(connHandle, xid, credential, parms)
 	default:
 		err = fmt.Errorf("proc %v not available", proc)
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.ProcUnavail)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.ProcUnavail)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) null(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) null(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
-		err error
+		err    error
+		export *exportStruct
 	)
 
 	if 0 != len(parms) {
 		err = fmt.Errorf("ProcNULL(...parms) should have been void")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsRequestHandler.callbacks.NFSProc3Null(authSysBody)
+	export, credential, _, _ = nfsRequestHandler.admit(connHandle, credential)
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, nil)
+	if !nfsRequestHandler.invoke(ProcNULL, xid, func() (returnedResults bool) {
+		nfsRequestHandler.callbacks.NFSProc3Null(nfsRequestHandler.requestContext(connHandle, xid, credential, export))
+		returnedResults = true
+		return
+	}) {
+		err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	err = connHandle.sendAcceptedSuccess(nil)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) getattr(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) getattr(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed          uint64
 		err                    error
+		export                 *exportStruct
 		nfsProc3GetAttrArgs    NFSProc3GetAttrArgsStruct
 		nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct
 		results                []byte
+		status                 uint32
 		statusOnlyResults      StatusOnlyStruct
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3GetAttrArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -295,23 +628,42 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) getattr(connHandle oncserver.C
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3GetAttrResults = nfsRequestHandler.callbacks.NFSProc3GetAttr(authSysBody, &nfsProc3GetAttrArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3GetAttrArgs.Object)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3GETATTR, xid, func() (returnedResults bool) {
+			nfsProc3GetAttrResults = nfsRequestHandler.callbacks.NFSProc3GetAttr(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3GetAttrArgs)
+			returnedResults = (nil != nfsProc3GetAttrResults)
+			return
+		}) {
+			nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3GetAttrResults.Status {
-		// TODO
+		results, err = xdr.Pack(nfsProc3GetAttrResults)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
 		statusOnlyResults.Status = nfsProc3GetAttrResults.Status
 		results, err = xdr.Pack(statusOnlyResults)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -319,34 +671,70 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) getattr(connHandle oncserver.C
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) setattr(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) setattr(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed          uint64
 		err                    error
+		export                 *exportStruct
 		nfsProc3SetAttrArgs    NFSProc3SetAttrArgsStruct
 		nfsProc3SetAttrResults *NFSProc3SetAttrResultsStruct
 		results                []byte
-		statusOnlyResults      StatusOnlyStruct
+		status                 uint32
 	)
 
-	// TODO - unpack nfsProc3SetAttrArgs
+	bytesConsumed, err = nfsProc3SetAttrArgs.unpack(parms)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+	if uint64(len(parms)) != bytesConsumed {
+		err = fmt.Errorf("nfsProc3SetAttrArgs.unpack() failed to consume all of parms")
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
 
-	nfsProc3SetAttrResults = nfsRequestHandler.callbacks.NFSProc3SetAttr(authSysBody, &nfsProc3SetAttrArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3SetAttrArgs.Object)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3SETATTR, xid, func() (returnedResults bool) {
+			nfsProc3SetAttrResults = nfsRequestHandler.callbacks.NFSProc3SetAttr(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3SetAttrArgs)
+			returnedResults = (nil != nfsProc3SetAttrResults)
+			return
+		}) {
+			nfsProc3SetAttrResults = &NFSProc3SetAttrResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3SetAttrResults = &NFSProc3SetAttrResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3SetAttrResults.Status {
-		// TODO
+		results, err = nfsProc3SetAttrResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3SetAttrResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3SetAttrResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -354,26 +742,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) setattr(connHandle oncserver.C
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) lookup(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) lookup(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3LookupArgs    NFSProc3LookupArgsStruct
 		nfsProc3LookupResults *NFSProc3LookupResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3LookupArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -382,23 +771,53 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) lookup(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3LookupResults = nfsRequestHandler.callbacks.NFSProc3Lookup(authSysBody, &nfsProc3LookupArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3LookupArgs.What.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3LOOKUP, xid, func() (returnedResults bool) {
+			nfsProc3LookupResults = nfsRequestHandler.callbacks.NFSProc3Lookup(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3LookupArgs)
+			returnedResults = (nil != nfsProc3LookupResults)
+			return
+		}) {
+			nfsProc3LookupResults = &NFSProc3LookupResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3LookupResults = &NFSProc3LookupResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3LookupResults.Status {
+		nfsProc3LookupResults.Object, err = export.wrapFHandle(nfsProc3LookupResults.Object)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	}
 
 	if OK == nfsProc3LookupResults.Status {
-		// TODO
+		results, err = nfsProc3LookupResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3LookupResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3LookupResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -406,26 +825,28 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) lookup(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) access(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) access(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3AccessArgs    NFSProc3AccessArgsStruct
 		nfsProc3AccessResults *NFSProc3AccessResultsStruct
+		readOnly              bool
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3AccessArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -434,23 +855,45 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) access(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3AccessResults = nfsRequestHandler.callbacks.NFSProc3Access(authSysBody, &nfsProc3AccessArgs)
+	export, credential, readOnly, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3AccessArgs.Object)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3ACCESS, xid, func() (returnedResults bool) {
+			nfsProc3AccessResults = nfsRequestHandler.callbacks.NFSProc3Access(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3AccessArgs)
+			returnedResults = (nil != nfsProc3AccessResults)
+			return
+		}) {
+			nfsProc3AccessResults = &NFSProc3AccessResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3AccessResults = &NFSProc3AccessResultsStruct{Status: status}
+	}
+
+	if (OK == nfsProc3AccessResults.Status) && readOnly {
+		nfsProc3AccessResults.Access &= readOnlyAccessMask
+	}
 
 	if OK == nfsProc3AccessResults.Status {
-		// TODO
+		results, err = nfsProc3AccessResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3AccessResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3AccessResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -458,26 +901,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) access(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) readlink(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) readlink(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed           uint64
 		err                     error
+		export                  *exportStruct
 		nfsProc3ReadLinkArgs    NFSProc3ReadLinkArgsStruct
 		nfsProc3ReadLinkResults *NFSProc3ReadLinkResultsStruct
 		results                 []byte
-		statusOnlyResults       StatusOnlyStruct
+		status                  uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3ReadLinkArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -486,23 +930,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readlink(connHandle oncserver.
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3ReadLinkResults = nfsRequestHandler.callbacks.NFSProc3ReadLink(authSysBody, &nfsProc3ReadLinkArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3ReadLinkArgs.SymLink)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3READLINK, xid, func() (returnedResults bool) {
+			nfsProc3ReadLinkResults = nfsRequestHandler.callbacks.NFSProc3ReadLink(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3ReadLinkArgs)
+			returnedResults = (nil != nfsProc3ReadLinkResults)
+			return
+		}) {
+			nfsProc3ReadLinkResults = &NFSProc3ReadLinkResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3ReadLinkResults = &NFSProc3ReadLinkResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3ReadLinkResults.Status {
-		// TODO
+		results, err = nfsProc3ReadLinkResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3ReadLinkResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3ReadLinkResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -510,51 +972,224 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readlink(connHandle oncserver.
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) read(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) read(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed       uint64
 		err                 error
+		export              *exportStruct
 		nfsProc3ReadArgs    NFSProc3ReadArgsStruct
 		nfsProc3ReadResults *NFSProc3ReadResultsStruct
 		results             []byte
-		statusOnlyResults   StatusOnlyStruct
+		status              uint32
+	)
+
+	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3ReadArgs)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+	if uint64(len(parms)) != bytesConsumed {
+		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3ReadArgs.File)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3READ, xid, func() (returnedResults bool) {
+			nfsProc3ReadResults = nfsRequestHandler.callbacks.NFSProc3Read(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3ReadArgs)
+			returnedResults = (nil != nfsProc3ReadResults)
+			return
+		}) {
+			nfsProc3ReadResults = &NFSProc3ReadResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3ReadResults = &NFSProc3ReadResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3ReadResults.Status {
+		results, err = nfsProc3ReadResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	} else {
+		results, err = nfsProc3ReadResults.packResFail()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	}
+
+	err = connHandle.sendAcceptedSuccess(results)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+	}
+}
+
+func (nfsRequestHandler *nfsRequestHandlerStruct) write(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		bytesConsumed        uint64
+		err                  error
+		export               *exportStruct
+		nfsProc3WriteArgs    NFSProc3WriteArgsStruct
+		nfsProc3WriteResults *NFSProc3WriteResultsStruct
+		results              []byte
+		status               uint32
+	)
+
+	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3WriteArgs)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+	if uint64(len(parms)) != bytesConsumed {
+		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3WriteArgs.File)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3WRITE, xid, func() (returnedResults bool) {
+			nfsProc3WriteResults = nfsRequestHandler.callbacks.NFSProc3Write(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3WriteArgs)
+			returnedResults = (nil != nfsProc3WriteResults)
+			return
+		}) {
+			nfsProc3WriteResults = &NFSProc3WriteResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3WriteResults = &NFSProc3WriteResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3WriteResults.Status {
+		results, err = nfsProc3WriteResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	} else {
+		results, err = nfsProc3WriteResults.packResFail()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	}
+
+	err = connHandle.sendAcceptedSuccess(results)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+	}
+}
+
+func (nfsRequestHandler *nfsRequestHandlerStruct) create(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
+	var (
+		bytesConsumed         uint64
+		err                   error
+		export                *exportStruct
+		nfsProc3CreateArgs    NFSProc3CreateArgsStruct
+		nfsProc3CreateResults *NFSProc3CreateResultsStruct
+		results               []byte
+		status                uint32
 	)
 
-	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3ReadArgs)
+	bytesConsumed, err = nfsProc3CreateArgs.unpack(parms)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 	if uint64(len(parms)) != bytesConsumed {
-		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
+		err = fmt.Errorf("nfsProc3CreateArgs.unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3ReadResults = nfsRequestHandler.callbacks.NFSProc3Read(authSysBody, &nfsProc3ReadArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3CreateArgs.Where.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3CREATE, xid, func() (returnedResults bool) {
+			nfsProc3CreateResults = nfsRequestHandler.callbacks.NFSProc3Create(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3CreateArgs)
+			returnedResults = (nil != nfsProc3CreateResults)
+			return
+		}) {
+			nfsProc3CreateResults = &NFSProc3CreateResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3CreateResults = &NFSProc3CreateResultsStruct{Status: status}
+	}
 
-	if OK == nfsProc3ReadResults.Status {
-		// TODO
+	if OK == nfsProc3CreateResults.Status {
+		err = export.wrapPostOpFh3(&nfsProc3CreateResults.Obj)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	}
+
+	if OK == nfsProc3CreateResults.Status {
+		results, err = nfsProc3CreateResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3ReadResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3CreateResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -562,51 +1197,60 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) read(connHandle oncserver.Conn
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) write(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) mkdir(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed        uint64
 		err                  error
-		nfsProc3WriteArgs    NFSProc3WriteArgsStruct
-		nfsProc3WriteResults *NFSProc3WriteResultsStruct
+		export               *exportStruct
+		nfsProc3MKDirArgs    NFSProc3MKDirArgsStruct
+		nfsProc3MKDirResults *NFSProc3MKDirResultsStruct
 		results              []byte
-		statusOnlyResults    StatusOnlyStruct
+		status               uint32
 	)
 
-	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3WriteArgs)
+	bytesConsumed, err = nfsProc3MKDirArgs.unpack(parms)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 	if uint64(len(parms)) != bytesConsumed {
-		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
+		err = fmt.Errorf("nfsProc3MKDirArgs.unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3WriteResults = nfsRequestHandler.callbacks.NFSProc3Write(authSysBody, &nfsProc3WriteArgs)
-
-	if OK == nfsProc3WriteResults.Status {
-		// TODO
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3MKDirArgs.Where.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3MKDIR, xid, func() (returnedResults bool) {
+			nfsProc3MKDirResults = nfsRequestHandler.callbacks.NFSProc3MKDir(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3MKDirArgs)
+			returnedResults = (nil != nfsProc3MKDirResults)
+			return
+		}) {
+			nfsProc3MKDirResults = &NFSProc3MKDirResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3WriteResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		nfsProc3MKDirResults = &NFSProc3MKDirResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3MKDirResults.Status {
+		err = export.wrapPostOpFh3(&nfsProc3MKDirResults.Obj)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -614,69 +1258,21 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) write(connHandle oncserver.Con
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
-	if nil != err {
-		nfsRequestHandler.callbacks.ErrorLog(err)
-	}
-}
-
-func (nfsRequestHandler *nfsRequestHandlerStruct) create(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
-	var (
-		bytesConsumed         uint64
-		err                   error
-		nfsProc3CreateArgs    NFSProc3CreateArgsStruct
-		nfsProc3CreateResults *NFSProc3CreateResultsStruct
-		results               []byte
-		statusOnlyResults     StatusOnlyStruct
-	)
-
-	// TODO - unpack nfsProc3CreateArgs
-
-	nfsProc3CreateResults = nfsRequestHandler.callbacks.NFSProc3Create(authSysBody, &nfsProc3CreateArgs)
-
-	if OK == nfsProc3CreateResults.Status {
-		// TODO
-	} else {
-		statusOnlyResults.Status = nfsProc3CreateResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+	if OK == nfsProc3MKDirResults.Status {
+		results, err = nfsProc3MKDirResults.packResOK()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
 			return
 		}
-	}
-
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
-	if nil != err {
-		nfsRequestHandler.callbacks.ErrorLog(err)
-	}
-}
-
-func (nfsRequestHandler *nfsRequestHandlerStruct) mkdir(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
-	var (
-		bytesConsumed        uint64
-		err                  error
-		nfsProc3MKDirArgs    NFSProc3MKDirArgsStruct
-		nfsProc3MKDirResults *NFSProc3MKDirResultsStruct
-		results              []byte
-		statusOnlyResults    StatusOnlyStruct
-	)
-
-	// TODO - unpack nfsProc3MKDirArgs
-
-	nfsProc3MKDirResults = nfsRequestHandler.callbacks.NFSProc3MKDir(authSysBody, &nfsProc3MKDirArgs)
-
-	if OK == nfsProc3MKDirResults.Status {
-		// TODO
 	} else {
-		statusOnlyResults.Status = nfsProc3MKDirResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3MKDirResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -684,34 +1280,82 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) mkdir(connHandle oncserver.Con
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) symlink(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) symlink(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed          uint64
 		err                    error
+		export                 *exportStruct
 		nfsProc3SymLinkArgs    NFSProc3SymLinkArgsStruct
 		nfsProc3SymLinkResults *NFSProc3SymLinkResultsStruct
 		results                []byte
-		statusOnlyResults      StatusOnlyStruct
+		status                 uint32
 	)
 
-	// TODO - unpack nfsProc3SymLinkArgs
+	bytesConsumed, err = nfsProc3SymLinkArgs.unpack(parms)
+	if nil != err {
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+	if uint64(len(parms)) != bytesConsumed {
+		err = fmt.Errorf("nfsProc3SymLinkArgs.unpack() failed to consume all of parms")
+		nfsRequestHandler.callbacks.ErrorLog(err)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+		}
+		return
+	}
+
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3SymLinkArgs.Where.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3SYMLINK, xid, func() (returnedResults bool) {
+			nfsProc3SymLinkResults = nfsRequestHandler.callbacks.NFSProc3SymLink(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3SymLinkArgs)
+			returnedResults = (nil != nfsProc3SymLinkResults)
+			return
+		}) {
+			nfsProc3SymLinkResults = &NFSProc3SymLinkResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3SymLinkResults = &NFSProc3SymLinkResultsStruct{Status: status}
+	}
 
-	nfsProc3SymLinkResults = nfsRequestHandler.callbacks.NFSProc3SymLink(authSysBody, &nfsProc3SymLinkArgs)
+	if OK == nfsProc3SymLinkResults.Status {
+		err = export.wrapPostOpFh3(&nfsProc3SymLinkResults.Obj)
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
+	}
 
 	if OK == nfsProc3SymLinkResults.Status {
-		// TODO
+		results, err = nfsProc3SymLinkResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3SymLinkResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3SymLinkResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -719,26 +1363,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) symlink(connHandle oncserver.C
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) remove(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) remove(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3RemoveArgs    NFSProc3RemoveArgsStruct
 		nfsProc3RemoveResults *NFSProc3RemoveResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3RemoveArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -747,23 +1392,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) remove(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3RemoveResults = nfsRequestHandler.callbacks.NFSProc3Remove(authSysBody, &nfsProc3RemoveArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3RemoveArgs.Where.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3REMOVE, xid, func() (returnedResults bool) {
+			nfsProc3RemoveResults = nfsRequestHandler.callbacks.NFSProc3Remove(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3RemoveArgs)
+			returnedResults = (nil != nfsProc3RemoveResults)
+			return
+		}) {
+			nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3RemoveResults = &NFSProc3RemoveResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3RemoveResults.Status {
-		// TODO
+		results, err = nfsProc3RemoveResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3RemoveResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3RemoveResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -771,26 +1434,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) remove(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) rmdir(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) rmdir(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed        uint64
 		err                  error
+		export               *exportStruct
 		nfsProc3RMDirArgs    NFSProc3RMDirArgsStruct
 		nfsProc3RMDirResults *NFSProc3RMDirResultsStruct
 		results              []byte
-		statusOnlyResults    StatusOnlyStruct
+		status               uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3RMDirArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -799,23 +1463,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) rmdir(connHandle oncserver.Con
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3RMDirResults = nfsRequestHandler.callbacks.NFSProc3RMDir(authSysBody, &nfsProc3RMDirArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3RMDirArgs.Where.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3RMDIR, xid, func() (returnedResults bool) {
+			nfsProc3RMDirResults = nfsRequestHandler.callbacks.NFSProc3RMDir(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3RMDirArgs)
+			returnedResults = (nil != nfsProc3RMDirResults)
+			return
+		}) {
+			nfsProc3RMDirResults = &NFSProc3RMDirResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3RMDirResults = &NFSProc3RMDirResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3RMDirResults.Status {
-		// TODO
+		results, err = nfsProc3RMDirResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3RMDirResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3RMDirResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -823,26 +1505,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) rmdir(connHandle oncserver.Con
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) rename(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) rename(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3RenameArgs    NFSProc3RenameArgsStruct
 		nfsProc3RenameResults *NFSProc3RenameResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3RenameArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -851,23 +1534,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) rename(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3RenameResults = nfsRequestHandler.callbacks.NFSProc3Rename(authSysBody, &nfsProc3RenameArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3RenameArgs.From.Dir, &nfsProc3RenameArgs.To.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3RENAME, xid, func() (returnedResults bool) {
+			nfsProc3RenameResults = nfsRequestHandler.callbacks.NFSProc3Rename(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3RenameArgs)
+			returnedResults = (nil != nfsProc3RenameResults)
+			return
+		}) {
+			nfsProc3RenameResults = &NFSProc3RenameResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3RenameResults = &NFSProc3RenameResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3RenameResults.Status {
-		// TODO
+		results, err = nfsProc3RenameResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3RenameResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3RenameResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -875,26 +1576,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) rename(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) link(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) link(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed       uint64
 		err                 error
+		export              *exportStruct
 		nfsProc3LinkArgs    NFSProc3LinkArgsStruct
 		nfsProc3LinkResults *NFSProc3LinkResultsStruct
 		results             []byte
-		statusOnlyResults   StatusOnlyStruct
+		status              uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3LinkArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -903,23 +1605,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) link(connHandle oncserver.Conn
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3LinkResults = nfsRequestHandler.callbacks.NFSProc3Link(authSysBody, &nfsProc3LinkArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3LinkArgs.File, &nfsProc3LinkArgs.Link.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3LINK, xid, func() (returnedResults bool) {
+			nfsProc3LinkResults = nfsRequestHandler.callbacks.NFSProc3Link(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3LinkArgs)
+			returnedResults = (nil != nfsProc3LinkResults)
+			return
+		}) {
+			nfsProc3LinkResults = &NFSProc3LinkResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3LinkResults = &NFSProc3LinkResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3LinkResults.Status {
-		// TODO
+		results, err = nfsProc3LinkResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3LinkResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3LinkResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -927,26 +1647,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) link(connHandle oncserver.Conn
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) readdir(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) readdir(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed          uint64
 		err                    error
+		export                 *exportStruct
 		nfsProc3ReadDirArgs    NFSProc3ReadDirArgsStruct
 		nfsProc3ReadDirResults *NFSProc3ReadDirResultsStruct
 		results                []byte
-		statusOnlyResults      StatusOnlyStruct
+		status                 uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3ReadDirArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -955,23 +1676,45 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readdir(connHandle oncserver.C
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3ReadDirResults = nfsRequestHandler.callbacks.NFSProc3ReadDir(authSysBody, &nfsProc3ReadDirArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3ReadDirArgs.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3READDIR, xid, func() (returnedResults bool) {
+			nfsProc3ReadDirResults = nfsRequestHandler.callbacks.NFSProc3ReadDir(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3ReadDirArgs)
+			returnedResults = (nil != nfsProc3ReadDirResults)
+			return
+		}) {
+			nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3ReadDirResults = &NFSProc3ReadDirResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3ReadDirResults.Status {
+		nfsProc3ReadDirResults.trimToCount(nfsProc3ReadDirArgs.Count)
+	}
 
 	if OK == nfsProc3ReadDirResults.Status {
-		// TODO
+		results, err = nfsProc3ReadDirResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3ReadDirResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3ReadDirResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -979,26 +1722,28 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readdir(connHandle oncserver.C
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) readdirplus(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) readdirplus(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed              uint64
+		entryIndex                 int
 		err                        error
+		export                     *exportStruct
 		nfsProc3ReadDirPlusArgs    NFSProc3ReadDirPlusArgsStruct
 		nfsProc3ReadDirPlusResults *NFSProc3ReadDirPlusResultsStruct
 		results                    []byte
-		statusOnlyResults          StatusOnlyStruct
+		status                     uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3ReadDirPlusArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -1007,23 +1752,59 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readdirplus(connHandle oncserv
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3ReadDirPlusResults = nfsRequestHandler.callbacks.NFSProc3ReadDirPlus(authSysBody, &nfsProc3ReadDirPlusArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3ReadDirPlusArgs.Dir)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3READDIRPLUS, xid, func() (returnedResults bool) {
+			nfsProc3ReadDirPlusResults = nfsRequestHandler.callbacks.NFSProc3ReadDirPlus(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3ReadDirPlusArgs)
+			returnedResults = (nil != nfsProc3ReadDirPlusResults)
+			return
+		}) {
+			nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3ReadDirPlusResults = &NFSProc3ReadDirPlusResultsStruct{Status: status}
+	}
+
+	if OK == nfsProc3ReadDirPlusResults.Status {
+		for entryIndex = range nfsProc3ReadDirPlusResults.Entries {
+			err = export.wrapPostOpFh3(&nfsProc3ReadDirPlusResults.Entries[entryIndex].NameHandle)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+				err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+				if nil != err {
+					nfsRequestHandler.callbacks.ErrorLog(err)
+				}
+				return
+			}
+		}
+	}
+
+	if OK == nfsProc3ReadDirPlusResults.Status {
+		nfsProc3ReadDirPlusResults.trimToCounts(nfsProc3ReadDirPlusArgs.DirCount, nfsProc3ReadDirPlusArgs.MaxCount)
+	}
 
 	if OK == nfsProc3ReadDirPlusResults.Status {
-		// TODO
+		results, err = nfsProc3ReadDirPlusResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3ReadDirPlusResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3ReadDirPlusResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -1031,26 +1812,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) readdirplus(connHandle oncserv
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) fsstat(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) fsstat(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3FSStatArgs    NFSProc3FSStatArgsStruct
 		nfsProc3FSStatResults *NFSProc3FSStatResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3FSStatArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -1059,23 +1841,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) fsstat(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3FSStatResults = nfsRequestHandler.callbacks.NFSProc3FSStat(authSysBody, &nfsProc3FSStatArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3FSStatArgs.FSRoot)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3FSSTAT, xid, func() (returnedResults bool) {
+			nfsProc3FSStatResults = nfsRequestHandler.callbacks.NFSProc3FSStat(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3FSStatArgs)
+			returnedResults = (nil != nfsProc3FSStatResults)
+			return
+		}) {
+			nfsProc3FSStatResults = &NFSProc3FSStatResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3FSStatResults = &NFSProc3FSStatResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3FSStatResults.Status {
-		// TODO
+		results, err = nfsProc3FSStatResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3FSStatResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3FSStatResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -1083,26 +1883,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) fsstat(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) fsinfo(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) fsinfo(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3FSInfoArgs    NFSProc3FSInfoArgsStruct
 		nfsProc3FSInfoResults *NFSProc3FSInfoResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3FSInfoArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -1111,23 +1912,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) fsinfo(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3FSInfoResults = nfsRequestHandler.callbacks.NFSProc3FSInfo(authSysBody, &nfsProc3FSInfoArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3FSInfoArgs.FSRoot)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3FSINFO, xid, func() (returnedResults bool) {
+			nfsProc3FSInfoResults = nfsRequestHandler.callbacks.NFSProc3FSInfo(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3FSInfoArgs)
+			returnedResults = (nil != nfsProc3FSInfoResults)
+			return
+		}) {
+			nfsProc3FSInfoResults = &NFSProc3FSInfoResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3FSInfoResults = &NFSProc3FSInfoResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3FSInfoResults.Status {
-		// TODO
+		results, err = nfsProc3FSInfoResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3FSInfoResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3FSInfoResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -1135,26 +1954,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) fsinfo(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) pathconf(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) pathconf(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed           uint64
 		err                     error
+		export                  *exportStruct
 		nfsProc3PathConfArgs    NFSProc3PathConfArgsStruct
 		nfsProc3PathConfResults *NFSProc3PathConfResultsStruct
 		results                 []byte
-		statusOnlyResults       StatusOnlyStruct
+		status                  uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3PathConfArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -1163,23 +1983,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) pathconf(connHandle oncserver.
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3PathConfResults = nfsRequestHandler.callbacks.NFSProc3PathConf(authSysBody, &nfsProc3PathConfArgs)
+	export, credential, _, status = nfsRequestHandler.admit(connHandle, credential, &nfsProc3PathConfArgs.Object)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3PATHCONF, xid, func() (returnedResults bool) {
+			nfsProc3PathConfResults = nfsRequestHandler.callbacks.NFSProc3PathConf(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3PathConfArgs)
+			returnedResults = (nil != nfsProc3PathConfResults)
+			return
+		}) {
+			nfsProc3PathConfResults = &NFSProc3PathConfResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3PathConfResults = &NFSProc3PathConfResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3PathConfResults.Status {
-		// TODO
+		results, err = nfsProc3PathConfResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3PathConfResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3PathConfResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -1187,26 +2025,27 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) pathconf(connHandle oncserver.
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
 }
 
-func (nfsRequestHandler *nfsRequestHandlerStruct) commit(connHandle oncserver.ConnHandle, xid uint32, authSysBody *onc.AuthSysBodyStruct, parms []byte) {
+func (nfsRequestHandler *nfsRequestHandlerStruct) commit(connHandle connHandleInterface, xid uint32, credential *CredentialStruct, parms []byte) {
 	var (
 		bytesConsumed         uint64
 		err                   error
+		export                *exportStruct
 		nfsProc3CommitArgs    NFSProc3CommitArgsStruct
 		nfsProc3CommitResults *NFSProc3CommitResultsStruct
 		results               []byte
-		statusOnlyResults     StatusOnlyStruct
+		status                uint32
 	)
 
 	bytesConsumed, err = xdr.Unpack(parms, &nfsProc3CommitArgs)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
@@ -1215,23 +2054,41 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) commit(connHandle oncserver.Co
 	if uint64(len(parms)) != bytesConsumed {
 		err = fmt.Errorf("xdr.Unpack() failed to consume all of parms")
 		nfsRequestHandler.callbacks.ErrorLog(err)
-		err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.GarbageArgs)
+		err = connHandle.sendAcceptedOtherErrorReply(onc.GarbageArgs)
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
 		}
 		return
 	}
 
-	nfsProc3CommitResults = nfsRequestHandler.callbacks.NFSProc3Commit(authSysBody, &nfsProc3CommitArgs)
+	export, credential, status = nfsRequestHandler.admitMutation(connHandle, credential, &nfsProc3CommitArgs.File)
+	if OK == status {
+		if !nfsRequestHandler.invoke(NFSPROC3COMMIT, xid, func() (returnedResults bool) {
+			nfsProc3CommitResults = nfsRequestHandler.callbacks.NFSProc3Commit(nfsRequestHandler.requestContext(connHandle, xid, credential, export), &nfsProc3CommitArgs)
+			returnedResults = (nil != nfsProc3CommitResults)
+			return
+		}) {
+			nfsProc3CommitResults = &NFSProc3CommitResultsStruct{Status: NFS3ErrSERVERFAULT}
+		}
+	} else {
+		nfsProc3CommitResults = &NFSProc3CommitResultsStruct{Status: status}
+	}
 
 	if OK == nfsProc3CommitResults.Status {
-		// TODO
+		results, err = nfsProc3CommitResults.packResOK()
+		if nil != err {
+			nfsRequestHandler.callbacks.ErrorLog(err)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
+			if nil != err {
+				nfsRequestHandler.callbacks.ErrorLog(err)
+			}
+			return
+		}
 	} else {
-		statusOnlyResults.Status = nfsProc3CommitResults.Status
-		results, err = xdr.Pack(statusOnlyResults)
+		results, err = nfsProc3CommitResults.packResFail()
 		if nil != err {
 			nfsRequestHandler.callbacks.ErrorLog(err)
-			err = oncserver.SendAcceptedOtherErrorReply(connHandle, xid, onc.SystemErr)
+			err = connHandle.sendAcceptedOtherErrorReply(onc.SystemErr)
 			if nil != err {
 				nfsRequestHandler.callbacks.ErrorLog(err)
 			}
@@ -1239,7 +2096,7 @@ func (nfsRequestHandler *nfsRequestHandlerStruct) commit(connHandle oncserver.Co
 		}
 	}
 
-	err = oncserver.SendAcceptedSuccess(connHandle, xid, results)
+	err = connHandle.sendAcceptedSuccess(results)
 	if nil != err {
 		nfsRequestHandler.callbacks.ErrorLog(err)
 	}
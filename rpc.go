@@ -0,0 +1,242 @@
+package nfsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/swiftstack/onc"
+)
+
+// ONC RPC (RFC 5531) message encoding & decoding for requests received via this package's own transport (see
+// rpc_server.go) rather than via oncserver. This is necessary for those credential flavors (e.g. RPCSEC_GSS)
+// whose raw credential & verifier (as well as the reply verifier) oncserver does not surface.
+
+const (
+	rpcVers = uint32(2)
+
+	rpcMsgTypeCall  = uint32(0) // enum msg_type
+	rpcMsgTypeReply = uint32(1) // enum msg_type
+
+	rpcReplyStatAccepted = uint32(0) // enum reply_stat
+	rpcReplyStatDenied   = uint32(1) // enum reply_stat
+
+	rpcRejectStatRPCMismatch = uint32(0) // enum reject_stat
+	rpcRejectStatAuthError   = uint32(1) // enum reject_stat
+
+	rpcMaxAuthBodySize = 400 // opaque_auth body is limited to opaque<400>
+)
+
+const ( // enum auth_stat
+	rpcAuthStatOK             = uint32(0)  // AUTH_OK
+	rpcAuthStatBadCred        = uint32(1)  // AUTH_BADCRED
+	rpcAuthStatRejectedCred   = uint32(2)  // AUTH_REJECTEDCRED
+	rpcAuthStatBadVerf        = uint32(3)  // AUTH_BADVERF
+	rpcAuthStatRejectedVerf   = uint32(4)  // AUTH_REJECTEDVERF
+	rpcAuthStatTooWeak        = uint32(5)  // AUTH_TOOWEAK
+	rpcAuthStatGSSCredProblem = uint32(13) // RPCSEC_GSS_CREDPROBLEM
+	rpcAuthStatGSSCtxProblem  = uint32(14) // RPCSEC_GSS_CTXPROBLEM
+)
+
+// rpcCallStruct holds a decoded ONC RPC call message
+type rpcCallStruct struct {
+	xid        uint32
+	rpcVers    uint32
+	prog       uint32
+	vers       uint32
+	proc       uint32
+	credFlavor uint32
+	credBody   []byte
+	verfFlavor uint32
+	verfBody   []byte
+	header     []byte // the encoded call from xid thru the credential (i.e. that covered by an RPCSEC_GSS verifier)
+	parms      []byte
+}
+
+// decodeRPCCall decodes an ONC RPC message. If the message is not a call (or is too short to be one), ok is
+// returned as false and the message should be silently discarded. If the call's rpcvers is not rpcVers, only
+// xid & rpcVers are valid.
+func decodeRPCCall(msg []byte) (call *rpcCallStruct, ok bool) {
+	var (
+		msgType  uint32
+		unpacker = unpackerStruct{buf: msg}
+	)
+
+	call = &rpcCallStruct{}
+
+	call.xid = unpacker.unpackUint32()
+	msgType = unpacker.unpackUint32()
+	call.rpcVers = unpacker.unpackUint32()
+	if (nil != unpacker.err) || (rpcMsgTypeCall != msgType) {
+		ok = false
+		return
+	}
+	if rpcVers != call.rpcVers {
+		ok = true
+		return
+	}
+
+	call.prog = unpacker.unpackUint32()
+	call.vers = unpacker.unpackUint32()
+	call.proc = unpacker.unpackUint32()
+	call.credFlavor = unpacker.unpackUint32()
+	call.credBody = unpacker.unpackOpaque()
+	call.header = msg[:unpacker.bytesConsumed]
+	call.verfFlavor = unpacker.unpackUint32()
+	call.verfBody = unpacker.unpackOpaque()
+	if (nil != unpacker.err) || (rpcMaxAuthBodySize < len(call.credBody)) || (rpcMaxAuthBodySize < len(call.verfBody)) {
+		ok = false
+		return
+	}
+
+	call.parms = msg[unpacker.bytesConsumed:]
+
+	ok = true
+	return
+}
+
+// encodeRPCAcceptedReply encodes an accepted_reply. The body follows accept_stat (e.g. the results if
+// acceptStat == onc.Success or the mismatch_info if acceptStat == onc.ProgMismatch).
+func encodeRPCAcceptedReply(xid uint32, verfFlavor uint32, verfBody []byte, acceptStat uint32, body []byte) (reply []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(xid)
+	packer.packUint32(rpcMsgTypeReply)
+	packer.packUint32(rpcReplyStatAccepted)
+	packer.packUint32(verfFlavor)
+	packer.packOpaque(verfBody)
+	packer.packUint32(acceptStat)
+
+	reply, err = append(packer.buf, body...), packer.err
+	return
+}
+
+// encodeRPCProgMismatchReply encodes an accepted_reply indicating the range of supported versions of a program
+func encodeRPCProgMismatchReply(xid uint32, low uint32, high uint32) (reply []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(low)
+	packer.packUint32(high)
+	if nil != packer.err {
+		err = packer.err
+		return
+	}
+
+	reply, err = encodeRPCAcceptedReply(xid, AuthNone, []byte{}, onc.ProgMismatch, packer.buf)
+	return
+}
+
+// encodeRPCMismatchReply encodes a rejected_reply indicating that only rpcVers is supported
+func encodeRPCMismatchReply(xid uint32) (reply []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(xid)
+	packer.packUint32(rpcMsgTypeReply)
+	packer.packUint32(rpcReplyStatDenied)
+	packer.packUint32(rpcRejectStatRPCMismatch)
+	packer.packUint32(rpcVers)
+	packer.packUint32(rpcVers)
+
+	reply, err = packer.buf, packer.err
+	return
+}
+
+// encodeRPCAuthErrorReply encodes a rejected_reply indicating why the call's credential was not accepted
+func encodeRPCAuthErrorReply(xid uint32, authStat uint32) (reply []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(xid)
+	packer.packUint32(rpcMsgTypeReply)
+	packer.packUint32(rpcReplyStatDenied)
+	packer.packUint32(rpcRejectStatAuthError)
+	packer.packUint32(authStat)
+
+	reply, err = packer.buf, packer.err
+	return
+}
+
+// encodeRPCCall encodes a call bearing an AUTH_NONE credential & verifier (as issued by this package to rpcbind)
+func encodeRPCCall(xid uint32, prog uint32, vers uint32, proc uint32, parms []byte) (msg []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packUint32(xid)
+	packer.packUint32(rpcMsgTypeCall)
+	packer.packUint32(rpcVers)
+	packer.packUint32(prog)
+	packer.packUint32(vers)
+	packer.packUint32(proc)
+	packer.packUint32(AuthNone)
+	packer.packOpaque([]byte{})
+	packer.packUint32(AuthNone)
+	packer.packOpaque([]byte{})
+
+	msg, err = append(packer.buf, parms...), packer.err
+	return
+}
+
+// decodeRPCReply decodes the reply to a call issued via encodeRPCCall. A rejected_reply is returned as an error.
+// The body follows accept_stat (e.g. the results if acceptStat == onc.Success).
+func decodeRPCReply(msg []byte) (xid uint32, acceptStat uint32, body []byte, err error) {
+	var (
+		msgType   uint32
+		replyStat uint32
+		unpacker  = unpackerStruct{buf: msg}
+	)
+
+	xid = unpacker.unpackUint32()
+	msgType = unpacker.unpackUint32()
+	replyStat = unpacker.unpackUint32()
+	if nil != unpacker.err {
+		err = unpacker.err
+		return
+	}
+	if rpcMsgTypeReply != msgType {
+		err = fmt.Errorf("msg_type (%v) not REPLY", msgType)
+		return
+	}
+	if rpcReplyStatAccepted != replyStat {
+		err = fmt.Errorf("call rejected (reject_stat %v)", unpacker.unpackUint32())
+		return
+	}
+
+	_ = unpacker.unpackUint32() // verifier flavor
+	_ = unpacker.unpackOpaque() // verifier body
+	acceptStat = unpacker.unpackUint32()
+	if nil != unpacker.err {
+		err = unpacker.err
+		return
+	}
+
+	body = msg[unpacker.bytesConsumed:]
+
+	return
+}
+
+// connHandleInterface is satisfied by the connHandle supplied to the handler of each call. Calls received via this
+// package's own transport are supplied an *rpcReplierStruct (which may need to protect the results and supply a
+// reply verifier).
+type connHandleInterface interface {
+	RemoteAddr() net.Addr // address of the client (or nil if not available)
+	sendAcceptedSuccess(results []byte) (err error)
+	sendAcceptedOtherErrorReply(acceptStat uint32) (err error)
+	sendAuthErrorReply(authStat uint32) (err error) // fails with errAuthErrorReplyUnavailable if calls may not be rejected
+	sentReply() (sent bool)                         // whether the call has already been answered
+}
+
+// errAuthErrorReplyUnavailable is returned by sendAuthErrorReply() of a connHandle offering no means to reject a
+// call. Such calls are instead answered with a procedure-specific error status (e.g. NFS3ErrACCES).
+var errAuthErrorReplyUnavailable = errors.New("unable to reject call")
+
+func (call *rpcCallStruct) String() string {
+	return fmt.Sprintf("xid:0x%08X prog:%v vers:%v proc:%v flavor:%v", call.xid, call.prog, call.vers, call.proc, call.credFlavor)
+}
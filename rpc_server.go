@@ -0,0 +1,822 @@
+package nfsd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+// This package's own ONC RPC transport is used in place of oncserver as each server requires access to the raw
+// credential & verifier of each call (e.g. when RPCSEC_GSS has been enabled via EnableRPCSecGSS), the address of
+// the client (e.g. to apply an export table or the secure option), the ability to stop reading calls while those
+// in flight are answered (see DrainIPv4TCPNFSv3Server), and control over the number of calls handled at once.
+// As for the Linux kernel's server, AUTH_NONE & AUTH_SYS calls must bear an AUTH_NONE verifier.
+
+const (
+	rpcRecordLastFragment = uint32(0x80000000) // record marking (RFC 5531 section 11) last fragment indicator
+	rpcMaxRecordSize      = uint64(1 << 22)    // limit on the size of a reassembled TCP record
+	rpcMaxDatagramSize    = 1 << 16            // limit on the size of a UDP datagram
+
+	rpcMaxCallsInFlight     = 1024    // limit on calls being handled by a server at once
+	rpcMaxBytesInFlight     = 1 << 26 // limit on the total size of the calls being handled by a server at once
+	rpcMaxConnCallsInFlight = 32      // limit on calls received via a single TCP connection being handled at once
+	rpcMaxConnBytesInFlight = 1 << 24 // limit on the total size of calls received via a single TCP connection being handled at once
+)
+
+// rpcProgramInterface is satisfied by the request handlers for each ONC RPC program served by this package
+type rpcProgramInterface interface {
+	dispatch(connHandle connHandleInterface, xid uint32, proc uint32, credential *CredentialStruct, parms []byte)
+}
+
+// rpcServerStruct serves a single ONC RPC program:version on a single protocol:port
+type rpcServerStruct struct {
+	sync.WaitGroup                       // tracks each goroutine serving the listener, a connection, or a call
+	network        string                // one of "tcp", "tcp4", "tcp6", "udp", "udp4", or "udp6" (as for net.Listen)
+	bindAddr       string                // IP address upon which to listen ("" for all addresses) unless listener or packetConn supplied
+	prot           uint32                // either onc.IPProtoTCP or onc.IPProtoUDP (as implied by network)
+	port           uint16                //
+	prog           uint32                //
+	vers           uint32                //
+	program        rpcProgramInterface   //
+	errorLog       func(err error)       //
+	anonUID        uint32                // UID to which AUTH_NONE requests (and unmapped RPCSEC_GSS principals) are mapped
+	anonGID        uint32                // GID to which AUTH_NONE requests (and unmapped RPCSEC_GSS principals) are mapped
+	gss            *gssStruct            // if nil, RPCSEC_GSS credentials are rejected
+	listener       net.Listener          // only used/valid if prot == onc.IPProtoTCP (may be supplied by the caller)
+	packetConn     net.PacketConn        // only used/valid if prot == onc.IPProtoUDP (may be supplied by the caller)
+	connsLock      sync.Mutex            //
+	conns          map[net.Conn]struct{} // open TCP connections (closed upon stop())
+	stopping       bool                  // protected by connsLock
+	jukebox        bool                  // protected by connsLock; if set (while stopping), calls are deferred rather than dropped
+	calls          sync.WaitGroup        // tracks each call admitted (i.e. neither dropped nor deferred) not yet answered
+	inFlight       *rpcLimiterStruct     // bounds the calls (admitted or deferred) being handled
+	ctx            context.Context       // parent of the context supplied to callbacks (cancelled upon stop())
+	cancel         context.CancelFunc    // cancels ctx
+}
+
+// rpcReplierStruct is the connHandle supplied to request handlers for each call received by an rpcServerStruct
+type rpcReplierStruct struct {
+	server     *rpcServerStruct
+	call       *rpcCallStruct
+	remoteAddr net.Addr
+	send       func(reply []byte) (err error)
+	gssContext *gssContextStruct // if non-nil, replies bear an RPCSEC_GSS verifier
+	gssSeqNum  uint32            // only used/valid if gssContext != nil
+	gssService uint32            // only used/valid if gssContext != nil
+	deferred   bool              // if set, the call was received while draining & is to be answered with NFS3ErrJUKEBOX
+	ctx        context.Context   // cancelled should the connection via which the call was received be closed
+	received   time.Time         //
+	replied    bool              // set once a reply has been sent (see reply)
+}
+
+// rpcLimiterStruct bounds both the number and total size of calls being handled at once
+type rpcLimiterStruct struct {
+	sync.Mutex
+	cond     *sync.Cond
+	maxCalls int
+	maxBytes int
+	calls    int
+	bytes    int
+}
+
+// rpcServerKeyStruct identifies a server in globalRPCServers. For a server supplied a listener or packetConn,
+// network & bindAddr are those of its address (i.e. as returned by addr.Network() & addr.String()) and port is 0.
+type rpcServerKeyStruct struct {
+	network  string
+	bindAddr string
+	port     uint16
+}
+
+var (
+	globalRPCServersLock sync.Mutex
+	globalRPCServers     = make(map[rpcServerKeyStruct]*rpcServerStruct)
+)
+
+// parseRPCNetwork validates network (as supplied to net.Listen or net.ListenPacket) returning the transport
+// (i.e. network sans any "4" or "6" suffix) and the corresponding protocol
+func parseRPCNetwork(network string) (transport string, prot uint32, err error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		transport = "tcp"
+		prot = onc.IPProtoTCP
+	case "udp", "udp4", "udp6":
+		transport = "udp"
+		prot = onc.IPProtoUDP
+	default:
+		err = fmt.Errorf("network (\"%s\") not supported", network)
+	}
+	return
+}
+
+// addrKey returns the key in globalRPCServers of a server supplied a listener or packetConn listening on addr
+func addrKey(addr net.Addr) (key rpcServerKeyStruct) {
+	key = rpcServerKeyStruct{network: addr.Network(), bindAddr: addr.String()}
+	return
+}
+
+// startRPCServer begins serving as specified by a partially populated rpcServerStruct (i.e. either listener,
+// packetConn, or network, bindAddr, & port determine the address upon which to listen)
+func startRPCServer(server *rpcServerStruct) (err error) {
+	var (
+		key rpcServerKeyStruct
+		ok  bool
+	)
+
+	switch {
+	case nil != server.listener:
+		key = addrKey(server.listener.Addr())
+	case nil != server.packetConn:
+		key = addrKey(server.packetConn.LocalAddr())
+	default:
+		key = rpcServerKeyStruct{network: server.network, bindAddr: server.bindAddr, port: server.port}
+	}
+
+	globalRPCServersLock.Lock()
+	defer globalRPCServersLock.Unlock()
+
+	_, ok = globalRPCServers[key]
+	if ok {
+		err = fmt.Errorf("%s %s already being served", key.network, net.JoinHostPort(key.bindAddr, strconv.Itoa(int(key.port))))
+		return
+	}
+
+	err = server.start()
+	if nil != err {
+		return
+	}
+
+	globalRPCServers[key] = server
+
+	return
+}
+
+// stopRPCServer halts an rpcServerStruct previously launched via startRPCServer(). If no such server was
+// launched, found is returned as false.
+func stopRPCServer(key rpcServerKeyStruct) (found bool, err error) {
+	var (
+		server *rpcServerStruct
+	)
+
+	globalRPCServersLock.Lock()
+	server, found = globalRPCServers[key]
+	if found {
+		delete(globalRPCServers, key)
+	}
+	globalRPCServersLock.Unlock()
+
+	if !found {
+		return
+	}
+
+	err = server.stop()
+
+	return
+}
+
+// drainRPCServer halts an rpcServerStruct previously launched via startRPCServer() as by shutdown(). If no such
+// server was launched, found is returned as false.
+func drainRPCServer(ctx context.Context, key rpcServerKeyStruct, jukebox bool) (found bool, err error) {
+	var (
+		server *rpcServerStruct
+	)
+
+	globalRPCServersLock.Lock()
+	server, found = globalRPCServers[key]
+	if found {
+		delete(globalRPCServers, key)
+	}
+	globalRPCServersLock.Unlock()
+
+	if !found {
+		return
+	}
+
+	err = server.shutdown(ctx, jukebox)
+
+	return
+}
+
+// start listens as specified by server.network, server.bindAddr, & server.port (unless supplied a listener or
+// packetConn, which it then owns) and begins serving
+func (server *rpcServerStruct) start() (err error) {
+	var (
+		address string
+	)
+
+	server.conns = make(map[net.Conn]struct{})
+	server.ctx, server.cancel = context.WithCancel(context.Background())
+	server.inFlight = newRPCLimiter(rpcMaxCallsInFlight, rpcMaxBytesInFlight)
+
+	if nil != server.listener {
+		server.network = server.listener.Addr().Network()
+		server.prot = onc.IPProtoTCP
+		server.port = addrPort(server.listener.Addr())
+		server.Add(1)
+		go server.serveTCP()
+		return
+	}
+
+	if nil != server.packetConn {
+		server.network = server.packetConn.LocalAddr().Network()
+		server.prot = onc.IPProtoUDP
+		server.port = addrPort(server.packetConn.LocalAddr())
+		server.Add(1)
+		go server.serveUDP()
+		return
+	}
+
+	_, server.prot, err = parseRPCNetwork(server.network)
+	if nil != err {
+		return
+	}
+
+	if ("" != server.bindAddr) && (nil == net.ParseIP(server.bindAddr)) {
+		err = fmt.Errorf("bindAddr (\"%s\") must be an IP address", server.bindAddr)
+		return
+	}
+
+	address = net.JoinHostPort(server.bindAddr, strconv.Itoa(int(server.port)))
+
+	if onc.IPProtoTCP == server.prot {
+		server.listener, err = net.Listen(server.network, address)
+		if nil != err {
+			return
+		}
+		server.port = uint16(server.listener.Addr().(*net.TCPAddr).Port) // in case server.port was 0
+		server.Add(1)
+		go server.serveTCP()
+	} else {
+		server.packetConn, err = net.ListenPacket(server.network, address)
+		if nil != err {
+			return
+		}
+		server.port = uint16(server.packetConn.LocalAddr().(*net.UDPAddr).Port) // in case server.port was 0
+		server.Add(1)
+		go server.serveUDP()
+	}
+
+	return
+}
+
+// shutdown stops the server accepting further calls, waits for those in flight to be answered, and then
+// releases its listener & connections. Calls received in the meantime are dropped (such that clients will
+// retransmit them) unless jukebox is set, in which case they are deferred (i.e. answered with NFS3ErrJUKEBOX
+// without invoking any callback). If ctx is done first, the server is instead stopped immediately.
+func (server *rpcServerStruct) shutdown(ctx context.Context, jukebox bool) (err error) {
+	var (
+		conn    net.Conn
+		drained = make(chan struct{})
+	)
+
+	server.connsLock.Lock()
+	server.stopping = true
+	server.jukebox = jukebox
+	if onc.IPProtoTCP == server.prot {
+		_ = server.listener.Close()
+		if !jukebox {
+			for conn = range server.conns {
+				_ = conn.SetReadDeadline(time.Now())
+			}
+		}
+	} else if !jukebox {
+		_ = server.packetConn.SetReadDeadline(time.Now())
+	}
+	server.connsLock.Unlock()
+
+	go func() {
+		server.calls.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		err = server.stop()
+	case <-ctx.Done():
+		_ = server.stop()
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// admitCall determines whether a call just received is to be handled. Once stopping, calls are either deferred
+// (see shutdown()) or, if neither admitted nor deferred, dropped.
+func (server *rpcServerStruct) admitCall() (admitted bool, deferred bool) {
+	server.connsLock.Lock()
+	if !server.stopping {
+		server.calls.Add(1)
+		admitted = true
+	} else {
+		deferred = server.jukebox
+	}
+	server.connsLock.Unlock()
+	return
+}
+
+// addrPort returns the port of addr (or 0 if addr is not an IP address, e.g. that of a Unix-domain socket)
+func addrPort(addr net.Addr) (port uint16) {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		port = uint16(addr.Port)
+	case *net.UDPAddr:
+		port = uint16(addr.Port)
+	default:
+		port = 0
+	}
+	return
+}
+
+func (server *rpcServerStruct) isStopping() (stopping bool) {
+	server.connsLock.Lock()
+	stopping = server.stopping
+	server.connsLock.Unlock()
+	return
+}
+
+// stop releases the server's listener & connections (without waiting for calls in flight to be answered)
+func (server *rpcServerStruct) stop() (err error) {
+	var (
+		conn net.Conn
+	)
+
+	server.connsLock.Lock()
+	server.stopping = true
+	server.jukebox = false
+	if onc.IPProtoTCP == server.prot {
+		err = server.listener.Close()
+		for conn = range server.conns {
+			_ = conn.Close()
+		}
+	} else {
+		err = server.packetConn.Close()
+	}
+	server.connsLock.Unlock()
+
+	if errors.Is(err, net.ErrClosed) {
+		err = nil // i.e. already closed by shutdown()
+	}
+
+	server.cancel()
+	server.Wait()
+
+	return
+}
+
+func (server *rpcServerStruct) serveTCP() {
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	defer server.Done()
+
+	for {
+		conn, err = server.listener.Accept()
+		if nil != err {
+			return // server.listener has been closed
+		}
+
+		server.connsLock.Lock()
+		if server.stopping {
+			server.connsLock.Unlock()
+			_ = conn.Close()
+			continue
+		}
+		server.conns[conn] = struct{}{}
+		server.connsLock.Unlock()
+
+		server.Add(1)
+		go server.serveConn(conn)
+	}
+}
+
+func (server *rpcServerStruct) serveConn(conn net.Conn) {
+	var (
+		admitted  bool
+		cancel    context.CancelFunc
+		ctx       context.Context // cancelled once conn is closed
+		deferred  bool
+		connLimit = newRPCLimiter(rpcMaxConnCallsInFlight, rpcMaxConnBytesInFlight)
+		err       error
+		inFlight  sync.WaitGroup // calls received via conn not yet answered
+		msg       []byte
+		reader    = bufio.NewReader(conn)
+		writeLock sync.Mutex
+	)
+
+	defer server.Done()
+
+	ctx, cancel = context.WithCancel(server.ctx)
+	defer cancel()
+
+	send := func(reply []byte) (err error) {
+		var (
+			record = make([]byte, 4, 4+len(reply))
+		)
+
+		binary.BigEndian.PutUint32(record, rpcRecordLastFragment|uint32(len(reply)))
+		record = append(record, reply...)
+
+		writeLock.Lock()
+		_, err = conn.Write(record)
+		writeLock.Unlock()
+
+		return
+	}
+
+	for {
+		msg, err = readRPCRecord(reader)
+		if nil != err {
+			if (io.EOF != err) && !errors.Is(err, net.ErrClosed) && !server.isStopping() {
+				server.errorLog(fmt.Errorf("connection from %v dropped: %v", conn.RemoteAddr(), err))
+			}
+			break
+		}
+
+		// Reading further calls from conn pauses while either it or the server has too many in flight
+
+		if !connLimit.acquire(ctx, len(msg)) {
+			break
+		}
+		if !server.inFlight.acquire(ctx, len(msg)) {
+			connLimit.release(len(msg))
+			break
+		}
+
+		admitted, deferred = server.admitCall()
+		if !admitted && !deferred {
+			server.inFlight.release(len(msg))
+			connLimit.release(len(msg))
+			continue
+		}
+
+		inFlight.Add(1)
+		server.Add(1)
+		go func(msg []byte, admitted bool, deferred bool) {
+			defer server.Done()
+			defer inFlight.Done()
+			defer connLimit.release(len(msg))
+			defer server.inFlight.release(len(msg))
+			if admitted {
+				defer server.calls.Done()
+			}
+			server.handleCall(ctx, msg, conn.RemoteAddr(), send, deferred)
+		}(msg, admitted, deferred)
+	}
+
+	if !server.isStopping() {
+		cancel() // the client closed the connection, so callbacks in flight on its behalf needn't complete
+	}
+
+	inFlight.Wait()
+
+	server.connsLock.Lock()
+	delete(server.conns, conn)
+	server.connsLock.Unlock()
+
+	_ = conn.Close()
+}
+
+// readRPCRecord reassembles the fragments of a record marked (RFC 5531 section 11) TCP message
+func readRPCRecord(reader io.Reader) (msg []byte, err error) {
+	var (
+		fragment       []byte
+		fragmentHeader [4]byte
+		fragmentLen    uint32
+		lastFragment   bool
+	)
+
+	msg = make([]byte, 0)
+
+	for !lastFragment {
+		_, err = io.ReadFull(reader, fragmentHeader[:])
+		if nil != err {
+			if (io.ErrUnexpectedEOF == err) || ((io.EOF == err) && (0 != len(msg))) {
+				err = fmt.Errorf("record truncated")
+			}
+			return
+		}
+
+		lastFragment = (0 != (binary.BigEndian.Uint32(fragmentHeader[:]) & rpcRecordLastFragment))
+		fragmentLen = binary.BigEndian.Uint32(fragmentHeader[:]) &^ rpcRecordLastFragment
+
+		if rpcMaxRecordSize < (uint64(len(msg)) + uint64(fragmentLen)) {
+			err = fmt.Errorf("record exceeds %v bytes", rpcMaxRecordSize)
+			return
+		}
+
+		fragment = make([]byte, fragmentLen)
+		_, err = io.ReadFull(reader, fragment)
+		if nil != err {
+			if (io.EOF == err) || (io.ErrUnexpectedEOF == err) {
+				err = fmt.Errorf("record truncated")
+			}
+			return
+		}
+
+		msg = append(msg, fragment...)
+	}
+
+	return
+}
+
+func (server *rpcServerStruct) serveUDP() {
+	var (
+		admitted   bool
+		buf        = make([]byte, rpcMaxDatagramSize)
+		deferred   bool
+		err        error
+		msg        []byte
+		n          int
+		remoteAddr net.Addr
+	)
+
+	defer server.Done()
+
+	for {
+		n, remoteAddr, err = server.packetConn.ReadFrom(buf)
+		if nil != err {
+			return // server.packetConn has been closed
+		}
+
+		// Datagrams arriving while the server has too many calls in flight are dropped (to be retransmitted)
+
+		if !server.inFlight.tryAcquire(n) {
+			continue
+		}
+
+		msg = make([]byte, n)
+		copy(msg, buf[:n])
+
+		admitted, deferred = server.admitCall()
+		if !admitted && !deferred {
+			server.inFlight.release(n)
+			continue
+		}
+
+		server.Add(1)
+		go func(msg []byte, remoteAddr net.Addr, admitted bool, deferred bool) {
+			defer server.Done()
+			defer server.inFlight.release(len(msg))
+			if admitted {
+				defer server.calls.Done()
+			}
+			server.handleCall(server.ctx, msg, remoteAddr, func(reply []byte) (err error) {
+				_, err = server.packetConn.WriteTo(reply, remoteAddr)
+				return
+			}, deferred)
+		}(msg, remoteAddr, admitted, deferred)
+	}
+}
+
+func newRPCLimiter(maxCalls int, maxBytes int) (limiter *rpcLimiterStruct) {
+	limiter = &rpcLimiterStruct{maxCalls: maxCalls, maxBytes: maxBytes}
+	limiter.cond = sync.NewCond(&limiter.Mutex)
+	return
+}
+
+// fits returns whether a call of size bytes may be handled now. A call is always permitted if none are in flight
+// (lest one larger than maxBytes never be). Note that limiter.Mutex must be held.
+func (limiter *rpcLimiterStruct) fits(size int) (ok bool) {
+	ok = (0 == limiter.calls) || ((limiter.calls < limiter.maxCalls) && ((limiter.bytes + size) <= limiter.maxBytes))
+	return
+}
+
+// acquire waits until a call of size bytes may be handled, returning false should ctx be done first
+func (limiter *rpcLimiterStruct) acquire(ctx context.Context, size int) (ok bool) {
+	var (
+		stop func() bool
+	)
+
+	stop = context.AfterFunc(ctx, func() {
+		limiter.Lock()
+		limiter.cond.Broadcast()
+		limiter.Unlock()
+	})
+	defer stop()
+
+	limiter.Lock()
+	defer limiter.Unlock()
+
+	for !limiter.fits(size) {
+		if nil != ctx.Err() {
+			return
+		}
+		limiter.cond.Wait()
+	}
+
+	limiter.calls++
+	limiter.bytes += size
+
+	ok = true
+	return
+}
+
+// tryAcquire is like acquire but returns false rather than wait
+func (limiter *rpcLimiterStruct) tryAcquire(size int) (ok bool) {
+	limiter.Lock()
+	if limiter.fits(size) {
+		limiter.calls++
+		limiter.bytes += size
+		ok = true
+	}
+	limiter.Unlock()
+	return
+}
+
+func (limiter *rpcLimiterStruct) release(size int) {
+	limiter.Lock()
+	limiter.calls--
+	limiter.bytes -= size
+	limiter.cond.Broadcast()
+	limiter.Unlock()
+}
+
+// handleCall authenticates a single call and, if successful, dispatches it to server.program
+func (server *rpcServerStruct) handleCall(ctx context.Context, msg []byte, remoteAddr net.Addr, send func(reply []byte) (err error), deferred bool) {
+	var (
+		authSysBody   onc.AuthSysBodyStruct
+		bytesConsumed uint64
+		call          *rpcCallStruct
+		credential    *CredentialStruct
+		err           error
+		ok            bool
+		parms         []byte
+		replier       *rpcReplierStruct
+		reply         []byte
+	)
+
+	call, ok = decodeRPCCall(msg)
+	if !ok {
+		return
+	}
+
+	replier = &rpcReplierStruct{
+		server:     server,
+		call:       call,
+		remoteAddr: remoteAddr,
+		send:       send,
+		deferred:   deferred,
+		ctx:        ctx,
+		received:   time.Now(),
+	}
+
+	if rpcVers != call.rpcVers {
+		reply, err = encodeRPCMismatchReply(call.xid)
+		if nil == err {
+			err = send(reply)
+		}
+		if nil != err {
+			server.errorLog(err)
+		}
+		return
+	}
+
+	if server.prog != call.prog {
+		err = replier.sendAcceptedOtherErrorReply(onc.ProgUnavail)
+		if nil != err {
+			server.errorLog(err)
+		}
+		return
+	}
+
+	if server.vers != call.vers {
+		reply, err = encodeRPCProgMismatchReply(call.xid, server.vers, server.vers)
+		if nil == err {
+			err = send(reply)
+		}
+		if nil != err {
+			server.errorLog(err)
+		}
+		return
+	}
+
+	if ((AuthNone == call.credFlavor) || (AuthSys == call.credFlavor)) && ((AuthNone != call.verfFlavor) || (0 != len(call.verfBody))) {
+		replier.sendAuthError(rpcAuthStatBadVerf)
+		return
+	}
+
+	switch call.credFlavor {
+	case AuthNone:
+		credential = newCredential(nil, server.anonUID, server.anonGID)
+		parms = call.parms
+	case AuthSys:
+		bytesConsumed, err = xdr.Unpack(call.credBody, &authSysBody)
+		if (nil != err) || (uint64(len(call.credBody)) != bytesConsumed) {
+			replier.sendAuthError(rpcAuthStatBadCred)
+			return
+		}
+		credential = newCredential(&authSysBody, server.anonUID, server.anonGID)
+		parms = call.parms
+	case RPCSecGSS:
+		if nil == server.gss {
+			replier.sendAuthError(rpcAuthStatBadCred)
+			return
+		}
+		credential, parms, ok = server.gss.handleCall(replier)
+		if !ok {
+			return
+		}
+	default:
+		replier.sendAuthError(rpcAuthStatBadCred)
+		return
+	}
+
+	server.program.dispatch(replier, call.xid, call.proc, credential, parms)
+}
+
+// RemoteAddr satisfies connHandleInterface
+func (replier *rpcReplierStruct) RemoteAddr() net.Addr {
+	return replier.remoteAddr
+}
+
+func (replier *rpcReplierStruct) sendAcceptedSuccess(results []byte) (err error) {
+	err = replier.sendAccepted(onc.Success, results)
+	return
+}
+
+func (replier *rpcReplierStruct) sendAcceptedOtherErrorReply(acceptStat uint32) (err error) {
+	err = replier.sendAccepted(acceptStat, []byte{})
+	return
+}
+
+func (replier *rpcReplierStruct) sendAccepted(acceptStat uint32, results []byte) (err error) {
+	var (
+		reply      []byte
+		verfBody   = []byte{}
+		verfFlavor = AuthNone
+	)
+
+	if nil != replier.gssContext {
+		verfBody, err = replier.gssContext.replyVerifier(replier.gssSeqNum)
+		if nil != err {
+			return
+		}
+		verfFlavor = RPCSecGSS
+
+		if onc.Success == acceptStat {
+			results, err = replier.gssContext.protect(replier.gssService, replier.gssSeqNum, results)
+			if nil != err {
+				return
+			}
+		}
+	}
+
+	reply, err = encodeRPCAcceptedReply(replier.call.xid, verfFlavor, verfBody, acceptStat, results)
+	if nil != err {
+		return
+	}
+
+	err = replier.reply(reply)
+
+	return
+}
+
+func (replier *rpcReplierStruct) sendAuthErrorReply(authStat uint32) (err error) {
+	var (
+		reply []byte
+	)
+
+	reply, err = encodeRPCAuthErrorReply(replier.call.xid, authStat)
+	if nil == err {
+		err = replier.reply(reply)
+	}
+
+	return
+}
+
+// reply sends reply to the client recording that the call has been answered (such that it is not answered again)
+func (replier *rpcReplierStruct) reply(reply []byte) (err error) {
+	err = replier.send(reply)
+	if nil == err {
+		replier.replied = true
+	}
+	return
+}
+
+func (replier *rpcReplierStruct) sentReply() (sent bool) {
+	sent = replier.replied
+	return
+}
+
+// sendAuthError rejects a call whose credential (or verifier) was not accepted
+func (replier *rpcReplierStruct) sendAuthError(authStat uint32) {
+	var (
+		err error
+	)
+
+	err = replier.sendAuthErrorReply(authStat)
+	if nil != err {
+		replier.server.errorLog(err)
+	}
+}
@@ -0,0 +1,558 @@
+package nfsd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+// testNullNFSv3Struct answers only NFSPROC3_NULL (any other callback panics via the nil embedded interface)
+type testNullNFSv3Struct struct {
+	NFSv3Interface
+	t *testing.T
+}
+
+func (testNullNFSv3 *testNullNFSv3Struct) ErrorLog(err error) {
+	testNullNFSv3.t.Logf("ErrorLog(%v)", err)
+}
+
+func (testNullNFSv3 *testNullNFSv3Struct) NFSProc3Null(credential *CredentialStruct) {}
+
+// testFreePort returns a port (probably) not in use for network on bindAddr (or 0 if network is not available)
+func testFreePort(network string, bindAddr string) (port uint16) {
+	if "udp6" == network {
+		packetConn, err := net.ListenPacket(network, net.JoinHostPort(bindAddr, "0"))
+		if nil != err {
+			return
+		}
+		port = uint16(packetConn.LocalAddr().(*net.UDPAddr).Port)
+		_ = packetConn.Close()
+		return
+	}
+
+	listener, err := net.Listen(network, net.JoinHostPort(bindAddr, "0"))
+	if nil != err {
+		return
+	}
+	port = uint16(listener.Addr().(*net.TCPAddr).Port)
+	_ = listener.Close()
+	return
+}
+
+// testNullCall issues NFSPROC3_NULL via conn returning the accept_stat of the reply
+func testNullCall(t *testing.T, conn net.Conn, recordMarking bool) (acceptStat uint32) {
+	var (
+		err   error
+		msg   []byte
+		reply []byte
+	)
+
+	msg, err = encodeRPCCall(7, onc.ProgNumNFS, 3, ProcNULL, []byte{})
+	if nil != err {
+		t.Fatalf("encodeRPCCall() failed: %v", err)
+	}
+
+	if recordMarking {
+		record := make([]byte, 4)
+		binary.BigEndian.PutUint32(record, rpcRecordLastFragment|uint32(len(msg)))
+		msg = append(record, msg...)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write(msg)
+	if nil != err {
+		t.Fatalf("conn.Write() failed: %v", err)
+	}
+
+	if recordMarking {
+		reply, err = readRPCRecord(conn)
+	} else {
+		reply = make([]byte, rpcMaxDatagramSize)
+		var n int
+		n, err = conn.Read(reply)
+		reply = reply[:n]
+	}
+	if nil != err {
+		t.Fatalf("reading reply failed: %v", err)
+	}
+
+	_, acceptStat, _, err = decodeRPCReply(reply)
+	if nil != err {
+		t.Fatalf("decodeRPCReply() failed: %v", err)
+	}
+
+	return
+}
+
+func TestIPv6Server(t *testing.T) {
+	for _, network := range []string{"tcp6", "udp6"} {
+		port := testFreePort(network, "::1")
+		if 0 == port {
+			t.Skipf("%s on ::1 not available", network)
+		}
+
+		_, err := StartNFSv3Server(network, "::1", port, false, &testNullNFSv3Struct{t: t})
+		if nil != err {
+			t.Fatalf("StartNFSv3Server(%s) failed: %v", network, err)
+		}
+
+		_, err = StartNFSv3Server(network, "::1", port, false, &testNullNFSv3Struct{t: t})
+		if nil == err {
+			t.Fatalf("StartNFSv3Server(%s) of an already served port should have failed", network)
+		}
+
+		conn, err := net.Dial(network, net.JoinHostPort("::1", strconv.Itoa(int(port))))
+		if nil != err {
+			t.Fatalf("net.Dial(%s) failed: %v", network, err)
+		}
+
+		if onc.Success != testNullCall(t, conn, ("tcp6" == network)) {
+			t.Fatalf("NULL via %s not successful", network)
+		}
+
+		_ = conn.Close()
+
+		_, err = StopNFSv3Server(network, "::1", port, false)
+		if nil != err {
+			t.Fatalf("StopNFSv3Server(%s) failed: %v", network, err)
+		}
+
+		_, err = StopNFSv3Server(network, "::1", port, false)
+		if nil == err {
+			t.Fatalf("StopNFSv3Server(%s) of a stopped server should have failed", network)
+		}
+	}
+}
+
+// testBlockingNFSv3Struct answers NFSPROC3_GETATTR only once unblock is closed (any other callback but NULL panics)
+type testBlockingNFSv3Struct struct {
+	testNullNFSv3Struct
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func (testBlockingNFSv3 *testBlockingNFSv3Struct) NFSProc3GetAttr(credential *CredentialStruct, nfsProc3GetAttrArgs *NFSProc3GetAttrArgsStruct) (nfsProc3GetAttrResults *NFSProc3GetAttrResultsStruct) {
+	testBlockingNFSv3.entered <- struct{}{}
+	<-testBlockingNFSv3.unblock
+	nfsProc3GetAttrResults = &NFSProc3GetAttrResultsStruct{Status: OK}
+	return
+}
+
+// testSendGetAttr issues NFSPROC3_GETATTR via conn (a TCP connection) without awaiting the reply
+func testSendGetAttr(t *testing.T, conn net.Conn, xid uint32) {
+	parms, err := xdr.Pack(&NFSProc3GetAttrArgsStruct{Object: []byte{1, 2}})
+	if nil != err {
+		t.Fatalf("xdr.Pack() failed: %v", err)
+	}
+
+	msg, err := encodeRPCCall(xid, onc.ProgNumNFS, 3, NFSPROC3GETATTR, parms)
+	if nil != err {
+		t.Fatalf("encodeRPCCall() failed: %v", err)
+	}
+
+	record := make([]byte, 4)
+	binary.BigEndian.PutUint32(record, rpcRecordLastFragment|uint32(len(msg)))
+
+	_, err = conn.Write(append(record, msg...))
+	if nil != err {
+		t.Fatalf("conn.Write() failed: %v", err)
+	}
+}
+
+// testRecvStatus reads a reply via conn (a TCP connection) returning its xid & nfsstat3
+func testRecvStatus(t *testing.T, conn net.Conn) (xid uint32, status uint32) {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	reply, err := readRPCRecord(conn)
+	if nil != err {
+		t.Fatalf("reading reply failed: %v", err)
+	}
+
+	xid, acceptStat, body, err := decodeRPCReply(reply)
+	if (nil != err) || (onc.Success != acceptStat) || (4 > len(body)) {
+		t.Fatalf("decodeRPCReply() returned acceptStat %v & body %v (err: %v)", acceptStat, body, err)
+	}
+
+	status = binary.BigEndian.Uint32(body)
+
+	return
+}
+
+// testDrainingServer polls until server is stopping (i.e. draining)
+func testDrainingServer(t *testing.T, server *rpcServerStruct) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if server.isStopping() {
+			return
+		}
+	}
+
+	t.Fatalf("server not draining")
+}
+
+func TestDrain(t *testing.T) {
+	port := testFreePort("tcp4", "127.0.0.1")
+	callbacks := &testBlockingNFSv3Struct{testNullNFSv3Struct: testNullNFSv3Struct{t: t}, entered: make(chan struct{}, 1), unblock: make(chan struct{})}
+
+	_, err := StartNFSv3Server("tcp4", "127.0.0.1", port, false, callbacks)
+	if nil != err {
+		t.Fatalf("StartNFSv3Server() failed: %v", err)
+	}
+
+	globalRPCServersLock.Lock()
+	server := globalRPCServers[rpcServerKeyStruct{network: "tcp4", bindAddr: "127.0.0.1", port: port}]
+	globalRPCServersLock.Unlock()
+
+	conn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if nil != err {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	testSendGetAttr(t, conn, 1)
+	<-callbacks.entered
+
+	drainErrChan := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, drainErr := DrainNFSv3Server(ctx, "tcp4", "127.0.0.1", port, false, true)
+		drainErrChan <- drainErr
+	}()
+
+	testDrainingServer(t, server)
+
+	testSendGetAttr(t, conn, 2)
+
+	xid, status := testRecvStatus(t, conn)
+	if (2 != xid) || (NFS3ErrJUKEBOX != status) {
+		t.Fatalf("GETATTR received while draining returned xid %v status %v... expected xid 2 status NFS3ErrJUKEBOX", xid, status)
+	}
+
+	select {
+	case err = <-drainErrChan:
+		t.Fatalf("DrainNFSv3Server() returned (%v) while a callback was in flight", err)
+	default:
+	}
+
+	close(callbacks.unblock)
+
+	xid, status = testRecvStatus(t, conn)
+	if (1 != xid) || (OK != status) {
+		t.Fatalf("GETATTR in flight returned xid %v status %v... expected xid 1 status OK", xid, status)
+	}
+
+	err = <-drainErrChan
+	if nil != err {
+		t.Fatalf("DrainNFSv3Server() failed: %v", err)
+	}
+
+	_, err = net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if nil == err {
+		t.Fatalf("net.Dial() of a drained server should have failed")
+	}
+}
+
+func TestDrainDeadline(t *testing.T) {
+	port := testFreePort("tcp4", "127.0.0.1")
+	callbacks := &testBlockingNFSv3Struct{testNullNFSv3Struct: testNullNFSv3Struct{t: t}, entered: make(chan struct{}, 1), unblock: make(chan struct{})}
+
+	_, err := StartIPv4TCPNFSv3Server(port, false, callbacks)
+	if nil != err {
+		t.Fatalf("StartIPv4TCPNFSv3Server() failed: %v", err)
+	}
+
+	conn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if nil != err {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	testSendGetAttr(t, conn, 1)
+	<-callbacks.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	time.AfterFunc(100*time.Millisecond, func() { close(callbacks.unblock) })
+
+	_, err = DrainIPv4TCPNFSv3Server(ctx, port, false, false)
+	if context.DeadlineExceeded != err {
+		t.Fatalf("DrainIPv4TCPNFSv3Server() returned %v... expected context.DeadlineExceeded", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = readRPCRecord(conn)
+	if nil == err {
+		t.Fatalf("reply to GETATTR in flight when the deadline expired should not have been received")
+	}
+}
+
+func TestServerOnListener(t *testing.T) {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "nfsd.sock"))
+	if nil != err {
+		t.Skipf("Unix-domain sockets not available: %v", err)
+	}
+
+	packetConn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.ListenPacket() failed: %v", err)
+	}
+
+	_, err = StartNFSv3ServerOnListener(listener, false, &testNullNFSv3Struct{t: t})
+	if nil != err {
+		t.Fatalf("StartNFSv3ServerOnListener() failed: %v", err)
+	}
+
+	_, err = StartNFSv3ServerOnListener(listener, false, &testNullNFSv3Struct{t: t})
+	if nil == err {
+		t.Fatalf("StartNFSv3ServerOnListener() of an already served listener should have failed")
+	}
+
+	_, err = StartNFSv3ServerOnPacketConn(packetConn, false, &testNullNFSv3Struct{t: t})
+	if nil != err {
+		t.Fatalf("StartNFSv3ServerOnPacketConn() failed: %v", err)
+	}
+
+	for _, addr := range []net.Addr{listener.Addr(), packetConn.LocalAddr()} {
+		conn, err := net.Dial(addr.Network(), addr.String())
+		if nil != err {
+			t.Fatalf("net.Dial(%s) failed: %v", addr.Network(), err)
+		}
+
+		if onc.Success != testNullCall(t, conn, ("unix" == addr.Network())) {
+			t.Fatalf("NULL via %s not successful", addr.Network())
+		}
+
+		_ = conn.Close()
+
+		_, err = StopNFSv3ServerOn(addr, false)
+		if nil != err {
+			t.Fatalf("StopNFSv3ServerOn(%s) failed: %v", addr.Network(), err)
+		}
+
+		_, err = StopNFSv3ServerOn(addr, false)
+		if nil == err {
+			t.Fatalf("StopNFSv3ServerOn(%s) of a stopped server should have failed", addr.Network())
+		}
+	}
+
+	_, err = listener.Accept()
+	if nil == err {
+		t.Fatalf("listener should have been closed by StopNFSv3ServerOn()")
+	}
+}
+
+func TestConnCallsInFlightLimit(t *testing.T) {
+	port := testFreePort("tcp4", "127.0.0.1")
+	callsNum := rpcMaxConnCallsInFlight + 8
+	callbacks := &testBlockingNFSv3Struct{testNullNFSv3Struct: testNullNFSv3Struct{t: t}, entered: make(chan struct{}, callsNum), unblock: make(chan struct{})}
+
+	_, err := StartNFSv3Server("tcp4", "127.0.0.1", port, false, callbacks)
+	if nil != err {
+		t.Fatalf("StartNFSv3Server() failed: %v", err)
+	}
+	defer func() { _, _ = StopNFSv3Server("tcp4", "127.0.0.1", port, false) }()
+
+	conn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if nil != err {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	for xid := 1; xid <= callsNum; xid++ {
+		testSendGetAttr(t, conn, uint32(xid))
+	}
+
+	for i := 0; i < rpcMaxConnCallsInFlight; i++ {
+		select {
+		case <-callbacks.entered:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %v calls dispatched... expected %v", i, rpcMaxConnCallsInFlight)
+		}
+	}
+
+	select {
+	case <-callbacks.entered:
+		t.Fatalf("more than rpcMaxConnCallsInFlight (%v) calls via a single connection dispatched", rpcMaxConnCallsInFlight)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Other connections continue to be served
+
+	otherConn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if nil != err {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer otherConn.Close()
+
+	if onc.Success != testNullCall(t, otherConn, true) {
+		t.Fatalf("NULL via another connection not successful")
+	}
+
+	close(callbacks.unblock)
+
+	for i := 0; i < callsNum; i++ {
+		_, status := testRecvStatus(t, conn)
+		if OK != status {
+			t.Fatalf("GETATTR returned status %v", status)
+		}
+	}
+}
+
+func TestRPCLimiter(t *testing.T) {
+	limiter := newRPCLimiter(2, 100)
+
+	if !limiter.tryAcquire(60) || limiter.tryAcquire(60) || !limiter.tryAcquire(40) || limiter.tryAcquire(0) {
+		t.Fatalf("tryAcquire() did not enforce maxCalls & maxBytes")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	acquired := make(chan bool, 1)
+	go func() { acquired <- limiter.acquire(ctx, 10) }()
+
+	select {
+	case <-acquired:
+		t.Fatalf("acquire() beyond maxCalls did not wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release(60)
+
+	if !<-acquired {
+		t.Fatalf("acquire() failed once released")
+	}
+
+	go func() { acquired <- limiter.acquire(ctx, 10) }()
+	cancel()
+
+	if <-acquired {
+		t.Fatalf("acquire() succeeded once ctx was done")
+	}
+
+	limiter.release(40)
+	limiter.release(10)
+
+	if !limiter.tryAcquire(1000) {
+		t.Fatalf("tryAcquire() of a call larger than maxBytes should succeed when none are in flight")
+	}
+}
+
+func TestReadRPCRecord(t *testing.T) {
+	var (
+		err    error
+		header [4]byte
+		msg    []byte
+		stream bytes.Buffer
+	)
+
+	binary.BigEndian.PutUint32(header[:], 3)
+	stream.Write(header[:])
+	stream.WriteString("abc")
+	binary.BigEndian.PutUint32(header[:], 0)
+	stream.Write(header[:])
+	binary.BigEndian.PutUint32(header[:], rpcRecordLastFragment|2)
+	stream.Write(header[:])
+	stream.WriteString("de")
+	binary.BigEndian.PutUint32(header[:], rpcRecordLastFragment|1)
+	stream.Write(header[:])
+	stream.WriteString("f")
+
+	msg, err = readRPCRecord(&stream)
+	if (nil != err) || ("abcde" != string(msg)) {
+		t.Fatalf("readRPCRecord() of fragmented record returned %q, %v", msg, err)
+	}
+	msg, err = readRPCRecord(&stream)
+	if (nil != err) || ("f" != string(msg)) {
+		t.Fatalf("readRPCRecord() of single fragment record returned %q, %v", msg, err)
+	}
+	_, err = readRPCRecord(&stream)
+	if io.EOF != err {
+		t.Fatalf("readRPCRecord() at end of stream returned %v", err)
+	}
+
+	stream.Reset()
+	binary.BigEndian.PutUint32(header[:], 4)
+	stream.Write(header[:])
+	stream.WriteString("ab")
+	_, err = readRPCRecord(&stream)
+	if (nil == err) || (io.EOF == err) {
+		t.Fatalf("readRPCRecord() of truncated record returned %v", err)
+	}
+
+	stream.Reset()
+	binary.BigEndian.PutUint32(header[:], uint32(rpcMaxRecordSize/2))
+	stream.Write(header[:])
+	stream.Write(make([]byte, rpcMaxRecordSize/2))
+	binary.BigEndian.PutUint32(header[:], rpcRecordLastFragment|uint32(rpcMaxRecordSize/2+1))
+	stream.Write(header[:])
+	_, err = readRPCRecord(&stream)
+	if nil == err {
+		t.Fatalf("readRPCRecord() of oversize record returned %v", err)
+	}
+}
+
+func TestHandleCallRejects(t *testing.T) {
+	var (
+		packer   packerStruct
+		replies  [][]byte
+		unpacker unpackerStruct
+	)
+
+	server := &rpcServerStruct{prog: NFSProgram, vers: NFSVersion, program: &echoProgramStruct{}, errorLog: func(err error) {}}
+
+	call := func(rpcVers uint32, prog uint32, vers uint32) (reply []byte) {
+		packer = packerStruct{}
+		packer.packUint32(1) // xid
+		packer.packUint32(rpcMsgTypeCall)
+		packer.packUint32(rpcVers)
+		packer.packUint32(prog)
+		packer.packUint32(vers)
+		packer.packUint32(ProcNULL)
+		packer.packUint32(AuthNone)
+		packer.packOpaque([]byte{})
+		packer.packUint32(AuthNone)
+		packer.packOpaque([]byte{})
+
+		replies = nil
+		server.handleCall(context.Background(), packer.buf, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 800}, func(reply []byte) (err error) {
+			replies = append(replies, reply)
+			return
+		}, false)
+		if 1 != len(replies) {
+			t.Fatalf("handleCall() sent %v replies", len(replies))
+		}
+
+		unpacker = unpackerStruct{buf: replies[0]}
+		if (1 != unpacker.unpackUint32()) || (rpcMsgTypeReply != unpacker.unpackUint32()) {
+			t.Fatalf("reply header mismatch")
+		}
+		reply = replies[0][unpacker.bytesConsumed:]
+		return
+	}
+
+	unpacker = unpackerStruct{buf: call(rpcVers+1, NFSProgram, NFSVersion)}
+	if (rpcReplyStatDenied != unpacker.unpackUint32()) || (rpcRejectStatRPCMismatch != unpacker.unpackUint32()) || (rpcVers != unpacker.unpackUint32()) || (rpcVers != unpacker.unpackUint32()) || (nil != unpacker.err) {
+		t.Fatalf("RPC version mismatch reply malformed")
+	}
+
+	unpacker = unpackerStruct{buf: call(rpcVers, MountProgram, NFSVersion)}
+	if (rpcReplyStatAccepted != unpacker.unpackUint32()) || (AuthNone != unpacker.unpackUint32()) || (0 != len(unpacker.unpackOpaque())) || (onc.ProgUnavail != unpacker.unpackUint32()) || (nil != unpacker.err) {
+		t.Fatalf("PROG_UNAVAIL reply malformed")
+	}
+
+	unpacker = unpackerStruct{buf: call(rpcVers, NFSProgram, NFSVersion+1)}
+	if (rpcReplyStatAccepted != unpacker.unpackUint32()) || (AuthNone != unpacker.unpackUint32()) || (0 != len(unpacker.unpackOpaque())) || (onc.ProgMismatch != unpacker.unpackUint32()) || (NFSVersion != unpacker.unpackUint32()) || (NFSVersion != unpacker.unpackUint32()) || (nil != unpacker.err) {
+		t.Fatalf("PROG_MISMATCH reply malformed")
+	}
+}
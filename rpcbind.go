@@ -0,0 +1,311 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+// Servers launched via Start{MountV3|NFSv3}Server are published via rpcbind protocol versions 4 & 3 (RFC 1833)
+// which, unlike the portmap (version 2) protocol used by StartIPv4{TCP|UDP}{MountV3|NFSv3}Server, convey IPv6
+// addresses (as netid & universal address pairs)
+
+const (
+	rpcbProg      = uint32(100000)
+	rpcbVers3     = uint32(3)
+	rpcbVers4     = uint32(4)
+	rpcbProcSet   = uint32(1)
+	rpcbProcUnset = uint32(2)
+
+	rpcbTimeout = 5 * time.Second
+)
+
+// rpcbStruct is struct rpcb (the arguments of RPCBPROC_SET & RPCBPROC_UNSET)
+type rpcbStruct struct {
+	Prog  uint32 `XDR_Name:"Unsigned Integer"`
+	Vers  uint32 `XDR_Name:"Unsigned Integer"`
+	NetID string `XDR_Name:"String"`
+	Addr  string `XDR_Name:"String"`
+	Owner string `XDR_Name:"String"`
+}
+
+// rpcbEndpointStruct identifies a means of reaching the local rpcbind service
+type rpcbEndpointStruct struct {
+	network string
+	address string
+}
+
+// rpcbRegistrationStruct is a netid & universal address pair to be registered with rpcbind
+type rpcbRegistrationStruct struct {
+	netID string
+	addr  string
+}
+
+var (
+	globalRPCBEndpoints = []rpcbEndpointStruct{ // tried in order until one is reachable
+		{network: "unix", address: "/run/rpcbind.sock"},
+		{network: "unix", address: "/var/run/rpcbind.sock"},
+		{network: "tcp", address: "127.0.0.1:111"},
+		{network: "tcp", address: "[::1]:111"},
+	}
+	globalRPCBXID uint32 // incremented for each call issued to rpcbind
+)
+
+// universalAddr renders ip:port as an RFC 5665 universal address (e.g. "192.0.2.1.8.1" or "2001:db8::1.8.1")
+func universalAddr(ip net.IP, port uint16) (uaddr string) {
+	uaddr = fmt.Sprintf("%s.%d.%d", ip.String(), port>>8, port&0xFF)
+	return
+}
+
+// rpcbRegistrations returns the netid & universal address pairs under which a server listening on
+// network (e.g. "tcp" for dual-stack or "udp6" for IPv6-only) & bindAddr ("" for all addresses) is published.
+// A server listening on a Unix-domain stream socket is published under the "local" netid (with bindAddr being
+// the path of the socket).
+func rpcbRegistrations(network string, bindAddr string, port uint16) (registrations []rpcbRegistrationStruct, err error) {
+	var (
+		bindIP    net.IP
+		transport string
+	)
+
+	if "unix" == network {
+		registrations = []rpcbRegistrationStruct{{netID: "local", addr: bindAddr}}
+		return
+	}
+
+	transport, _, err = parseRPCNetwork(network)
+	if nil != err {
+		return
+	}
+
+	if "" != bindAddr {
+		bindIP = net.ParseIP(bindAddr)
+		if nil == bindIP {
+			err = fmt.Errorf("bindAddr (\"%s\") must be an IP address", bindAddr)
+			return
+		}
+	}
+
+	registrations = make([]rpcbRegistrationStruct, 0, 2)
+
+	switch {
+	case nil != bindIP && nil != bindIP.To4():
+		registrations = append(registrations, rpcbRegistrationStruct{netID: transport, addr: universalAddr(bindIP, port)})
+	case nil != bindIP:
+		registrations = append(registrations, rpcbRegistrationStruct{netID: transport + "6", addr: universalAddr(bindIP, port)})
+	default:
+		if network != transport+"6" {
+			registrations = append(registrations, rpcbRegistrationStruct{netID: transport, addr: universalAddr(net.IPv4zero, port)})
+		}
+		if network != transport+"4" {
+			registrations = append(registrations, rpcbRegistrationStruct{netID: transport + "6", addr: universalAddr(net.IPv6zero, port)})
+		}
+	}
+
+	return
+}
+
+// rpcbAddrArgs returns the network, bindAddr, & port (as supplied to rpcbSet & rpcbUnset) for a server
+// listening on addr (i.e. the address of a net.Listener or net.PacketConn supplied by the caller)
+func rpcbAddrArgs(addr net.Addr) (network string, bindAddr string, port uint16, err error) {
+	var (
+		ip net.IP
+	)
+
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		network = "tcp"
+		ip = addr.IP
+		port = uint16(addr.Port)
+	case *net.UDPAddr:
+		network = "udp"
+		ip = addr.IP
+		port = uint16(addr.Port)
+	case *net.UnixAddr:
+		if "unix" != addr.Net {
+			err = fmt.Errorf("rpcbind does not support %s addresses", addr.Net)
+			return
+		}
+		network = "unix"
+		bindAddr = addr.Name
+		return
+	default:
+		err = fmt.Errorf("rpcbind does not support %s addresses", addr.Network())
+		return
+	}
+
+	switch {
+	case (nil == ip) || (ip.IsUnspecified() && (nil == ip.To4())):
+		// dual-stack (e.g. "[::]:2049")
+	case ip.IsUnspecified():
+		network += "4"
+	default:
+		bindAddr = ip.String()
+	}
+
+	return
+}
+
+// rpcbSet publishes prog:vers for each netid & universal address under which network:bindAddr:port is reachable
+func rpcbSet(prog uint32, vers uint32, network string, bindAddr string, port uint16) (err error) {
+	var (
+		registration  rpcbRegistrationStruct
+		registrations []rpcbRegistrationStruct
+		success       bool
+	)
+
+	registrations, err = rpcbRegistrations(network, bindAddr, port)
+	if nil != err {
+		return
+	}
+
+	for _, registration = range registrations {
+		_, _ = rpcbCall(rpcbProcUnset, &rpcbStruct{Prog: prog, Vers: vers, NetID: registration.netID, Owner: rpcbOwner()}) // discard any stale registration
+
+		success, err = rpcbCall(rpcbProcSet, &rpcbStruct{Prog: prog, Vers: vers, NetID: registration.netID, Addr: registration.addr, Owner: rpcbOwner()})
+		if nil != err {
+			return
+		}
+		if !success {
+			err = fmt.Errorf("rpcbind declined to register %v:%v on %s (%s)", prog, vers, registration.netID, registration.addr)
+			return
+		}
+	}
+
+	return
+}
+
+// rpcbUnset removes each registration made by a prior rpcbSet
+func rpcbUnset(prog uint32, vers uint32, network string, bindAddr string) (err error) {
+	var (
+		registration  rpcbRegistrationStruct
+		registrations []rpcbRegistrationStruct
+		success       bool
+	)
+
+	registrations, err = rpcbRegistrations(network, bindAddr, 0)
+	if nil != err {
+		return
+	}
+
+	for _, registration = range registrations {
+		success, err = rpcbCall(rpcbProcUnset, &rpcbStruct{Prog: prog, Vers: vers, NetID: registration.netID, Owner: rpcbOwner()})
+		if nil != err {
+			return
+		}
+		if !success {
+			err = fmt.Errorf("rpcbind declined to unregister %v:%v on %s", prog, vers, registration.netID)
+			return
+		}
+	}
+
+	return
+}
+
+// rpcbOwner returns the r_owner identifying this process (as does libtirpc's rpcb_set())
+func rpcbOwner() (owner string) {
+	owner = strconv.Itoa(os.Geteuid())
+	return
+}
+
+// rpcbCall issues proc (via rpcbind version 4, falling back to version 3) to the first reachable endpoint
+func rpcbCall(proc uint32, rpcb *rpcbStruct) (success bool, err error) {
+	var (
+		acceptStat uint32
+		body       []byte
+		conn       net.Conn
+		endpoint   rpcbEndpointStruct
+		parms      []byte
+		result     BooleanOnlyStruct
+		vers       uint32
+	)
+
+	parms, err = xdr.Pack(rpcb)
+	if nil != err {
+		return
+	}
+
+	for _, endpoint = range globalRPCBEndpoints {
+		conn, err = net.DialTimeout(endpoint.network, endpoint.address, rpcbTimeout)
+		if nil == err {
+			break
+		}
+	}
+	if nil != err {
+		err = fmt.Errorf("rpcbind not reachable: %v", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	for _, vers = range []uint32{rpcbVers4, rpcbVers3} {
+		acceptStat, body, err = rpcbRoundTrip(conn, vers, proc, parms)
+		if nil != err {
+			return
+		}
+		if onc.ProgMismatch != acceptStat {
+			break
+		}
+	}
+
+	if onc.Success != acceptStat {
+		err = fmt.Errorf("rpcbind returned accept_stat %v", acceptStat)
+		return
+	}
+
+	_, err = xdr.Unpack(body, &result)
+	if nil != err {
+		return
+	}
+
+	success = result.Bool
+
+	return
+}
+
+func rpcbRoundTrip(conn net.Conn, vers uint32, proc uint32, parms []byte) (acceptStat uint32, body []byte, err error) {
+	var (
+		msg      []byte
+		record   []byte
+		replyXID uint32
+		xid      = atomic.AddUint32(&globalRPCBXID, 1)
+	)
+
+	msg, err = encodeRPCCall(xid, rpcbProg, vers, proc, parms)
+	if nil != err {
+		return
+	}
+
+	record = make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(record, rpcRecordLastFragment|uint32(len(msg)))
+	record = append(record, msg...)
+
+	err = conn.SetDeadline(time.Now().Add(rpcbTimeout))
+	if nil != err {
+		return
+	}
+
+	_, err = conn.Write(record)
+	if nil != err {
+		return
+	}
+
+	msg, err = readRPCRecord(conn)
+	if nil != err {
+		return
+	}
+
+	replyXID, acceptStat, body, err = decodeRPCReply(msg)
+	if (nil == err) && (xid != replyXID) {
+		err = fmt.Errorf("rpcbind reply xid (0x%08X) does not match call xid (0x%08X)", replyXID, xid)
+	}
+
+	return
+}
@@ -0,0 +1,152 @@
+package nfsd
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/swiftstack/onc"
+	"github.com/swiftstack/xdr"
+)
+
+func TestRPCBRegistrations(t *testing.T) {
+	for _, testCase := range []struct {
+		network  string
+		bindAddr string
+		expected []rpcbRegistrationStruct
+	}{
+		{"tcp", "", []rpcbRegistrationStruct{{"tcp", "0.0.0.0.8.1"}, {"tcp6", "::.8.1"}}},
+		{"udp4", "", []rpcbRegistrationStruct{{"udp", "0.0.0.0.8.1"}}},
+		{"tcp6", "", []rpcbRegistrationStruct{{"tcp6", "::.8.1"}}},
+		{"tcp", "192.0.2.7", []rpcbRegistrationStruct{{"tcp", "192.0.2.7.8.1"}}},
+		{"udp", "2001:db8::1", []rpcbRegistrationStruct{{"udp6", "2001:db8::1.8.1"}}},
+	} {
+		registrations, err := rpcbRegistrations(testCase.network, testCase.bindAddr, 2049)
+		if nil != err {
+			t.Fatalf("rpcbRegistrations(%s, %s) failed: %v", testCase.network, testCase.bindAddr, err)
+		}
+		if !reflect.DeepEqual(testCase.expected, registrations) {
+			t.Fatalf("rpcbRegistrations(%s, %s) returned %v... expected %v", testCase.network, testCase.bindAddr, registrations, testCase.expected)
+		}
+	}
+
+	_, err := rpcbRegistrations("sctp", "", 2049)
+	if nil == err {
+		t.Fatalf("rpcbRegistrations(sctp) should have failed")
+	}
+	_, err = rpcbRegistrations("tcp", "nfs.example.com", 2049)
+	if nil == err {
+		t.Fatalf("rpcbRegistrations() with a host name bindAddr should have failed")
+	}
+}
+
+func TestRPCBAddrArgs(t *testing.T) {
+	for _, testCase := range []struct {
+		addr     net.Addr
+		expected []rpcbRegistrationStruct
+	}{
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 2049}, []rpcbRegistrationStruct{{"tcp", "0.0.0.0.8.1"}, {"tcp6", "::.8.1"}}},
+		{&net.UDPAddr{IP: net.IPv4zero, Port: 2049}, []rpcbRegistrationStruct{{"udp", "0.0.0.0.8.1"}}},
+		{&net.TCPAddr{IP: net.ParseIP("192.0.2.7"), Port: 2049}, []rpcbRegistrationStruct{{"tcp", "192.0.2.7.8.1"}}},
+		{&net.UnixAddr{Name: "/run/nfsd.sock", Net: "unix"}, []rpcbRegistrationStruct{{"local", "/run/nfsd.sock"}}},
+	} {
+		network, bindAddr, port, err := rpcbAddrArgs(testCase.addr)
+		if nil != err {
+			t.Fatalf("rpcbAddrArgs(%v) failed: %v", testCase.addr, err)
+		}
+		registrations, err := rpcbRegistrations(network, bindAddr, port)
+		if nil != err {
+			t.Fatalf("rpcbRegistrations(%s, %s) failed: %v", network, bindAddr, err)
+		}
+		if !reflect.DeepEqual(testCase.expected, registrations) {
+			t.Fatalf("registrations for %v were %v... expected %v", testCase.addr, registrations, testCase.expected)
+		}
+	}
+
+	_, _, _, err := rpcbAddrArgs(&net.UnixAddr{Name: "/run/nfsd.sock", Net: "unixgram"})
+	if nil == err {
+		t.Fatalf("rpcbAddrArgs(unixgram) should have failed")
+	}
+}
+
+// testRPCBindServe answers calls on listener as would an rpcbind supporting only version 3
+func testRPCBindServe(t *testing.T, listener net.Listener, calls chan<- *rpcbStruct) {
+	for {
+		conn, err := listener.Accept()
+		if nil != err {
+			return
+		}
+		go testRPCBindServeConn(t, conn, calls)
+	}
+}
+
+func testRPCBindServeConn(t *testing.T, conn net.Conn, calls chan<- *rpcbStruct) {
+	defer conn.Close()
+
+	for {
+		msg, err := readRPCRecord(conn)
+		if nil != err {
+			return
+		}
+
+		call, ok := decodeRPCCall(msg)
+		if !ok || (rpcbProg != call.prog) {
+			t.Errorf("rpcbind received unexpected call %v", call)
+			return
+		}
+
+		var reply []byte
+		if rpcbVers3 != call.vers {
+			reply, _ = encodeRPCProgMismatchReply(call.xid, rpcbVers3, rpcbVers3)
+		} else {
+			rpcb := &rpcbStruct{}
+			_, err = xdr.Unpack(call.parms, rpcb)
+			if nil != err {
+				t.Errorf("rpcbind received malformed rpcb: %v", err)
+				return
+			}
+			calls <- rpcb
+			result, _ := xdr.Pack(&BooleanOnlyStruct{Bool: true})
+			reply, _ = encodeRPCAcceptedReply(call.xid, AuthNone, []byte{}, onc.Success, result)
+		}
+
+		record := make([]byte, 4)
+		binary.BigEndian.PutUint32(record, rpcRecordLastFragment|uint32(len(reply)))
+		_, err = conn.Write(append(record, reply...))
+		if nil != err {
+			return
+		}
+	}
+}
+
+func TestRPCBSet(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer listener.Close()
+
+	savedRPCBEndpoints := globalRPCBEndpoints
+	globalRPCBEndpoints = []rpcbEndpointStruct{{network: "unix", address: "/nonexistent/rpcbind.sock"}, {network: "tcp", address: listener.Addr().String()}}
+	defer func() {
+		globalRPCBEndpoints = savedRPCBEndpoints
+	}()
+
+	calls := make(chan *rpcbStruct, 8)
+	go testRPCBindServe(t, listener, calls)
+
+	err = rpcbSet(onc.ProgNumNFS, 3, "tcp6", "", 2049)
+	if nil != err {
+		t.Fatalf("rpcbSet() failed: %v", err)
+	}
+
+	unset := <-calls
+	if ("tcp6" != unset.NetID) || (onc.ProgNumNFS != unset.Prog) || (3 != unset.Vers) {
+		t.Fatalf("rpcbSet() first issued %+v... expected RPCBPROC_UNSET of tcp6", unset)
+	}
+	set := <-calls
+	if ("tcp6" != set.NetID) || ("::.8.1" != set.Addr) || (rpcbOwner() != set.Owner) {
+		t.Fatalf("rpcbSet() issued %+v", set)
+	}
+}
@@ -0,0 +1,425 @@
+package nfsd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/swiftstack/onc"
+)
+
+// serverStateType tracks the lifecycle of a ServerStruct
+type serverStateType int
+
+const (
+	serverStateNew serverStateType = iota
+	serverStateServing
+	serverStateClosed
+)
+
+// ServerStruct is a Mount V3 & NFSv3 server instance (see NewServer). Unlike servers launched via the
+// Start{IPv4{TCP|UDP}}{MountV3|NFSv3}Server functions, each ServerStruct holds its own configuration (rather
+// than that established via the Set*/Enable* functions) and owns its listeners.
+type ServerStruct struct {
+	sync.Mutex                           // protects state & rpcServers
+	config       ServerConfigStruct      // as supplied to NewServer (with defaults applied)
+	nfsCallbacks NFSv3ContextInterface   // either config.NFSContextCallbacks or config.NFSCallbacks (adapted as necessary)
+	mountTable   *mountTableStruct       // if nil, MNT requests are not tracked
+	exports      *exportTableStruct      // if nil, all requests are admitted
+	gss          *gssStruct              // if nil, RPCSEC_GSS credentials are rejected
+	drc          *drcStruct              // if nil, retransmitted calls are executed anew
+	state        serverStateType         //
+	rpcServers   []*rpcServerStruct      // a Mount V3 & an NFSv3 server (in that order) for each of config.Listeners
+	closed       chan struct{}           // closed once Shutdown() has completed
+	serveErr     error                   // returned by Serve() following Shutdown()
+	published    []*ListenerConfigStruct // listeners successfully registered with rpcbind
+}
+
+func newServer(config *ServerConfigStruct) (server *ServerStruct, err error) {
+	var (
+		listenerConfig ListenerConfigStruct
+	)
+
+	if (nil == config.MountCallbacks) || ((nil == config.NFSCallbacks) && (nil == config.NFSContextCallbacks)) {
+		err = fmt.Errorf("config must specify both MountCallbacks & either NFSCallbacks or NFSContextCallbacks")
+		return
+	}
+	if 0 == len(config.Listeners) {
+		err = fmt.Errorf("config must specify at least one listener")
+		return
+	}
+
+	for _, listenerConfig = range config.Listeners {
+		if ((nil != listenerConfig.MountListener) && (nil != listenerConfig.MountPacketConn)) ||
+			((nil != listenerConfig.Listener) && (nil != listenerConfig.PacketConn)) {
+			err = fmt.Errorf("listener config must not supply both a listener & a packet connection for the same program")
+			return
+		}
+		if (nil != listenerConfig.suppliedAddr(onc.ProgNumMount)) && (nil != listenerConfig.suppliedAddr(onc.ProgNumNFS)) {
+			continue // Network is unused
+		}
+		_, _, err = parseRPCNetwork(listenerConfig.Network)
+		if nil != err {
+			return
+		}
+	}
+
+	server = &ServerStruct{
+		config: *config,
+		state:  serverStateNew,
+		closed: make(chan struct{}),
+	}
+
+	server.config.Listeners = append([]ListenerConfigStruct{}, config.Listeners...)
+	server.config.IdentityMapping = copyIdentityMapping(config.IdentityMapping)
+
+	if nil == config.NFSContextCallbacks {
+		server.nfsCallbacks = newNFSv3ContextAdapter(config.NFSCallbacks)
+	} else {
+		server.nfsCallbacks = newNFSv3BackendAdapter(config.NFSContextCallbacks)
+	}
+
+	if !server.config.AnonUIDSet && (0 == server.config.AnonUID) {
+		server.config.AnonUID = DefaultAnonUID
+	}
+	if !server.config.AnonGIDSet && (0 == server.config.AnonGID) {
+		server.config.AnonGID = DefaultAnonGID
+	}
+
+	if nil != config.ExportTable {
+		server.exports, err = newExportTable(config.ExportTable)
+		if nil != err {
+			server = nil
+			return
+		}
+	}
+
+	if config.MountTable {
+		server.mountTable, err = newMountTable(config.RmtabPath, config.MountCallbacks.ErrorLog)
+		if nil != err {
+			server = nil
+			return
+		}
+	}
+
+	if nil != config.GSSMechanism {
+		server.gss = newGSS(config.GSSMechanism, config.GSSPrincipalMapper)
+	}
+
+	if config.DRC {
+		server.drc = newDRC(config.DRCMaxEntries, config.DRCMaxAge)
+	}
+
+	return
+}
+
+// suppliedAddr returns the address of the listener or packet connection supplied to serve prog (or nil should prog
+// be served via Network, BindAddr, & the corresponding port)
+func (listenerConfig *ListenerConfigStruct) suppliedAddr(prog uint32) (addr net.Addr) {
+	switch {
+	case (onc.ProgNumMount == prog) && (nil != listenerConfig.MountListener):
+		addr = listenerConfig.MountListener.Addr()
+	case (onc.ProgNumMount == prog) && (nil != listenerConfig.MountPacketConn):
+		addr = listenerConfig.MountPacketConn.LocalAddr()
+	case (onc.ProgNumNFS == prog) && (nil != listenerConfig.Listener):
+		addr = listenerConfig.Listener.Addr()
+	case (onc.ProgNumNFS == prog) && (nil != listenerConfig.PacketConn):
+		addr = listenerConfig.PacketConn.LocalAddr()
+	default:
+		addr = nil
+	}
+	return
+}
+
+// rpcbArgs returns the network, bindAddr, & port (as supplied to rpcbSet & rpcbUnset) of rpcServer serving prog
+// for listenerConfig (rpcServer being nil when unregistering)
+func (listenerConfig *ListenerConfigStruct) rpcbArgs(prog uint32, rpcServer *rpcServerStruct) (network string, bindAddr string, port uint16, err error) {
+	var (
+		addr net.Addr
+	)
+
+	addr = listenerConfig.suppliedAddr(prog)
+	if nil != addr {
+		network, bindAddr, port, err = rpcbAddrArgs(addr)
+		return
+	}
+
+	network = listenerConfig.Network
+	bindAddr = listenerConfig.BindAddr
+
+	if nil != rpcServer {
+		port = rpcServer.port
+	}
+
+	return
+}
+
+// newRPCServers returns (but does not start) the Mount V3 & NFSv3 servers for listenerConfig
+func (server *ServerStruct) newRPCServers(listenerConfig *ListenerConfigStruct) (mountServer *rpcServerStruct, nfsServer *rpcServerStruct) {
+	var (
+		mountPort uint16
+		mountProt uint32
+		nfsPort   uint16
+		nfsProt   uint32
+	)
+
+	_, mountProt, _ = parseRPCNetwork(listenerConfig.Network)
+	nfsProt = mountProt
+
+	mountPort = listenerConfig.MountPort
+	nfsPort = listenerConfig.NFSPort
+
+	switch {
+	case nil != listenerConfig.MountListener:
+		mountProt = onc.IPProtoTCP
+		mountPort = addrPort(listenerConfig.MountListener.Addr())
+	case nil != listenerConfig.MountPacketConn:
+		mountProt = onc.IPProtoUDP
+		mountPort = addrPort(listenerConfig.MountPacketConn.LocalAddr())
+	}
+
+	switch {
+	case nil != listenerConfig.Listener:
+		nfsProt = onc.IPProtoTCP
+		nfsPort = addrPort(listenerConfig.Listener.Addr())
+	case nil != listenerConfig.PacketConn:
+		nfsProt = onc.IPProtoUDP
+		nfsPort = addrPort(listenerConfig.PacketConn.LocalAddr())
+	}
+
+	mountServer = &rpcServerStruct{
+		network:    listenerConfig.Network,
+		bindAddr:   listenerConfig.BindAddr,
+		port:       mountPort,
+		prog:       onc.ProgNumMount,
+		vers:       3,
+		listener:   listenerConfig.MountListener,
+		packetConn: listenerConfig.MountPacketConn,
+		program: &mountRequestHandlerStruct{
+			callbacks:   server.config.MountCallbacks,
+			prot:        mountProt,
+			port:        mountPort,
+			mountTable:  server.mountTable,
+			anonUID:     server.config.AnonUID,
+			anonGID:     server.config.AnonGID,
+			gss:         server.gss,
+			exportTable: server.exports,
+		},
+		errorLog: server.config.MountCallbacks.ErrorLog,
+		anonUID:  server.config.AnonUID,
+		anonGID:  server.config.AnonGID,
+		gss:      server.gss,
+	}
+
+	nfsServer = &rpcServerStruct{
+		network:    listenerConfig.Network,
+		bindAddr:   listenerConfig.BindAddr,
+		port:       nfsPort,
+		prog:       onc.ProgNumNFS,
+		vers:       3,
+		listener:   listenerConfig.Listener,
+		packetConn: listenerConfig.PacketConn,
+		program: &nfsRequestHandlerStruct{
+			callbacks:       server.nfsCallbacks,
+			prot:            nfsProt,
+			port:            nfsPort,
+			anonUID:         server.config.AnonUID,
+			anonGID:         server.config.AnonGID,
+			identityMapping: server.config.IdentityMapping,
+			exportTable:     server.exports,
+			readOnly:        server.config.ReadOnly,
+			drc:             server.drc,
+		},
+		errorLog: server.nfsCallbacks.ErrorLog,
+		anonUID:  server.config.AnonUID,
+		anonGID:  server.config.AnonGID,
+		gss:      server.gss,
+	}
+
+	return
+}
+
+func (server *ServerStruct) serve(ctx context.Context) (err error) {
+	var (
+		listenerConfig *ListenerConfigStruct
+		listenerIndex  int
+		mountServer    *rpcServerStruct
+		nfsServer      *rpcServerStruct
+		rpcServer      *rpcServerStruct
+	)
+
+	server.Lock()
+
+	switch server.state {
+	case serverStateServing:
+		server.Unlock()
+		err = fmt.Errorf("server already serving")
+		return
+	case serverStateClosed:
+		server.Unlock()
+		err = ErrServerClosed
+		return
+	}
+
+	for listenerIndex = range server.config.Listeners {
+		listenerConfig = &server.config.Listeners[listenerIndex]
+		mountServer, nfsServer = server.newRPCServers(listenerConfig)
+		for _, rpcServer = range []*rpcServerStruct{mountServer, nfsServer} {
+			err = rpcServer.start()
+			if nil != err {
+				for _, rpcServer = range server.rpcServers {
+					_ = rpcServer.stop()
+				}
+				server.rpcServers = nil
+				server.Unlock()
+				return
+			}
+			server.rpcServers = append(server.rpcServers, rpcServer)
+		}
+	}
+
+	server.state = serverStateServing
+
+	if server.config.Publish {
+		server.publish()
+	}
+
+	server.Unlock()
+
+	select {
+	case <-server.closed:
+		err = server.serveErr
+	case <-ctx.Done():
+		_ = server.shutdown(ctx) // as ctx is already done, calls in flight are not answered (though are waited upon)
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// publish registers each listener with rpcbind (logging any failures). The caller must hold server's lock.
+func (server *ServerStruct) publish() {
+	var (
+		bindAddr       string
+		err            error
+		listenerConfig *ListenerConfigStruct
+		listenerIndex  int
+		network        string
+		port           uint16
+	)
+
+	for listenerIndex = range server.config.Listeners {
+		listenerConfig = &server.config.Listeners[listenerIndex]
+
+		network, bindAddr, port, err = listenerConfig.rpcbArgs(onc.ProgNumMount, server.rpcServers[2*listenerIndex])
+		if nil == err {
+			err = rpcbSet(onc.ProgNumMount, 3, network, bindAddr, port)
+		}
+		if nil != err {
+			server.config.MountCallbacks.ErrorLog(fmt.Errorf("unable to publish Mount V3 server on %s: %v", network, err))
+			continue
+		}
+
+		network, bindAddr, port, err = listenerConfig.rpcbArgs(onc.ProgNumNFS, server.rpcServers[2*listenerIndex+1])
+		if nil == err {
+			err = rpcbSet(onc.ProgNumNFS, 3, network, bindAddr, port)
+		}
+		if nil != err {
+			server.nfsCallbacks.ErrorLog(fmt.Errorf("unable to publish NFSv3 server on %s: %v", network, err))
+			network, bindAddr, _, _ = listenerConfig.rpcbArgs(onc.ProgNumMount, nil)
+			_ = rpcbUnset(onc.ProgNumMount, 3, network, bindAddr)
+			continue
+		}
+
+		server.published = append(server.published, listenerConfig)
+	}
+}
+
+// unpublish reverses publish(). The caller must hold server's lock.
+func (server *ServerStruct) unpublish() {
+	var (
+		bindAddr       string
+		err            error
+		listenerConfig *ListenerConfigStruct
+		network        string
+	)
+
+	for _, listenerConfig = range server.published {
+		network, bindAddr, _, _ = listenerConfig.rpcbArgs(onc.ProgNumMount, nil)
+		err = rpcbUnset(onc.ProgNumMount, 3, network, bindAddr)
+		if nil != err {
+			server.config.MountCallbacks.ErrorLog(fmt.Errorf("unable to unpublish Mount V3 server on %s: %v", network, err))
+		}
+		network, bindAddr, _, _ = listenerConfig.rpcbArgs(onc.ProgNumNFS, nil)
+		err = rpcbUnset(onc.ProgNumNFS, 3, network, bindAddr)
+		if nil != err {
+			server.nfsCallbacks.ErrorLog(fmt.Errorf("unable to unpublish NFSv3 server on %s: %v", network, err))
+		}
+	}
+
+	server.published = nil
+}
+
+// shutdown gracefully halts each of the server's rpcServers concurrently (see rpcServerStruct.shutdown()). Only
+// NFSv3 calls are ever deferred (as Mount V3 lacks an equivalent of NFS3ErrJUKEBOX).
+func (server *ServerStruct) shutdown(ctx context.Context) (err error) {
+	var (
+		errLock   sync.Mutex
+		rpcServer *rpcServerStruct
+		wg        sync.WaitGroup
+	)
+
+	server.Lock()
+
+	if serverStateClosed == server.state {
+		server.Unlock()
+		<-server.closed
+		return
+	}
+
+	if serverStateNew == server.state {
+		server.state = serverStateClosed
+		server.serveErr = ErrServerClosed
+		close(server.closed)
+		server.Unlock()
+		return
+	}
+
+	server.state = serverStateClosed
+
+	server.unpublish()
+
+	for _, rpcServer = range server.rpcServers {
+		wg.Add(1)
+		go func(rpcServer *rpcServerStruct) {
+			var (
+				rpcServerErr error
+			)
+
+			defer wg.Done()
+
+			rpcServerErr = rpcServer.shutdown(ctx, server.config.ShutdownJukebox && (onc.ProgNumNFS == rpcServer.prog))
+
+			if nil != rpcServerErr {
+				errLock.Lock()
+				if nil == err {
+					err = rpcServerErr
+				}
+				errLock.Unlock()
+			}
+		}(rpcServer)
+	}
+
+	server.Unlock()
+
+	wg.Wait()
+
+	server.Lock()
+	server.rpcServers = nil
+	server.serveErr = ErrServerClosed
+	close(server.closed)
+	server.Unlock()
+
+	return
+}
@@ -0,0 +1,242 @@
+package nfsd
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/swiftstack/onc"
+)
+
+// testNullMountV3Struct panics (via the nil embedded interface) if any callback other than ErrorLog is invoked
+type testNullMountV3Struct struct {
+	MountV3Interface
+	t *testing.T
+}
+
+func (testNullMountV3 *testNullMountV3Struct) ErrorLog(err error) {
+	testNullMountV3.t.Logf("ErrorLog(%v)", err)
+}
+
+// testServerNFSPorts waits for server to be serving returning the (system chosen) NFSv3 port of each listener
+func testServerNFSPorts(t *testing.T, server *ServerStruct) (nfsPorts []uint16) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		server.Lock()
+		if serverStateServing == server.state {
+			for rpcServerIndex := 1; rpcServerIndex < len(server.rpcServers); rpcServerIndex += 2 {
+				nfsPorts = append(nfsPorts, server.rpcServers[rpcServerIndex].port)
+			}
+			server.Unlock()
+			return
+		}
+		server.Unlock()
+	}
+
+	t.Fatalf("server not serving")
+	return
+}
+
+func TestServer(t *testing.T) {
+	config := &ServerConfigStruct{
+		Listeners: []ListenerConfigStruct{
+			{Network: "tcp4", BindAddr: "127.0.0.1"},
+			{Network: "udp4", BindAddr: "127.0.0.1"},
+		},
+		MountCallbacks: &testNullMountV3Struct{t: t},
+		NFSCallbacks:   &testNullNFSv3Struct{t: t},
+	}
+
+	_, err := NewServer(&ServerConfigStruct{Listeners: config.Listeners})
+	if nil == err {
+		t.Fatalf("NewServer() lacking callbacks should have failed")
+	}
+
+	server, err := NewServer(config)
+	if nil != err {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+
+	serveErrChan := make(chan error, 1)
+	go func() {
+		serveErrChan <- server.Serve(context.Background())
+	}()
+
+	nfsPorts := testServerNFSPorts(t, server)
+
+	for listenerIndex, network := range []string{"tcp4", "udp4"} {
+		conn, err := net.Dial(network, net.JoinHostPort("127.0.0.1", strconv.Itoa(int(nfsPorts[listenerIndex]))))
+		if nil != err {
+			t.Fatalf("net.Dial(%s) failed: %v", network, err)
+		}
+
+		if onc.Success != testNullCall(t, conn, ("tcp4" == network)) {
+			t.Fatalf("NULL via %s not successful", network)
+		}
+
+		_ = conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = server.Shutdown(ctx)
+	if nil != err {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	err = <-serveErrChan
+	if ErrServerClosed != err {
+		t.Fatalf("Serve() returned %v... expected ErrServerClosed", err)
+	}
+
+	_, err = net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(nfsPorts[0]))))
+	if nil == err {
+		t.Fatalf("net.Dial() following Shutdown() should have failed")
+	}
+
+	err = server.Serve(context.Background())
+	if ErrServerClosed != err {
+		t.Fatalf("Serve() following Shutdown() returned %v... expected ErrServerClosed", err)
+	}
+}
+
+func TestServerContext(t *testing.T) {
+	server, err := NewServer(&ServerConfigStruct{
+		Listeners:      []ListenerConfigStruct{{Network: "tcp4", BindAddr: "127.0.0.1"}},
+		MountCallbacks: &testNullMountV3Struct{t: t},
+		NFSCallbacks:   &testNullNFSv3Struct{t: t},
+	})
+	if nil != err {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	serveErrChan := make(chan error, 1)
+	go func() {
+		serveErrChan <- server.Serve(ctx)
+	}()
+
+	_ = testServerNFSPorts(t, server)
+
+	cancel()
+
+	err = <-serveErrChan
+	if context.Canceled != err {
+		t.Fatalf("Serve() returned %v... expected context.Canceled", err)
+	}
+
+	err = server.Shutdown(context.Background())
+	if nil != err {
+		t.Fatalf("Shutdown() of a closed server failed: %v", err)
+	}
+}
+
+func TestServerAnonIdentity(t *testing.T) {
+	server, err := NewServer(&ServerConfigStruct{
+		Listeners:      []ListenerConfigStruct{{Network: "tcp4", BindAddr: "127.0.0.1"}},
+		MountCallbacks: &testNullMountV3Struct{t: t},
+		NFSCallbacks:   &testNullNFSv3Struct{t: t},
+		AnonUIDSet:     true,
+	})
+	if nil != err {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+
+	// AnonUID of 0 is used as specified via AnonUIDSet whereas AnonGID of 0 reverts to DefaultAnonGID
+
+	if (0 != server.config.AnonUID) || (DefaultAnonGID != server.config.AnonGID) {
+		t.Fatalf("AUTH_NONE requests mapped to %v:%v... expected 0:%v", server.config.AnonUID, server.config.AnonGID, DefaultAnonGID)
+	}
+}
+
+func TestServerSuppliedListeners(t *testing.T) {
+	mountListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	packetConn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.ListenPacket() failed: %v", err)
+	}
+
+	_, err = NewServer(&ServerConfigStruct{
+		Listeners:      []ListenerConfigStruct{{Network: "tcp4", Listener: listener, PacketConn: packetConn}},
+		MountCallbacks: &testNullMountV3Struct{t: t},
+		NFSCallbacks:   &testNullNFSv3Struct{t: t},
+	})
+	if nil == err {
+		t.Fatalf("NewServer() supplied both a listener & a packet connection for NFSv3 should have failed")
+	}
+
+	server, err := NewServer(&ServerConfigStruct{
+		Listeners: []ListenerConfigStruct{
+			{MountListener: mountListener, Listener: listener},               // Network unused
+			{Network: "udp4", BindAddr: "127.0.0.1", PacketConn: packetConn}, // Mount V3 bound by the server
+		},
+		MountCallbacks: &testNullMountV3Struct{t: t},
+		NFSCallbacks:   &testNullNFSv3Struct{t: t},
+	})
+	if nil != err {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+
+	serveErrChan := make(chan error, 1)
+	go func() {
+		serveErrChan <- server.Serve(context.Background())
+	}()
+
+	nfsPorts := testServerNFSPorts(t, server)
+
+	if (addrPort(listener.Addr()) != nfsPorts[0]) || (addrPort(packetConn.LocalAddr()) != nfsPorts[1]) {
+		t.Fatalf("NFSv3 served on ports %v... expected those of the supplied listener & packet connection", nfsPorts)
+	}
+	if addrPort(mountListener.Addr()) != server.rpcServers[0].port {
+		t.Fatalf("Mount V3 served on port %v... expected that of the supplied listener", server.rpcServers[0].port)
+	}
+	if (onc.IPProtoUDP != server.rpcServers[2].prot) || (0 == server.rpcServers[2].port) {
+		t.Fatalf("Mount V3 of the second listener not bound by the server")
+	}
+
+	for _, network := range []string{"tcp4", "udp4"} {
+		var addr net.Addr = listener.Addr()
+		if "udp4" == network {
+			addr = packetConn.LocalAddr()
+		}
+
+		conn, err := net.Dial(network, addr.String())
+		if nil != err {
+			t.Fatalf("net.Dial(%s) failed: %v", network, err)
+		}
+
+		if onc.Success != testNullCall(t, conn, ("tcp4" == network)) {
+			t.Fatalf("NULL via supplied %s not successful", network)
+		}
+
+		_ = conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = server.Shutdown(ctx)
+	if nil != err {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	err = <-serveErrChan
+	if ErrServerClosed != err {
+		t.Fatalf("Serve() returned %v... expected ErrServerClosed", err)
+	}
+
+	_, err = mountListener.Accept()
+	if nil == err {
+		t.Fatalf("supplied listener should have been closed by Shutdown()")
+	}
+}
@@ -1,7 +1,129 @@
 package nfsd
 
+import (
+	"net"
+	"time"
+)
+
 // Mount V3 / NFSv3 API embedded structs
 
+type CredentialStruct struct { // flavor-agnostic identity of the requester supplied to each callback
+	Flavor      uint32   // enum auth_flavor
+	MachineName string   // only used/valid if Flavor == AuthSys
+	UID         uint32   // if Flavor == AuthNone (or an unmapped RPCSecGSS Principal), the anonymous UID
+	GID         uint32   // if Flavor == AuthNone (or an unmapped RPCSecGSS Principal), the anonymous GID
+	GIDs        []uint32 // if Flavor == AuthNone, empty
+	Principal   string   // only used/valid if Flavor == RPCSecGSS
+	Service     uint32   // enum rpc_gss_service_t - only used/valid if Flavor == RPCSecGSS
+}
+
+type RequestInfoStruct struct { // metadata of the request on whose behalf an NFSv3ContextInterface callback is invoked
+	XID         uint32            // transaction ID of the call (as retransmitted by the client)
+	RemoteAddr  net.Addr          // address of the client (nil if not known)
+	Prot        uint32            // either onc.IPProtoTCP or onc.IPProtoUDP
+	LocalPort   uint16            // port upon which the call was received (0 if not an IP port)
+	Credential  *CredentialStruct // the requester (following any identity mapping)
+	ExportPath  string            // path of the export to which the request's file handles belong ("" if no export table)
+	ReceiveTime time.Time         // when the call was received
+}
+
+type IdentityMappingStruct struct { // exports(5)-style rewriting of the CredentialStruct supplied to NFSv3 callbacks
+	RootSquash bool              // map requests from UID 0 to AnonUID (and GID 0, including in GIDs, to AnonGID)
+	AllSquash  bool              // map all requests to AnonUID & AnonGID (with empty GIDs)
+	AnonUID    uint32            // UID to which squashed requests are mapped (if 0, DefaultAnonUID unless AnonUIDSet)
+	AnonGID    uint32            // GID to which squashed requests are mapped (if 0, DefaultAnonGID unless AnonGIDSet)
+	AnonUIDSet bool              // if true, AnonUID is used even if 0 (e.g. as for anonuid=0)
+	AnonGIDSet bool              // if true, AnonGID is used even if 0 (e.g. as for anongid=0)
+	UIDMap     map[uint32]uint32 // client UID to server UID translations applied to requests not squashed (may be nil)
+	GIDMap     map[uint32]uint32 // client GID to server GID translations applied to requests not squashed (may be nil)
+}
+
+type ExportOptionsStruct struct { // exports(5)-style options applying to a client of an export
+	ReadOnly        bool                  // ro (vs. rw)
+	Secure          bool                  // secure (vs. insecure) - requests must originate from a privileged port
+	Sync            bool                  // sync (vs. async) - advisory (i.e. it is up to callbacks to honor it)
+	IdentityMapping IdentityMappingStruct // root_squash, all_squash, anonuid, & anongid (along with any uid/gid map table)
+	AuthFlavors     []uint32              // sec= (e.g. AuthSys, AuthKrb5, AuthKrb5I, AuthKrb5P) in order of preference
+}
+
+type ExportClientStruct struct { // a client specification (and its options) within an export
+	Pattern string              // "*", host name (optionally with * and ? wildcards), IP address, IP network (CIDR or address/netmask), or @netgroup
+	Options ExportOptionsStruct //
+}
+
+type ExportStruct struct { // an exports(5)-style entry
+	Path    string               // the exported directory (as supplied in MountProc3MntArgsStruct.DirPath)
+	Clients []ExportClientStruct // the first client specification matching a client applies
+}
+
+type ExportTableStruct struct { // the set of exports enforced via SetExportTable
+	Exports   []ExportStruct      //
+	Netgroups map[string][]string // netgroup name to member host names ("" matches any host) as returned by ParseNetgroups
+}
+
+type FileSystemAttrStruct struct { // attributes of an inode as returned by FileSystemInterface.GetAttr
+	Type       uint32          // enum ftype3
+	Mode       uint32          // permission bits (including setuid, setgid, & sticky)
+	NLink      uint32          //
+	UID        uint32          //
+	GID        uint32          //
+	Size       uint64          //
+	Used       uint64          // if 0, Size is reported
+	RDev       SpecData3Struct // only used/valid if Type == FTypeBLK || Type == FTypeCHR
+	Generation uint32          // distinguishes successive uses of an inode number (older file handles become stale)
+	ATime      time.Time       //
+	MTime      time.Time       //
+	CTime      time.Time       //
+}
+
+type FileSystemDirEntryStruct struct { // a directory entry as returned by FileSystemInterface.ReadDir
+	Ino    uint64 // inode number of the entry
+	Name   string //
+	Cookie uint64 // identifies the position following this entry (never 0)
+}
+
+type ListenerConfigStruct struct { // the addresses upon which a ServerStruct serves Mount V3 & NFSv3
+	Network         string         // one of "tcp" or "udp" (dual-stack), "tcp4" or "udp4" (IPv4 only), or "tcp6" or "udp6" (IPv6 only)
+	BindAddr        string         // IP address upon which to listen ("" for all addresses)
+	MountPort       uint16         // port upon which to serve Mount V3 (if 0, a port is chosen by the system)
+	NFSPort         uint16         // port upon which to serve NFSv3 (e.g. 2049; if 0, a port is chosen by the system)
+	MountListener   net.Listener   // if non-nil, Mount V3 is served via its connections (rather than Network, BindAddr, & MountPort)
+	MountPacketConn net.PacketConn // if non-nil, Mount V3 is served via its datagrams (rather than Network, BindAddr, & MountPort)
+	Listener        net.Listener   // if non-nil, NFSv3 is served via its connections (rather than Network, BindAddr, & NFSPort)
+	PacketConn      net.PacketConn // if non-nil, NFSv3 is served via its datagrams (rather than Network, BindAddr, & NFSPort)
+}
+
+type ServerConfigStruct struct { // the configuration of a ServerStruct (see NewServer)
+	Listeners           []ListenerConfigStruct // each specifies a Mount V3 & an NFSv3 listener
+	Publish             bool                   // if true, each listener is registered with rpcbind (versions 4 & 3)
+	MountCallbacks      MountV3Interface       //
+	NFSCallbacks        NFSv3Interface         // ignored if NFSContextCallbacks != nil
+	NFSContextCallbacks NFSv3BackendInterface  // if nil, NFSCallbacks are invoked (see NewNFSv3ContextAdapter); may be any NFSv3ContextInterface
+	AnonUID             uint32                 // UID to which AUTH_NONE requests are mapped (if 0, DefaultAnonUID unless AnonUIDSet)
+	AnonGID             uint32                 // GID to which AUTH_NONE requests are mapped (if 0, DefaultAnonGID unless AnonGIDSet)
+	AnonUIDSet          bool                   // if true, AnonUID is used even if 0
+	AnonGIDSet          bool                   // if true, AnonGID is used even if 0
+	IdentityMapping     *IdentityMappingStruct // if nil, credentials are supplied to callbacks as received; ignored if ExportTable != nil
+	ExportTable         *ExportTableStruct     // if nil, all requests are admitted (see SetExportTable)
+	ReadOnly            bool                   // if true, all file systems are served read-only (see SetReadOnly)
+	MountTable          bool                   // if true, MNT requests are tracked (see EnableMountTable)
+	RmtabPath           string                 // only used/valid if MountTable == true
+	GSSMechanism        GSSMechanismInterface  // if nil, RPCSEC_GSS credentials are rejected (see EnableRPCSecGSS)
+	GSSPrincipalMapper  GSSPrincipalMapper     // only used/valid if GSSMechanism != nil
+	ShutdownJukebox     bool                   // if true, NFSv3 requests received during Shutdown are answered with NFS3ErrJUKEBOX
+	DRC                 bool                   // if true, retransmitted calls are answered from a duplicate request cache (see EnableDuplicateRequestCache)
+	DRCMaxEntries       int                    // only used/valid if DRC == true (if 0, DefaultDRCMaxEntries)
+	DRCMaxAge           time.Duration          // only used/valid if DRC == true (if 0, DefaultDRCMaxAge)
+}
+
+type DRCStatsStruct struct { // the counters of a duplicate request cache
+	Hits       uint64 // retransmissions answered with the cached reply
+	Misses     uint64 // calls executed (& their replies cached)
+	InProgress uint64 // retransmissions dropped as the original call was still in progress
+	Evictions  uint64 // entries removed to enforce the size & age limits
+	Entries    int    // entries currently cached
+}
+
 type SpecData3Struct struct { // struct specdata3
 	SpecData1 uint32 `XDR_Name:"Unsigned Integer"` // should be == 0
 	SpecData2 uint32 `XDR_Name:"Unsigned Integer"` // should be == 0
@@ -144,13 +266,31 @@ type MountProc3MntArgsStruct struct {
 type MountProc3MntResultsStruct struct { // union mountres3
 	Status      uint32   `XDR_Name:"Enumeration"`                                  // OK or enum mountstat3
 	FHandle     []byte   `XDR_Name:"Variable-Length Opaque Data" XDR_MaxSize:"64"` // only used/valid if Status == OK
-	AuthFlavors []uint32 `XDR_Name:"Variable-Length Array"`                        // only used/valid if Status == OK; enum auth_flavor; must == AuthSys
+	AuthFlavors []uint32 `XDR_Name:"Variable-Length Array"`                        // only used/valid if Status == OK; enum auth_flavor; in order of preference
 }
 
 type MountProc3UmntArgsStruct struct {
 	DirPath string `XDR_Name:"String" XDR_MaxSize:"1024"`
 }
 
+type MountBodyStruct struct { // struct mountbody
+	HostName  string // name
+	Directory string // dirpath
+}
+
+type MountProc3DumpResultsStruct struct { // mountlist
+	MountList []MountBodyStruct
+}
+
+type ExportNodeStruct struct { // struct exportnode
+	Dir    string   // dirpath
+	Groups []string // groups (i.e. list of name)
+}
+
+type MountProc3ExportResultsStruct struct { // exports
+	Exports []ExportNodeStruct
+}
+
 // NFSv3 API call/reply structs
 
 type NFSProc3GetAttrArgsStruct struct {
@@ -370,6 +510,7 @@ type NFSProc3FSInfoResultsStruct struct {
 	RTPref        uint32           // only used/valid if Status == OK
 	RTMult        uint32           // only used/valid if Status == OK
 	WTMax         uint32           // only used/valid if Status == OK
+	WTPref        uint32           // only used/valid if Status == OK
 	WTMult        uint32           // only used/valid if Status == OK
 	DTPref        uint32           // only used/valid if Status == OK
 	MaxFileSize   uint64           // only used/valid if Status == OK
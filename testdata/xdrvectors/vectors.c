@@ -0,0 +1,114 @@
+/*
+ * Prints the XDR encoding (as produced by libtirpc from the rpcgen output for nfs3.x) of the post_op_attr,
+ * pre_op_attr, post_op_fh3, wcc_data, sattr3, sattrguard3, & createhow3 values checked by union_test.go.
+ *
+ *   rpcgen -h nfs3.x -o nfs3.h && rpcgen -c nfs3.x -o nfs3_xdr.c
+ *   cc -I/usr/include/tirpc -o vectors vectors.c nfs3_xdr.c -ltirpc && ./vectors
+ */
+
+#include <stdio.h>
+#include <string.h>
+#include <rpc/rpc.h>
+
+#include "nfs3.h"
+
+static void emit(const char *name, xdrproc_t proc, void *obj)
+{
+	char buf[1024];
+	XDR xdrs;
+	u_int i, len;
+
+	xdrmem_create(&xdrs, buf, sizeof(buf), XDR_ENCODE);
+	if (!proc(&xdrs, obj)) {
+		printf("%-40s encode failed\n", name);
+		return;
+	}
+	len = xdr_getpos(&xdrs);
+	printf("%-40s", name);
+	for (i = 0; i < len; i++)
+		printf("%s%02x", (0 == (i % 4)) ? " " : "", (unsigned char)buf[i]);
+	printf("\n");
+	xdr_destroy(&xdrs);
+}
+
+int main(void)
+{
+	fattr3 fattr = {
+		.type = NF3REG, .mode = 0644, .nlink = 1, .uid = 1000, .gid = 1000,
+		.size = 4096, .used = 4096, .fsid = 0xfd01, .fileid = 42,
+		.atime = {0x5f5e1000, 1}, .mtime = {0x5f5e1000, 2}, .ctime = {0x5f5e1000, 3},
+	};
+	wcc_attr wccattr = {.size = 4096, .mtime = {0x5f5e1000, 2}, .ctime = {0x5f5e1000, 3}};
+	char fh[] = {0x01, 0x00, 0x07, 0x00, 0x2a};
+	post_op_attr poa;
+	pre_op_attr pra;
+	post_op_fh3 pof;
+	wcc_data wcc;
+	sattr3 sattr;
+	sattrguard3 guard;
+	createhow3 how;
+
+	memset(&poa, 0, sizeof(poa));
+	emit("post_op_attr (absent)", (xdrproc_t)xdr_post_op_attr, &poa);
+	poa.attributes_follow = TRUE;
+	poa.post_op_attr_u.attributes = fattr;
+	emit("post_op_attr (present)", (xdrproc_t)xdr_post_op_attr, &poa);
+
+	memset(&pra, 0, sizeof(pra));
+	emit("pre_op_attr (absent)", (xdrproc_t)xdr_pre_op_attr, &pra);
+	pra.attributes_follow = TRUE;
+	pra.pre_op_attr_u.attributes = wccattr;
+	emit("pre_op_attr (present)", (xdrproc_t)xdr_pre_op_attr, &pra);
+
+	memset(&pof, 0, sizeof(pof));
+	emit("post_op_fh3 (absent)", (xdrproc_t)xdr_post_op_fh3, &pof);
+	pof.handle_follows = TRUE;
+	pof.post_op_fh3_u.handle.data.data_len = sizeof(fh);
+	pof.post_op_fh3_u.handle.data.data_val = fh;
+	emit("post_op_fh3 (present, padded)", (xdrproc_t)xdr_post_op_fh3, &pof);
+
+	memset(&wcc, 0, sizeof(wcc));
+	emit("wcc_data (neither)", (xdrproc_t)xdr_wcc_data, &wcc);
+	wcc.before = pra;
+	wcc.after = poa;
+	emit("wcc_data (both)", (xdrproc_t)xdr_wcc_data, &wcc);
+
+	memset(&sattr, 0, sizeof(sattr));
+	sattr.mode.set_it = TRUE;
+	sattr.mode.set_mode3_u.mode = 0644;
+	sattr.atime.set_it = SET_TO_SERVER_TIME;
+	emit("sattr3 (chmod 0644, atime to server time)", (xdrproc_t)xdr_sattr3, &sattr);
+	sattr.mode.set_mode3_u.mode = 0755;
+	sattr.uid.set_it = TRUE;
+	sattr.uid.set_uid3_u.uid = 1000;
+	sattr.gid.set_it = TRUE;
+	sattr.gid.set_gid3_u.gid = 100;
+	sattr.size.set_it = TRUE;
+	sattr.size.set_size3_u.size = 0x100000000ULL;
+	sattr.atime.set_it = SET_TO_CLIENT_TIME;
+	sattr.atime.set_atime_u.atime.seconds = 1;
+	sattr.atime.set_atime_u.atime.nseconds = 2;
+	sattr.mtime.set_it = SET_TO_CLIENT_TIME;
+	sattr.mtime.set_mtime_u.mtime.seconds = 3;
+	sattr.mtime.set_mtime_u.mtime.nseconds = 4;
+	emit("sattr3 (everything set)", (xdrproc_t)xdr_sattr3, &sattr);
+
+	memset(&guard, 0, sizeof(guard));
+	emit("sattrguard3 (no check)", (xdrproc_t)xdr_sattrguard3, &guard);
+	guard.check = TRUE;
+	guard.sattrguard3_u.obj_ctime.seconds = 0x5f5e1000;
+	guard.sattrguard3_u.obj_ctime.nseconds = 3;
+	emit("sattrguard3 (check ctime)", (xdrproc_t)xdr_sattrguard3, &guard);
+
+	memset(&how, 0, sizeof(how));
+	how.mode = GUARDED;
+	how.createhow3_u.obj_attributes.mode.set_it = TRUE;
+	how.createhow3_u.obj_attributes.mode.set_mode3_u.mode = 0600;
+	emit("createhow3 (GUARDED, mode 0600)", (xdrproc_t)xdr_createhow3, &how);
+	memset(&how, 0, sizeof(how));
+	how.mode = EXCLUSIVE;
+	memcpy(how.createhow3_u.verf, "\xde\xad\xbe\xef\x00\x00\x30\x39", 8);
+	emit("createhow3 (EXCLUSIVE)", (xdrproc_t)xdr_createhow3, &how);
+
+	return 0;
+}
@@ -0,0 +1,163 @@
+package nfsd
+
+// XDRUnionInterface is satisfied by those structs in structs.go that model an XDR discriminated union (or
+// embed one) and hence cannot be handled by xdr.Pack() and xdr.Unpack() directly. Pack() returns the XDR
+// encoding of the struct while Unpack() decodes the struct from the start of buf in the same manner as xdr.Unpack().
+type XDRUnionInterface interface {
+	Pack() (buf []byte, err error)
+	Unpack(buf []byte) (bytesConsumed uint64, err error)
+}
+
+func (sAttr3 *SAttr3Struct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packSAttr3(sAttr3)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (sAttr3 *SAttr3Struct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackSAttr3(sAttr3)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (sAttrGuard3 *SAttrGuard3Struct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packSAttrGuard3(sAttrGuard3)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (sAttrGuard3 *SAttrGuard3Struct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackSAttrGuard3(sAttrGuard3)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (preOpAttr *PreOpAttrStruct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packPreOpAttr(preOpAttr)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (preOpAttr *PreOpAttrStruct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackPreOpAttr(preOpAttr)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (postOpAttr *PostOpAttrStruct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packPostOpAttr(postOpAttr)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (postOpAttr *PostOpAttrStruct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackPostOpAttr(postOpAttr)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (postOpFh3 *PostOpFh3Struct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packPostOpFh3(postOpFh3)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (postOpFh3 *PostOpFh3Struct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackPostOpFh3(postOpFh3)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (wccData *WCCDataStruct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packWCCData(wccData)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (wccData *WCCDataStruct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackWCCData(wccData)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (createHow *CreateHowStruct) Pack() (buf []byte, err error) {
+	var (
+		packer packerStruct
+	)
+
+	packer.packCreateHow(createHow)
+
+	buf, err = packer.buf, packer.err
+	return
+}
+
+func (createHow *CreateHowStruct) Unpack(buf []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: buf}
+	)
+
+	unpacker.unpackCreateHow(createHow)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
@@ -0,0 +1,246 @@
+package nfsd
+
+import (
+	"fmt"
+
+	"github.com/swiftstack/xdr"
+)
+
+// unpackerStruct consumes the XDR encoding of a sequence of items, remembering only the first error encountered
+type unpackerStruct struct {
+	buf           []byte
+	bytesConsumed uint64
+	err           error
+}
+
+func (unpacker *unpackerStruct) unpack(obj interface{}) {
+	var (
+		bytesConsumed uint64
+	)
+
+	if nil != unpacker.err {
+		return
+	}
+
+	bytesConsumed, unpacker.err = xdr.Unpack(unpacker.buf[unpacker.bytesConsumed:], obj)
+	if nil != unpacker.err {
+		return
+	}
+
+	unpacker.bytesConsumed += bytesConsumed
+}
+
+func (unpacker *unpackerStruct) unpackBool() (b bool) {
+	var (
+		booleanOnly BooleanOnlyStruct
+	)
+
+	unpacker.unpack(&booleanOnly)
+
+	b = booleanOnly.Bool
+	return
+}
+
+func (unpacker *unpackerStruct) unpackUint32() (u32 uint32) {
+	var (
+		unsignedIntegerOnly UnsignedIntegerOnlyStruct
+	)
+
+	unpacker.unpack(&unsignedIntegerOnly)
+
+	u32 = unsignedIntegerOnly.UnsignedInteger
+	return
+}
+
+func (unpacker *unpackerStruct) unpackUint64() (u64 uint64) {
+	var (
+		unsignedHyperIntegerOnly UnsignedHyperIntegerOnlyStruct
+	)
+
+	unpacker.unpack(&unsignedHyperIntegerOnly)
+
+	u64 = unsignedHyperIntegerOnly.UnsignedHyperInteger
+	return
+}
+
+func (unpacker *unpackerStruct) unpackOpaque() (b []byte) {
+	var (
+		variableLengthOpaqueDataOnly VariableLengthOpaqueDataOnlyStruct
+	)
+
+	unpacker.unpack(&variableLengthOpaqueDataOnly)
+
+	b = variableLengthOpaqueDataOnly.VariableLengthOpaqueData
+	return
+}
+
+func (unpacker *unpackerStruct) unpackFHandle() (fHandle []byte) {
+	fHandle = unpacker.unpackOpaque()
+	if nil != unpacker.err {
+		return
+	}
+
+	if FHSize3 < uint32(len(fHandle)) {
+		unpacker.err = fmt.Errorf("file handle length (%v) exceeds FHSize3 (%v)", len(fHandle), FHSize3)
+	}
+
+	return
+}
+
+func (unpacker *unpackerStruct) unpackPreOpAttr(preOpAttr *PreOpAttrStruct) {
+	preOpAttr.AttributesFollow = unpacker.unpackBool()
+	if preOpAttr.AttributesFollow {
+		unpacker.unpack(&preOpAttr.Attributes)
+	}
+}
+
+func (unpacker *unpackerStruct) unpackPostOpAttr(postOpAttr *PostOpAttrStruct) {
+	postOpAttr.AttributesFollow = unpacker.unpackBool()
+	if postOpAttr.AttributesFollow {
+		unpacker.unpack(&postOpAttr.Attributes)
+	}
+}
+
+func (unpacker *unpackerStruct) unpackPostOpFh3(postOpFh3 *PostOpFh3Struct) {
+	postOpFh3.HandleFollows = unpacker.unpackBool()
+	if postOpFh3.HandleFollows {
+		postOpFh3.Handle = unpacker.unpackFHandle()
+	}
+}
+
+func (unpacker *unpackerStruct) unpackWCCData(wccData *WCCDataStruct) {
+	unpacker.unpackPreOpAttr(&wccData.Before)
+	unpacker.unpackPostOpAttr(&wccData.After)
+}
+
+func (unpacker *unpackerStruct) unpackTimeHow() (timeHow uint32) {
+	timeHow = unpacker.unpackUint32()
+	if nil != unpacker.err {
+		return
+	}
+
+	switch timeHow {
+	case DontChange:
+	case SetToServerTime:
+	case SetToClientTime:
+	default:
+		unpacker.err = fmt.Errorf("time_how (%v) not recognized", timeHow)
+	}
+
+	return
+}
+
+func (unpacker *unpackerStruct) unpackSAttr3(sAttr3 *SAttr3Struct) {
+	sAttr3.SetMode = unpacker.unpackBool()
+	if sAttr3.SetMode {
+		sAttr3.Mode = unpacker.unpackUint32()
+	}
+
+	sAttr3.SetUID = unpacker.unpackBool()
+	if sAttr3.SetUID {
+		sAttr3.UID = unpacker.unpackUint32()
+	}
+
+	sAttr3.SetGID = unpacker.unpackBool()
+	if sAttr3.SetGID {
+		sAttr3.GID = unpacker.unpackUint32()
+	}
+
+	sAttr3.SetSize = unpacker.unpackBool()
+	if sAttr3.SetSize {
+		sAttr3.Size = unpacker.unpackUint64()
+	}
+
+	sAttr3.SetATime = unpacker.unpackTimeHow()
+	if SetToClientTime == sAttr3.SetATime {
+		unpacker.unpack(&sAttr3.ATime)
+	}
+
+	sAttr3.SetMTime = unpacker.unpackTimeHow()
+	if SetToClientTime == sAttr3.SetMTime {
+		unpacker.unpack(&sAttr3.MTime)
+	}
+}
+
+func (unpacker *unpackerStruct) unpackSAttrGuard3(sAttrGuard3 *SAttrGuard3Struct) {
+	sAttrGuard3.CheckCTime = unpacker.unpackBool()
+	if sAttrGuard3.CheckCTime {
+		unpacker.unpack(&sAttrGuard3.CTime)
+	}
+}
+
+func (unpacker *unpackerStruct) unpackCreateHow(createHow *CreateHowStruct) {
+	var (
+		createVerfOnly CreateVerfOnlyStruct
+	)
+
+	createHow.Mode = unpacker.unpackUint32()
+	if nil != unpacker.err {
+		return
+	}
+
+	switch createHow.Mode {
+	case Unchecked:
+		unpacker.unpackSAttr3(&createHow.ObjAttributes)
+	case Guarded:
+		unpacker.unpackSAttr3(&createHow.ObjAttributes)
+	case Exclusive:
+		unpacker.unpack(&createVerfOnly)
+		createHow.Verf = createVerfOnly.Verf
+	default:
+		unpacker.err = fmt.Errorf("createmode3 (%v) not recognized", createHow.Mode)
+	}
+}
+
+// The following unpack() methods decode those NFSv3 procedure arguments containing discriminated unions
+// that xdr.Unpack() cannot express, returning bytesConsumed in the same manner as xdr.Unpack()
+
+func (nfsProc3SetAttrArgs *NFSProc3SetAttrArgsStruct) unpack(parms []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: parms}
+	)
+
+	nfsProc3SetAttrArgs.Object = unpacker.unpackFHandle()
+	unpacker.unpackSAttr3(&nfsProc3SetAttrArgs.NewAttributes)
+	unpacker.unpackSAttrGuard3(&nfsProc3SetAttrArgs.Guard)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (nfsProc3CreateArgs *NFSProc3CreateArgsStruct) unpack(parms []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: parms}
+	)
+
+	unpacker.unpack(&nfsProc3CreateArgs.Where)
+	unpacker.unpackCreateHow(&nfsProc3CreateArgs.How)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (nfsProc3MKDirArgs *NFSProc3MKDirArgsStruct) unpack(parms []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: parms}
+	)
+
+	unpacker.unpack(&nfsProc3MKDirArgs.Where)
+	unpacker.unpackSAttr3(&nfsProc3MKDirArgs.Attributes)
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
+
+func (nfsProc3SymLinkArgs *NFSProc3SymLinkArgsStruct) unpack(parms []byte) (bytesConsumed uint64, err error) {
+	var (
+		unpacker = unpackerStruct{buf: parms}
+	)
+
+	unpacker.unpack(&nfsProc3SymLinkArgs.Where)
+	unpacker.unpackSAttr3(&nfsProc3SymLinkArgs.SymLinkAttributes)
+	nfsProc3SymLinkArgs.SymLinkData = unpacker.unpackOpaque() // nfspath3 is a string<> which shares the opaque<> encoding
+
+	bytesConsumed, err = unpacker.bytesConsumed, unpacker.err
+	return
+}
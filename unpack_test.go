@@ -0,0 +1,154 @@
+package nfsd
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// sattr3 setting every attribute (mode=0755 uid=1000 gid=100 size=4096, atime from the client, mtime from the server)
+const sattr3AllHex = "00000001 000001ed 00000001 000003e8 00000001 00000064 00000001 00000000 00001000" +
+	" 00000002 5f5e1000 00000001" + // SET_TO_CLIENT_TIME
+	" 00000001" //                    SET_TO_SERVER_TIME
+
+var sattr3All = SAttr3Struct{
+	SetMode:  true,
+	Mode:     0755,
+	SetUID:   true,
+	UID:      1000,
+	SetGID:   true,
+	GID:      100,
+	SetSize:  true,
+	Size:     4096,
+	SetATime: SetToClientTime,
+	ATime:    NFSTime3Struct{Seconds: 0x5f5e1000, NSeconds: 1},
+	SetMTime: SetToServerTime,
+}
+
+func TestUnpackSAttr3(t *testing.T) {
+	var (
+		sAttr3   SAttr3Struct
+		unpacker unpackerStruct
+	)
+
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000000 00000000 00000000 00000000 00000000 00000000")}
+	unpacker.unpackSAttr3(&sAttr3)
+	if nil != unpacker.err {
+		t.Fatalf("unpackSAttr3() of empty sattr3 failed: %v", unpacker.err)
+	}
+	if (24 != unpacker.bytesConsumed) || !reflect.DeepEqual(SAttr3Struct{}, sAttr3) {
+		t.Fatalf("unpackSAttr3() of empty sattr3 consumed %v bytes returning %+v", unpacker.bytesConsumed, sAttr3)
+	}
+
+	unpacker = unpackerStruct{buf: wireBytes(t, sattr3AllHex+" deadbeef")}
+	unpacker.unpackSAttr3(&sAttr3)
+	if nil != unpacker.err {
+		t.Fatalf("unpackSAttr3() failed: %v", unpacker.err)
+	}
+	if (uint64(len(wireBytes(t, sattr3AllHex))) != unpacker.bytesConsumed) || !reflect.DeepEqual(sattr3All, sAttr3) {
+		t.Fatalf("unpackSAttr3() consumed %v bytes returning %+v", unpacker.bytesConsumed, sAttr3)
+	}
+
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000000 00000000 00000000 00000000 00000003 00000000")}
+	unpacker.unpackSAttr3(&sAttr3)
+	if nil == unpacker.err {
+		t.Fatalf("unpackSAttr3() of unrecognized time_how should have failed")
+	}
+
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000001 000001ed 00000000")}
+	unpacker.unpackSAttr3(&sAttr3)
+	if nil == unpacker.err {
+		t.Fatalf("unpackSAttr3() of truncated sattr3 should have failed")
+	}
+}
+
+func TestUnpackCreateHow(t *testing.T) {
+	var (
+		createHow CreateHowStruct
+		unpacker  unpackerStruct
+	)
+
+	for _, mode := range []uint32{Unchecked, Guarded} {
+		createHow = CreateHowStruct{}
+		unpacker = unpackerStruct{buf: wireBytes(t, fmt.Sprintf("%08x ", mode)+sattr3AllHex)}
+		unpacker.unpackCreateHow(&createHow)
+		if nil != unpacker.err {
+			t.Fatalf("unpackCreateHow() of createmode3 %v failed: %v", mode, unpacker.err)
+		}
+		if !reflect.DeepEqual(CreateHowStruct{Mode: mode, ObjAttributes: sattr3All}, createHow) {
+			t.Fatalf("unpackCreateHow() of createmode3 %v returned %+v", mode, createHow)
+		}
+	}
+
+	createHow = CreateHowStruct{}
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000002 01020304 05060708")}
+	unpacker.unpackCreateHow(&createHow)
+	if nil != unpacker.err {
+		t.Fatalf("unpackCreateHow() of EXCLUSIVE failed: %v", unpacker.err)
+	}
+	if (12 != unpacker.bytesConsumed) || !reflect.DeepEqual(CreateHowStruct{Mode: Exclusive, Verf: [NFS3CreateVerfSize]byte{1, 2, 3, 4, 5, 6, 7, 8}}, createHow) {
+		t.Fatalf("unpackCreateHow() of EXCLUSIVE consumed %v bytes returning %+v", unpacker.bytesConsumed, createHow)
+	}
+
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000003 00000000")}
+	unpacker.unpackCreateHow(&createHow)
+	if nil == unpacker.err {
+		t.Fatalf("unpackCreateHow() of unrecognized createmode3 should have failed")
+	}
+
+	unpacker = unpackerStruct{buf: wireBytes(t, "00000002 01020304")}
+	unpacker.unpackCreateHow(&createHow)
+	if nil == unpacker.err {
+		t.Fatalf("unpackCreateHow() of truncated createverf3 should have failed")
+	}
+}
+
+func TestNFSArgsUnpack(t *testing.T) {
+	var (
+		bytesConsumed       uint64
+		err                 error
+		nfsProc3CreateArgs  NFSProc3CreateArgsStruct
+		nfsProc3MKDirArgs   NFSProc3MKDirArgsStruct
+		nfsProc3SetAttrArgs NFSProc3SetAttrArgsStruct
+		nfsProc3SymLinkArgs NFSProc3SymLinkArgsStruct
+		where               = DirOpArgs3Struct{Dir: []byte{0x01, 0x02, 0x03}, Name: "name"}
+		whereHex            = "00000003 01020300 00000004 6e616d65"
+	)
+
+	bytesConsumed, err = nfsProc3SetAttrArgs.unpack(wireBytes(t, "00000003 01020300 "+sattr3AllHex+" 00000001 5f5e1000 00000003"))
+	if nil != err {
+		t.Fatalf("NFSProc3SetAttrArgsStruct.unpack() failed: %v", err)
+	}
+	if (uint64(len(wireBytes(t, "00000003 01020300 "+sattr3AllHex+" 00000001 5f5e1000 00000003"))) != bytesConsumed) ||
+		!reflect.DeepEqual(NFSProc3SetAttrArgsStruct{
+			Object:        []byte{0x01, 0x02, 0x03},
+			NewAttributes: sattr3All,
+			Guard:         SAttrGuard3Struct{CheckCTime: true, CTime: NFSTime3Struct{Seconds: 0x5f5e1000, NSeconds: 3}},
+		}, nfsProc3SetAttrArgs) {
+		t.Fatalf("NFSProc3SetAttrArgsStruct.unpack() consumed %v bytes returning %+v", bytesConsumed, nfsProc3SetAttrArgs)
+	}
+
+	_, err = nfsProc3CreateArgs.unpack(wireBytes(t, whereHex+" 00000002 01020304 05060708"))
+	if nil != err {
+		t.Fatalf("NFSProc3CreateArgsStruct.unpack() failed: %v", err)
+	}
+	if !reflect.DeepEqual(NFSProc3CreateArgsStruct{Where: where, How: CreateHowStruct{Mode: Exclusive, Verf: [NFS3CreateVerfSize]byte{1, 2, 3, 4, 5, 6, 7, 8}}}, nfsProc3CreateArgs) {
+		t.Fatalf("NFSProc3CreateArgsStruct.unpack() returned %+v", nfsProc3CreateArgs)
+	}
+
+	_, err = nfsProc3MKDirArgs.unpack(wireBytes(t, whereHex+" "+sattr3AllHex))
+	if nil != err {
+		t.Fatalf("NFSProc3MKDirArgsStruct.unpack() failed: %v", err)
+	}
+	if !reflect.DeepEqual(NFSProc3MKDirArgsStruct{Where: where, Attributes: sattr3All}, nfsProc3MKDirArgs) {
+		t.Fatalf("NFSProc3MKDirArgsStruct.unpack() returned %+v", nfsProc3MKDirArgs)
+	}
+
+	_, err = nfsProc3SymLinkArgs.unpack(wireBytes(t, whereHex+" 00000000 00000000 00000000 00000000 00000000 00000000 00000005 2e2e2f78 79000000"))
+	if nil != err {
+		t.Fatalf("NFSProc3SymLinkArgsStruct.unpack() failed: %v", err)
+	}
+	if !reflect.DeepEqual(NFSProc3SymLinkArgsStruct{Where: where, SymLinkData: []byte("../xy")}, nfsProc3SymLinkArgs) {
+		t.Fatalf("NFSProc3SymLinkArgsStruct.unpack() returned %+v", nfsProc3SymLinkArgs)
+	}
+}